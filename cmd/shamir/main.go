@@ -0,0 +1,156 @@
+// Command shamir splits a private key into verifiable Shamir shares and
+// recombines them.
+//
+//	shamir split -curve Gost341012512paramSetA -t 3 -n 5 -out shares/ < key.hex
+//	shamir verify shares/share-1.txt
+//	shamir combine shares/share-1.txt shares/share-3.txt shares/share-4.txt
+//
+// The private key is read from standard input as hex. Shares are written one
+// per line, or to share-<i>.txt in the -out directory, in the text format of
+// vss.KeyShare; each contains the Feldman commitments, so any holder can
+// check a share and see which public key it belongs to.
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	_ "github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/gost"
+	_ "github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/nist"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/vss"
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, `usage:
+  shamir split -curve NAME -t T -n N [-out DIR] < key.hex
+  shamir verify SHARE_FILE...
+  shamir combine SHARE_FILE...
+
+curves: %s
+`, strings.Join(ecgeneric.RegisteredCurves(), ", "))
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	var err error
+	switch os.Args[1] {
+	case "split":
+		err = split(os.Args[2:])
+	case "verify":
+		err = verify(os.Args[2:])
+	case "combine":
+		err = combine(os.Args[2:])
+	default:
+		usage()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "shamir:", err)
+		os.Exit(1)
+	}
+}
+
+func split(args []string) error {
+	fs := flag.NewFlagSet("split", flag.ExitOnError)
+	curveName := fs.String("curve", "Gost341012512paramSetA", "curve of the key")
+	threshold := fs.Int("t", 2, "number of shares needed to recover the key")
+	n := fs.Int("n", 3, "number of shares")
+	out := fs.String("out", "", "directory to write share-<i>.txt files to instead of standard output")
+	fs.Parse(args)
+
+	curve, ok := ecgeneric.CurveByName(*curveName)
+	if !ok {
+		return fmt.Errorf("unknown curve %q", *curveName)
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return errors.New("no private key on standard input")
+	}
+	d, ok := new(big.Int).SetString(strings.TrimPrefix(strings.TrimSpace(line), "0x"), 16)
+	if !ok {
+		return errors.New("private key is not hex")
+	}
+	x, y := curve.ScalarBaseMultGLV(d.Bytes())
+	priv := &ecgeneric.PrivateKey{PublicKey: ecgeneric.PublicKey{Curve: curve, X: x, Y: y}, D: d}
+
+	shares, err := vss.SplitKey(rand.Reader, priv, *threshold, *n)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "public key: %x\n", ecgeneric.Marshal(curve, x, y))
+	for _, ks := range shares {
+		if *out == "" {
+			fmt.Println(ks)
+			continue
+		}
+		name := filepath.Join(*out, fmt.Sprintf("share-%d.txt", ks.Share.Index))
+		if err := os.WriteFile(name, []byte(ks.String()+"\n"), 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readShares(files []string) ([]*vss.KeyShare, error) {
+	if len(files) == 0 {
+		return nil, errors.New("no share files given")
+	}
+	shares := make([]*vss.KeyShare, len(files))
+	for i, name := range files {
+		b, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		if shares[i], err = vss.ParseKeyShare(strings.TrimSpace(string(b))); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return shares, nil
+}
+
+func verify(files []string) error {
+	shares, err := readShares(files)
+	if err != nil {
+		return err
+	}
+	failed := false
+	for i, ks := range shares {
+		pub := ks.PublicKey()
+		status := "ok"
+		if err := ks.Verify(); err != nil {
+			status, failed = err.Error(), true
+		}
+		fmt.Printf("%s: share %d, threshold %d, %s key %x: %s\n", files[i], ks.Share.Index, ks.Threshold(), ks.Curve.Name, ecgeneric.Marshal(ks.Curve, pub.X, pub.Y), status)
+	}
+	if failed {
+		return errors.New("some shares are invalid")
+	}
+	return nil
+}
+
+func combine(files []string) error {
+	shares, err := readShares(files)
+	if err != nil {
+		return err
+	}
+	priv, err := vss.CombineKey(shares)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "public key: %x\n", ecgeneric.Marshal(priv.Curve, priv.X, priv.Y))
+	b := make([]byte, (priv.Params().N.BitLen()+7)/8)
+	priv.D.FillBytes(b)
+	fmt.Println(hex.EncodeToString(b))
+	return nil
+}
@@ -0,0 +1,133 @@
+// Command softtoken is a software token: it decrypts the keys of a keystore
+// directory and signs with them for other processes over a socket, so that
+// the keys never leave it.
+//
+//	softtoken -keystore keys/ -passfile pass.txt -pinfile pin.txt -listen /run/user/1000/token.sock
+//
+// Every key in the directory must decrypt with the passphrase. Keys are
+// served under their keystore ids, signing with ECDSA on secp256k1 and
+// P-256 and with GOST R 34.10 on other curves unless -mechanism is given.
+// Clients connect with keybackend.Dial; the protocol is described in the
+// keybackend package. The token listens only on a unix socket that only its
+// owner can open, since the protocol sends the PIN in the clear. Three
+// wrong PINs in a row lock the PIN until softtoken is restarted.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	_ "github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/gost"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/keybackend"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/keystore"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/nist"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "softtoken:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	dir := flag.String("keystore", "", "keystore directory")
+	passFile := flag.String("passfile", "", "file holding the passphrase of the keys")
+	pinFile := flag.String("pinfile", "", "file holding the PIN clients log in with; no PIN if empty")
+	addr := flag.String("listen", "softtoken.sock", "unix socket path to listen on")
+	mech := flag.String("mechanism", "", "signature mechanism for all keys, gost or ecdsa")
+	flag.Parse()
+
+	if *dir == "" || *passFile == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	passphrase, err := readSecret(*passFile)
+	if err != nil {
+		return err
+	}
+	pin := ""
+	if *pinFile != "" {
+		if pin, err = readSecret(*pinFile); err != nil {
+			return err
+		}
+		if strings.ContainsAny(pin, " \t") {
+			return errors.New("PIN must not contain spaces")
+		}
+	}
+
+	store, err := keystore.Open(*dir, nil)
+	if err != nil {
+		return err
+	}
+	entries, err := store.List()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("no keys in %s", *dir)
+	}
+	token := keybackend.NewToken(pin)
+	for _, e := range entries {
+		m, err := mechanism(*mech, e.Curve)
+		if err != nil {
+			return err
+		}
+		k, err := keybackend.OpenKeystore(e.Path, passphrase, m)
+		if err != nil {
+			return fmt.Errorf("%s: %w", e.Path, err)
+		}
+		if err := token.Add(e.ID, k); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "key %s: %s, %v\n", e.ID, e.Curve, m)
+	}
+
+	// The socket takes its mode from the umask when it is created, so it
+	// is never open to other users, not even briefly.
+	umask := syscall.Umask(0177)
+	l, err := net.Listen("unix", *addr)
+	syscall.Umask(umask)
+	if err != nil {
+		return err
+	}
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sig
+		l.Close()
+	}()
+	fmt.Fprintf(os.Stderr, "listening on %s\n", l.Addr())
+	if err := token.Serve(l); err != nil && !errors.Is(err, net.ErrClosed) {
+		return err
+	}
+	return nil
+}
+
+func mechanism(flagValue, curve string) (keybackend.Mechanism, error) {
+	if flagValue != "" {
+		return keybackend.ParseMechanism(flagValue)
+	}
+	if curve == nist.Secp256k1.Name || curve == nist.P256.Name {
+		return keybackend.ECDSA, nil
+	}
+	return keybackend.GOST, nil
+}
+
+func readSecret(name string) (string, error) {
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return "", err
+	}
+	s := strings.TrimRight(string(b), "\r\n")
+	if s == "" {
+		return "", errors.New(name + " is empty")
+	}
+	return s, nil
+}
@@ -0,0 +1,119 @@
+// Package adaptor implements adaptor signatures, also called
+// pre-signatures, for BIP-340 Schnorr and ECDSA over Secp256k1 and for GOST
+// R 34.10-2012.
+//
+// A pre-signature is made for an adaptor point T = t·G. Anyone can check it
+// against the signer's public key and T, but only someone who knows t can
+// turn it into a valid signature, and whoever sees both the pre-signature and
+// the signature learns t. In an atomic swap Alice knows t and Bob gives her a
+// pre-signature for his payment; by publishing the completed signature to
+// take Bob's coins, Alice reveals t, which Bob needs to complete her
+// pre-signature for the payment in the other direction.
+//
+// Both payments must be locked to the same secret. When the two ledgers use
+// different curves, as with a GOST-signed ledger and Bitcoin, the points
+// t·G₁ and t·G₂ live in different groups, and the party choosing t must also
+// convince the other that they share a discrete logarithm, for example with a
+// cross-group DLEQ proof. This package does not provide such a proof.
+package adaptor
+
+import (
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/transcript"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/vss"
+)
+
+var (
+	ErrAdaptorPoint   = errors.New("adaptor: invalid adaptor point")
+	ErrSecretMismatch = errors.New("adaptor: signature does not reveal the adaptor secret")
+
+	errPrivateKey   = errors.New("adaptor: private key out of range")
+	errSecret       = errors.New("adaptor: adaptor secret out of range")
+	errPreSignature = errors.New("adaptor: malformed pre-signature")
+)
+
+// GenerateSecret returns a random adaptor secret t and its point T = t·G.
+func GenerateSecret(rand io.Reader, curve *ecgeneric.CurveParams) (*big.Int, ecgeneric.Point, error) {
+	t, err := vss.RandomScalar(rand, curve)
+	if err != nil {
+		return nil, ecgeneric.Point{}, err
+	}
+	x, y := curve.ScalarBaseMultJ(t.Bytes())
+	return t, ecgeneric.Point{X: x, Y: y}, nil
+}
+
+// validPoint reports whether p is a finite point on curve.
+func validPoint(curve *ecgeneric.CurveParams, p ecgeneric.Point) bool {
+	if p.X == nil || p.Y == nil || isInfinity(p) {
+		return false
+	}
+	return curve.IsOnCurve(p.X, p.Y)
+}
+
+func isInfinity(p ecgeneric.Point) bool {
+	return p.X.Sign() == 0 && p.Y.Sign() == 0
+}
+
+func negate(curve *ecgeneric.CurveParams, p ecgeneric.Point) ecgeneric.Point {
+	return ecgeneric.Point{X: p.X, Y: new(big.Int).Sub(curve.P, p.Y)}
+}
+
+func add(curve *ecgeneric.CurveParams, p, q ecgeneric.Point) ecgeneric.Point {
+	x, y := curve.AddJ(p.X, p.Y, q.X, q.Y)
+	return ecgeneric.Point{X: x, Y: y}
+}
+
+func generator(curve *ecgeneric.CurveParams) ecgeneric.Point {
+	return ecgeneric.Point{X: curve.Gx, Y: curve.Gy}
+}
+
+func validScalar(curve *ecgeneric.CurveParams, k *big.Int) bool {
+	return k != nil && k.Sign() > 0 && k.Cmp(curve.N) < 0
+}
+
+// DLEQProof is a non-interactive Chaum–Pedersen proof that A = x·G and
+// B = x·H for the same x. The challenge is drawn from a SHA-256 transcript
+// of the points and the commitments w·G and w·H made with a random scalar w,
+// and S = w + C·x mod N.
+type DLEQProof struct {
+	C *big.Int
+	S *big.Int
+}
+
+func proveDLEQ(rand io.Reader, curve *ecgeneric.CurveParams, x *big.Int, g, h, a, b ecgeneric.Point) (*DLEQProof, error) {
+	w, err := vss.RandomScalar(rand, curve)
+	if err != nil {
+		return nil, err
+	}
+	wgx, wgy := curve.ScalarMultJ(g.X, g.Y, w.Bytes())
+	whx, why := curve.ScalarMultJ(h.X, h.Y, w.Bytes())
+	c := dleqChallenge(curve, g, h, a, b, ecgeneric.Point{X: wgx, Y: wgy}, ecgeneric.Point{X: whx, Y: why})
+	s := new(big.Int).Mul(c, x)
+	s.Add(s, w)
+	return &DLEQProof{C: c, S: s.Mod(s, curve.N)}, nil
+}
+
+// verify checks the proof by recomputing the commitments as S·G - C·A and
+// S·H - C·B.
+func (p *DLEQProof) verify(curve *ecgeneric.CurveParams, g, h, a, b ecgeneric.Point) bool {
+	if p == nil || p.C == nil || p.S == nil || p.S.Sign() < 0 || p.S.Cmp(curve.N) >= 0 {
+		return false
+	}
+	negC := new(big.Int).Sub(curve.N, new(big.Int).Mod(p.C, curve.N))
+	wgx, wgy := curve.MultiScalarMult([]ecgeneric.Point{g, a}, []*big.Int{p.S, negC})
+	whx, why := curve.MultiScalarMult([]ecgeneric.Point{h, b}, []*big.Int{p.S, negC})
+	c := dleqChallenge(curve, g, h, a, b, ecgeneric.Point{X: wgx, Y: wgy}, ecgeneric.Point{X: whx, Y: why})
+	return c.Cmp(p.C) == 0
+}
+
+func dleqChallenge(curve *ecgeneric.CurveParams, points ...ecgeneric.Point) *big.Int {
+	t := transcript.New(transcript.SHA256, "adaptor/dleq")
+	for _, p := range points {
+		t.AppendPoint(curve, "point", p)
+	}
+	return t.ChallengeScalar(curve, "c")
+}
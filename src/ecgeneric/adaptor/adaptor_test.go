@@ -0,0 +1,165 @@
+package adaptor_test
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/adaptor"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/gost"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/nist"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/streebog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchnorrAdaptor(t *testing.T) {
+	curve := &nist.Secp256k1
+	msg := []byte("pay Alice 1 BTC")
+	// Enough rounds to see final nonces of both parities.
+	for i := 0; i < 8; i++ {
+		priv, err := ecgeneric.GenerateKey(curve, rand.Reader)
+		require.NoError(t, err)
+		pk, err := nist.SchnorrPubKey(priv.D)
+		require.NoError(t, err)
+		secret, T, err := adaptor.GenerateSecret(rand.Reader, curve)
+		require.NoError(t, err)
+
+		pre, err := adaptor.SchnorrPreSign(rand.Reader, priv.D, msg, T)
+		require.NoError(t, err)
+		require.True(t, adaptor.SchnorrPreVerify(pk, msg, T, pre))
+		require.False(t, adaptor.SchnorrPreVerify(pk, []byte("pay Alice 2 BTC"), T, pre))
+		_, other, err := adaptor.GenerateSecret(rand.Reader, curve)
+		require.NoError(t, err)
+		require.False(t, adaptor.SchnorrPreVerify(pk, msg, other, pre))
+
+		// The pre-signature itself is not a valid signature.
+		unadapted := append(append([]byte{}, pre.R.X.FillBytes(make([]byte, 32))...), pre.S.FillBytes(make([]byte, 32))...)
+		require.False(t, nist.SchnorrVerify(pk, msg, unadapted))
+
+		sig, err := adaptor.SchnorrAdapt(pre, secret)
+		require.NoError(t, err)
+		require.True(t, nist.SchnorrVerify(pk, msg, sig))
+
+		got, err := adaptor.SchnorrExtract(pre, sig, T)
+		require.NoError(t, err)
+		require.Equal(t, secret, got)
+		_, err = adaptor.SchnorrExtract(pre, sig, other)
+		require.ErrorIs(t, err, adaptor.ErrSecretMismatch)
+	}
+}
+
+func TestECDSAAdaptor(t *testing.T) {
+	curve := &nist.Secp256k1
+	hash := nist.Hash([]byte("pay Bob 10 ETH"))
+	for i := 0; i < 4; i++ {
+		priv, err := ecgeneric.GenerateKey(curve, rand.Reader)
+		require.NoError(t, err)
+		secret, T, err := adaptor.GenerateSecret(rand.Reader, curve)
+		require.NoError(t, err)
+
+		pre, err := adaptor.ECDSAPreSign(rand.Reader, priv, hash[:], T)
+		require.NoError(t, err)
+		require.True(t, adaptor.ECDSAPreVerify(&priv.PublicKey, hash[:], T, pre))
+		require.False(t, adaptor.ECDSAPreVerify(&priv.PublicKey, hash[1:], T, pre))
+		_, other, err := adaptor.GenerateSecret(rand.Reader, curve)
+		require.NoError(t, err)
+		require.False(t, adaptor.ECDSAPreVerify(&priv.PublicKey, hash[:], other, pre))
+
+		// R and RHat must share their discrete logarithm.
+		forged := *pre
+		forged.RHat = ecgeneric.Point{X: curve.Gx, Y: curve.Gy}
+		require.False(t, adaptor.ECDSAPreVerify(&priv.PublicKey, hash[:], T, &forged))
+
+		r, s, err := adaptor.ECDSAAdapt(pre, secret)
+		require.NoError(t, err)
+		require.True(t, s.Cmp(new(big.Int).Rsh(curve.N, 1)) <= 0)
+		ok, err := nist.Verify(hash[:], r, s, priv.X, priv.Y)
+		require.NoError(t, err)
+		require.True(t, ok)
+		sig := append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+		require.True(t, ecgeneric.VerifySignature(ecgeneric.Marshal(curve, priv.X, priv.Y), hash[:], sig))
+
+		got, err := adaptor.ECDSAExtract(pre, r, s, T)
+		require.NoError(t, err)
+		require.Equal(t, secret, got)
+		_, err = adaptor.ECDSAExtract(pre, r, s, other)
+		require.ErrorIs(t, err, adaptor.ErrSecretMismatch)
+	}
+}
+
+var gostCurves = []*ecgeneric.CurveParams{
+	&gost.GostEx1,
+	&gost.Gost34102001paramSetA,
+	&gost.Gost341012512paramSetA,
+	&gost.Gost341012512paramSetB,
+}
+
+func TestGOSTAdaptor(t *testing.T) {
+	h := streebog.New256()
+	h.Write([]byte("transfer 100 RUB"))
+	digest := h.Sum(nil)
+	for _, curve := range gostCurves {
+		priv, err := ecgeneric.GenerateKey(curve, rand.Reader)
+		require.NoError(t, err)
+		secret, T, err := adaptor.GenerateSecret(rand.Reader, curve)
+		require.NoError(t, err)
+
+		pre, err := adaptor.GOSTPreSign(rand.Reader, priv, digest, T)
+		require.NoError(t, err)
+		require.True(t, adaptor.GOSTPreVerify(&priv.PublicKey, digest, T, pre), curve.Name)
+		require.False(t, adaptor.GOSTPreVerify(&priv.PublicKey, digest[1:], T, pre), curve.Name)
+		_, other, err := adaptor.GenerateSecret(rand.Reader, curve)
+		require.NoError(t, err)
+		require.False(t, adaptor.GOSTPreVerify(&priv.PublicKey, digest, other, pre), curve.Name)
+
+		r, s, err := adaptor.GOSTAdapt(pre, digest, secret)
+		require.NoError(t, err)
+		ok, err := gost.Verify(digest, r, s, priv.X, priv.Y, curve)
+		require.NoError(t, err)
+		require.True(t, ok, curve.Name)
+
+		got, err := adaptor.GOSTExtract(pre, digest, r, s, T)
+		require.NoError(t, err)
+		require.Equal(t, secret, got, curve.Name)
+		_, err = adaptor.GOSTExtract(pre, digest, r, s, other)
+		require.ErrorIs(t, err, adaptor.ErrSecretMismatch)
+	}
+}
+
+// TestAtomicSwap swaps between a Schnorr-signed and an ECDSA-signed payment
+// on Secp256k1, so both sides can use the same adaptor point.
+func TestAtomicSwap(t *testing.T) {
+	curve := &nist.Secp256k1
+	alice, err := ecgeneric.GenerateKey(curve, rand.Reader)
+	require.NoError(t, err)
+	bob, err := ecgeneric.GenerateKey(curve, rand.Reader)
+	require.NoError(t, err)
+	bobPK, err := nist.SchnorrPubKey(bob.D)
+	require.NoError(t, err)
+
+	// Alice picks the secret; each pre-signs the payment to the other.
+	secret, T, err := adaptor.GenerateSecret(rand.Reader, curve)
+	require.NoError(t, err)
+	toAlice := []byte("bob pays alice")
+	toBob := nist.Hash([]byte("alice pays bob"))
+	bobPre, err := adaptor.SchnorrPreSign(rand.Reader, bob.D, toAlice, T)
+	require.NoError(t, err)
+	alicePre, err := adaptor.ECDSAPreSign(rand.Reader, alice, toBob[:], T)
+	require.NoError(t, err)
+	require.True(t, adaptor.SchnorrPreVerify(bobPK, toAlice, T, bobPre))
+	require.True(t, adaptor.ECDSAPreVerify(&alice.PublicKey, toBob[:], T, alicePre))
+
+	// Alice claims her payment, which reveals the secret to Bob.
+	claim, err := adaptor.SchnorrAdapt(bobPre, secret)
+	require.NoError(t, err)
+	require.True(t, nist.SchnorrVerify(bobPK, toAlice, claim))
+	learned, err := adaptor.SchnorrExtract(bobPre, claim, T)
+	require.NoError(t, err)
+
+	r, s, err := adaptor.ECDSAAdapt(alicePre, learned)
+	require.NoError(t, err)
+	ok, err := nist.Verify(toBob[:], r, s, alice.X, alice.Y)
+	require.NoError(t, err)
+	require.True(t, ok)
+}
@@ -0,0 +1,137 @@
+package adaptor
+
+import (
+	"io"
+	"math/big"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/vss"
+)
+
+// ECDSAPreSignature is an ECDSA pre-signature in the one-time verifiably
+// encrypted signature scheme of Fournier. The signer picks k and publishes
+// R = k·T, whose x coordinate is the r of the final signature, RHat = k·G and
+// S = k⁻¹(z + r·d). The final nonce is k·t, so the signature is (r, S·t⁻¹).
+// Proof shows that R and RHat share the scalar k.
+type ECDSAPreSignature struct {
+	Curve *ecgeneric.CurveParams
+	R     ecgeneric.Point
+	RHat  ecgeneric.Point
+	S     *big.Int
+	Proof *DLEQProof
+}
+
+// r returns the r value of the final signature.
+func (pre *ECDSAPreSignature) r() *big.Int {
+	return new(big.Int).Mod(pre.R.X, pre.Curve.N)
+}
+
+// ECDSAPreSign makes a pre-signature of hash with priv for the adaptor point
+// T. The hash is converted to an integer as in ECDSA signing. The scheme
+// works on any prime-order curve, but is meant for nist.Secp256k1.
+func ECDSAPreSign(rand io.Reader, priv *ecgeneric.PrivateKey, hash []byte, T ecgeneric.Point) (*ECDSAPreSignature, error) {
+	curve := priv.Curve.Params()
+	N := curve.N
+	if !validScalar(curve, priv.D) {
+		return nil, errPrivateKey
+	}
+	if !validPoint(curve, T) {
+		return nil, ErrAdaptorPoint
+	}
+	z := ecgeneric.HashToInt(hash, curve)
+	for {
+		k, err := vss.RandomScalar(rand, curve)
+		if err != nil {
+			return nil, err
+		}
+		Rx, Ry := curve.ScalarMultJ(T.X, T.Y, k.Bytes())
+		Hx, Hy := curve.ScalarBaseMultJ(k.Bytes())
+		pre := &ECDSAPreSignature{
+			Curve: curve,
+			R:     ecgeneric.Point{X: Rx, Y: Ry},
+			RHat:  ecgeneric.Point{X: Hx, Y: Hy},
+		}
+		r := pre.r()
+		if r.Sign() == 0 {
+			continue
+		}
+		s := new(big.Int).Mul(r, priv.D)
+		s.Add(s, z)
+		s.Mul(s, new(big.Int).ModInverse(k, N))
+		if s.Mod(s, N).Sign() == 0 {
+			continue
+		}
+		pre.S = s
+		if pre.Proof, err = proveDLEQ(rand, curve, k, generator(curve), T, pre.RHat, pre.R); err != nil {
+			return nil, err
+		}
+		return pre, nil
+	}
+}
+
+// ECDSAPreVerify reports whether pre is a valid pre-signature of hash under
+// pub for the adaptor point T.
+func ECDSAPreVerify(pub *ecgeneric.PublicKey, hash []byte, T ecgeneric.Point, pre *ECDSAPreSignature) bool {
+	curve := pub.Curve.Params()
+	N := curve.N
+	if pre == nil || pre.Curve == nil || (pre.Curve != curve && pre.Curve.Name != curve.Name) || !validScalar(curve, pre.S) {
+		return false
+	}
+	if !validPoint(curve, T) || !validPoint(curve, pre.R) || !validPoint(curve, pre.RHat) {
+		return false
+	}
+	if !pre.Proof.verify(curve, generator(curve), T, pre.RHat, pre.R) {
+		return false
+	}
+	// S·RHat = z·G + r·P.
+	r := pre.r()
+	if r.Sign() == 0 {
+		return false
+	}
+	z := ecgeneric.HashToInt(hash, curve)
+	x, y := curve.MultiScalarMult(
+		[]ecgeneric.Point{pre.RHat, generator(curve), {X: pub.X, Y: pub.Y}},
+		[]*big.Int{pre.S, new(big.Int).Sub(N, z.Mod(z, N)), new(big.Int).Sub(N, r)},
+	)
+	return x.Sign() == 0 && y.Sign() == 0
+}
+
+// ECDSAAdapt completes pre with the adaptor secret t. The signature is
+// normalized to a low S, as Bitcoin and Ethereum require.
+func ECDSAAdapt(pre *ECDSAPreSignature, t *big.Int) (r, s *big.Int, err error) {
+	if pre == nil || pre.Curve == nil || pre.S == nil || pre.R.X == nil {
+		return nil, nil, errPreSignature
+	}
+	N := pre.Curve.N
+	if !validScalar(pre.Curve, t) {
+		return nil, nil, errSecret
+	}
+	s = new(big.Int).ModInverse(t, N)
+	s.Mul(s, pre.S)
+	s.Mod(s, N)
+	if s.Cmp(new(big.Int).Rsh(N, 1)) > 0 {
+		s.Sub(N, s)
+	}
+	return pre.r(), s, nil
+}
+
+// ECDSAExtract returns the adaptor secret for T from a pre-signature and the
+// signature (r, s) completed from it. The signature may have had its s
+// negated, as low-S normalization does.
+func ECDSAExtract(pre *ECDSAPreSignature, r, s *big.Int, T ecgeneric.Point) (*big.Int, error) {
+	if pre == nil || pre.Curve == nil || pre.S == nil || pre.R.X == nil {
+		return nil, errPreSignature
+	}
+	curve := pre.Curve
+	N := curve.N
+	if r.Cmp(pre.r()) != 0 || !validScalar(curve, s) {
+		return nil, ErrSecretMismatch
+	}
+	t := new(big.Int).ModInverse(s, N)
+	t.Mul(t, pre.S)
+	t.Mod(t, N)
+	if found, err := checkSecret(curve, t, T); err == nil {
+		return found, nil
+	}
+	return checkSecret(curve, t.Sub(N, t), T)
+}
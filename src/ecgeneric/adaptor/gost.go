@@ -0,0 +1,123 @@
+package adaptor
+
+import (
+	"io"
+	"math/big"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/vss"
+)
+
+// GOSTPreSignature is a GOST R 34.10-2012 pre-signature. R = k·G + T is the
+// nonce point of the final signature, whose r is R.x mod N, and
+// S = r·d + k·e. Since s is linear in the nonce, the final signature is
+// (r, S + t·e).
+type GOSTPreSignature struct {
+	Curve *ecgeneric.CurveParams
+	R     ecgeneric.Point
+	S     *big.Int
+}
+
+// r returns the r value of the final signature.
+func (pre *GOSTPreSignature) r() *big.Int {
+	return new(big.Int).Mod(pre.R.X, pre.Curve.N)
+}
+
+// gostDigest returns e as computed by gost.Sign: the digest modulo N, with
+// zero replaced by one.
+func gostDigest(curve *ecgeneric.CurveParams, digest []byte) *big.Int {
+	e := new(big.Int).SetBytes(digest)
+	e.Mod(e, curve.N)
+	if e.Sign() == 0 {
+		e.SetInt64(1)
+	}
+	return e
+}
+
+// GOSTPreSign makes a pre-signature of digest with priv for the adaptor
+// point T. The completed signature verifies with gost.Verify.
+func GOSTPreSign(rand io.Reader, priv *ecgeneric.PrivateKey, digest []byte, T ecgeneric.Point) (*GOSTPreSignature, error) {
+	curve := priv.Curve.Params()
+	N := curve.N
+	if !validScalar(curve, priv.D) {
+		return nil, errPrivateKey
+	}
+	if !validPoint(curve, T) {
+		return nil, ErrAdaptorPoint
+	}
+	e := gostDigest(curve, digest)
+	for {
+		k, err := vss.RandomScalar(rand, curve)
+		if err != nil {
+			return nil, err
+		}
+		kx, ky := curve.ScalarBaseMultJ(k.Bytes())
+		pre := &GOSTPreSignature{Curve: curve, R: add(curve, ecgeneric.Point{X: kx, Y: ky}, T)}
+		if isInfinity(pre.R) {
+			continue
+		}
+		r := pre.r()
+		if r.Sign() == 0 {
+			continue
+		}
+		s := r.Mul(r, priv.D)
+		s.Add(s, k.Mul(k, e))
+		pre.S = s.Mod(s, N)
+		return pre, nil
+	}
+}
+
+// GOSTPreVerify reports whether pre is a valid pre-signature of digest under
+// pub for the adaptor point T.
+func GOSTPreVerify(pub *ecgeneric.PublicKey, digest []byte, T ecgeneric.Point, pre *GOSTPreSignature) bool {
+	curve := pub.Curve.Params()
+	N := curve.N
+	if pre == nil || pre.Curve == nil || (pre.Curve != curve && pre.Curve.Name != curve.Name) || pre.S == nil || pre.S.Sign() < 0 || pre.S.Cmp(N) >= 0 {
+		return false
+	}
+	if !validPoint(curve, T) || !validPoint(curve, pre.R) {
+		return false
+	}
+	r := pre.r()
+	if r.Sign() == 0 {
+		return false
+	}
+	// S·G - r·P - e·(R - T) must vanish.
+	e := gostDigest(curve, digest)
+	R0 := add(curve, pre.R, negate(curve, T))
+	x, y := curve.MultiScalarMult(
+		[]ecgeneric.Point{generator(curve), {X: pub.X, Y: pub.Y}, R0},
+		[]*big.Int{pre.S, r.Sub(N, r), e.Sub(N, e)},
+	)
+	return x.Sign() == 0 && y.Sign() == 0
+}
+
+// GOSTAdapt completes pre, made for digest, with the adaptor secret t.
+func GOSTAdapt(pre *GOSTPreSignature, digest []byte, t *big.Int) (r, s *big.Int, err error) {
+	if pre == nil || pre.Curve == nil || pre.S == nil || pre.R.X == nil {
+		return nil, nil, errPreSignature
+	}
+	if !validScalar(pre.Curve, t) {
+		return nil, nil, errSecret
+	}
+	s = gostDigest(pre.Curve, digest)
+	s.Mul(s, t)
+	s.Add(s, pre.S)
+	return pre.r(), s.Mod(s, pre.Curve.N), nil
+}
+
+// GOSTExtract returns the adaptor secret for T from a pre-signature of digest
+// and the signature (r, s) completed from it.
+func GOSTExtract(pre *GOSTPreSignature, digest []byte, r, s *big.Int, T ecgeneric.Point) (*big.Int, error) {
+	if pre == nil || pre.Curve == nil || pre.S == nil || pre.R.X == nil {
+		return nil, errPreSignature
+	}
+	curve := pre.Curve
+	N := curve.N
+	if r.Cmp(pre.r()) != 0 || s.Sign() < 0 || s.Cmp(N) >= 0 {
+		return nil, ErrSecretMismatch
+	}
+	t := new(big.Int).ModInverse(gostDigest(curve, digest), N)
+	t.Mul(t, new(big.Int).Sub(s, pre.S))
+	return checkSecret(curve, t.Mod(t, N), T)
+}
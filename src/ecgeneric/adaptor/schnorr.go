@@ -0,0 +1,161 @@
+package adaptor
+
+import (
+	"io"
+	"math/big"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/nist"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/vss"
+)
+
+// SchnorrPreSignature is a BIP-340 pre-signature. R = k·G + T is the nonce of
+// the final signature. BIP-340 only uses nonces with an even y coordinate, so
+// if R has an odd one the final nonce is really -R, and S = -k + e·d instead
+// of k + e·d; completing then subtracts t instead of adding it.
+type SchnorrPreSignature struct {
+	R ecgeneric.Point
+	S *big.Int
+}
+
+// oddR reports whether the final nonce must be negated.
+func (pre *SchnorrPreSignature) oddR() bool {
+	return pre.R.Y.Bit(0) != 0
+}
+
+// SchnorrPreSign makes a pre-signature of msg with the private key d for the
+// adaptor point T on nist.Secp256k1.
+func SchnorrPreSign(rand io.Reader, d *big.Int, msg []byte, T ecgeneric.Point) (*SchnorrPreSignature, error) {
+	curve := &nist.Secp256k1
+	N := curve.N
+	if !validScalar(curve, d) {
+		return nil, errPrivateKey
+	}
+	if !validPoint(curve, T) {
+		return nil, ErrAdaptorPoint
+	}
+	Px, Py := curve.ScalarBaseMultJ(d.Bytes())
+	if Py.Bit(0) != 0 {
+		d = new(big.Int).Sub(N, d)
+	}
+	pk := bytes32(Px)
+
+	for {
+		k, err := vss.RandomScalar(rand, curve)
+		if err != nil {
+			return nil, err
+		}
+		kx, ky := curve.ScalarBaseMultJ(k.Bytes())
+		R := add(curve, ecgeneric.Point{X: kx, Y: ky}, T)
+		if isInfinity(R) {
+			continue
+		}
+		pre := &SchnorrPreSignature{R: R}
+		if pre.oddR() {
+			k.Sub(N, k)
+		}
+		e := schnorrChallenge(bytes32(R.X), pk, msg)
+		s := e.Mul(e, d)
+		s.Add(s, k)
+		pre.S = s.Mod(s, N)
+		return pre, nil
+	}
+}
+
+// SchnorrPreVerify reports whether pre is a valid pre-signature of msg under
+// the x-only public key pubKey for the adaptor point T.
+func SchnorrPreVerify(pubKey, msg []byte, T ecgeneric.Point, pre *SchnorrPreSignature) bool {
+	curve := &nist.Secp256k1
+	N := curve.N
+	if len(pubKey) != nist.SchnorrPubKeyLength || pre == nil || pre.S == nil || pre.S.Sign() < 0 || pre.S.Cmp(N) >= 0 {
+		return false
+	}
+	if !validPoint(curve, T) || !validPoint(curve, pre.R) {
+		return false
+	}
+	Px, Py, err := nist.LiftX(new(big.Int).SetBytes(pubKey))
+	if err != nil {
+		return false
+	}
+	e := schnorrChallenge(bytes32(pre.R.X), pubKey, msg)
+
+	// S·G - e·P ∓ (R - T) must vanish.
+	R0 := add(curve, pre.R, negate(curve, T))
+	sign := big.NewInt(1)
+	if !pre.oddR() {
+		sign.Sub(N, sign)
+	}
+	x, y := curve.MultiScalarMult(
+		[]ecgeneric.Point{generator(curve), {X: Px, Y: Py}, R0},
+		[]*big.Int{pre.S, e.Sub(N, e), sign},
+	)
+	return x.Sign() == 0 && y.Sign() == 0
+}
+
+// SchnorrAdapt completes pre with the adaptor secret t and returns a 64-byte
+// BIP-340 signature, which nist.SchnorrVerify accepts if pre was valid for
+// T = t·G.
+func SchnorrAdapt(pre *SchnorrPreSignature, t *big.Int) ([]byte, error) {
+	curve := &nist.Secp256k1
+	if !validScalar(curve, t) {
+		return nil, errSecret
+	}
+	if pre == nil || pre.S == nil || pre.R.X == nil {
+		return nil, errPreSignature
+	}
+	s := new(big.Int)
+	if pre.oddR() {
+		s.Sub(pre.S, t)
+	} else {
+		s.Add(pre.S, t)
+	}
+	s.Mod(s, curve.N)
+	sig := make([]byte, nist.SchnorrSignatureLength)
+	pre.R.X.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+	return sig, nil
+}
+
+// SchnorrExtract returns the adaptor secret for T from a pre-signature and
+// the signature completed from it.
+func SchnorrExtract(pre *SchnorrPreSignature, sig []byte, T ecgeneric.Point) (*big.Int, error) {
+	curve := &nist.Secp256k1
+	if pre == nil || pre.S == nil || pre.R.X == nil {
+		return nil, errPreSignature
+	}
+	if len(sig) != nist.SchnorrSignatureLength || new(big.Int).SetBytes(sig[:32]).Cmp(pre.R.X) != 0 {
+		return nil, ErrSecretMismatch
+	}
+	t := new(big.Int).SetBytes(sig[32:])
+	t.Sub(t, pre.S)
+	if pre.oddR() {
+		t.Neg(t)
+	}
+	return checkSecret(curve, t.Mod(t, curve.N), T)
+}
+
+// checkSecret returns t if t·G = T.
+func checkSecret(curve *ecgeneric.CurveParams, t *big.Int, T ecgeneric.Point) (*big.Int, error) {
+	if t.Sign() == 0 || T.X == nil || T.Y == nil {
+		return nil, ErrSecretMismatch
+	}
+	x, y := curve.ScalarBaseMultJ(t.Bytes())
+	if x.Cmp(T.X) != 0 || y.Cmp(T.Y) != 0 {
+		return nil, ErrSecretMismatch
+	}
+	return t, nil
+}
+
+// schnorrChallenge and bytes32 repeat the BIP-340 challenge and encoding of
+// the nist package, which keeps its own copies unexported.
+func schnorrChallenge(r, pk, msg []byte) *big.Int {
+	h := nist.TaggedHash("BIP0340/challenge", r, pk, msg)
+	e := new(big.Int).SetBytes(h[:])
+	return e.Mod(e, nist.Secp256k1.N)
+}
+
+func bytes32(x *big.Int) []byte {
+	b := make([]byte, 32)
+	x.FillBytes(b)
+	return b
+}
@@ -0,0 +1,286 @@
+// Package batch verifies many GOST R 34.10 or ECDSA signatures at once.
+//
+// When every signature carries a recovery id the nonce points R can be
+// rebuilt, and the whole batch is checked with a single randomized linear
+// combination
+//
+//	Σ aᵢ·(u1ᵢ·G + u2ᵢ·Qᵢ - Rᵢ) = O
+//
+// evaluated as one Pippenger multi-scalar multiplication. Otherwise, or when
+// the combined check fails, the signatures are verified one by one over a pool
+// of workers so that the failing entries can be reported.
+package batch
+
+import (
+	"errors"
+	"io"
+	"math/big"
+	"runtime"
+	"sync"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/gost"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/nist"
+)
+
+// Scheme selects the signature equation checked by a BatchVerifier.
+type Scheme int
+
+const (
+	// GOST is GOST R 34.10-2012, s = rd + ke, as checked by gost.VerifyJ.
+	GOST Scheme = iota
+	// ECDSA is SEC 1 ECDSA, s = k⁻¹(e + rd), as checked by nist.VerifyJ.
+	ECDSA
+)
+
+// randomizerBits is the size of the random coefficients aᵢ. A forged entry
+// passes the combined check with probability 2^-randomizerBits.
+const randomizerBits = 128
+
+var errNoRandomness = errors.New("batch: no randomness source")
+
+type entry struct {
+	pubX, pubY *big.Int
+	hash       []byte
+	r, s       *big.Int
+	v          int // recovery id, or -1 when unknown
+}
+
+// BatchVerifier accumulates (public key, hash, signature) tuples and checks
+// them together.
+type BatchVerifier struct {
+	Curve  *ecgeneric.CurveParams
+	Scheme Scheme
+	// Workers bounds the number of goroutines used for the multi-scalar
+	// multiplication and for per-signature verification. Zero means
+	// runtime.NumCPU().
+	Workers int
+
+	entries []entry
+}
+
+// NewBatchVerifier returns an empty verifier for signatures of the given
+// scheme over curve.
+func NewBatchVerifier(curve *ecgeneric.CurveParams, scheme Scheme) *BatchVerifier {
+	return &BatchVerifier{Curve: curve, Scheme: scheme}
+}
+
+// Add queues a signature without a recovery id. A batch containing such an
+// entry is always verified signature by signature.
+func (bv *BatchVerifier) Add(pubX, pubY *big.Int, hash []byte, r, s *big.Int) {
+	bv.entries = append(bv.entries, entry{pubX, pubY, hash, r, s, -1})
+}
+
+// AddRecoverable queues a signature together with its recovery id v: bit 0
+// is the parity of R.y and bit 1 is set when R.x = r + N.
+func (bv *BatchVerifier) AddRecoverable(pubX, pubY *big.Int, hash []byte, r, s *big.Int, v byte) {
+	bv.entries = append(bv.entries, entry{pubX, pubY, hash, r, s, int(v & 3)})
+}
+
+// Len returns the number of queued signatures.
+func (bv *BatchVerifier) Len() int {
+	return len(bv.entries)
+}
+
+// Reset drops all queued signatures.
+func (bv *BatchVerifier) Reset() {
+	bv.entries = bv.entries[:0]
+}
+
+// Verify checks every queued signature. It reports whether all of them are
+// valid and, if not, the indexes (in insertion order) of the invalid ones.
+// rand is only read when the randomized combined check is used.
+func (bv *BatchVerifier) Verify(rand io.Reader) (bool, []int) {
+	if len(bv.entries) == 0 {
+		return true, nil
+	}
+	if bv.recoverable() {
+		ok, err := bv.verifyCombined(rand)
+		if err == nil && ok {
+			return true, nil
+		}
+	}
+	failed := bv.verifyEach()
+	return len(failed) == 0, failed
+}
+
+func (bv *BatchVerifier) recoverable() bool {
+	if len(bv.entries) < 2 {
+		return false
+	}
+	for _, e := range bv.entries {
+		if e.v < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// verifyCombined runs the randomized linear combination check. A false
+// result only means that at least one entry is invalid.
+func (bv *BatchVerifier) verifyCombined(rand io.Reader) (bool, error) {
+	if rand == nil {
+		return false, errNoRandomness
+	}
+	curve := bv.Curve
+	N := curve.N
+
+	gScalar := new(big.Int)
+	points := make([]ecgeneric.Point, 0, 2*len(bv.entries)+1)
+	scalars := make([]*big.Int, 0, 2*len(bv.entries)+1)
+
+	buf := make([]byte, randomizerBits/8)
+	for _, e := range bv.entries {
+		if !bv.wellFormed(e) {
+			return false, nil
+		}
+		Rx, Ry, ok := liftR(curve, e.r, e.v)
+		if !ok {
+			return false, nil
+		}
+		u1, u2 := bv.coefficients(e)
+
+		a := new(big.Int)
+		for a.Sign() == 0 {
+			if _, err := io.ReadFull(rand, buf); err != nil {
+				return false, err
+			}
+			a.SetBytes(buf)
+		}
+
+		u1.Mul(u1, a)
+		gScalar.Add(gScalar, u1)
+
+		u2.Mul(u2, a)
+		u2.Mod(u2, N)
+		points = append(points, ecgeneric.Point{X: e.pubX, Y: e.pubY})
+		scalars = append(scalars, u2)
+
+		// -aᵢ·Rᵢ is added as aᵢ·(-Rᵢ) to keep the scalar short.
+		points = append(points, ecgeneric.Point{X: Rx, Y: new(big.Int).Sub(curve.P, Ry)})
+		scalars = append(scalars, a)
+	}
+	gScalar.Mod(gScalar, N)
+	points = append(points, ecgeneric.Point{X: curve.Gx, Y: curve.Gy})
+	scalars = append(scalars, gScalar)
+
+	x, y := curve.MultiScalarMultParallel(points, scalars, bv.workers())
+	return x.Sign() == 0 && y.Sign() == 0, nil
+}
+
+// coefficients returns (u1, u2) such that a valid signature satisfies
+// R = u1·G + u2·Q.
+func (bv *BatchVerifier) coefficients(e entry) (u1, u2 *big.Int) {
+	N := bv.Curve.N
+	z := new(big.Int).Mod(ecgeneric.HashToInt(e.hash, bv.Curve), N)
+
+	switch bv.Scheme {
+	case GOST:
+		// R = s·e⁻¹·G - r·e⁻¹·Q
+		if z.Sign() == 0 {
+			z.SetInt64(1)
+		}
+		v := new(big.Int).ModInverse(z, N)
+		u1 = new(big.Int).Mul(e.s, v)
+		u1.Mod(u1, N)
+		u2 = new(big.Int).Mul(e.r, v)
+		u2.Neg(u2)
+		u2.Mod(u2, N)
+	default:
+		// R = e·s⁻¹·G + r·s⁻¹·Q
+		w := new(big.Int).ModInverse(e.s, N)
+		u1 = new(big.Int).Mul(z, w)
+		u1.Mod(u1, N)
+		u2 = new(big.Int).Mul(e.r, w)
+		u2.Mod(u2, N)
+	}
+	return
+}
+
+// wellFormed performs the range and public key checks shared by both
+// verification paths.
+func (bv *BatchVerifier) wellFormed(e entry) bool {
+	N := bv.Curve.N
+	if e.r == nil || e.s == nil || e.pubX == nil || e.pubY == nil {
+		return false
+	}
+	if e.r.Sign() <= 0 || e.s.Sign() <= 0 || e.r.Cmp(N) >= 0 || e.s.Cmp(N) >= 0 {
+		return false
+	}
+	return bv.Curve.IsOnCurve(e.pubX, e.pubY)
+}
+
+func (bv *BatchVerifier) verifyOne(e entry) bool {
+	if !bv.wellFormed(e) {
+		return false
+	}
+	var ok bool
+	switch bv.Scheme {
+	case GOST:
+		ok, _ = gost.VerifyJ(e.hash, e.r, e.s, e.pubX, e.pubY, bv.Curve)
+	default:
+		ok, _ = nist.VerifyJ(e.hash, e.r, e.s, e.pubX, e.pubY, bv.Curve)
+	}
+	return ok
+}
+
+// verifyEach checks every entry on its own over a worker pool and returns
+// the sorted indexes of the invalid ones.
+func (bv *BatchVerifier) verifyEach() []int {
+	workers := bv.workers()
+	if workers > len(bv.entries) {
+		workers = len(bv.entries)
+	}
+
+	valid := make([]bool, len(bv.entries))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				valid[i] = bv.verifyOne(bv.entries[i])
+			}
+		}()
+	}
+	for i := range bv.entries {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var failed []int
+	for i, ok := range valid {
+		if !ok {
+			failed = append(failed, i)
+		}
+	}
+	return failed
+}
+
+func (bv *BatchVerifier) workers() int {
+	if bv.Workers <= 0 {
+		return runtime.NumCPU()
+	}
+	return bv.Workers
+}
+
+// liftR rebuilds the nonce point R from r and the recovery id v.
+func liftR(curve *ecgeneric.CurveParams, r *big.Int, v int) (x, y *big.Int, ok bool) {
+	x = new(big.Int).Set(r)
+	if v&2 != 0 {
+		x.Add(x, curve.N)
+	}
+	if x.Cmp(curve.P) >= 0 {
+		return nil, nil, false
+	}
+	y = new(big.Int).ModSqrt(curve.PolynomialGeneric(x), curve.P)
+	if y == nil {
+		return nil, nil, false
+	}
+	if y.Bit(0) != uint(v&1) {
+		y.Sub(curve.P, y)
+	}
+	return x, y, true
+}
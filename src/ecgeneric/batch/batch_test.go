@@ -0,0 +1,156 @@
+package batch_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/batch"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/gost"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/nist"
+	"github.com/stretchr/testify/require"
+)
+
+type gostSig struct {
+	X, Y *big.Int
+	hash []byte
+	r, s *big.Int
+	v    byte
+}
+
+// gostRecoveryID rebuilds R = e⁻¹(s·G - r·Q) to find the recovery id of a
+// GOST signature.
+func gostRecoveryID(curve *ecgeneric.CurveParams, X, Y *big.Int, hash []byte, r, s *big.Int) byte {
+	e := new(big.Int).Mod(ecgeneric.HashToInt(hash, curve), curve.N)
+	if e.Sign() == 0 {
+		e.SetInt64(1)
+	}
+	v := new(big.Int).ModInverse(e, curve.N)
+	z1 := new(big.Int).Mul(s, v)
+	z1.Mod(z1, curve.N)
+	z2 := new(big.Int).Mul(r, v)
+	z2.Neg(z2)
+	z2.Mod(z2, curve.N)
+	x1, y1 := curve.ScalarBaseMultJ(z1.Bytes())
+	x2, y2 := curve.ScalarMultJ(X, Y, z2.Bytes())
+	Rx, Ry := curve.AddJ(x1, y1, x2, y2)
+
+	var id byte
+	if Ry.Bit(0) == 1 {
+		id |= 1
+	}
+	if Rx.Cmp(curve.N) >= 0 {
+		id |= 2
+	}
+	return id
+}
+
+func gostSignatures(t *testing.T, curve *ecgeneric.CurveParams, n int) []gostSig {
+	sigs := make([]gostSig, n)
+	for i := range sigs {
+		priv, err := ecgeneric.GenerateKey(curve, rand.Reader)
+		require.NoError(t, err)
+		hash := sha256.Sum256([]byte(fmt.Sprintf("block tx %d", i)))
+		r, s, err := gost.SignJ(priv.D, hash[:], curve, rand.Reader)
+		require.NoError(t, err)
+		sigs[i] = gostSig{priv.X, priv.Y, hash[:], r, s, gostRecoveryID(curve, priv.X, priv.Y, hash[:], r, s)}
+	}
+	return sigs
+}
+
+func TestBatchGOSTRecoverable(t *testing.T) {
+	curve := &gost.Gost34102001paramSetA
+	sigs := gostSignatures(t, curve, 8)
+
+	bv := batch.NewBatchVerifier(curve, batch.GOST)
+	for _, sig := range sigs {
+		bv.AddRecoverable(sig.X, sig.Y, sig.hash, sig.r, sig.s, sig.v)
+	}
+	ok, failed := bv.Verify(rand.Reader)
+	require.True(t, ok)
+	require.Empty(t, failed)
+	// The combined check accepts the batch by itself.
+	ok, err := bv.VerifyCombined(rand.Reader)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	bv.Reset()
+	for i, sig := range sigs {
+		s := sig.s
+		if i == 3 || i == 6 {
+			s = new(big.Int).Add(s, big.NewInt(1))
+		}
+		bv.AddRecoverable(sig.X, sig.Y, sig.hash, sig.r, s, sig.v)
+	}
+	ok, failed = bv.Verify(rand.Reader)
+	require.False(t, ok)
+	require.Equal(t, []int{3, 6}, failed)
+}
+
+func TestBatchGOSTWorkerPool(t *testing.T) {
+	curve := &gost.Gost34102001paramSetA
+	sigs := gostSignatures(t, curve, 6)
+
+	bv := batch.NewBatchVerifier(curve, batch.GOST)
+	bv.Workers = 3
+	for i, sig := range sigs {
+		hash := sig.hash
+		if i == 0 {
+			hash = []byte("another message")
+		}
+		bv.Add(sig.X, sig.Y, hash, sig.r, sig.s)
+	}
+	ok, failed := bv.Verify(nil)
+	require.False(t, ok)
+	require.Equal(t, []int{0}, failed)
+}
+
+func TestBatchECDSASecp256k1(t *testing.T) {
+	curve := &nist.Secp256k1
+	type ecdsaSig struct {
+		X, Y, r, s *big.Int
+		hash       []byte
+		v          byte
+	}
+	sigs := make([]ecdsaSig, 8)
+	for i := range sigs {
+		d, err := rand.Int(rand.Reader, curve.N)
+		require.NoError(t, err)
+		d.Add(d, big.NewInt(1))
+		X, Y := curve.ScalarBaseMultJ(d.Bytes())
+		priv := &ecdsa.PrivateKey{PublicKey: ecdsa.PublicKey{Curve: ecgeneric.S256(), X: X, Y: Y}, D: d}
+
+		hash := sha256.Sum256([]byte(fmt.Sprintf("eth tx %d", i)))
+		sig, err := ecgeneric.Sign(hash[:], priv)
+		require.NoError(t, err)
+		sigs[i] = ecdsaSig{X, Y, new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:64]), hash[:], sig[64]}
+	}
+
+	bv := batch.NewBatchVerifier(curve, batch.ECDSA)
+	for _, sig := range sigs {
+		bv.AddRecoverable(sig.X, sig.Y, sig.hash, sig.r, sig.s, sig.v)
+	}
+	ok, failed := bv.Verify(rand.Reader)
+	require.True(t, ok)
+	require.Empty(t, failed)
+	// The combined check accepts the batch by itself.
+	ok, err := bv.VerifyCombined(rand.Reader)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	bv.Reset()
+	for i, sig := range sigs {
+		r := sig.r
+		if i == 5 {
+			r = new(big.Int).Add(r, big.NewInt(1))
+		}
+		bv.AddRecoverable(sig.X, sig.Y, sig.hash, r, sig.s, sig.v)
+	}
+	ok, failed = bv.Verify(rand.Reader)
+	require.False(t, ok)
+	require.Equal(t, []int{5}, failed)
+}
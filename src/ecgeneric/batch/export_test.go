@@ -0,0 +1,9 @@
+package batch
+
+import "io"
+
+// VerifyCombined runs only the randomized combined check, so that tests can
+// tell it apart from the per-signature fallback of Verify.
+func (bv *BatchVerifier) VerifyCombined(rand io.Reader) (bool, error) {
+	return bv.verifyCombined(rand)
+}
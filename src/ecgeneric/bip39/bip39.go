@@ -0,0 +1,137 @@
+// Package bip39 implements BIP-39 mnemonic phrases: encoding entropy as
+// words with a checksum, validating phrases and stretching them into a seed
+// with PBKDF2-HMAC-SHA512.
+//
+// A seed can be turned into an ecgeneric.PrivateKey on any registered curve
+// with NewKey, so that secp256k1 and GOST keys can be backed up with the same
+// phrase.
+package bip39
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/hdkey"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/text/unicode/norm"
+)
+
+// SeedSize is the length of a seed returned by NewSeed.
+const SeedSize = 64
+
+var (
+	ErrEntropyLength  = errors.New("bip39: entropy must be 128 to 256 bits in steps of 32")
+	ErrMnemonicLength = errors.New("bip39: mnemonic must have 12, 15, 18, 21 or 24 words")
+	ErrChecksum       = errors.New("bip39: checksum mismatch")
+)
+
+// NewEntropy reads bits of entropy from rand. bits must be 128, 160, 192,
+// 224 or 256.
+func NewEntropy(rand io.Reader, bits int) ([]byte, error) {
+	if bits < 128 || bits > 256 || bits%32 != 0 {
+		return nil, ErrEntropyLength
+	}
+	entropy := make([]byte, bits/8)
+	if _, err := io.ReadFull(rand, entropy); err != nil {
+		return nil, err
+	}
+	return entropy, nil
+}
+
+// NewMnemonic encodes entropy as a mnemonic in the words of wl. The first
+// len(entropy)/4 bits of SHA-256(entropy) are appended as a checksum.
+func NewMnemonic(entropy []byte, wl *Wordlist) (string, error) {
+	bits := len(entropy) * 8
+	if bits < 128 || bits > 256 || bits%32 != 0 {
+		return "", ErrEntropyLength
+	}
+	csBits := bits / 32
+	h := sha256.Sum256(entropy)
+
+	// entropy || checksum, as a number.
+	x := new(big.Int).SetBytes(entropy)
+	x.Lsh(x, uint(csBits))
+	x.Or(x, big.NewInt(int64(h[0]>>(8-csBits))))
+
+	n := (bits + csBits) / 11
+	words := make([]string, n)
+	mask := big.NewInt(2047)
+	idx := new(big.Int)
+	for i := n - 1; i >= 0; i-- {
+		idx.And(x, mask)
+		words[i] = wl.Word(int(idx.Int64()))
+		x.Rsh(x, 11)
+	}
+	return strings.Join(words, " "), nil
+}
+
+// EntropyFromMnemonic decodes a mnemonic written in the words of wl and
+// verifies its checksum.
+func EntropyFromMnemonic(mnemonic string, wl *Wordlist) ([]byte, error) {
+	words := strings.Fields(norm.NFKD.String(mnemonic))
+	n := len(words)
+	if n < 12 || n > 24 || n%3 != 0 {
+		return nil, ErrMnemonicLength
+	}
+
+	x := new(big.Int)
+	for _, w := range words {
+		i, ok := wl.Index(w)
+		if !ok {
+			return nil, fmt.Errorf("bip39: unknown word %q", w)
+		}
+		x.Lsh(x, 11)
+		x.Or(x, big.NewInt(int64(i)))
+	}
+
+	csBits := n / 3
+	checksum := new(big.Int).And(x, big.NewInt(1<<csBits-1))
+	x.Rsh(x, uint(csBits))
+	entropy := make([]byte, (n*11-csBits)/8)
+	x.FillBytes(entropy)
+
+	h := sha256.Sum256(entropy)
+	if checksum.Int64() != int64(h[0]>>(8-csBits)) {
+		return nil, ErrChecksum
+	}
+	return entropy, nil
+}
+
+// IsValid reports whether mnemonic is made of words of wl and has a correct
+// checksum.
+func IsValid(mnemonic string, wl *Wordlist) bool {
+	_, err := EntropyFromMnemonic(mnemonic, wl)
+	return err == nil
+}
+
+// NewSeed derives a 64-byte seed from a mnemonic and an optional passphrase
+// with PBKDF2-HMAC-SHA512, 2048 iterations and the salt "mnemonic" ||
+// passphrase, both normalized to NFKD. As the specification prescribes, the
+// mnemonic is not validated; call IsValid or EntropyFromMnemonic first.
+func NewSeed(mnemonic, passphrase string) []byte {
+	m := strings.Join(strings.Fields(norm.NFKD.String(mnemonic)), " ")
+	salt := "mnemonic" + norm.NFKD.String(passphrase)
+	return pbkdf2.Key([]byte(m), []byte(salt), 2048, SeedSize, sha512.New)
+}
+
+// NewKey derives a private key on the registered curve named curve from a
+// seed. It is the master key of the curve's BIP-32 tree (see
+// hdkey.ParamsForCurve), so on secp256k1 it matches the key of "m" in any
+// BIP-32 wallet restored from the same seed.
+func NewKey(seed []byte, curve string) (*ecgeneric.PrivateKey, error) {
+	c, ok := ecgeneric.CurveByName(curve)
+	if !ok {
+		return nil, fmt.Errorf("bip39: unknown curve %q", curve)
+	}
+	master, err := hdkey.NewMaster(seed, hdkey.ParamsForCurve(c))
+	if err != nil {
+		return nil, err
+	}
+	return master.PrivateKey()
+}
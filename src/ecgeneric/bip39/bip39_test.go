@@ -0,0 +1,107 @@
+package bip39_test
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/bip39"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/gost"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/hdkey"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/nist"
+	"github.com/stretchr/testify/require"
+)
+
+// Vectors from the BIP-39 reference implementation, passphrase "TREZOR".
+var vectors = []struct {
+	entropy, mnemonic, seed string
+}{
+	{
+		"00000000000000000000000000000000",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+		"c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+	},
+	{
+		"80808080808080808080808080808080",
+		"letter advice cage absurd amount doctor acoustic avoid letter advice cage above",
+		"d71de856f81a8acc65e6fc851a38d4d7ec216fd0796d0a6827a3ad6ed5511a30fa280f12eb2e47ed2ac03b5c462a0358d18d69fe4f985ec81778c1b370b652a8",
+	},
+	{
+		"7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f",
+		"legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth useful legal will",
+		"f2b94508732bcbacbcc020faefecfc89feafa6649a5491b8c952cede496c214a0c7b3c392d168748f2d4a612bada0753b52a1c7ac53c1e93abd5c6320b9e95dd",
+	},
+	{
+		"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+		"zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo vote",
+		"dd48c104698c30cfe2b6142103248622fb7bb0ff692eebb00089b32d22484e1613912f0a5b694407be899ffd31ed3992c456cdf60f5d4564b8ba3f05a69890ad",
+	},
+}
+
+func TestVectors(t *testing.T) {
+	for _, v := range vectors {
+		entropy, _ := hex.DecodeString(v.entropy)
+		mnemonic, err := bip39.NewMnemonic(entropy, bip39.English)
+		require.NoError(t, err)
+		require.Equal(t, v.mnemonic, mnemonic)
+
+		decoded, err := bip39.EntropyFromMnemonic(v.mnemonic, bip39.English)
+		require.NoError(t, err)
+		require.Equal(t, entropy, decoded)
+
+		require.Equal(t, v.seed, hex.EncodeToString(bip39.NewSeed(v.mnemonic, "TREZOR")))
+	}
+}
+
+func TestInvalidMnemonic(t *testing.T) {
+	_, err := bip39.EntropyFromMnemonic("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon", bip39.English)
+	require.ErrorIs(t, err, bip39.ErrChecksum)
+	_, err = bip39.EntropyFromMnemonic("abandon abandon abandon", bip39.English)
+	require.ErrorIs(t, err, bip39.ErrMnemonicLength)
+	require.False(t, bip39.IsValid("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon sputnik", bip39.English))
+	_, err = bip39.NewMnemonic(make([]byte, 15), bip39.English)
+	require.ErrorIs(t, err, bip39.ErrEntropyLength)
+}
+
+func TestRussian(t *testing.T) {
+	for _, bits := range []int{128, 160, 192, 224, 256} {
+		entropy, err := bip39.NewEntropy(rand.Reader, bits)
+		require.NoError(t, err)
+		mnemonic, err := bip39.NewMnemonic(entropy, bip39.Russian)
+		require.NoError(t, err)
+
+		wl, err := bip39.Detect(mnemonic)
+		require.NoError(t, err)
+		require.Equal(t, bip39.Russian, wl)
+		decoded, err := bip39.EntropyFromMnemonic(mnemonic, wl)
+		require.NoError(t, err)
+		require.Equal(t, entropy, decoded)
+		require.False(t, bip39.IsValid(mnemonic, bip39.English))
+	}
+}
+
+func TestNewKey(t *testing.T) {
+	seed := bip39.NewSeed(vectors[0].mnemonic, "TREZOR")
+
+	// On secp256k1 the key is the BIP-32 master key.
+	master, err := hdkey.NewMaster(seed, hdkey.Bitcoin)
+	require.NoError(t, err)
+	want, err := master.PrivateKey()
+	require.NoError(t, err)
+	priv, err := bip39.NewKey(seed, nist.Secp256k1.Name)
+	require.NoError(t, err)
+	require.Equal(t, want.D, priv.D)
+
+	for _, curve := range []*ecgeneric.CurveParams{&gost.Gost34102001paramSetA, &gost.Gost341012512paramSetA, &gost.Gost341012512paramSetB} {
+		a, err := bip39.NewKey(seed, curve.Name)
+		require.NoError(t, err)
+		b, err := bip39.NewKey(seed, curve.Name)
+		require.NoError(t, err)
+		require.Equal(t, a.D, b.D)
+		require.True(t, curve.IsOnCurve(a.X, a.Y))
+	}
+
+	_, err = bip39.NewKey(seed, "no-such-curve")
+	require.Error(t, err)
+}
@@ -0,0 +1,99 @@
+package bip39
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+//go:embed wordlists/english.txt
+var englishWords string
+
+//go:embed wordlists/russian.txt
+var russianWords string
+
+// Wordlist is a list of 2048 distinct words, each encoding 11 bits of a
+// mnemonic.
+type Wordlist struct {
+	words []string
+	index map[string]int
+}
+
+var (
+	// English is the wordlist of the BIP-39 specification.
+	English = mustWordlist(englishWords)
+
+	// Russian is a list of common Russian nouns without the letters й and ё,
+	// which NFKD would decompose. The BIP-39 repository does not define a
+	// Russian wordlist, so mnemonics written with it can only be restored by
+	// software that ships the same list. No two words share their first four
+	// letters.
+	Russian = mustWordlist(russianWords)
+)
+
+// NewWordlist builds a wordlist from 2048 distinct words. The words are
+// normalized to NFKD, as mnemonics are.
+func NewWordlist(words []string) (*Wordlist, error) {
+	if len(words) != 2048 {
+		return nil, fmt.Errorf("bip39: wordlist has %d words, want 2048", len(words))
+	}
+	wl := &Wordlist{
+		words: make([]string, len(words)),
+		index: make(map[string]int, len(words)),
+	}
+	for i, w := range words {
+		w = norm.NFKD.String(w)
+		if w == "" || strings.ContainsAny(w, " \t\n") {
+			return nil, fmt.Errorf("bip39: invalid word %q", w)
+		}
+		if _, ok := wl.index[w]; ok {
+			return nil, fmt.Errorf("bip39: duplicate word %q", w)
+		}
+		wl.words[i] = w
+		wl.index[w] = i
+	}
+	return wl, nil
+}
+
+func mustWordlist(s string) *Wordlist {
+	wl, err := NewWordlist(strings.Fields(s))
+	if err != nil {
+		panic(err)
+	}
+	return wl
+}
+
+// Word returns the word with index i.
+func (wl *Wordlist) Word(i int) string {
+	return wl.words[i]
+}
+
+// Index returns the index of word in the list.
+func (wl *Wordlist) Index(word string) (int, bool) {
+	i, ok := wl.index[norm.NFKD.String(word)]
+	return i, ok
+}
+
+var errUnknownWordlist = errors.New("bip39: mnemonic does not match any wordlist")
+
+// Detect returns the first of English and Russian that contains every word of
+// mnemonic.
+func Detect(mnemonic string) (*Wordlist, error) {
+	words := strings.Fields(norm.NFKD.String(mnemonic))
+	for _, wl := range []*Wordlist{English, Russian} {
+		ok := len(words) > 0
+		for _, w := range words {
+			if _, found := wl.index[w]; !found {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return wl, nil
+		}
+	}
+	return nil, errUnknownWordlist
+}
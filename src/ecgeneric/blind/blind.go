@@ -0,0 +1,209 @@
+// Package blind implements Schnorr blind signatures over ecgeneric curves.
+//
+// A user obtains a signature on a message the signer never sees, and the
+// signer cannot later link the signature to the session that produced it.
+// This is what anonymous tokens need: the issuer signs blinded tokens, and
+// when a token is redeemed it can check the signature but not tell whom it
+// was issued to.
+//
+// The signatures are ordinary EC-SDSA signatures, as made by gost.SignSDSA,
+// and are checked with gost.VerifySDSA or Verify on any curve, secp256k1
+// included. Two issuance protocols produce them:
+//
+//   - The classic protocol takes three moves: the signer commits to a nonce,
+//     the user sends a blinded challenge, the signer answers. It is secure
+//     only while sessions of one key are run one after another: the ROS
+//     attack of Benhamouda, Lepoint, Loss, Orrù and Raykova forges a
+//     signature from a few hundred concurrent sessions.
+//   - The Clause-Blind protocol of Fuchsbauer and Wolf has the signer commit
+//     to two nonces, the user blind a challenge for each, and the signer
+//     answer one of them at random. It stays secure with concurrent
+//     sessions, at the cost of one more commitment and challenge.
+package blind
+
+import (
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/gost"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/vss"
+)
+
+var (
+	// ErrInvalidResponse is returned by the user when the signer's response
+	// does not match its commitment.
+	ErrInvalidResponse = errors.New("blind: invalid signer response")
+
+	errSessionUsed = errors.New("blind: session already used")
+	errKey         = errors.New("blind: invalid key")
+	errPoint       = errors.New("blind: invalid commitment")
+	errScalar      = errors.New("blind: scalar out of range")
+)
+
+// Signer holds the issuing key.
+type Signer struct {
+	priv *ecgeneric.PrivateKey
+}
+
+// NewSigner returns a signer for priv.
+func NewSigner(priv *ecgeneric.PrivateKey) (*Signer, error) {
+	if priv == nil || priv.Curve == nil || priv.D == nil || priv.D.Sign() <= 0 || priv.D.Cmp(priv.Params().N) >= 0 {
+		return nil, errKey
+	}
+	return &Signer{priv: priv}, nil
+}
+
+// PublicKey returns the key signatures verify under.
+func (s *Signer) PublicKey() *ecgeneric.PublicKey {
+	return &s.priv.PublicKey
+}
+
+// SignerSession is the signer's side of one run of the classic protocol.
+type SignerSession struct {
+	signer *Signer
+	k      *big.Int
+}
+
+// Commit starts a session of the classic protocol and returns the nonce
+// commitment R = k·G for the user. Sessions of one signer must not overlap.
+func (s *Signer) Commit(rand io.Reader) (*SignerSession, ecgeneric.Point, error) {
+	k, R, err := s.nonce(rand)
+	if err != nil {
+		return nil, ecgeneric.Point{}, err
+	}
+	return &SignerSession{signer: s, k: k}, R, nil
+}
+
+// Sign answers the user's blinded challenge c with s = k + c·d. A session
+// answers once; the nonce is forgotten afterwards.
+func (ss *SignerSession) Sign(c *big.Int) (*big.Int, error) {
+	if ss.k == nil {
+		return nil, errSessionUsed
+	}
+	s, err := ss.signer.respond(ss.k, c)
+	ss.k = nil
+	return s, err
+}
+
+// UserSession is the user's side of one run of the classic protocol.
+type UserSession struct {
+	pub *ecgeneric.PublicKey
+	b   blinding
+}
+
+// Blind blinds the signer's commitment R for msg and returns the challenge
+// to send to the signer.
+func Blind(rand io.Reader, pub *ecgeneric.PublicKey, R ecgeneric.Point, msg []byte) (*UserSession, *big.Int, error) {
+	if !validKey(pub) {
+		return nil, nil, errKey
+	}
+	b, err := newBlinding(rand, pub, R, msg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &UserSession{pub: pub, b: b}, b.c, nil
+}
+
+// Unblind checks the signer's response s and returns the EC-SDSA signature
+// (r, s') of the message.
+func (us *UserSession) Unblind(s *big.Int) (r []byte, sig *big.Int, err error) {
+	return us.b.unblind(us.pub, s)
+}
+
+// Verify reports whether (r, s) is a valid signature of msg by pub. It is
+// gost.VerifySDSA.
+func Verify(pub *ecgeneric.PublicKey, msg, r []byte, s *big.Int) bool {
+	return gost.VerifySDSA(pub, msg, r, s)
+}
+
+func (s *Signer) nonce(rand io.Reader) (*big.Int, ecgeneric.Point, error) {
+	curve := s.priv.Params()
+	k, err := vss.RandomScalar(rand, curve)
+	if err != nil {
+		return nil, ecgeneric.Point{}, err
+	}
+	x, y := curve.ScalarBaseMultJ(k.Bytes())
+	return k, ecgeneric.Point{X: x, Y: y}, nil
+}
+
+func (s *Signer) respond(k, c *big.Int) (*big.Int, error) {
+	N := s.priv.Params().N
+	if c == nil || c.Sign() < 0 || c.Cmp(N) >= 0 {
+		return nil, errScalar
+	}
+	r := new(big.Int).Mul(c, s.priv.D)
+	r.Add(r, k)
+	return r.Mod(r, N), nil
+}
+
+// blinding is the user's state for one commitment R. The blinded commitment
+// is R' = R + α·G + β·X with EC-SDSA challenge r = H(R' || m), and the
+// challenge sent to the signer is c = r + β. From the response s = k + c·x
+// the signature is (r, s + α), since (s + α)·G - r·X = R'.
+type blinding struct {
+	R     ecgeneric.Point
+	alpha *big.Int
+	c     *big.Int
+	r     []byte
+}
+
+func newBlinding(rand io.Reader, pub *ecgeneric.PublicKey, R ecgeneric.Point, msg []byte) (blinding, error) {
+	curve := pub.Params()
+	if R.X == nil || R.Y == nil || (R.X.Sign() == 0 && R.Y.Sign() == 0) || !curve.IsOnCurve(R.X, R.Y) {
+		return blinding{}, errPoint
+	}
+	X := ecgeneric.Point{X: pub.X, Y: pub.Y}
+	for {
+		alpha, err := vss.RandomScalar(rand, curve)
+		if err != nil {
+			return blinding{}, err
+		}
+		beta, err := vss.RandomScalar(rand, curve)
+		if err != nil {
+			return blinding{}, err
+		}
+		bx, by := curve.MultiScalarMult([]ecgeneric.Point{{X: curve.Gx, Y: curve.Gy}, X}, []*big.Int{alpha, beta})
+		Rx, Ry := curve.AddJ(R.X, R.Y, bx, by)
+		if Rx.Sign() == 0 && Ry.Sign() == 0 {
+			continue
+		}
+		r := gost.SDSAChallenge(curve, Rx, Ry, msg)
+		e := new(big.Int).SetBytes(r)
+		if e.Mod(e, curve.N).Sign() == 0 {
+			continue
+		}
+		c := e.Add(e, beta)
+		c.Mod(c, curve.N)
+		return blinding{R: R, alpha: alpha, c: c, r: r}, nil
+	}
+}
+
+func (b *blinding) unblind(pub *ecgeneric.PublicKey, s *big.Int) ([]byte, *big.Int, error) {
+	curve := pub.Params()
+	if b.alpha == nil {
+		return nil, nil, errSessionUsed
+	}
+	if s == nil || s.Sign() < 0 || s.Cmp(curve.N) >= 0 {
+		return nil, nil, ErrInvalidResponse
+	}
+	// s·G = R + c·X
+	negC := new(big.Int).Sub(curve.N, b.c)
+	x, y := curve.MultiScalarMult([]ecgeneric.Point{{X: curve.Gx, Y: curve.Gy}, {X: pub.X, Y: pub.Y}}, []*big.Int{s, negC})
+	if x.Cmp(b.R.X) != 0 || y.Cmp(b.R.Y) != 0 {
+		return nil, nil, ErrInvalidResponse
+	}
+	sig := new(big.Int).Add(s, b.alpha)
+	sig.Mod(sig, curve.N)
+	b.alpha = nil
+	if sig.Sign() == 0 {
+		return nil, nil, ErrInvalidResponse
+	}
+	return b.r, sig, nil
+}
+
+func validKey(pub *ecgeneric.PublicKey) bool {
+	return pub != nil && pub.Curve != nil && pub.X != nil && pub.Y != nil &&
+		!(pub.X.Sign() == 0 && pub.Y.Sign() == 0) && pub.Params().IsOnCurve(pub.X, pub.Y)
+}
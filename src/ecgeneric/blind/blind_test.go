@@ -0,0 +1,136 @@
+package blind_test
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/blind"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/gost"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/nist"
+	"github.com/stretchr/testify/require"
+)
+
+var curves = []*ecgeneric.CurveParams{
+	&nist.Secp256k1,
+	&gost.Gost34102001paramSetA,
+	&gost.Gost341012512paramSetA,
+}
+
+func newSigner(t *testing.T, curve *ecgeneric.CurveParams) *blind.Signer {
+	priv, err := ecgeneric.GenerateKey(curve, rand.Reader)
+	require.NoError(t, err)
+	s, err := blind.NewSigner(priv)
+	require.NoError(t, err)
+	return s
+}
+
+func TestClassic(t *testing.T) {
+	for _, curve := range curves {
+		signer := newSigner(t, curve)
+		pub := signer.PublicKey()
+		msg := []byte("token")
+
+		ss, R, err := signer.Commit(rand.Reader)
+		require.NoError(t, err)
+		us, c, err := blind.Blind(rand.Reader, pub, R, msg)
+		require.NoError(t, err)
+		s, err := ss.Sign(c)
+		require.NoError(t, err)
+		r, sig, err := us.Unblind(s)
+		require.NoError(t, err)
+
+		require.True(t, blind.Verify(pub, msg, r, sig), curve.Name)
+		require.True(t, gost.VerifySDSA(pub, msg, r, sig), curve.Name)
+		require.False(t, blind.Verify(pub, []byte("other"), r, sig), curve.Name)
+		// The response in the signature is not the one the signer sent.
+		require.NotEqual(t, s, sig)
+
+		_, err = ss.Sign(c)
+		require.Error(t, err)
+		_, _, err = us.Unblind(s)
+		require.Error(t, err)
+	}
+}
+
+func TestClassicBadResponse(t *testing.T) {
+	curve := &nist.Secp256k1
+	signer := newSigner(t, curve)
+	ss, R, err := signer.Commit(rand.Reader)
+	require.NoError(t, err)
+	us, c, err := blind.Blind(rand.Reader, signer.PublicKey(), R, []byte("token"))
+	require.NoError(t, err)
+	s, err := ss.Sign(c)
+	require.NoError(t, err)
+	_, _, err = us.Unblind(new(big.Int).Add(s, big.NewInt(1)))
+	require.ErrorIs(t, err, blind.ErrInvalidResponse)
+
+	// A response under another key is rejected too.
+	other := newSigner(t, curve)
+	ss, R, err = other.Commit(rand.Reader)
+	require.NoError(t, err)
+	us, c, err = blind.Blind(rand.Reader, signer.PublicKey(), R, []byte("token"))
+	require.NoError(t, err)
+	s, err = ss.Sign(c)
+	require.NoError(t, err)
+	_, _, err = us.Unblind(s)
+	require.ErrorIs(t, err, blind.ErrInvalidResponse)
+
+	_, _, err = blind.Blind(rand.Reader, signer.PublicKey(), ecgeneric.Point{X: big.NewInt(1), Y: big.NewInt(1)}, nil)
+	require.Error(t, err)
+}
+
+func TestClause(t *testing.T) {
+	for _, curve := range curves {
+		signer := newSigner(t, curve)
+		pub := signer.PublicKey()
+
+		// Sessions may run concurrently.
+		const sessions = 8
+		var (
+			ss  [sessions]*blind.ClauseSignerSession
+			us  [sessions]*blind.ClauseUserSession
+			cs  [sessions][2]*big.Int
+			msg [sessions][]byte
+		)
+		for i := range ss {
+			var R [2]ecgeneric.Point
+			var err error
+			ss[i], R, err = signer.CommitClause(rand.Reader)
+			require.NoError(t, err)
+			msg[i] = []byte{byte(i)}
+			us[i], cs[i], err = blind.BlindClause(rand.Reader, pub, R, msg[i])
+			require.NoError(t, err)
+		}
+		for i := range ss {
+			b, s, err := ss[i].Sign(rand.Reader, cs[i])
+			require.NoError(t, err)
+			r, sig, err := us[i].Unblind(b, s)
+			require.NoError(t, err)
+			require.True(t, blind.Verify(pub, msg[i], r, sig), curve.Name)
+
+			_, _, err = ss[i].Sign(rand.Reader, cs[i])
+			require.Error(t, err)
+			_, _, err = us[i].Unblind(1-b, s)
+			require.Error(t, err)
+		}
+	}
+}
+
+func TestClauseWrongClause(t *testing.T) {
+	signer := newSigner(t, &nist.Secp256k1)
+	ss, R, err := signer.CommitClause(rand.Reader)
+	require.NoError(t, err)
+	us, c, err := blind.BlindClause(rand.Reader, signer.PublicKey(), R, []byte("token"))
+	require.NoError(t, err)
+	b, s, err := ss.Sign(rand.Reader, c)
+	require.NoError(t, err)
+	_, _, err = us.Unblind(1-b, s)
+	require.ErrorIs(t, err, blind.ErrInvalidResponse)
+	_, _, err = us.Unblind(2, s)
+	require.ErrorIs(t, err, blind.ErrInvalidResponse)
+	r, sig, err := us.Unblind(b, s)
+	require.NoError(t, err)
+	require.True(t, blind.Verify(signer.PublicKey(), []byte("token"), r, sig))
+}
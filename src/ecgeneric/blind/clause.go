@@ -0,0 +1,88 @@
+package blind
+
+import (
+	"io"
+	"math/big"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+)
+
+// ClauseSignerSession is the signer's side of one run of the Clause-Blind
+// protocol.
+type ClauseSignerSession struct {
+	signer *Signer
+	k      [2]*big.Int
+}
+
+// CommitClause starts a session of the Clause-Blind protocol and returns the
+// two nonce commitments for the user. Any number of sessions may be open at
+// once.
+func (s *Signer) CommitClause(rand io.Reader) (*ClauseSignerSession, [2]ecgeneric.Point, error) {
+	ss := &ClauseSignerSession{signer: s}
+	var R [2]ecgeneric.Point
+	for i := range R {
+		var err error
+		if ss.k[i], R[i], err = s.nonce(rand); err != nil {
+			return nil, R, err
+		}
+	}
+	return ss, R, nil
+}
+
+// Sign picks one of the user's two challenges at random and answers it. It
+// returns the index of the clause it answered and the response; the other
+// nonce is never used.
+func (ss *ClauseSignerSession) Sign(rand io.Reader, c [2]*big.Int) (int, *big.Int, error) {
+	if ss.k[0] == nil {
+		return 0, nil, errSessionUsed
+	}
+	var bit [1]byte
+	if _, err := io.ReadFull(rand, bit[:]); err != nil {
+		return 0, nil, err
+	}
+	i := int(bit[0] & 1)
+	s, err := ss.signer.respond(ss.k[i], c[i])
+	ss.k = [2]*big.Int{}
+	return i, s, err
+}
+
+// ClauseUserSession is the user's side of one run of the Clause-Blind
+// protocol.
+type ClauseUserSession struct {
+	pub *ecgeneric.PublicKey
+	b   [2]blinding
+}
+
+// BlindClause blinds both of the signer's commitments for msg, each with
+// its own factors, and returns the two challenges to send to the signer.
+func BlindClause(rand io.Reader, pub *ecgeneric.PublicKey, R [2]ecgeneric.Point, msg []byte) (*ClauseUserSession, [2]*big.Int, error) {
+	var c [2]*big.Int
+	if !validKey(pub) {
+		return nil, c, errKey
+	}
+	us := &ClauseUserSession{pub: pub}
+	for i := range R {
+		var err error
+		if us.b[i], err = newBlinding(rand, pub, R[i], msg); err != nil {
+			return nil, c, err
+		}
+		c[i] = us.b[i].c
+	}
+	return us, c, nil
+}
+
+// Unblind checks the signer's response s to clause i and returns the EC-SDSA
+// signature (r, s') of the message.
+func (us *ClauseUserSession) Unblind(i int, s *big.Int) (r []byte, sig *big.Int, err error) {
+	if i != 0 && i != 1 {
+		return nil, nil, ErrInvalidResponse
+	}
+	if us.b[1-i].alpha == nil {
+		return nil, nil, errSessionUsed
+	}
+	r, sig, err = us.b[i].unblind(us.pub, s)
+	if err == nil {
+		us.b[1-i].alpha = nil
+	}
+	return r, sig, err
+}
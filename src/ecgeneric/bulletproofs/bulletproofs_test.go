@@ -0,0 +1,188 @@
+package bulletproofs_test
+
+import (
+	"crypto/rand"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/bulletproofs"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/gost"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/nist"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/vss"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/zkp"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	gensOnce sync.Once
+	gens     *bulletproofs.Generators
+)
+
+// secpGens returns generators for up to four aggregated 64-bit values.
+func secpGens(t *testing.T) *bulletproofs.Generators {
+	gensOnce.Do(func() {
+		var err error
+		gens, err = bulletproofs.NewGenerators(&nist.Secp256k1, 256)
+		require.NoError(t, err)
+	})
+	return gens
+}
+
+func blindings(t *testing.T, curve *ecgeneric.CurveParams, m int) []*big.Int {
+	out := make([]*big.Int, m)
+	for i := range out {
+		var err error
+		out[i], err = vss.RandomScalar(rand.Reader, curve)
+		require.NoError(t, err)
+	}
+	return out
+}
+
+func TestRangeProof(t *testing.T) {
+	gens := secpGens(t)
+	curve := &nist.Secp256k1
+	for _, v := range []uint64{0, 1, 1 << 40, ^uint64(0)} {
+		gamma := blindings(t, curve, 1)
+		p, V, err := bulletproofs.Prove(rand.Reader, zkp.NewTranscript("ledger"), gens, []uint64{v}, gamma, 64)
+		require.NoError(t, err)
+		require.True(t, gens.Pedersen.Open(V[0], new(big.Int).SetUint64(v), gamma[0]))
+		require.Len(t, p.IPP.L, 6)
+		require.True(t, p.Verify(rand.Reader, zkp.NewTranscript("ledger"), gens, V, 64), v)
+		require.False(t, p.Verify(rand.Reader, zkp.NewTranscript("other"), gens, V, 64), v)
+
+		// Another commitment, or a shorter range, is rejected.
+		other := gens.Pedersen.Commit(new(big.Int).SetUint64(v), blindings(t, curve, 1)[0])
+		require.False(t, p.Verify(rand.Reader, zkp.NewTranscript("ledger"), gens, []ecgeneric.Point{other}, 64), v)
+		require.False(t, p.Verify(rand.Reader, zkp.NewTranscript("ledger"), gens, V, 32), v)
+
+		b, err := p.MarshalBinary()
+		require.NoError(t, err)
+		var q bulletproofs.RangeProof
+		require.NoError(t, q.UnmarshalBinary(b))
+		require.True(t, q.Verify(rand.Reader, zkp.NewTranscript("ledger"), gens, V, 64), v)
+		require.Error(t, q.UnmarshalBinary(b[:len(b)-1]))
+
+		bad := q
+		bad.THat = new(big.Int).Add(q.THat, big.NewInt(1))
+		require.False(t, bad.Verify(rand.Reader, zkp.NewTranscript("ledger"), gens, V, 64), v)
+		bad = q
+		bad.IPP = &bulletproofs.InnerProductProof{L: q.IPP.L, R: q.IPP.R, A: q.IPP.B, B: q.IPP.A}
+		require.False(t, bad.Verify(rand.Reader, zkp.NewTranscript("ledger"), gens, V, 64), v)
+	}
+
+	_, _, err := bulletproofs.Prove(rand.Reader, zkp.NewTranscript("ledger"), gens, []uint64{256}, blindings(t, curve, 1), 8)
+	require.Error(t, err)
+	_, _, err = bulletproofs.Prove(rand.Reader, zkp.NewTranscript("ledger"), gens, []uint64{1, 2, 3}, blindings(t, curve, 3), 64)
+	require.Error(t, err)
+	_, _, err = bulletproofs.Prove(rand.Reader, zkp.NewTranscript("ledger"), gens, make([]uint64, 8), blindings(t, curve, 8), 64)
+	require.Error(t, err)
+}
+
+func TestAggregated(t *testing.T) {
+	gens := secpGens(t)
+	curve := &nist.Secp256k1
+	values := []uint64{7, 1 << 63, 123456789, 0}
+	p, V, err := bulletproofs.Prove(rand.Reader, zkp.NewTranscript("ledger"), gens, values, blindings(t, curve, 4), 64)
+	require.NoError(t, err)
+	require.Len(t, p.IPP.L, 8)
+	require.True(t, p.Verify(rand.Reader, zkp.NewTranscript("ledger"), gens, V, 64))
+	swapped := []ecgeneric.Point{V[1], V[0], V[2], V[3]}
+	require.False(t, p.Verify(rand.Reader, zkp.NewTranscript("ledger"), gens, swapped, 64))
+	require.False(t, p.Verify(rand.Reader, zkp.NewTranscript("ledger"), gens, V[:2], 64))
+}
+
+// TestOutOfRange commits to 2^32 + 5 and proves only its low 32 bits, which
+// must not pass for the committed value.
+func TestOutOfRange(t *testing.T) {
+	gens := secpGens(t)
+	curve := &nist.Secp256k1
+	gamma := blindings(t, curve, 1)
+	p, _, err := bulletproofs.Prove(rand.Reader, zkp.NewTranscript("ledger"), gens, []uint64{5}, gamma, 32)
+	require.NoError(t, err)
+	V := gens.Pedersen.Commit(new(big.Int).SetUint64(1<<32+5), gamma[0])
+	require.False(t, p.Verify(rand.Reader, zkp.NewTranscript("ledger"), gens, []ecgeneric.Point{V}, 32))
+}
+
+func TestVerifyBatch(t *testing.T) {
+	gens := secpGens(t)
+	curve := &nist.Secp256k1
+	var items []bulletproofs.BatchItem
+	for _, values := range [][]uint64{{1}, {2, 3}, {4}, {5, 6, 7, 8}} {
+		p, V, err := bulletproofs.Prove(rand.Reader, zkp.NewTranscript("ledger"), gens, values, blindings(t, curve, len(values)), 64)
+		require.NoError(t, err)
+		items = append(items, bulletproofs.BatchItem{Proof: p, Commitments: V})
+	}
+	fresh := func(items []bulletproofs.BatchItem) []bulletproofs.BatchItem {
+		out := append([]bulletproofs.BatchItem{}, items...)
+		for i := range out {
+			out[i].Transcript = zkp.NewTranscript("ledger")
+		}
+		return out
+	}
+	require.True(t, bulletproofs.VerifyBatch(rand.Reader, gens, fresh(items), 64))
+
+	bad := fresh(items)
+	bad[2].Commitments = bad[0].Commitments
+	require.False(t, bulletproofs.VerifyBatch(rand.Reader, gens, bad, 64))
+	require.False(t, bulletproofs.VerifyBatch(rand.Reader, gens, nil, 64))
+}
+
+func TestInnerProduct(t *testing.T) {
+	gens := secpGens(t)
+	curve := &nist.Secp256k1
+	const n = 16
+	a := blindings(t, curve, n)
+	b := blindings(t, curve, n)
+	c := new(big.Int)
+	for i := range a {
+		c.Add(c, new(big.Int).Mul(a[i], b[i]))
+	}
+	Q := gens.Pedersen.H
+	G, H := gens.G[:n], gens.H[:n]
+	x, y := curve.MultiScalarMult(append(append([]ecgeneric.Point{Q}, G...), H...), append(append([]*big.Int{c}, a...), b...))
+	P := ecgeneric.Point{X: x, Y: y}
+
+	p, err := bulletproofs.ProveInnerProduct(zkp.NewTranscript("ipp"), curve, Q, G, H, a, b)
+	require.NoError(t, err)
+	require.True(t, p.Verify(zkp.NewTranscript("ipp"), curve, P, Q, G, H))
+	require.False(t, p.Verify(zkp.NewTranscript("ipp"), curve, P, Q, H, G))
+	_, err = bulletproofs.ProveInnerProduct(zkp.NewTranscript("ipp"), curve, Q, G[:3], H[:3], a[:3], b[:3])
+	require.Error(t, err)
+}
+
+func TestGOSTCurve(t *testing.T) {
+	if testing.Short() {
+		t.Skip("generates a second set of generators")
+	}
+	curve := &gost.Gost34102001paramSetA
+	gens, err := bulletproofs.NewGenerators(curve, 64)
+	require.NoError(t, err)
+	p, V, err := bulletproofs.Prove(rand.Reader, zkp.NewTranscript("ledger"), gens, []uint64{1000, 2000}, blindings(t, curve, 2), 32)
+	require.NoError(t, err)
+	require.True(t, p.Verify(rand.Reader, zkp.NewTranscript("ledger"), gens, V, 32))
+}
+
+// wideCurve is GostEx2 under a name of its own. Its prime is 511 bits long
+// but its BitSize is 256, so its points must be encoded at the width of P.
+// The registry leaves example curves out, so the test registers the copy.
+var wideCurve = func() *ecgeneric.CurveParams {
+	c := gost.GostEx2
+	c.Name = "bulletproofs-test-GostEx2"
+	ecgeneric.RegisterCurve(&c)
+	return &c
+}()
+
+func TestMarshalFieldWidth(t *testing.T) {
+	curve := wideCurve
+	gens, err := bulletproofs.NewGenerators(curve, 8)
+	require.NoError(t, err)
+	p, V, err := bulletproofs.Prove(rand.Reader, zkp.NewTranscript("ledger"), gens, []uint64{200}, blindings(t, curve, 1), 8)
+	require.NoError(t, err)
+	b, err := p.MarshalBinary()
+	require.NoError(t, err)
+	var q bulletproofs.RangeProof
+	require.NoError(t, q.UnmarshalBinary(b))
+	require.True(t, q.Verify(rand.Reader, zkp.NewTranscript("ledger"), gens, V, 8))
+}
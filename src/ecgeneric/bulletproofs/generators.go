@@ -0,0 +1,156 @@
+// Package bulletproofs implements Bulletproofs range proofs (Bünz, Bootle,
+// Boneh, Poelstra, Wuille and Maxwell) over ecgeneric curves.
+//
+// A range proof shows that Pedersen commitments V = v·G + γ·H, as made by
+// zkp.Pedersen, hold values in [0, 2^n) without revealing them. Proofs for m
+// values can be aggregated into one proof, whose size grows only with
+// log(n·m). The proof ends in the logarithmic inner-product argument, which
+// is also available on its own.
+//
+// Verification collapses every check of a proof into one multi-scalar
+// multiplication, and VerifyBatch does the same for many proofs at once
+// under random weights. Challenges come from a zkp.Transcript, so proofs can
+// be bound to the surrounding protocol.
+package bulletproofs
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/gost"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/h2c"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/zkp"
+)
+
+// generatorsDST is the hash-to-curve domain separation tag for the vector
+// generators.
+const generatorsDST = "bulletproofs/generators"
+
+// Generators are the points a range proof is made over: the Pedersen bases
+// and the vectors G and H of the inner-product argument. Proofs over n-bit
+// values aggregated m at a time need n·m of each.
+type Generators struct {
+	Pedersen *zkp.Pedersen
+	G, H     []ecgeneric.Point
+}
+
+// NewGenerators returns generators for curve with room for capacity bits in
+// total, such as 64·m for m aggregated 64-bit values. The vector generators
+// are hashed to the curve, so nobody knows discrete logarithms between them
+// and generators of a smaller capacity are a prefix of those of a larger one.
+func NewGenerators(curve *ecgeneric.CurveParams, capacity int) (*Generators, error) {
+	if capacity < 1 {
+		return nil, errors.New("bulletproofs: capacity must be positive")
+	}
+	pp, err := zkp.NewPedersen(curve)
+	if err != nil {
+		return nil, err
+	}
+	suite, err := h2c.NewSuite(curve, gost.SDSAHash(curve), 0)
+	if err != nil {
+		return nil, err
+	}
+	gens := &Generators{
+		Pedersen: pp,
+		G:        make([]ecgeneric.Point, capacity),
+		H:        make([]ecgeneric.Point, capacity),
+	}
+	msg := make([]byte, 1+len(curve.Name)+4)
+	copy(msg[1:], curve.Name)
+	for i := 0; i < capacity; i++ {
+		binary.BigEndian.PutUint32(msg[1+len(curve.Name):], uint32(i))
+		for _, v := range []struct {
+			label byte
+			out   *ecgeneric.Point
+		}{{'G', &gens.G[i]}, {'H', &gens.H[i]}} {
+			msg[0] = v.label
+			if *v.out, err = suite.HashToCurve(msg, []byte(generatorsDST)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return gens, nil
+}
+
+// Curve returns the curve of the generators.
+func (gens *Generators) Curve() *ecgeneric.CurveParams {
+	return gens.Pedersen.Curve
+}
+
+// Capacity returns the number of vector generators.
+func (gens *Generators) Capacity() int {
+	return len(gens.G)
+}
+
+// scalar arithmetic modulo N
+
+type scalars struct {
+	n *big.Int
+}
+
+func (s scalars) mod(a *big.Int) *big.Int { return a.Mod(a, s.n) }
+
+func (s scalars) add(a, b *big.Int) *big.Int { return s.mod(new(big.Int).Add(a, b)) }
+
+func (s scalars) sub(a, b *big.Int) *big.Int { return s.mod(new(big.Int).Sub(a, b)) }
+
+func (s scalars) mul(a, b *big.Int) *big.Int { return s.mod(new(big.Int).Mul(a, b)) }
+
+func (s scalars) inv(a *big.Int) *big.Int { return new(big.Int).ModInverse(a, s.n) }
+
+// powers returns 1, x, …, x^(k-1).
+func (s scalars) powers(x *big.Int, k int) []*big.Int {
+	out := make([]*big.Int, k)
+	if k == 0 {
+		return out
+	}
+	out[0] = big.NewInt(1)
+	for i := 1; i < k; i++ {
+		out[i] = s.mul(out[i-1], x)
+	}
+	return out
+}
+
+func (s scalars) inner(a, b []*big.Int) *big.Int {
+	r := new(big.Int)
+	for i := range a {
+		r.Add(r, new(big.Int).Mul(a[i], b[i]))
+	}
+	return s.mod(r)
+}
+
+func (s scalars) sum(a []*big.Int) *big.Int {
+	r := new(big.Int)
+	for _, v := range a {
+		r.Add(r, v)
+	}
+	return s.mod(r)
+}
+
+// challenge draws a nonzero challenge, so that it can be inverted.
+func challenge(t *zkp.Transcript, curve *ecgeneric.CurveParams, label string) (*big.Int, error) {
+	c := t.ChallengeScalar(curve, label)
+	if c.Sign() == 0 {
+		return nil, errZeroChallenge
+	}
+	return c, nil
+}
+
+func isPowerOfTwo(n int) bool {
+	return n > 0 && n&(n-1) == 0
+}
+
+func msm(curve *ecgeneric.CurveParams, points []ecgeneric.Point, ks []*big.Int) ecgeneric.Point {
+	x, y := curve.MultiScalarMult(points, ks)
+	return ecgeneric.Point{X: x, Y: y}
+}
+
+func validPoint(curve *ecgeneric.CurveParams, p ecgeneric.Point) bool {
+	return p.X != nil && p.Y != nil && !(p.X.Sign() == 0 && p.Y.Sign() == 0) && curve.IsOnCurve(p.X, p.Y)
+}
+
+func validScalar(curve *ecgeneric.CurveParams, k *big.Int) bool {
+	return k != nil && k.Sign() >= 0 && k.Cmp(curve.N) < 0
+}
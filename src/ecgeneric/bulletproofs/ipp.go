@@ -0,0 +1,200 @@
+package bulletproofs
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/zkp"
+)
+
+var (
+	errZeroChallenge = errors.New("bulletproofs: zero challenge")
+	errVectorLength  = errors.New("bulletproofs: vector length must be a power of two and match the generators")
+)
+
+// InnerProductProof shows knowledge of vectors a and b with
+// P = <a, G> + <b, H> + <a, b>·Q. Each round halves the vectors and commits
+// to the cross terms in L and R; A and B are what is left of a and b.
+type InnerProductProof struct {
+	L, R []ecgeneric.Point
+	A, B *big.Int
+}
+
+// ProveInnerProduct proves knowledge of a and b for the point
+// P = <a, G> + <b, H> + <a, b>·Q. All vectors must have the same length, a
+// power of two.
+func ProveInnerProduct(t *zkp.Transcript, curve *ecgeneric.CurveParams, Q ecgeneric.Point, G, H []ecgeneric.Point, a, b []*big.Int) (*InnerProductProof, error) {
+	return proveInnerProduct(t, curve, Q, G, H, nil, a, b)
+}
+
+// proveInnerProduct is ProveInnerProduct over the generators hFactors[i]·H_i,
+// or H_i if hFactors is nil.
+//
+// Rather than folding the generators each round, which costs two scalar
+// multiplications per point, it keeps the factor with which each original
+// generator enters the folded ones and computes L and R as multi-scalar
+// multiplications over the originals.
+func proveInnerProduct(t *zkp.Transcript, curve *ecgeneric.CurveParams, Q ecgeneric.Point, G, H []ecgeneric.Point, hFactors, a, b []*big.Int) (*InnerProductProof, error) {
+	n := len(G)
+	if !isPowerOfTwo(n) || len(H) != n || len(a) != n || len(b) != n || (hFactors != nil && len(hFactors) != n) {
+		return nil, errVectorLength
+	}
+	f := scalars{curve.N}
+	gScale := make([]*big.Int, n)
+	hScale := make([]*big.Int, n)
+	for j := range gScale {
+		gScale[j] = big.NewInt(1)
+		hScale[j] = big.NewInt(1)
+		if hFactors != nil {
+			hScale[j] = hFactors[j]
+		}
+	}
+	a = append([]*big.Int{}, a...)
+	b = append([]*big.Int{}, b...)
+	ippDomain(t, n)
+
+	p := new(InnerProductProof)
+	size := len(G)
+	for n > 1 {
+		half := n / 2
+		aLo, aHi, bLo, bHi := a[:half], a[half:], b[:half], b[half:]
+		cL, cR := f.inner(aLo, bHi), f.inner(aHi, bLo)
+
+		// The folded G_i and H_i gather the originals j with j mod n = i.
+		lPoints, rPoints := []ecgeneric.Point{Q}, []ecgeneric.Point{Q}
+		lScalars, rScalars := []*big.Int{cL}, []*big.Int{cR}
+		for j := 0; j < size; j++ {
+			i := j % n
+			if i >= half {
+				lPoints = append(lPoints, G[j])
+				lScalars = append(lScalars, f.mul(aLo[i-half], gScale[j]))
+				rPoints = append(rPoints, H[j])
+				rScalars = append(rScalars, f.mul(bLo[i-half], hScale[j]))
+			} else {
+				lPoints = append(lPoints, H[j])
+				lScalars = append(lScalars, f.mul(bHi[i], hScale[j]))
+				rPoints = append(rPoints, G[j])
+				rScalars = append(rScalars, f.mul(aHi[i], gScale[j]))
+			}
+		}
+		L := msm(curve, lPoints, lScalars)
+		R := msm(curve, rPoints, rScalars)
+		t.AppendPoint(curve, "L", L)
+		t.AppendPoint(curve, "R", R)
+		p.L, p.R = append(p.L, L), append(p.R, R)
+		u, err := challenge(t, curve, "u")
+		if err != nil {
+			return nil, err
+		}
+		uInv := f.inv(u)
+
+		for i := 0; i < half; i++ {
+			a[i] = f.add(f.mul(aLo[i], u), f.mul(aHi[i], uInv))
+			b[i] = f.add(f.mul(bLo[i], uInv), f.mul(bHi[i], u))
+		}
+		a, b = a[:half], b[:half]
+		for j := 0; j < size; j++ {
+			if j%n >= half {
+				gScale[j] = f.mul(gScale[j], u)
+				hScale[j] = f.mul(hScale[j], uInv)
+			} else {
+				gScale[j] = f.mul(gScale[j], uInv)
+				hScale[j] = f.mul(hScale[j], u)
+			}
+		}
+		n = half
+	}
+	p.A, p.B = a[0], b[0]
+	return p, nil
+}
+
+// Verify reports whether p proves knowledge of the opening of P.
+func (p *InnerProductProof) Verify(t *zkp.Transcript, curve *ecgeneric.CurveParams, P, Q ecgeneric.Point, G, H []ecgeneric.Point) bool {
+	n := len(G)
+	if len(H) != n || !validPoint(curve, P) || !validPoint(curve, Q) {
+		return false
+	}
+	u2, uInv2, s, err := p.verificationScalars(t, curve, n)
+	if err != nil {
+		return false
+	}
+	f := scalars{curve.N}
+	// a·<s, G> + b·<s⁻¹, H> + a·b·Q - P - Σ(u²·L + u⁻²·R) = O
+	points := make([]ecgeneric.Point, 0, 2*n+2+2*len(u2))
+	ks := make([]*big.Int, 0, cap(points))
+	for i := 0; i < n; i++ {
+		points = append(points, G[i], H[i])
+		ks = append(ks, f.mul(p.A, s[i]), f.mul(p.B, s[n-1-i]))
+	}
+	points = append(points, Q, P)
+	ks = append(ks, f.mul(p.A, p.B), big.NewInt(-1))
+	for k := range u2 {
+		points = append(points, p.L[k], p.R[k])
+		ks = append(ks, new(big.Int).Neg(u2[k]), new(big.Int).Neg(uInv2[k]))
+	}
+	R := msm(curve, points, ks)
+	return R.X.Sign() == 0 && R.Y.Sign() == 0
+}
+
+// verificationScalars replays the transcript of p for vectors of length n
+// and returns u_k², u_k⁻² for each round and the coefficients s_i with which
+// G_i enters the final commitment. H_i enters with s_(n-1-i) = 1/s_i.
+func (p *InnerProductProof) verificationScalars(t *zkp.Transcript, curve *ecgeneric.CurveParams, n int) (u2, uInv2, s []*big.Int, err error) {
+	rounds := len(p.L)
+	if !isPowerOfTwo(n) || n != 1<<uint(rounds) || len(p.R) != rounds || !validScalar(curve, p.A) || !validScalar(curve, p.B) {
+		return nil, nil, nil, errVectorLength
+	}
+	f := scalars{curve.N}
+	ippDomain(t, n)
+	u := make([]*big.Int, rounds)
+	uInv := make([]*big.Int, rounds)
+	for k := 0; k < rounds; k++ {
+		if !validPoint(curve, p.L[k]) || !validPoint(curve, p.R[k]) {
+			return nil, nil, nil, errVectorLength
+		}
+		t.AppendPoint(curve, "L", p.L[k])
+		t.AppendPoint(curve, "R", p.R[k])
+		if u[k], err = challenge(t, curve, "u"); err != nil {
+			return nil, nil, nil, err
+		}
+		uInv[k] = f.inv(u[k])
+		u2 = append(u2, f.mul(u[k], u[k]))
+		uInv2 = append(uInv2, f.mul(uInv[k], uInv[k]))
+	}
+	// Round k splits on bit rounds-1-k of the index: the low half is folded
+	// with u⁻¹ and the high half with u.
+	s = make([]*big.Int, n)
+	for i := range s {
+		v := big.NewInt(1)
+		for k := 0; k < rounds; k++ {
+			if i>>uint(rounds-1-k)&1 == 1 {
+				v = f.mul(v, u[k])
+			} else {
+				v = f.mul(v, uInv[k])
+			}
+		}
+		s[i] = v
+	}
+	return u2, uInv2, s, nil
+}
+
+func ippDomain(t *zkp.Transcript, n int) {
+	t.AppendMessage("bulletproofs/ipp", big.NewInt(int64(n)).Bytes())
+}
+
+func concat(vs ...[]ecgeneric.Point) []ecgeneric.Point {
+	var out []ecgeneric.Point
+	for _, v := range vs {
+		out = append(out, v...)
+	}
+	return out
+}
+
+func concatScalars(vs ...[]*big.Int) []*big.Int {
+	var out []*big.Int
+	for _, v := range vs {
+		out = append(out, v...)
+	}
+	return out
+}
@@ -0,0 +1,434 @@
+package bulletproofs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/vss"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/zkp"
+)
+
+// randomizerBits is the size of the random weights of batch verification.
+const randomizerBits = 128
+
+var (
+	errBitSize  = errors.New("bulletproofs: bit size must be 8, 16, 32 or 64")
+	errValues   = errors.New("bulletproofs: number of values must be a power of two")
+	errCapacity = errors.New("bulletproofs: not enough generators")
+	errFormat   = errors.New("bulletproofs: malformed proof")
+)
+
+// RangeProof is an aggregated range proof for m commitments to n-bit
+// values, with the notation of the Bulletproofs paper.
+type RangeProof struct {
+	Curve *ecgeneric.CurveParams
+	// A and S commit to the bits of the values and to blinding vectors.
+	A, S ecgeneric.Point
+	// T1 and T2 commit to the coefficients of t(X).
+	T1, T2 ecgeneric.Point
+	// TauX and Mu are blinding factors and THat = t(x).
+	TauX, Mu, THat *big.Int
+	IPP            *InnerProductProof
+}
+
+func checkShape(gens *Generators, n, m int) error {
+	switch n {
+	case 8, 16, 32, 64:
+	default:
+		return errBitSize
+	}
+	if !isPowerOfTwo(m) {
+		return errValues
+	}
+	if n*m > gens.Capacity() {
+		return errCapacity
+	}
+	return nil
+}
+
+// rangeDomain starts the proof in the transcript and records the
+// commitments.
+func rangeDomain(t *zkp.Transcript, curve *ecgeneric.CurveParams, n int, V []ecgeneric.Point) {
+	t.AppendMessage("bulletproofs/range", []byte{byte(n), byte(len(V) >> 8), byte(len(V))})
+	t.AppendMessage("curve", []byte(curve.Name))
+	for _, v := range V {
+		t.AppendPoint(curve, "V", v)
+	}
+}
+
+// Prove proves that values[j] < 2^n, for the commitments
+// values[j]·G + blindings[j]·H, which it returns. The number of values must
+// be a power of two; to prove fewer, pad with commitments to zero.
+func Prove(rand io.Reader, t *zkp.Transcript, gens *Generators, values []uint64, blindings []*big.Int, n int) (*RangeProof, []ecgeneric.Point, error) {
+	m := len(values)
+	if err := checkShape(gens, n, m); err != nil {
+		return nil, nil, err
+	}
+	if len(blindings) != m {
+		return nil, nil, errors.New("bulletproofs: need one blinding factor per value")
+	}
+	curve := gens.Curve()
+	f := scalars{curve.N}
+	B, Bt := ecgeneric.Point{X: curve.Gx, Y: curve.Gy}, gens.Pedersen.H
+	nm := n * m
+	G, H := gens.G[:nm], gens.H[:nm]
+
+	V := make([]ecgeneric.Point, m)
+	for j, v := range values {
+		if n < 64 && v>>uint(n) != 0 {
+			return nil, nil, fmt.Errorf("bulletproofs: value %d out of range", j)
+		}
+		V[j] = gens.Pedersen.Commit(new(big.Int).SetUint64(v), blindings[j])
+	}
+	rangeDomain(t, curve, n, V)
+
+	random := func() (*big.Int, error) { return vss.RandomScalar(rand, curve) }
+	aL := make([]*big.Int, nm)
+	aR := make([]*big.Int, nm)
+	for i := range aL {
+		aL[i] = big.NewInt(int64(values[i/n] >> uint(i%n) & 1))
+		aR[i] = f.sub(aL[i], big.NewInt(1))
+	}
+	alpha, err := random()
+	if err != nil {
+		return nil, nil, err
+	}
+	A := msm(curve, concat([]ecgeneric.Point{Bt}, G, H), concatScalars([]*big.Int{alpha}, aL, aR))
+
+	sL := make([]*big.Int, nm)
+	sR := make([]*big.Int, nm)
+	for i := range sL {
+		if sL[i], err = random(); err != nil {
+			return nil, nil, err
+		}
+		if sR[i], err = random(); err != nil {
+			return nil, nil, err
+		}
+	}
+	rho, err := random()
+	if err != nil {
+		return nil, nil, err
+	}
+	S := msm(curve, concat([]ecgeneric.Point{Bt}, G, H), concatScalars([]*big.Int{rho}, sL, sR))
+	t.AppendPoint(curve, "A", A)
+	t.AppendPoint(curve, "S", S)
+	y, err := challenge(t, curve, "y")
+	if err != nil {
+		return nil, nil, err
+	}
+	z, err := challenge(t, curve, "z")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// l(X) = l0 + l1·X and r(X) = r0 + r1·X.
+	yPow := f.powers(y, nm)
+	zPow := f.powers(z, m+3)
+	l0 := make([]*big.Int, nm)
+	r0 := make([]*big.Int, nm)
+	r1 := make([]*big.Int, nm)
+	for i := range l0 {
+		l0[i] = f.sub(aL[i], z)
+		r0[i] = f.add(f.mul(yPow[i], f.add(aR[i], z)), f.mul(zPow[2+i/n], new(big.Int).Lsh(big.NewInt(1), uint(i%n))))
+		r1[i] = f.mul(yPow[i], sR[i])
+	}
+	t1 := f.add(f.inner(l0, r1), f.inner(sL, r0))
+	t2 := f.inner(sL, r1)
+	tau1, err := random()
+	if err != nil {
+		return nil, nil, err
+	}
+	tau2, err := random()
+	if err != nil {
+		return nil, nil, err
+	}
+	T1 := gens.Pedersen.Commit(t1, tau1)
+	T2 := gens.Pedersen.Commit(t2, tau2)
+	t.AppendPoint(curve, "T1", T1)
+	t.AppendPoint(curve, "T2", T2)
+	x, err := challenge(t, curve, "x")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	taux := f.add(f.mul(tau2, f.mul(x, x)), f.mul(tau1, x))
+	for j := range blindings {
+		taux = f.add(taux, f.mul(zPow[2+j], blindings[j]))
+	}
+	mu := f.add(alpha, f.mul(rho, x))
+	l := make([]*big.Int, nm)
+	r := make([]*big.Int, nm)
+	for i := range l {
+		l[i] = f.add(l0[i], f.mul(sL[i], x))
+		r[i] = f.add(r0[i], f.mul(r1[i], x))
+	}
+	that := f.inner(l, r)
+	t.AppendMessage("tau_x", taux.Bytes())
+	t.AppendMessage("mu", mu.Bytes())
+	t.AppendMessage("t_hat", that.Bytes())
+	w, err := challenge(t, curve, "w")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The inner-product argument runs over H'_i = y^-i·H_i with Q = w·B.
+	qx, qy := curve.ScalarMultJ(B.X, B.Y, w.Bytes())
+	ipp, err := proveInnerProduct(t, curve, ecgeneric.Point{X: qx, Y: qy}, G, H, f.powers(f.inv(y), nm), l, r)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &RangeProof{
+		Curve: curve,
+		A:     A, S: S, T1: T1, T2: T2,
+		TauX: taux, Mu: mu, THat: that,
+		IPP: ipp,
+	}, V, nil
+}
+
+// Verify reports whether p proves that each of the commitments V holds an
+// n-bit value.
+func (p *RangeProof) Verify(rand io.Reader, t *zkp.Transcript, gens *Generators, V []ecgeneric.Point, n int) bool {
+	return VerifyBatch(rand, gens, []BatchItem{{Transcript: t, Proof: p, Commitments: V}}, n)
+}
+
+// BatchItem is one proof for VerifyBatch with its transcript and
+// commitments.
+type BatchItem struct {
+	Transcript  *zkp.Transcript
+	Proof       *RangeProof
+	Commitments []ecgeneric.Point
+}
+
+// VerifyBatch reports whether every proof in items is valid for n-bit values.
+// The checks of all proofs are combined under random weights drawn from rand
+// and evaluated as a single multi-scalar multiplication, which shares the
+// generator terms between proofs. A false result does not say which proof
+// failed.
+func VerifyBatch(rand io.Reader, gens *Generators, items []BatchItem, n int) bool {
+	curve := gens.Curve()
+	f := scalars{curve.N}
+	size := 0
+	for _, it := range items {
+		if err := checkShape(gens, n, len(it.Commitments)); err != nil || it.Proof == nil || it.Transcript == nil {
+			return false
+		}
+		if len(it.Commitments)*n > size {
+			size = len(it.Commitments) * n
+		}
+	}
+	if len(items) == 0 {
+		return false
+	}
+
+	gCoef := make([]*big.Int, size)
+	hCoef := make([]*big.Int, size)
+	for i := range gCoef {
+		gCoef[i], hCoef[i] = new(big.Int), new(big.Int)
+	}
+	bCoef, btCoef := new(big.Int), new(big.Int)
+	var points []ecgeneric.Point
+	var ks []*big.Int
+	limit := new(big.Int).Lsh(big.NewInt(1), randomizerBits)
+
+	for _, it := range items {
+		p, V, t := it.Proof, it.Commitments, it.Transcript
+		m := len(V)
+		nm := n * m
+		if !p.valid(curve) {
+			return false
+		}
+		for _, v := range V {
+			if !validPoint(curve, v) {
+				return false
+			}
+		}
+		rangeDomain(t, curve, n, V)
+		t.AppendPoint(curve, "A", p.A)
+		t.AppendPoint(curve, "S", p.S)
+		y, err := challenge(t, curve, "y")
+		if err != nil {
+			return false
+		}
+		z, err := challenge(t, curve, "z")
+		if err != nil {
+			return false
+		}
+		t.AppendPoint(curve, "T1", p.T1)
+		t.AppendPoint(curve, "T2", p.T2)
+		x, err := challenge(t, curve, "x")
+		if err != nil {
+			return false
+		}
+		t.AppendMessage("tau_x", p.TauX.Bytes())
+		t.AppendMessage("mu", p.Mu.Bytes())
+		t.AppendMessage("t_hat", p.THat.Bytes())
+		w, err := challenge(t, curve, "w")
+		if err != nil {
+			return false
+		}
+		u2, uInv2, s, err := p.IPP.verificationScalars(t, curve, nm)
+		if err != nil {
+			return false
+		}
+
+		// ca weighs the inner-product check, cb the check of t(x).
+		ca, err := randInt(rand, limit)
+		if err != nil {
+			return false
+		}
+		cb, err := randInt(rand, limit)
+		if err != nil {
+			return false
+		}
+
+		yInvPow := f.powers(f.inv(y), nm)
+		zPow := f.powers(z, m+3)
+		a, b := p.IPP.A, p.IPP.B
+		for i := 0; i < nm; i++ {
+			// G_i: -z - a·s_i
+			gi := f.sub(new(big.Int).Neg(z), f.mul(a, s[i]))
+			gCoef[i].Add(gCoef[i], f.mul(ca, gi))
+			// H_i: z + y^-i·(z^(2+j)·2^(i mod n) - b/s_i)
+			wi := f.mul(zPow[2+i/n], new(big.Int).Lsh(big.NewInt(1), uint(i%n)))
+			hi := f.add(z, f.mul(yInvPow[i], f.sub(wi, f.mul(b, s[nm-1-i]))))
+			hCoef[i].Add(hCoef[i], f.mul(ca, hi))
+		}
+
+		// δ(y, z) = (z - z²)·Σ y^i - Σ_j z^(3+j)·(2^n - 1)
+		delta := f.mul(f.sub(z, zPow[2]), f.sum(f.powers(y, nm)))
+		twoN := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(n)), big.NewInt(1))
+		for j := 0; j < m; j++ {
+			delta = f.sub(delta, f.mul(zPow[3+j], twoN))
+		}
+		bCoef.Add(bCoef, f.mul(ca, f.mul(w, f.sub(p.THat, f.mul(a, b)))))
+		bCoef.Add(bCoef, f.mul(cb, f.sub(p.THat, delta)))
+		btCoef.Add(btCoef, f.sub(f.mul(cb, p.TauX), f.mul(ca, p.Mu)))
+
+		points = append(points, p.A, p.S, p.T1, p.T2)
+		ks = append(ks, ca, f.mul(ca, x), f.mul(cb, new(big.Int).Neg(x)), f.mul(cb, new(big.Int).Neg(f.mul(x, x))))
+		for j, v := range V {
+			points = append(points, v)
+			ks = append(ks, f.mul(cb, new(big.Int).Neg(zPow[2+j])))
+		}
+		for k := range u2 {
+			points = append(points, p.IPP.L[k], p.IPP.R[k])
+			ks = append(ks, f.mul(ca, u2[k]), f.mul(ca, uInv2[k]))
+		}
+	}
+
+	points = append(points, concat(
+		[]ecgeneric.Point{{X: curve.Gx, Y: curve.Gy}, gens.Pedersen.H},
+		gens.G[:size], gens.H[:size])...)
+	ks = append(ks, concatScalars([]*big.Int{bCoef, btCoef}, gCoef, hCoef)...)
+	R := msm(curve, points, ks)
+	return R.X.Sign() == 0 && R.Y.Sign() == 0
+}
+
+func (p *RangeProof) valid(curve *ecgeneric.CurveParams) bool {
+	if p.Curve == nil || (p.Curve != curve && p.Curve.Name != curve.Name) || p.IPP == nil {
+		return false
+	}
+	for _, q := range []ecgeneric.Point{p.A, p.S, p.T1, p.T2} {
+		if !validPoint(curve, q) {
+			return false
+		}
+	}
+	return validScalar(curve, p.TauX) && validScalar(curve, p.Mu) && validScalar(curve, p.THat)
+}
+
+func randInt(rand io.Reader, max *big.Int) (*big.Int, error) {
+	if rand == nil {
+		return nil, errors.New("bulletproofs: no randomness source")
+	}
+	b := make([]byte, (max.BitLen()+7)/8)
+	if _, err := io.ReadFull(rand, b); err != nil {
+		return nil, err
+	}
+	k := new(big.Int).SetBytes(b)
+	return k.Mod(k, max), nil
+}
+
+// MarshalBinary encodes the proof as the length-prefixed curve name, the
+// compressed points A, S, T1 and T2, the scalars TauX, Mu and THat, the
+// final scalars of the inner-product argument and its L and R points in
+// round order.
+func (p *RangeProof) MarshalBinary() ([]byte, error) {
+	curve := p.Curve
+	if curve == nil || curve.Name == "" || len(curve.Name) > 255 {
+		return nil, errors.New("bulletproofs: curve needs a registered name")
+	}
+	if !p.valid(curve) || len(p.IPP.L) != len(p.IPP.R) || !validScalar(curve, p.IPP.A) || !validScalar(curve, p.IPP.B) {
+		return nil, errFormat
+	}
+	b := append([]byte{byte(len(curve.Name))}, curve.Name...)
+	for _, q := range []ecgeneric.Point{p.A, p.S, p.T1, p.T2} {
+		b = append(b, ecgeneric.MarshalCompressed(curve, q.X, q.Y)...)
+	}
+	size := (curve.N.BitLen() + 7) / 8
+	for _, k := range []*big.Int{p.TauX, p.Mu, p.THat, p.IPP.A, p.IPP.B} {
+		b = append(b, k.FillBytes(make([]byte, size))...)
+	}
+	for i := range p.IPP.L {
+		b = append(b, ecgeneric.MarshalCompressed(curve, p.IPP.L[i].X, p.IPP.L[i].Y)...)
+		b = append(b, ecgeneric.MarshalCompressed(curve, p.IPP.R[i].X, p.IPP.R[i].Y)...)
+	}
+	return b, nil
+}
+
+// UnmarshalBinary decodes a proof encoded by MarshalBinary. The curve must
+// be registered with ecgeneric.RegisterCurve.
+func (p *RangeProof) UnmarshalBinary(data []byte) error {
+	if len(data) < 1 || len(data) < 1+int(data[0]) {
+		return errFormat
+	}
+	name := string(data[1 : 1+data[0]])
+	data = data[1+len(name):]
+	curve, ok := ecgeneric.CurveByName(name)
+	if !ok {
+		return fmt.Errorf("bulletproofs: unknown curve %q", name)
+	}
+	pLen := 1 + (curve.P.BitLen()+7)/8
+	size := (curve.N.BitLen() + 7) / 8
+	fixed := 4*pLen + 5*size
+	if len(data) < fixed || (len(data)-fixed)%(2*pLen) != 0 {
+		return errFormat
+	}
+	point := func() (ecgeneric.Point, error) {
+		x, y := ecgeneric.UnmarshalCompressed(curve, data[:pLen])
+		data = data[pLen:]
+		if x == nil {
+			return ecgeneric.Point{}, errFormat
+		}
+		return ecgeneric.Point{X: x, Y: y}, nil
+	}
+	out := RangeProof{Curve: curve, IPP: new(InnerProductProof)}
+	var err error
+	for _, q := range []*ecgeneric.Point{&out.A, &out.S, &out.T1, &out.T2} {
+		if *q, err = point(); err != nil {
+			return err
+		}
+	}
+	for _, k := range []**big.Int{&out.TauX, &out.Mu, &out.THat, &out.IPP.A, &out.IPP.B} {
+		*k = new(big.Int).SetBytes(data[:size])
+		data = data[size:]
+		if !validScalar(curve, *k) {
+			return errFormat
+		}
+	}
+	for len(data) > 0 {
+		L, err := point()
+		if err != nil {
+			return err
+		}
+		R, err := point()
+		if err != nil {
+			return err
+		}
+		out.IPP.L, out.IPP.R = append(out.IPP.L, L), append(out.IPP.R, R)
+	}
+	*p = out
+	return nil
+}
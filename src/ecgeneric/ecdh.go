@@ -0,0 +1,86 @@
+package ecgeneric
+
+import (
+	"errors"
+	"math/big"
+)
+
+var (
+	ErrInvalidPublicKey = errors.New("ecgeneric: invalid public key")
+	ErrCurveMismatch    = errors.New("ecgeneric: keys are on different curves")
+	ErrSharedIsIdentity = errors.New("ecgeneric: shared point is the point at infinity")
+)
+
+// Cofactor returns h = #E/N. It is computed as round((P+1)/N), which is exact
+// whenever N > 4√P, as it is for every curve used in practice.
+func (curve *CurveParams) Cofactor() *big.Int {
+	h := new(big.Int).Add(curve.P, one)
+	h.Add(h, new(big.Int).Rsh(curve.N, 1))
+	return h.Div(h, curve.N)
+}
+
+// sameCurve reports whether a and b have the same domain parameters.
+func sameCurve(a, b *CurveParams) bool {
+	return a == b || (a.P.Cmp(b.P) == 0 && a.N.Cmp(b.N) == 0 &&
+		a.A.Cmp(b.A) == 0 && a.B.Cmp(b.B) == 0 &&
+		a.Gx.Cmp(b.Gx) == 0 && a.Gy.Cmp(b.Gy) == 0)
+}
+
+// ValidatePublicKey performs the full public key validation of SEC 1,
+// Version 2.0, Section 3.2.2.1: pub must not be the point at infinity, must
+// lie on the curve and, when the cofactor is not 1, must have order N.
+func ValidatePublicKey(pub *PublicKey) error {
+	if pub == nil || pub.Curve == nil || pub.X == nil || pub.Y == nil {
+		return ErrInvalidPublicKey
+	}
+	curve := pub.Params()
+	if pub.X.Sign() == 0 && pub.Y.Sign() == 0 {
+		return ErrInvalidPublicKey
+	}
+	if !curve.IsOnCurve(pub.X, pub.Y) {
+		return ErrInvalidPublicKey
+	}
+	if curve.Cofactor().Cmp(one) != 0 {
+		x, y := curve.ScalarMultJ(pub.X, pub.Y, curve.N.Bytes())
+		if x.Sign() != 0 || y.Sign() != 0 {
+			return ErrInvalidPublicKey
+		}
+	}
+	return nil
+}
+
+// ECDH returns the x coordinate of priv.D·pub, left-padded to the field size,
+// as specified for the Elliptic Curve Diffie-Hellman primitive in SEC 1,
+// Version 2.0, Section 3.3.1. The peer key is fully validated first.
+func ECDH(priv *PrivateKey, pub *PublicKey) ([]byte, error) {
+	if err := ValidatePublicKey(pub); err != nil {
+		return nil, err
+	}
+	return sharedX(priv, pub, priv.D)
+}
+
+// ECDHCofactor is the Elliptic Curve Cofactor Diffie-Hellman primitive of
+// SEC 1, Version 2.0, Section 3.3.2: it multiplies pub by h·priv.D, which
+// clears any small-order component of pub, so only the cheaper partial
+// validation (on the curve, not the identity) is done.
+func ECDHCofactor(priv *PrivateKey, pub *PublicKey) ([]byte, error) {
+	if pub == nil || pub.Curve == nil || pub.X == nil || pub.Y == nil ||
+		(pub.X.Sign() == 0 && pub.Y.Sign() == 0) || !pub.Params().IsOnCurve(pub.X, pub.Y) {
+		return nil, ErrInvalidPublicKey
+	}
+	curve := pub.Params()
+	// h·d must not be reduced mod N, or the small-order part would survive.
+	return sharedX(priv, pub, new(big.Int).Mul(priv.D, curve.Cofactor()))
+}
+
+func sharedX(priv *PrivateKey, pub *PublicKey, k *big.Int) ([]byte, error) {
+	curve := pub.Params()
+	if !sameCurve(priv.Params(), curve) {
+		return nil, ErrCurveMismatch
+	}
+	x, y := curve.ScalarMultGLV(pub.X, pub.Y, k.Bytes())
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return nil, ErrSharedIsIdentity
+	}
+	return x.FillBytes(make([]byte, (curve.P.BitLen()+7)/8)), nil
+}
@@ -0,0 +1,208 @@
+// Package ecdh adapts ecgeneric curves to the API of the standard library's
+// crypto/ecdh package, so that code written against that API can switch to
+// any curve in the ecgeneric registry.
+//
+// Public keys are encoded in uncompressed SEC 1 form and private keys as
+// big-endian scalars of the order's byte length, as crypto/ecdh does for the
+// NIST curves.
+package ecdh
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/subtle"
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+)
+
+// Curve mirrors crypto/ecdh.Curve.
+type Curve interface {
+	// GenerateKey generates a random PrivateKey.
+	GenerateKey(rand io.Reader) (*PrivateKey, error)
+	// NewPrivateKey checks that key is valid and returns a PrivateKey.
+	NewPrivateKey(key []byte) (*PrivateKey, error)
+	// NewPublicKey checks that key is valid and returns a PublicKey.
+	NewPublicKey(key []byte) (*PublicKey, error)
+	// Params returns the underlying ecgeneric parameters.
+	Params() *ecgeneric.CurveParams
+}
+
+type genericCurve struct {
+	params   *ecgeneric.CurveParams
+	cofactor bool
+}
+
+// FromCurve returns a Curve for params whose ECDH method uses the plain
+// SEC 1 primitive with full validation of the peer key.
+func FromCurve(params *ecgeneric.CurveParams) Curve {
+	return &genericCurve{params: params}
+}
+
+// FromCurveCofactor is like FromCurve but uses cofactor Diffie-Hellman.
+func FromCurveCofactor(params *ecgeneric.CurveParams) Curve {
+	return &genericCurve{params: params, cofactor: true}
+}
+
+// CurveByName returns the Curve for a curve in the ecgeneric registry.
+func CurveByName(name string) (Curve, error) {
+	params, ok := ecgeneric.CurveByName(name)
+	if !ok {
+		return nil, errors.New("ecdh: unknown curve " + name)
+	}
+	return FromCurve(params), nil
+}
+
+func (c *genericCurve) Params() *ecgeneric.CurveParams {
+	return c.params
+}
+
+func (c *genericCurve) String() string {
+	return c.params.Name
+}
+
+func (c *genericCurve) scalarLen() int {
+	return (c.params.N.BitLen() + 7) / 8
+}
+
+func (c *genericCurve) GenerateKey(rand io.Reader) (*PrivateKey, error) {
+	priv, err := ecgeneric.GenerateKey(c.params, rand)
+	if err != nil {
+		return nil, err
+	}
+	return c.newPrivateKey(priv), nil
+}
+
+func (c *genericCurve) NewPrivateKey(key []byte) (*PrivateKey, error) {
+	if len(key) != c.scalarLen() {
+		return nil, errors.New("ecdh: invalid private key size")
+	}
+	d := new(big.Int).SetBytes(key)
+	if d.Sign() == 0 || d.Cmp(c.params.N) >= 0 {
+		return nil, errors.New("ecdh: invalid private key")
+	}
+	priv := &ecgeneric.PrivateKey{D: d}
+	priv.Curve = c.params
+	priv.X, priv.Y = c.params.ScalarBaseMultGLV(key)
+	return c.newPrivateKey(priv), nil
+}
+
+func (c *genericCurve) newPrivateKey(priv *ecgeneric.PrivateKey) *PrivateKey {
+	pub := &PublicKey{
+		curve: c,
+		key:   &priv.PublicKey,
+		raw:   ecgeneric.Marshal(c.params, priv.X, priv.Y),
+	}
+	return &PrivateKey{
+		curve:     c,
+		key:       priv,
+		raw:       priv.D.FillBytes(make([]byte, c.scalarLen())),
+		publicKey: pub,
+	}
+}
+
+func (c *genericCurve) NewPublicKey(key []byte) (*PublicKey, error) {
+	x, y := ecgeneric.Unmarshal(c.params, key)
+	if x == nil {
+		return nil, errors.New("ecdh: invalid public key")
+	}
+	pub := &ecgeneric.PublicKey{Curve: c.params, X: x, Y: y}
+	if !c.cofactor {
+		if err := ecgeneric.ValidatePublicKey(pub); err != nil {
+			return nil, errors.New("ecdh: invalid public key")
+		}
+	}
+	return &PublicKey{curve: c, key: pub, raw: append([]byte{}, key...)}, nil
+}
+
+func (c *genericCurve) ecdh(local *PrivateKey, remote *PublicKey) ([]byte, error) {
+	if c.cofactor {
+		return ecgeneric.ECDHCofactor(local.key, remote.key)
+	}
+	return ecgeneric.ECDH(local.key, remote.key)
+}
+
+// PrivateKey mirrors crypto/ecdh.PrivateKey.
+type PrivateKey struct {
+	curve     *genericCurve
+	key       *ecgeneric.PrivateKey
+	raw       []byte
+	publicKey *PublicKey
+}
+
+// ECDH performs an ECDH exchange and returns the shared secret, the x
+// coordinate of the shared point. remote must be on the same curve as k.
+func (k *PrivateKey) ECDH(remote *PublicKey) ([]byte, error) {
+	if remote.curve.params != k.curve.params {
+		return nil, errors.New("ecdh: private key and public key curves do not match")
+	}
+	return k.curve.ecdh(k, remote)
+}
+
+// Bytes returns a copy of the encoding of the private key.
+func (k *PrivateKey) Bytes() []byte {
+	return append([]byte{}, k.raw...)
+}
+
+// Equal returns whether x represents the same private key as k.
+func (k *PrivateKey) Equal(x crypto.PrivateKey) bool {
+	xx, ok := x.(*PrivateKey)
+	if !ok {
+		return false
+	}
+	return k.curve.params == xx.curve.params && subtle.ConstantTimeCompare(k.raw, xx.raw) == 1
+}
+
+// Curve returns the curve of k.
+func (k *PrivateKey) Curve() Curve {
+	return k.curve
+}
+
+// PublicKey returns the public key corresponding to k.
+func (k *PrivateKey) PublicKey() *PublicKey {
+	return k.publicKey
+}
+
+// Public implements the implicit interface of all standard library private
+// keys.
+func (k *PrivateKey) Public() crypto.PublicKey {
+	return k.PublicKey()
+}
+
+// Generic returns the underlying ecgeneric key.
+func (k *PrivateKey) Generic() *ecgeneric.PrivateKey {
+	return k.key
+}
+
+// PublicKey mirrors crypto/ecdh.PublicKey.
+type PublicKey struct {
+	curve *genericCurve
+	key   *ecgeneric.PublicKey
+	raw   []byte
+}
+
+// Bytes returns a copy of the encoding of the public key.
+func (k *PublicKey) Bytes() []byte {
+	return append([]byte{}, k.raw...)
+}
+
+// Equal returns whether x represents the same public key as k.
+func (k *PublicKey) Equal(x crypto.PublicKey) bool {
+	xx, ok := x.(*PublicKey)
+	if !ok {
+		return false
+	}
+	return k.curve.params == xx.curve.params && bytes.Equal(k.raw, xx.raw)
+}
+
+// Curve returns the curve of k.
+func (k *PublicKey) Curve() Curve {
+	return k.curve
+}
+
+// Generic returns the underlying ecgeneric key.
+func (k *PublicKey) Generic() *ecgeneric.PublicKey {
+	return k.key
+}
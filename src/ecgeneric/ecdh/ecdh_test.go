@@ -0,0 +1,65 @@
+package ecdh_test
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/ecdh"
+	_ "github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/gost"
+	_ "github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/nist"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisteredCurves(t *testing.T) {
+	names := ecgeneric.RegisteredCurves()
+	require.NotEmpty(t, names)
+	for _, name := range names {
+		curve, err := ecdh.CurveByName(name)
+		require.NoError(t, err)
+
+		alice, err := curve.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		bob, err := curve.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		// Keys survive a round trip through their encodings.
+		bobPub, err := curve.NewPublicKey(bob.PublicKey().Bytes())
+		require.NoError(t, err)
+		require.True(t, bobPub.Equal(bob.PublicKey()))
+		alice2, err := curve.NewPrivateKey(alice.Bytes())
+		require.NoError(t, err)
+		require.True(t, alice2.Equal(alice))
+		require.True(t, alice2.PublicKey().Equal(alice.PublicKey()))
+
+		s1, err := alice.ECDH(bobPub)
+		require.NoError(t, err)
+		s2, err := bob.ECDH(alice.PublicKey())
+		require.NoError(t, err)
+		require.Equal(t, s1, s2, name)
+
+		bad := bob.PublicKey().Bytes()
+		bad[len(bad)-1] ^= 1
+		_, err = curve.NewPublicKey(bad)
+		require.Error(t, err)
+		_, err = curve.NewPrivateKey(make([]byte, len(alice.Bytes())))
+		require.Error(t, err)
+	}
+
+	_, err := ecdh.CurveByName("no such curve")
+	require.Error(t, err)
+}
+
+func TestCurveMismatch(t *testing.T) {
+	a, err := ecdh.CurveByName("secp256k1")
+	require.NoError(t, err)
+	b, err := ecdh.CurveByName("id-gostR3410-2001-CryptoPro-A-ParamSet")
+	require.NoError(t, err)
+
+	ka, err := a.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	kb, err := b.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	_, err = ka.ECDH(kb.PublicKey())
+	require.Error(t, err)
+}
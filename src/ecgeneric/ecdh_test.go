@@ -0,0 +1,99 @@
+package ecgeneric_test
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/gost"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/nist"
+	"github.com/stretchr/testify/require"
+)
+
+// toyCofactor4 is y² = x³ + x + 3 over F₁₀₁₃ with #E = 4·257.
+var toyCofactor4 = ecgeneric.CurveParams{
+	P:       big.NewInt(1013),
+	N:       big.NewInt(257),
+	A:       big.NewInt(1),
+	B:       big.NewInt(3),
+	Gx:      big.NewInt(792),
+	Gy:      big.NewInt(480),
+	BitSize: 10,
+	Name:    "toy-h4",
+}
+
+func TestECDH(t *testing.T) {
+	for _, curve := range []*ecgeneric.CurveParams{&gost.Gost34102001paramSetA, &gost.Gost341012512paramSetB, &gost.GostEx2, &nist.Secp256k1} {
+		a, err := ecgeneric.GenerateKey(curve, rand.Reader)
+		require.NoError(t, err)
+		b, err := ecgeneric.GenerateKey(curve, rand.Reader)
+		require.NoError(t, err)
+
+		ab, err := ecgeneric.ECDH(a, &b.PublicKey)
+		require.NoError(t, err)
+		ba, err := ecgeneric.ECDH(b, &a.PublicKey)
+		require.NoError(t, err)
+		require.Equal(t, ab, ba)
+		require.Len(t, ab, (curve.P.BitLen()+7)/8)
+
+		abc, err := ecgeneric.ECDHCofactor(a, &b.PublicKey)
+		require.NoError(t, err)
+		require.Equal(t, ab, abc)
+
+		off := &ecgeneric.PublicKey{Curve: curve, X: b.X, Y: new(big.Int).Add(b.Y, big.NewInt(1))}
+		_, err = ecgeneric.ECDH(a, off)
+		require.ErrorIs(t, err, ecgeneric.ErrInvalidPublicKey)
+		_, err = ecgeneric.ECDHCofactor(a, off)
+		require.ErrorIs(t, err, ecgeneric.ErrInvalidPublicKey)
+		inf := &ecgeneric.PublicKey{Curve: curve, X: new(big.Int), Y: new(big.Int)}
+		_, err = ecgeneric.ECDH(a, inf)
+		require.ErrorIs(t, err, ecgeneric.ErrInvalidPublicKey)
+	}
+
+	a, err := ecgeneric.GenerateKey(&gost.Gost34102001paramSetA, rand.Reader)
+	require.NoError(t, err)
+	b, err := ecgeneric.GenerateKey(&nist.Secp256k1, rand.Reader)
+	require.NoError(t, err)
+	_, err = ecgeneric.ECDH(a, &b.PublicKey)
+	require.ErrorIs(t, err, ecgeneric.ErrCurveMismatch)
+}
+
+func TestECDHCofactor(t *testing.T) {
+	curve := &toyCofactor4
+	require.Equal(t, big.NewInt(4), curve.Cofactor())
+	require.Equal(t, big.NewInt(1), nist.Secp256k1.Cofactor())
+	require.Equal(t, big.NewInt(1), gost.Gost341012512paramSetA.Cofactor())
+
+	a := &ecgeneric.PrivateKey{D: big.NewInt(100)}
+	a.Curve = curve
+	a.X, a.Y = curve.ScalarBaseMultJ(a.D.Bytes())
+	b := &ecgeneric.PrivateKey{D: big.NewInt(77)}
+	b.Curve = curve
+	b.X, b.Y = curve.ScalarBaseMultJ(b.D.Bytes())
+
+	ab, err := ecgeneric.ECDHCofactor(a, &b.PublicKey)
+	require.NoError(t, err)
+	ba, err := ecgeneric.ECDHCofactor(b, &a.PublicKey)
+	require.NoError(t, err)
+	require.Equal(t, ab, ba)
+
+	// (10, 0) has order 2 and (2, 191) lies outside the subgroup of G.
+	small := &ecgeneric.PublicKey{Curve: curve, X: big.NewInt(10), Y: big.NewInt(0)}
+	outside := &ecgeneric.PublicKey{Curve: curve, X: big.NewInt(2), Y: big.NewInt(191)}
+	require.True(t, curve.IsOnCurve(small.X, small.Y))
+	require.True(t, curve.IsOnCurve(outside.X, outside.Y))
+
+	require.ErrorIs(t, ecgeneric.ValidatePublicKey(small), ecgeneric.ErrInvalidPublicKey)
+	require.ErrorIs(t, ecgeneric.ValidatePublicKey(outside), ecgeneric.ErrInvalidPublicKey)
+	require.NoError(t, ecgeneric.ValidatePublicKey(&b.PublicKey))
+
+	_, err = ecgeneric.ECDH(a, outside)
+	require.ErrorIs(t, err, ecgeneric.ErrInvalidPublicKey)
+	_, err = ecgeneric.ECDHCofactor(a, small)
+	require.ErrorIs(t, err, ecgeneric.ErrSharedIsIdentity)
+
+	// Cofactor DH ignores the small-order part of outside.
+	_, err = ecgeneric.ECDHCofactor(a, outside)
+	require.NoError(t, err)
+}
@@ -537,6 +537,9 @@ func (curve *CurveParams) DoubleJ(x1, y1 *big.Int) (*big.Int, *big.Int) {
 // doubleJacobian takes a point in Jacobian coordinates, (x, y, z), and
 // returns its double, also in Jacobian form.
 func (curve *CurveParams) doubleJacobian(x, y, z *big.Int) (*big.Int, *big.Int, *big.Int) {
+	if !curve.aIsMinusThree() {
+		return curve.doubleJacobianGeneric(x, y, z)
+	}
 	// See https://hyperelliptic.org/EFD/g1p/auto-shortw-jacobian-3.html#doubling-dbl-2001-b
 	delta := new(big.Int).Mul(z, z)
 	delta.Mod(delta, curve.P)
@@ -595,6 +598,64 @@ func (curve *CurveParams) doubleJacobian(x, y, z *big.Int) (*big.Int, *big.Int,
 	return x3, y3, z3
 }
 
+// aIsMinusThree reports whether the curve constant a is -3 mod p, which is
+// what the dbl-2001-b doubling formula assumes.
+func (curve *CurveParams) aIsMinusThree() bool {
+	a := new(big.Int).Add(curve.A, big.NewInt(3))
+	return a.Mod(a, curve.P).Sign() == 0
+}
+
+// doubleJacobianGeneric doubles a Jacobian point on a curve with an arbitrary
+// constant a, such as secp256k1 (a = 0) or the GOST test curves.
+func (curve *CurveParams) doubleJacobianGeneric(x, y, z *big.Int) (*big.Int, *big.Int, *big.Int) {
+	// See https://hyperelliptic.org/EFD/g1p/auto-shortw-jacobian.html#doubling-dbl-2007-bl
+	if z.Sign() == 0 || y.Sign() == 0 {
+		return new(big.Int), new(big.Int), new(big.Int)
+	}
+	xx := new(big.Int).Mul(x, x)
+	xx.Mod(xx, curve.P)
+	yy := new(big.Int).Mul(y, y)
+	yy.Mod(yy, curve.P)
+	yyyy := new(big.Int).Mul(yy, yy)
+	yyyy.Mod(yyyy, curve.P)
+	zz := new(big.Int).Mul(z, z)
+	zz.Mod(zz, curve.P)
+
+	// S = 2*((X1+YY)^2-XX-YYYY)
+	s := new(big.Int).Add(x, yy)
+	s.Mul(s, s)
+	s.Sub(s, xx)
+	s.Sub(s, yyyy)
+	s.Lsh(s, 1)
+	s.Mod(s, curve.P)
+
+	// M = 3*XX+a*ZZ^2
+	m := new(big.Int).Mul(zz, zz)
+	m.Mul(m, curve.A)
+	m.Add(m, new(big.Int).Mul(xx, big.NewInt(3)))
+	m.Mod(m, curve.P)
+
+	// X3 = M^2-2*S
+	x3 := new(big.Int).Mul(m, m)
+	x3.Sub(x3, new(big.Int).Lsh(s, 1))
+	x3.Mod(x3, curve.P)
+
+	// Y3 = M*(S-X3)-8*YYYY
+	y3 := new(big.Int).Sub(s, x3)
+	y3.Mul(y3, m)
+	y3.Sub(y3, new(big.Int).Lsh(yyyy, 3))
+	y3.Mod(y3, curve.P)
+
+	// Z3 = (Y1+Z1)^2-YY-ZZ
+	z3 := new(big.Int).Add(y, z)
+	z3.Mul(z3, z3)
+	z3.Sub(z3, yy)
+	z3.Sub(z3, zz)
+	z3.Mod(z3, curve.P)
+
+	return x3, y3, z3
+}
+
 func (curve *CurveParams) ScalarMultJ(Bx, By *big.Int, k []byte) (*big.Int, *big.Int) {
 	Bz := new(big.Int).SetInt64(1)
 	x, y, z := new(big.Int), new(big.Int), new(big.Int)
@@ -650,7 +711,7 @@ func GenerateKeyPair(curve Curve, rand io.Reader) (priv []byte, x, y *big.Int, e
 // SEC 1, Version 2.0, Section 2.3.3. If the point is not on the curve (or is
 // the conventional point at infinity), the behavior is undefined.
 func Marshal(curve Curve, x, y *big.Int) []byte {
-	byteLen := (curve.Params().BitSize + 7) / 8
+	byteLen := (curve.Params().P.BitLen() + 7) / 8
 
 	ret := make([]byte, 1+2*byteLen)
 	ret[0] = 4 // uncompressed point
@@ -661,6 +722,66 @@ func Marshal(curve Curve, x, y *big.Int) []byte {
 	return ret
 }
 
+// MarshalCompressed converts a point on the curve into the compressed form
+// specified in SEC 1, Version 2.0, Section 2.3.3.
+func MarshalCompressed(curve Curve, x, y *big.Int) []byte {
+	byteLen := (curve.Params().P.BitLen() + 7) / 8
+	compressed := make([]byte, 1+byteLen)
+	compressed[0] = byte(y.Bit(0)) | 2
+	x.FillBytes(compressed[1:])
+	return compressed
+}
+
+// Unmarshal converts a point, serialized by Marshal, into an x, y pair.
+// It is an error if the point is not in uncompressed form or is not on the curve.
+// On error, x = nil.
+func Unmarshal(curve Curve, data []byte) (x, y *big.Int) {
+	byteLen := (curve.Params().P.BitLen() + 7) / 8
+	if len(data) != 1+2*byteLen {
+		return nil, nil
+	}
+	if data[0] != 4 { // uncompressed form
+		return nil, nil
+	}
+	x = new(big.Int).SetBytes(data[1 : 1+byteLen])
+	y = new(big.Int).SetBytes(data[1+byteLen:])
+	if !curve.Params().IsOnCurve(x, y) {
+		return nil, nil
+	}
+	return
+}
+
+// UnmarshalCompressed converts a point, serialized by MarshalCompressed, into an x, y pair.
+// It is an error if the point is not in compressed form or is not on the curve.
+// On error, x = nil.
+func UnmarshalCompressed(curve Curve, data []byte) (x, y *big.Int) {
+	byteLen := (curve.Params().P.BitLen() + 7) / 8
+	if len(data) != 1+byteLen {
+		return nil, nil
+	}
+	if data[0] != 2 && data[0] != 3 { // compressed form
+		return nil, nil
+	}
+	p := curve.Params().P
+	x = new(big.Int).SetBytes(data[1:])
+	if x.Cmp(p) >= 0 {
+		return nil, nil
+	}
+	// y² = x³ + ax + b
+	y = curve.Params().PolynomialGeneric(x)
+	y = y.ModSqrt(y, p)
+	if y == nil {
+		return nil, nil
+	}
+	if byte(y.Bit(0)) != data[0]&1 {
+		y.Neg(y).Mod(y, p)
+	}
+	if !curve.Params().IsOnCurve(x, y) {
+		return nil, nil
+	}
+	return
+}
+
 
 // hashToInt converts a hash value to an integer. Per FIPS 186-4, Section 6.4,
 // we use the left-most bits of the hash to match the bit-length of the order of
@@ -681,6 +802,12 @@ func hashToInt(hash []byte, c Curve) *big.Int {
 }
 
 
+// HashToInt converts a hash value to an integer modulo the bit length of the
+// curve order, as used by the Jacobian signing and verification functions.
+func HashToInt(hash []byte, c Curve) *big.Int {
+	return hashToInt(hash, c)
+}
+
 // polynomial returns x³ - 3x + b.
 func PolynomialNIST(curve *CurveParams, x *big.Int) *big.Int {
 	x3 := new(big.Int).Mul(x, x)
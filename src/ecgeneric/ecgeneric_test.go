@@ -5,6 +5,9 @@ import (
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"runtime"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -212,6 +215,51 @@ func Benchmark_nist_sign(b *testing.B) {
 	})
 }
 
+func Benchmark_nist_sign_secp256k1(b *testing.B) {
+	priv := ecgeneric.BigFromHex("52edb68fe48aff9b5c071f076285c53ac5b1a3501139bb2cb2922b7f3923d23e")
+	X, Y := nist.Secp256k1.ScalarBaseMultGLV(priv.Bytes())
+	hash := sha256.Sum256([]byte("hello, world"))
+	b.ResetTimer()
+	b.Run("nist_sign_glv", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _, _ = nist.Sign(priv, hash[:], &nist.Secp256k1, rand.Reader)
+		}
+	})
+	r, s, _ := nist.Sign(priv, hash[:], &nist.Secp256k1, rand.Reader)
+	b.Run("nist_verify_glv", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			valid, _ := nist.Verify(hash[:], r, s, X, Y)
+			require.Equal(b, true, valid)
+		}
+	})
+}
+
+func Benchmark_secp256k1_scalar_mult(b *testing.B) {
+	k := ecgeneric.BigFromHex("52edb68fe48aff9b5c071f076285c53ac5b1a3501139bb2cb2922b7f3923d23e").Bytes()
+	X, Y := nist.Secp256k1.ScalarBaseMultGLV(k)
+	b.ResetTimer()
+	b.Run("jacobian", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			nist.Secp256k1.ScalarMultJ(X, Y, k)
+		}
+	})
+	b.Run("glv", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			nist.Secp256k1.ScalarMultGLV(X, Y, k)
+		}
+	})
+	b.Run("base_jacobian", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			nist.Secp256k1.ScalarBaseMultJ(k)
+		}
+	})
+	b.Run("base_glv", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			nist.Secp256k1.ScalarBaseMultGLV(k)
+		}
+	})
+}
+
 func Benchmark_gost_sign_STD(b *testing.B) {
 	privateKey, err := ecgeneric.GenerateKey(&gost.Gost34102001paramSetA, rand.Reader)
 	if err != nil {
@@ -272,6 +320,21 @@ func Benchmark_nist_ecrecover(b *testing.B) {
 	})
 }
 
+func Benchmark_nist_ecrecover_secp256k1(b *testing.B) {
+	priv := ecgeneric.BigFromHex("52edb68fe48aff9b5c071f076285c53ac5b1a3501139bb2cb2922b7f3923d23e")
+	X, Y := nist.Secp256k1.ScalarBaseMultGLV(priv.Bytes())
+	hash := sha256.Sum256([]byte("hello, world"))
+	r, s, _ := nist.Sign(priv, hash[:], &nist.Secp256k1, rand.Reader)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			ecRecX, ecRecY := nist.Ecrecover(hash[:], r, s, X, Y)
+			require.Equal(b, X, ecRecX)
+			require.Equal(b, Y, ecRecY)
+		}
+	})
+}
+
 func Benchmark_nist_ecrecover_p256(b *testing.B) {
 	curve := elliptic.P256()
 	privateKey, err := ecdsa.GenerateKey(curve, rand.Reader)
@@ -299,3 +362,77 @@ func BenchmarkEcrecoverEthSecp265k1Signature(b *testing.B) {
 		}
 	}
 }
+
+// msmInput returns n distinct points G, 2G, ... and n random scalars.
+func msmInput(curve *ecgeneric.CurveParams, n int) ([]ecgeneric.Point, []*big.Int) {
+	points := make([]ecgeneric.Point, n)
+	scalars := make([]*big.Int, n)
+	x, y := curve.Gx, curve.Gy
+	for i := 0; i < n; i++ {
+		points[i] = ecgeneric.Point{X: x, Y: y}
+		x, y = curve.AddJ(x, y, curve.Gx, curve.Gy)
+		k, err := rand.Int(rand.Reader, curve.N)
+		if err != nil {
+			panic(err)
+		}
+		scalars[i] = k
+	}
+	return points, scalars
+}
+
+func naiveMultiScalarMult(curve *ecgeneric.CurveParams, points []ecgeneric.Point, scalars []*big.Int) (*big.Int, *big.Int) {
+	x, y := new(big.Int), new(big.Int)
+	for i := range points {
+		k := new(big.Int).Mod(scalars[i], curve.N)
+		px, py := curve.ScalarMultJ(points[i].X, points[i].Y, k.Bytes())
+		x, y = curve.AddJ(x, y, px, py)
+	}
+	return x, y
+}
+
+func TestMultiScalarMult(t *testing.T) {
+	for _, curve := range []*ecgeneric.CurveParams{&nist.Secp256k1, &gost.Gost34102001paramSetA, &gost.GostEx1} {
+		for _, n := range []int{0, 1, 3, 4, 17, 64} {
+			points, scalars := msmInput(curve, n)
+			if n > 3 {
+				// Exercise the edge cases: zero, negative and oversized
+				// scalars, and the point at infinity.
+				scalars[0] = big.NewInt(0)
+				scalars[1] = big.NewInt(-5)
+				scalars[2] = new(big.Int).Add(curve.N, big.NewInt(7))
+				points[3] = ecgeneric.Point{X: new(big.Int), Y: new(big.Int)}
+			}
+			wantX, wantY := naiveMultiScalarMult(curve, points, scalars)
+
+			x, y := curve.MultiScalarMult(points, scalars)
+			require.Equal(t, wantX, x, "%s n=%d", curve.Name, n)
+			require.Equal(t, wantY, y, "%s n=%d", curve.Name, n)
+
+			x, y = curve.MultiScalarMultParallel(points, scalars, 4)
+			require.Equal(t, wantX, x, "%s n=%d parallel", curve.Name, n)
+			require.Equal(t, wantY, y, "%s n=%d parallel", curve.Name, n)
+		}
+	}
+}
+
+func BenchmarkMultiScalarMult(b *testing.B) {
+	curve := &nist.Secp256k1
+	for _, n := range []int{10, 100, 1000, 10000, 100000} {
+		points, scalars := msmInput(curve, n)
+		b.Run(fmt.Sprintf("naive/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				naiveMultiScalarMult(curve, points, scalars)
+			}
+		})
+		b.Run(fmt.Sprintf("pippenger/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				curve.MultiScalarMult(points, scalars)
+			}
+		})
+		b.Run(fmt.Sprintf("pippenger_parallel/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				curve.MultiScalarMultParallel(points, scalars, runtime.NumCPU())
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package ecies
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"io"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/kuznyechik"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/mgm"
+)
+
+// A Cipher is the data encapsulation part of ECIES. Seal authenticates s2
+// along with the plaintext and draws any nonce it needs from rand.
+type Cipher interface {
+	// KeySize returns the number of key bytes requested from the KDF.
+	KeySize() int
+	Seal(rand io.Reader, key, plaintext, s2 []byte) ([]byte, error)
+	Open(key, ciphertext, s2 []byte) ([]byte, error)
+}
+
+var (
+	// AES256GCM is AES-256 in GCM mode with a random 12-byte nonce prepended
+	// to the ciphertext.
+	AES256GCM Cipher = aeadCipher{keySize: 32, newAEAD: newAESGCM}
+
+	// KuznyechikMGM is Kuznyechik in MGM mode with a 16-byte tag and a random
+	// 16-byte nonce prepended to the ciphertext.
+	KuznyechikMGM Cipher = aeadCipher{keySize: kuznyechik.KeySize, newAEAD: newKuznyechikMGM, clearTopBit: true}
+
+	// AES128CTRHMACSHA256 is go-ethereum's scheme: the first half of the key
+	// encrypts with AES-128-CTR under a random IV, the SHA-256 hash of the
+	// second half keys an HMAC-SHA256 over IV ‖ ciphertext ‖ s2.
+	AES128CTRHMACSHA256 Cipher = ctrHMAC{}
+)
+
+func newAESGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func newKuznyechikMGM(key []byte) (cipher.AEAD, error) {
+	block, err := kuznyechik.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return mgm.NewMGM(block, mgm.BlockSize)
+}
+
+type aeadCipher struct {
+	keySize int
+	newAEAD func(key []byte) (cipher.AEAD, error)
+	// clearTopBit is set for MGM, whose nonces must be below 2^127.
+	clearTopBit bool
+}
+
+func (c aeadCipher) KeySize() int { return c.keySize }
+
+func (c aeadCipher) Seal(rand io.Reader, key, plaintext, s2 []byte) ([]byte, error) {
+	aead, err := c.newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := io.ReadFull(rand, nonce); err != nil {
+		return nil, err
+	}
+	if c.clearTopBit {
+		nonce[0] &= 0x7f
+	}
+	return aead.Seal(nonce, nonce, plaintext, s2), nil
+}
+
+func (c aeadCipher) Open(key, ciphertext, s2 []byte) ([]byte, error) {
+	aead, err := c.newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	n := aead.NonceSize()
+	if len(ciphertext) < n+aead.Overhead() || (c.clearTopBit && ciphertext[0]&0x80 != 0) {
+		return nil, ErrInvalidMessage
+	}
+	m, err := aead.Open(nil, ciphertext[:n], ciphertext[n:], s2)
+	if err != nil {
+		return nil, ErrInvalidMessage
+	}
+	return m, nil
+}
+
+type ctrHMAC struct{}
+
+func (ctrHMAC) KeySize() int { return 2 * 16 }
+
+func (ctrHMAC) keys(key []byte) (ke, km []byte) {
+	sum := sha256.Sum256(key[16:])
+	return key[:16], sum[:]
+}
+
+func (c ctrHMAC) Seal(rand io.Reader, key, plaintext, s2 []byte) ([]byte, error) {
+	ke, km := c.keys(key)
+	block, err := aes.NewCipher(ke)
+	if err != nil {
+		return nil, err
+	}
+	em := make([]byte, aes.BlockSize+len(plaintext), aes.BlockSize+len(plaintext)+sha256.Size)
+	if _, err := io.ReadFull(rand, em[:aes.BlockSize]); err != nil {
+		return nil, err
+	}
+	cipher.NewCTR(block, em[:aes.BlockSize]).XORKeyStream(em[aes.BlockSize:], plaintext)
+	return append(em, messageTag(km, em, s2)...), nil
+}
+
+func (c ctrHMAC) Open(key, ciphertext, s2 []byte) ([]byte, error) {
+	if len(ciphertext) <= aes.BlockSize+sha256.Size {
+		return nil, ErrInvalidMessage
+	}
+	ke, km := c.keys(key)
+	em := ciphertext[:len(ciphertext)-sha256.Size]
+	if subtle.ConstantTimeCompare(messageTag(km, em, s2), ciphertext[len(em):]) != 1 {
+		return nil, ErrInvalidMessage
+	}
+	block, err := aes.NewCipher(ke)
+	if err != nil {
+		return nil, err
+	}
+	m := make([]byte, len(em)-aes.BlockSize)
+	cipher.NewCTR(block, em[:aes.BlockSize]).XORKeyStream(m, em[aes.BlockSize:])
+	return m, nil
+}
+
+// messageTag is the SEC 1, Section 3.5 MAC over msg ‖ s2.
+func messageTag(km, msg, s2 []byte) []byte {
+	mac := hmac.New(sha256.New, km)
+	mac.Write(msg)
+	mac.Write(s2)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,142 @@
+// Package ecies implements the Elliptic Curve Integrated Encryption Scheme of
+// SEC 1, Version 2.0, Section 5.1 (and ISO/IEC 18033-2) for ecgeneric keys.
+//
+// A ciphertext is the encoded ephemeral public key R followed by the output
+// of the symmetric cipher. The key derivation function, the cipher and the
+// encoding of R are chosen with Params; EthereumParams produces the format
+// of go-ethereum's crypto/ecies on secp256k1.
+package ecies
+
+import (
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+)
+
+var (
+	ErrInvalidMessage   = errors.New("ecies: invalid message")
+	ErrInvalidPublicKey = errors.New("ecies: invalid public key")
+	ErrSharedKeyIsZero  = errors.New("ecies: shared key is the point at infinity")
+)
+
+// PointFormat selects the SEC 1 encoding of the ephemeral public key.
+type PointFormat int
+
+const (
+	Uncompressed PointFormat = iota
+	Compressed
+)
+
+// Params combines the parts of an ECIES instance.
+type Params struct {
+	KDF    KDF
+	Cipher Cipher
+	Format PointFormat
+}
+
+var (
+	// DefaultParams uses HKDF-SHA256 and AES-256-GCM.
+	DefaultParams = &Params{KDF: HKDFSHA256, Cipher: AES256GCM, Format: Uncompressed}
+
+	// GOSTParams uses KDF_TREE_GOSTR3411_2012_256 and Kuznyechik-MGM.
+	GOSTParams = &Params{KDF: KDFGOSTR3411_2012_256, Cipher: KuznyechikMGM, Format: Compressed}
+
+	// EthereumParams is interoperable with go-ethereum's ECIES_AES128_SHA256:
+	// the NIST concatenation KDF with SHA-256, AES-128-CTR and HMAC-SHA256.
+	EthereumParams = &Params{KDF: ConcatKDFSHA256, Cipher: AES128CTRHMACSHA256, Format: Uncompressed}
+)
+
+func (p *Params) orDefault() *Params {
+	if p == nil {
+		return DefaultParams
+	}
+	return p
+}
+
+// pointLen returns the length of an encoded point on curve.
+func (p *Params) pointLen(curve ecgeneric.Curve) int {
+	byteLen := (curve.Params().P.BitLen() + 7) / 8
+	if p.Format == Compressed {
+		return 1 + byteLen
+	}
+	return 1 + 2*byteLen
+}
+
+func (p *Params) marshal(curve ecgeneric.Curve, x, y *big.Int) []byte {
+	if p.Format == Compressed {
+		return ecgeneric.MarshalCompressed(curve, x, y)
+	}
+	return ecgeneric.Marshal(curve, x, y)
+}
+
+func (p *Params) unmarshal(curve ecgeneric.Curve, data []byte) (x, y *big.Int) {
+	if p.Format == Compressed {
+		return ecgeneric.UnmarshalCompressed(curve, data)
+	}
+	return ecgeneric.Unmarshal(curve, data)
+}
+
+// sharedSecret returns the x coordinate of d·(x, y), left-padded to the
+// field size.
+func sharedSecret(curve ecgeneric.Curve, x, y, d *big.Int) ([]byte, error) {
+	sx, sy := curve.Params().ScalarMultGLV(x, y, d.Bytes())
+	if sx.Sign() == 0 && sy.Sign() == 0 {
+		return nil, ErrSharedKeyIsZero
+	}
+	z := make([]byte, (curve.Params().P.BitLen()+7)/8)
+	return sx.FillBytes(z), nil
+}
+
+// Encrypt encrypts m to pub. s1 is fed into the key derivation and s2 is
+// authenticated by the cipher; both may be nil and must be given again to
+// Decrypt. A nil params selects DefaultParams.
+func Encrypt(rand io.Reader, pub *ecgeneric.PublicKey, m, s1, s2 []byte, params *Params) ([]byte, error) {
+	params = params.orDefault()
+	if pub.X == nil || pub.Y == nil || !pub.Params().IsOnCurve(pub.X, pub.Y) {
+		return nil, ErrInvalidPublicKey
+	}
+
+	r, err := ecgeneric.GenerateKey(pub.Curve, rand)
+	if err != nil {
+		return nil, err
+	}
+	z, err := sharedSecret(pub.Curve, pub.X, pub.Y, r.D)
+	if err != nil {
+		return nil, err
+	}
+	key, err := params.KDF(z, s1, params.Cipher.KeySize())
+	if err != nil {
+		return nil, err
+	}
+	em, err := params.Cipher.Seal(rand, key, m, s2)
+	if err != nil {
+		return nil, err
+	}
+	return append(params.marshal(pub.Curve, r.X, r.Y), em...), nil
+}
+
+// Decrypt decrypts a ciphertext produced by Encrypt with the same s1, s2 and
+// params.
+func Decrypt(priv *ecgeneric.PrivateKey, c, s1, s2 []byte, params *Params) ([]byte, error) {
+	params = params.orDefault()
+	rLen := params.pointLen(priv.Curve)
+	if len(c) <= rLen {
+		return nil, ErrInvalidMessage
+	}
+	rx, ry := params.unmarshal(priv.Curve, c[:rLen])
+	if rx == nil {
+		return nil, ErrInvalidPublicKey
+	}
+
+	z, err := sharedSecret(priv.Curve, rx, ry, priv.D)
+	if err != nil {
+		return nil, err
+	}
+	key, err := params.KDF(z, s1, params.Cipher.KeySize())
+	if err != nil {
+		return nil, err
+	}
+	return params.Cipher.Open(key, c[rLen:], s2)
+}
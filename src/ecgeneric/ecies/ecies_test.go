@@ -0,0 +1,103 @@
+package ecies_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	gethecies "github.com/ethereum/go-ethereum/crypto/ecies"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/ecies"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/gost"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/nist"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/streebog"
+	"github.com/stretchr/testify/require"
+)
+
+// R 50.1.113-2016, Section A.1.4.
+func TestKDFGOSTR3411_2012_256(t *testing.T) {
+	key, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	label, _ := hex.DecodeString("26bdb878")
+	seed, _ := hex.DecodeString("af21434145656378")
+	out, err := ecies.KDFTreeGOSTR3411_2012_256(key, label, seed, 32)
+	require.NoError(t, err)
+	require.Equal(t, "a1aa5f7de402d7b3d323f2991c8d4534013137010a83754fd0af6d7cd4922ed9", hex.EncodeToString(out))
+
+	// R 1323565.1.022-2018, KDF_TREE_GOSTR3411_2012_256 with R = 1 and
+	// L = 512.
+	out, err = ecies.KDFTreeGOSTR3411_2012_256(key, label, seed, 64)
+	require.NoError(t, err)
+	require.Equal(t, "22b6837845c6bef65ea71672b265831086d3c76aebe6dae91cad51d83f79d16b074c9330599d7f8d712fca54392f4ddde93751206b3584c8f43f9e6dc51531f9", hex.EncodeToString(out))
+
+	// L stays two bytes wide for outputs shorter than 32 bytes.
+	mac := hmac.New(streebog.New256, key)
+	mac.Write([]byte{1})
+	mac.Write(label)
+	mac.Write([]byte{0})
+	mac.Write(seed)
+	mac.Write([]byte{0x00, 0x80})
+	out, err = ecies.KDFTreeGOSTR3411_2012_256(key, label, seed, 16)
+	require.NoError(t, err)
+	require.Equal(t, mac.Sum(nil)[:16], out)
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	curves := []*ecgeneric.CurveParams{
+		&gost.Gost34102001paramSetA,
+		&gost.Gost341012512paramSetA,
+		// BitSize 256 with a 511-bit prime.
+		&gost.GostEx2,
+		&nist.Secp256k1,
+	}
+	params := []*ecies.Params{nil, ecies.DefaultParams, ecies.GOSTParams, ecies.EthereumParams}
+	msg := []byte("Hello ECIES!")
+	s1, s2 := []byte("shared info 1"), []byte("shared info 2")
+
+	for _, curve := range curves {
+		priv, err := ecgeneric.GenerateKey(curve, rand.Reader)
+		require.NoError(t, err)
+		for _, p := range params {
+			c, err := ecies.Encrypt(rand.Reader, &priv.PublicKey, msg, s1, s2, p)
+			require.NoError(t, err, curve.Name)
+
+			m, err := ecies.Decrypt(priv, c, s1, s2, p)
+			require.NoError(t, err, curve.Name)
+			require.Equal(t, msg, m)
+
+			_, err = ecies.Decrypt(priv, c, s1, []byte("other"), p)
+			require.Error(t, err)
+			_, err = ecies.Decrypt(priv, c, []byte("other"), s2, p)
+			require.Error(t, err)
+
+			c[len(c)-1] ^= 1
+			_, err = ecies.Decrypt(priv, c, s1, s2, p)
+			require.Error(t, err)
+		}
+	}
+}
+
+func TestEthereumInterop(t *testing.T) {
+	priv, err := ecgeneric.GenerateKey(&nist.Secp256k1, rand.Reader)
+	require.NoError(t, err)
+	gethPriv := gethecies.ImportECDSA(&ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{Curve: ethcrypto.S256(), X: priv.X, Y: priv.Y},
+		D:         priv.D,
+	})
+	msg := []byte("Hello go-ethereum!")
+	s1, s2 := []byte("s1"), []byte("s2")
+
+	c, err := gethecies.Encrypt(rand.Reader, &gethPriv.PublicKey, msg, s1, s2)
+	require.NoError(t, err)
+	m, err := ecies.Decrypt(priv, c, s1, s2, ecies.EthereumParams)
+	require.NoError(t, err)
+	require.Equal(t, msg, m)
+
+	c, err = ecies.Encrypt(rand.Reader, &priv.PublicKey, msg, nil, nil, ecies.EthereumParams)
+	require.NoError(t, err)
+	m, err = gethPriv.Decrypt(c, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, msg, m)
+}
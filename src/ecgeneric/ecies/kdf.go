@@ -0,0 +1,79 @@
+package ecies
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/streebog"
+	"golang.org/x/crypto/hkdf"
+)
+
+// A KDF derives length bytes of key material from the shared secret z and the
+// shared information s1.
+type KDF func(z, s1 []byte, length int) ([]byte, error)
+
+// HKDFSHA256 is HKDF (RFC 5869) with SHA-256, no salt and s1 as info.
+func HKDFSHA256(z, s1 []byte, length int) ([]byte, error) {
+	out := make([]byte, length)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, z, nil, s1), out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ConcatKDFSHA256 is the concatenation KDF of NIST SP 800-56A, Section 5.8.1,
+// with SHA-256, as used by go-ethereum: Hash(counter ‖ z ‖ s1) for
+// counter = 1, 2, ...
+func ConcatKDFSHA256(z, s1 []byte, length int) ([]byte, error) {
+	var out []byte
+	var counter [4]byte
+	h := sha256.New()
+	for i := uint32(1); len(out) < length; i++ {
+		binary.BigEndian.PutUint32(counter[:], i)
+		h.Reset()
+		h.Write(counter[:])
+		h.Write(z)
+		h.Write(s1)
+		out = h.Sum(out)
+	}
+	return out[:length], nil
+}
+
+// gostKDFLabel is the label passed to KDFTreeGOSTR3411_2012_256 by
+// KDFGOSTR3411_2012_256.
+var gostKDFLabel = []byte("ECIES")
+
+// KDFGOSTR3411_2012_256 is KDF_TREE_GOSTR3411_2012_256 keyed with z, with
+// the label "ECIES" and s1 as seed.
+func KDFGOSTR3411_2012_256(z, s1 []byte, length int) ([]byte, error) {
+	return KDFTreeGOSTR3411_2012_256(z, gostKDFLabel, s1, length)
+}
+
+// KDFTreeGOSTR3411_2012_256 implements KDF_TREE_GOSTR3411_2012_256 of
+// R 1323565.1.022-2018 with a one-byte counter: block i is
+// HMAC_GOSTR3411_2012_256(key, i ‖ label ‖ 0x00 ‖ seed ‖ L), where L is
+// the output length in bits as a two-byte big-endian integer. For a
+// 32-byte output this is KDF_GOSTR3411_2012_256 of R 50.1.113-2016.
+func KDFTreeGOSTR3411_2012_256(key, label, seed []byte, length int) ([]byte, error) {
+	blocks := (length + streebog.Size256 - 1) / streebog.Size256
+	if blocks > 255 {
+		return nil, errors.New("ecies: KDF output too long")
+	}
+	var l [2]byte
+	binary.BigEndian.PutUint16(l[:], uint16(length*8))
+
+	var out []byte
+	for i := 1; i <= blocks; i++ {
+		mac := hmac.New(streebog.New256, key)
+		mac.Write([]byte{byte(i)})
+		mac.Write(label)
+		mac.Write([]byte{0})
+		mac.Write(seed)
+		mac.Write(l[:])
+		out = mac.Sum(out)
+	}
+	return out[:length], nil
+}
@@ -0,0 +1,137 @@
+// Package eth provides Ethereum account tooling on top of the secp256k1
+// wrappers in ecgeneric: Keccak-256 addresses with EIP-55 checksums,
+// EIP-191 personal messages, EIP-712 typed data and EIP-155 recovery ids.
+//
+// Signatures are produced by ecgeneric.Sign and checked with
+// ecgeneric.Ecrecover, in the 65-byte [R || S || V] layout used by
+// go-ethereum.
+package eth
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"golang.org/x/crypto/sha3"
+)
+
+// AddressLength is the length of an address in bytes.
+const AddressLength = 20
+
+// Address is the last 20 bytes of the Keccak-256 hash of an uncompressed
+// public key.
+type Address [AddressLength]byte
+
+var (
+	errAddressLength   = errors.New("eth: address must be 20 bytes")
+	errAddressChecksum = errors.New("eth: invalid EIP-55 checksum")
+	errNotSecp256k1    = errors.New("eth: key is not on secp256k1")
+)
+
+// Keccak256 returns the legacy Keccak-256 hash of the concatenated data, as
+// used throughout Ethereum.
+func Keccak256(data ...[]byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	for _, b := range data {
+		h.Write(b)
+	}
+	return h.Sum(nil)
+}
+
+// ParseAddress parses a hex address with or without the 0x prefix. Addresses
+// written in mixed case must carry a valid EIP-55 checksum; all-lowercase
+// and all-uppercase addresses are accepted as is.
+func ParseAddress(s string) (Address, error) {
+	var a Address
+	hexPart := strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	if len(hexPart) != 2*AddressLength {
+		return a, errAddressLength
+	}
+	b, err := hex.DecodeString(hexPart)
+	if err != nil {
+		return a, fmt.Errorf("eth: %w", err)
+	}
+	copy(a[:], b)
+	if hexPart != strings.ToLower(hexPart) && hexPart != strings.ToUpper(hexPart) {
+		if a.Hex()[2:] != hexPart {
+			return a, errAddressChecksum
+		}
+	}
+	return a, nil
+}
+
+// Hex returns the EIP-55 mixed-case checksum encoding of a, with the 0x
+// prefix.
+func (a Address) Hex() string {
+	lower := hex.EncodeToString(a[:])
+	hash := Keccak256([]byte(lower))
+	out := []byte(lower)
+	for i, c := range out {
+		if c < 'a' {
+			continue
+		}
+		// Uppercase the letter if the matching nibble of the hash is 8 or more.
+		nibble := hash[i/2]
+		if i%2 == 0 {
+			nibble >>= 4
+		}
+		if nibble&0xf >= 8 {
+			out[i] = c - 'a' + 'A'
+		}
+	}
+	return "0x" + string(out)
+}
+
+func (a Address) String() string {
+	return a.Hex()
+}
+
+// AddressFromBytes derives the address of an uncompressed 65-byte public key,
+// as returned by ecgeneric.Ecrecover.
+func AddressFromBytes(pub []byte) (Address, error) {
+	var a Address
+	if len(pub) != 65 || pub[0] != 4 {
+		return a, errors.New("eth: public key must be 65 bytes uncompressed")
+	}
+	copy(a[:], Keccak256(pub[1:])[12:])
+	return a, nil
+}
+
+// PubkeyToAddress returns the address of an ecdsa public key.
+func PubkeyToAddress(pub ecdsa.PublicKey) Address {
+	return addressFromXY(pub.X, pub.Y)
+}
+
+// PublicKeyToAddress returns the address of a secp256k1 ecgeneric public key.
+func PublicKeyToAddress(pub *ecgeneric.PublicKey) (Address, error) {
+	if pub.Params().Name != "secp256k1" {
+		return Address{}, errNotSecp256k1
+	}
+	return addressFromXY(pub.X, pub.Y), nil
+}
+
+func addressFromXY(x, y *big.Int) Address {
+	var buf [64]byte
+	x.FillBytes(buf[:32])
+	y.FillBytes(buf[32:])
+	var a Address
+	copy(a[:], Keccak256(buf[:])[12:])
+	return a
+}
+
+// ToECDSA converts a secp256k1 ecgeneric key into the ecdsa form expected by
+// ecgeneric.Sign.
+func ToECDSA(priv *ecgeneric.PrivateKey) (*ecdsa.PrivateKey, error) {
+	if priv.Params().Name != "secp256k1" {
+		return nil, errNotSecp256k1
+	}
+	k := new(ecdsa.PrivateKey)
+	k.Curve = ecgeneric.S256()
+	k.D = new(big.Int).Set(priv.D)
+	k.X, k.Y = new(big.Int).Set(priv.X), new(big.Int).Set(priv.Y)
+	return k, nil
+}
@@ -0,0 +1,116 @@
+package eth_test
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/eth"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/nist"
+	"github.com/stretchr/testify/require"
+)
+
+func keyFromHex(t *testing.T, s string) *ecdsa.PrivateKey {
+	d, ok := new(big.Int).SetString(s, 16)
+	require.True(t, ok)
+	k := new(ecdsa.PrivateKey)
+	k.Curve = ecgeneric.S256()
+	k.D = d
+	k.X, k.Y = k.Curve.ScalarBaseMult(d.Bytes())
+	return k
+}
+
+func TestEIP55(t *testing.T) {
+	for _, s := range []string{
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+		"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+	} {
+		a, err := eth.ParseAddress(s)
+		require.NoError(t, err)
+		require.Equal(t, s, a.Hex())
+	}
+
+	_, err := eth.ParseAddress("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed")
+	require.NoError(t, err)
+	_, err = eth.ParseAddress("0x5AAeb6053F3E94C9b9A09f33669435E7Ef1BeAed")
+	require.Error(t, err)
+	_, err = eth.ParseAddress("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeA")
+	require.Error(t, err)
+}
+
+func TestAddress(t *testing.T) {
+	// keccak256("cow"), the key of the EIP-712 example.
+	prv := keyFromHex(t, "c85ef7d79691fe79573b1a7064c19c1a9819ebdbd1faaab1a8ec92344438aaf4")
+	want := "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"
+	require.Equal(t, want, eth.PubkeyToAddress(prv.PublicKey).Hex())
+
+	priv := &ecgeneric.PrivateKey{
+		PublicKey: ecgeneric.PublicKey{Curve: &nist.Secp256k1, X: prv.X, Y: prv.Y},
+		D:         prv.D,
+	}
+	a, err := eth.PublicKeyToAddress(&priv.PublicKey)
+	require.NoError(t, err)
+	require.Equal(t, want, a.Hex())
+
+	k, err := eth.ToECDSA(priv)
+	require.NoError(t, err)
+	require.Equal(t, want, eth.PubkeyToAddress(k.PublicKey).Hex())
+}
+
+func TestSignText(t *testing.T) {
+	require.Equal(t, "d9eba16ed0ecae432b71fe008c98cc872bb4cc214d3220a36f365326cf807d68",
+		hex.EncodeToString(eth.TextHash([]byte("hello world"))))
+
+	prv := keyFromHex(t, "c85ef7d79691fe79573b1a7064c19c1a9819ebdbd1faaab1a8ec92344438aaf4")
+	msg := []byte("hello world")
+	sig, err := eth.SignText(msg, prv)
+	require.NoError(t, err)
+	require.Contains(t, []byte{27, 28}, sig[64])
+
+	addr, err := eth.RecoverText(msg, sig)
+	require.NoError(t, err)
+	require.Equal(t, eth.PubkeyToAddress(prv.PublicKey), addr)
+
+	addr, err = eth.RecoverText([]byte("hello world!"), sig)
+	require.NoError(t, err)
+	require.NotEqual(t, eth.PubkeyToAddress(prv.PublicKey), addr)
+}
+
+// The example transaction of EIP-155.
+func TestEIP155(t *testing.T) {
+	prv := keyFromHex(t, "4646464646464646464646464646464646464646464646464646464646464646")
+	hash, _ := hex.DecodeString("daf5a779ae972f972197303d7b574746c7ef83eadac0f2791ad23db92e4c8e53")
+	chainID := big.NewInt(1)
+
+	r, s, v, err := eth.SignTx(hash, prv, chainID)
+	require.NoError(t, err)
+	require.Equal(t, "18515461264373351373200002665853028612451056578545711640558177340181847433846", r.String())
+	require.Equal(t, "46948507304638947509940763649030358759909902576025900602547168820602576006531", s.String())
+	require.Equal(t, int64(37), v.Int64())
+	got, err := eth.ChainID(v)
+	require.NoError(t, err)
+	require.Equal(t, chainID, got)
+
+	addr, err := eth.RecoverTx(hash, r, s, v, chainID)
+	require.NoError(t, err)
+	require.Equal(t, eth.PubkeyToAddress(prv.PublicKey), addr)
+
+	_, err = eth.RecoverTx(hash, r, s, v, big.NewInt(5))
+	require.Error(t, err)
+
+	require.Equal(t, int64(27), eth.EIP155V(0, nil).Int64())
+	id, err := eth.RecoveryID(big.NewInt(28), chainID)
+	require.NoError(t, err)
+	require.Equal(t, byte(1), id)
+	got, err = eth.ChainID(big.NewInt(28))
+	require.NoError(t, err)
+	require.Nil(t, got)
+	for _, v := range []int64{0, 1, 26, 29, 30, 34} {
+		_, err = eth.ChainID(big.NewInt(v))
+		require.Error(t, err, v)
+	}
+}
@@ -0,0 +1,132 @@
+package eth
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+)
+
+var errSignatureLength = errors.New("eth: signature must be 65 bytes")
+
+// TextHash returns the EIP-191 version 0x45 hash of msg,
+//
+//	keccak256("\x19Ethereum Signed Message:\n" || len(msg) || msg)
+//
+// which is what personal_sign and eth_sign sign.
+func TextHash(msg []byte) []byte {
+	prefix := fmt.Sprintf("\x19Ethereum Signed Message:\n%d", len(msg))
+	return Keccak256([]byte(prefix), msg)
+}
+
+// SignText signs the EIP-191 hash of msg. Like personal_sign, the returned
+// signature has V set to 27 or 28.
+func SignText(msg []byte, prv *ecdsa.PrivateKey) ([]byte, error) {
+	sig, err := ecgeneric.Sign(TextHash(msg), prv)
+	if err != nil {
+		return nil, err
+	}
+	sig[64] += 27
+	return sig, nil
+}
+
+// RecoverText returns the address that signed msg with SignText. V may be
+// either 27/28 or 0/1.
+func RecoverText(msg, sig []byte) (Address, error) {
+	return RecoverAddress(TextHash(msg), sig)
+}
+
+// RecoverAddress returns the address that produced sig over hash. V may be
+// either 27/28 or 0/1; EIP-155 values must be converted with RecoveryID
+// first.
+func RecoverAddress(hash, sig []byte) (Address, error) {
+	if len(sig) != 65 {
+		return Address{}, errSignatureLength
+	}
+	s := make([]byte, 65)
+	copy(s, sig)
+	if s[64] >= 27 {
+		s[64] -= 27
+	}
+	if s[64] > 1 {
+		return Address{}, fmt.Errorf("eth: invalid recovery id %d", sig[64])
+	}
+	pub, err := ecgeneric.Ecrecover(hash, s)
+	if err != nil {
+		return Address{}, err
+	}
+	return AddressFromBytes(pub)
+}
+
+// EIP155V returns the V value of a transaction signature with recovery id
+// recID on chain chainID: recID + 35 + 2·chainID. A nil chain id yields the
+// pre-EIP-155 value recID + 27.
+func EIP155V(recID byte, chainID *big.Int) *big.Int {
+	v := big.NewInt(int64(recID))
+	if chainID == nil {
+		return v.Add(v, big.NewInt(27))
+	}
+	v.Add(v, big.NewInt(35))
+	return v.Add(v, new(big.Int).Lsh(chainID, 1))
+}
+
+// RecoveryID extracts the recovery id from a transaction V value, checking it
+// against chainID. Legacy values 27 and 28 are accepted for any chain.
+func RecoveryID(v, chainID *big.Int) (byte, error) {
+	if v.Cmp(big.NewInt(27)) == 0 || v.Cmp(big.NewInt(28)) == 0 {
+		return byte(v.Int64() - 27), nil
+	}
+	if chainID == nil {
+		return 0, fmt.Errorf("eth: V %v is not a legacy value", v)
+	}
+	r := new(big.Int).Sub(v, big.NewInt(35))
+	r.Sub(r, new(big.Int).Lsh(chainID, 1))
+	if r.Sign() < 0 || r.Cmp(big.NewInt(1)) > 0 {
+		return 0, fmt.Errorf("eth: V %v does not match chain id %v", v, chainID)
+	}
+	return byte(r.Int64()), nil
+}
+
+// ChainID derives the chain id from an EIP-155 V value. It returns nil for
+// the legacy values 27 and 28, and an error for any other V below 35, which
+// is neither.
+func ChainID(v *big.Int) (*big.Int, error) {
+	if v.Cmp(big.NewInt(27)) == 0 || v.Cmp(big.NewInt(28)) == 0 {
+		return nil, nil
+	}
+	c := new(big.Int).Sub(v, big.NewInt(35))
+	if c.Sign() < 0 {
+		return nil, fmt.Errorf("eth: V %v is neither legacy nor EIP-155", v)
+	}
+	return c.Rsh(c, 1), nil
+}
+
+// SignTx signs a transaction signing hash for chainID and returns r, s and
+// the EIP-155 V.
+func SignTx(hash []byte, prv *ecdsa.PrivateKey, chainID *big.Int) (r, s, v *big.Int, err error) {
+	sig, err := ecgeneric.Sign(hash, prv)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	r = new(big.Int).SetBytes(sig[:32])
+	s = new(big.Int).SetBytes(sig[32:64])
+	return r, s, EIP155V(sig[64], chainID), nil
+}
+
+// RecoverTx returns the sender of a transaction signed with SignTx.
+func RecoverTx(hash []byte, r, s, v, chainID *big.Int) (Address, error) {
+	recID, err := RecoveryID(v, chainID)
+	if err != nil {
+		return Address{}, err
+	}
+	if r.BitLen() > 256 || s.BitLen() > 256 {
+		return Address{}, errors.New("eth: signature value out of range")
+	}
+	sig := make([]byte, 65)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:64])
+	sig[64] = recID
+	return RecoverAddress(hash, sig)
+}
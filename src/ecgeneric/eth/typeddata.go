@@ -0,0 +1,377 @@
+package eth
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+)
+
+// EIP-712 typed structured data hashing and signing.
+//
+// A TypedData value has the shape of the JSON accepted by
+// eth_signTypedData_v4. Message values may be given as the Go types produced
+// by encoding/json (with or without UseNumber), as *big.Int and integer
+// types, and as []byte for byte strings.
+
+// Type is a member of an EIP-712 struct type.
+type Type struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Types maps struct type names to their members.
+type Types map[string][]Type
+
+// TypedData is an EIP-712 message together with its types and domain.
+type TypedData struct {
+	Types       Types                  `json:"types"`
+	PrimaryType string                 `json:"primaryType"`
+	Domain      map[string]interface{} `json:"domain"`
+	Message     map[string]interface{} `json:"message"`
+}
+
+// domainFields lists the EIP712Domain fields in the order required by the
+// specification.
+var domainFields = []Type{
+	{"name", "string"},
+	{"version", "string"},
+	{"chainId", "uint256"},
+	{"verifyingContract", "address"},
+	{"salt", "bytes32"},
+}
+
+// ParseTypedData decodes the JSON form of typed data. Numbers are kept as
+// json.Number so that uint256 values do not lose precision.
+func ParseTypedData(data []byte) (*TypedData, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	td := new(TypedData)
+	if err := dec.Decode(td); err != nil {
+		return nil, err
+	}
+	return td, nil
+}
+
+func (td *TypedData) types() Types {
+	if _, ok := td.Types["EIP712Domain"]; ok {
+		return td.Types
+	}
+	// Derive the domain type from the fields present in the domain.
+	types := make(Types, len(td.Types)+1)
+	for k, v := range td.Types {
+		types[k] = v
+	}
+	var domain []Type
+	for _, f := range domainFields {
+		if _, ok := td.Domain[f.Name]; ok {
+			domain = append(domain, f)
+		}
+	}
+	types["EIP712Domain"] = domain
+	return types
+}
+
+// EncodeType returns the encoding of a struct type, e.g.
+// "Mail(Person from,Person to,string contents)Person(string name,address wallet)".
+func (td *TypedData) EncodeType(name string) (string, error) {
+	return encodeType(td.types(), name)
+}
+
+// TypeHash returns keccak256(EncodeType(name)).
+func (td *TypedData) TypeHash(name string) ([]byte, error) {
+	enc, err := td.EncodeType(name)
+	if err != nil {
+		return nil, err
+	}
+	return Keccak256([]byte(enc)), nil
+}
+
+// HashStruct returns hashStruct(data) for the struct type name.
+func (td *TypedData) HashStruct(name string, data map[string]interface{}) ([]byte, error) {
+	return hashStruct(td.types(), name, data)
+}
+
+// DomainSeparator returns hashStruct of the domain.
+func (td *TypedData) DomainSeparator() ([]byte, error) {
+	return td.HashStruct("EIP712Domain", td.Domain)
+}
+
+// Hash returns the digest to be signed,
+//
+//	keccak256("\x19\x01" || domainSeparator || hashStruct(message)).
+func (td *TypedData) Hash() ([]byte, error) {
+	domain, err := td.DomainSeparator()
+	if err != nil {
+		return nil, err
+	}
+	msg, err := td.HashStruct(td.PrimaryType, td.Message)
+	if err != nil {
+		return nil, err
+	}
+	return Keccak256([]byte{0x19, 0x01}, domain, msg), nil
+}
+
+// SignTypedData signs the EIP-712 digest of td. As with
+// eth_signTypedData_v4, V is 27 or 28.
+func SignTypedData(td *TypedData, prv *ecdsa.PrivateKey) ([]byte, error) {
+	hash, err := td.Hash()
+	if err != nil {
+		return nil, err
+	}
+	sig, err := ecgeneric.Sign(hash, prv)
+	if err != nil {
+		return nil, err
+	}
+	sig[64] += 27
+	return sig, nil
+}
+
+// RecoverTypedData returns the address that signed td.
+func RecoverTypedData(td *TypedData, sig []byte) (Address, error) {
+	hash, err := td.Hash()
+	if err != nil {
+		return Address{}, err
+	}
+	return RecoverAddress(hash, sig)
+}
+
+func encodeType(types Types, name string) (string, error) {
+	if _, ok := types[name]; !ok {
+		return "", fmt.Errorf("eip712: unknown type %q", name)
+	}
+	deps := map[string]bool{}
+	collectDeps(types, name, deps)
+	delete(deps, name)
+	names := make([]string, 0, len(deps))
+	for d := range deps {
+		names = append(names, d)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, n := range append([]string{name}, names...) {
+		b.WriteString(n)
+		b.WriteByte('(')
+		for i, m := range types[n] {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(m.Type)
+			b.WriteByte(' ')
+			b.WriteString(m.Name)
+		}
+		b.WriteByte(')')
+	}
+	return b.String(), nil
+}
+
+func collectDeps(types Types, name string, deps map[string]bool) {
+	if deps[name] {
+		return
+	}
+	deps[name] = true
+	for _, m := range types[name] {
+		base := m.Type
+		if i := strings.IndexByte(base, '['); i >= 0 {
+			base = base[:i]
+		}
+		if _, ok := types[base]; ok {
+			collectDeps(types, base, deps)
+		}
+	}
+}
+
+func hashStruct(types Types, name string, data map[string]interface{}) ([]byte, error) {
+	enc, err := encodeType(types, name)
+	if err != nil {
+		return nil, err
+	}
+	buf := Keccak256([]byte(enc))
+	for _, m := range types[name] {
+		v, ok := data[m.Name]
+		if !ok {
+			return nil, fmt.Errorf("eip712: %s is missing field %q", name, m.Name)
+		}
+		word, err := encodeValue(types, m.Type, v)
+		if err != nil {
+			return nil, fmt.Errorf("eip712: %s.%s: %w", name, m.Name, err)
+		}
+		buf = append(buf, word...)
+	}
+	return Keccak256(buf), nil
+}
+
+// encodeValue returns the 32-byte encoding of v as type typ.
+func encodeValue(types Types, typ string, v interface{}) ([]byte, error) {
+	if strings.HasSuffix(typ, "]") {
+		i := strings.LastIndexByte(typ, '[')
+		base, size := typ[:i], typ[i+1:len(typ)-1]
+		items, ok := v.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected an array for %s", typ)
+		}
+		if size != "" {
+			n, err := strconv.Atoi(size)
+			if err != nil || n != len(items) {
+				return nil, fmt.Errorf("expected %s elements for %s", size, typ)
+			}
+		}
+		var buf []byte
+		for _, item := range items {
+			word, err := encodeValue(types, base, item)
+			if err != nil {
+				return nil, err
+			}
+			buf = append(buf, word...)
+		}
+		return Keccak256(buf), nil
+	}
+
+	if _, ok := types[typ]; ok {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected an object for %s", typ)
+		}
+		return hashStruct(types, typ, m)
+	}
+
+	word := make([]byte, 32)
+	switch {
+	case typ == "string":
+		s, ok := v.(string)
+		if !ok {
+			return nil, errors.New("expected a string")
+		}
+		return Keccak256([]byte(s)), nil
+
+	case typ == "bytes":
+		b, err := toBytes(v)
+		if err != nil {
+			return nil, err
+		}
+		return Keccak256(b), nil
+
+	case typ == "bool":
+		b, ok := v.(bool)
+		if !ok {
+			return nil, errors.New("expected a bool")
+		}
+		if b {
+			word[31] = 1
+		}
+		return word, nil
+
+	case typ == "address":
+		var a Address
+		switch x := v.(type) {
+		case Address:
+			a = x
+		case string:
+			var err error
+			if a, err = ParseAddress(x); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, errors.New("expected an address")
+		}
+		copy(word[12:], a[:])
+		return word, nil
+
+	case strings.HasPrefix(typ, "bytes"):
+		n, err := strconv.Atoi(typ[len("bytes"):])
+		if err != nil || n < 1 || n > 32 {
+			return nil, fmt.Errorf("unknown type %q", typ)
+		}
+		b, err := toBytes(v)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) != n {
+			return nil, fmt.Errorf("expected %d bytes, got %d", n, len(b))
+		}
+		copy(word, b)
+		return word, nil
+
+	case strings.HasPrefix(typ, "uint"), strings.HasPrefix(typ, "int"):
+		signed := strings.HasPrefix(typ, "int")
+		bits, err := strconv.Atoi(strings.TrimPrefix(strings.TrimPrefix(typ, "u"), "int"))
+		if err != nil || bits < 8 || bits > 256 || bits%8 != 0 {
+			return nil, fmt.Errorf("unknown type %q", typ)
+		}
+		x, err := toBigInt(v)
+		if err != nil {
+			return nil, err
+		}
+		limit := new(big.Int).Lsh(big.NewInt(1), uint(bits))
+		if signed {
+			limit.Rsh(limit, 1)
+			if x.Cmp(limit) >= 0 || x.Cmp(new(big.Int).Neg(limit)) < 0 {
+				return nil, fmt.Errorf("%v overflows %s", x, typ)
+			}
+			if x.Sign() < 0 {
+				// Two's complement in 256 bits.
+				x = new(big.Int).Add(x, new(big.Int).Lsh(big.NewInt(1), 256))
+			}
+		} else if x.Sign() < 0 || x.Cmp(limit) >= 0 {
+			return nil, fmt.Errorf("%v overflows %s", x, typ)
+		}
+		x.FillBytes(word)
+		return word, nil
+	}
+	return nil, fmt.Errorf("unknown type %q", typ)
+}
+
+func toBytes(v interface{}) ([]byte, error) {
+	switch x := v.(type) {
+	case []byte:
+		return x, nil
+	case string:
+		if !strings.HasPrefix(x, "0x") {
+			return nil, errors.New("expected a 0x-prefixed hex string")
+		}
+		return hex.DecodeString(x[2:])
+	}
+	return nil, errors.New("expected bytes")
+}
+
+func toBigInt(v interface{}) (*big.Int, error) {
+	switch x := v.(type) {
+	case *big.Int:
+		return new(big.Int).Set(x), nil
+	case int:
+		return big.NewInt(int64(x)), nil
+	case int64:
+		return big.NewInt(x), nil
+	case uint64:
+		return new(big.Int).SetUint64(x), nil
+	case float64:
+		f := new(big.Float).SetFloat64(x)
+		i, acc := f.Int(nil)
+		if acc != big.Exact {
+			return nil, fmt.Errorf("%v is not an integer", x)
+		}
+		return i, nil
+	case json.Number:
+		return parseBigInt(string(x))
+	case string:
+		return parseBigInt(x)
+	}
+	return nil, fmt.Errorf("expected an integer, got %T", v)
+}
+
+func parseBigInt(s string) (*big.Int, error) {
+	x, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		return nil, fmt.Errorf("invalid integer %q", s)
+	}
+	return x, nil
+}
@@ -0,0 +1,121 @@
+package eth_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/eth"
+	"github.com/stretchr/testify/require"
+)
+
+// The example message of EIP-712.
+const mailJSON = `{
+  "types": {
+    "EIP712Domain": [
+      {"name": "name", "type": "string"},
+      {"name": "version", "type": "string"},
+      {"name": "chainId", "type": "uint256"},
+      {"name": "verifyingContract", "type": "address"}
+    ],
+    "Person": [
+      {"name": "name", "type": "string"},
+      {"name": "wallet", "type": "address"}
+    ],
+    "Mail": [
+      {"name": "from", "type": "Person"},
+      {"name": "to", "type": "Person"},
+      {"name": "contents", "type": "string"}
+    ]
+  },
+  "primaryType": "Mail",
+  "domain": {
+    "name": "Ether Mail",
+    "version": "1",
+    "chainId": 1,
+    "verifyingContract": "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+  },
+  "message": {
+    "from": {"name": "Cow", "wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+    "to": {"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+    "contents": "Hello, Bob!"
+  }
+}`
+
+func TestTypedDataMail(t *testing.T) {
+	td, err := eth.ParseTypedData([]byte(mailJSON))
+	require.NoError(t, err)
+
+	enc, err := td.EncodeType("Mail")
+	require.NoError(t, err)
+	require.Equal(t, "Mail(Person from,Person to,string contents)Person(string name,address wallet)", enc)
+
+	th, err := td.TypeHash("Mail")
+	require.NoError(t, err)
+	require.Equal(t, "a0cedeb2dc280ba39b857546d74f5549c3a1d7bdc2dd96bf881f76108e23dac2", hex.EncodeToString(th))
+
+	domain, err := td.DomainSeparator()
+	require.NoError(t, err)
+	require.Equal(t, "f2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f", hex.EncodeToString(domain))
+
+	msg, err := td.HashStruct("Mail", td.Message)
+	require.NoError(t, err)
+	require.Equal(t, "c52c0ee5d84264471806290a3f2c4cecfc5490626bf912d01f240d7a274b371e", hex.EncodeToString(msg))
+
+	hash, err := td.Hash()
+	require.NoError(t, err)
+	require.Equal(t, "be609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2", hex.EncodeToString(hash))
+
+	prv := keyFromHex(t, "c85ef7d79691fe79573b1a7064c19c1a9819ebdbd1faaab1a8ec92344438aaf4")
+	sig, err := eth.SignTypedData(td, prv)
+	require.NoError(t, err)
+	require.Equal(t, "4355c47d63924e8a72e509b65029052eb6c299d53a04e167c5775fd466751c9d", hex.EncodeToString(sig[:32]))
+	require.Equal(t, "07299936d304c153f6443dfa05f40ff007d72911b6f72307f996231605b91562", hex.EncodeToString(sig[32:64]))
+	require.Equal(t, byte(28), sig[64])
+
+	addr, err := eth.RecoverTypedData(td, sig)
+	require.NoError(t, err)
+	require.Equal(t, "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826", addr.Hex())
+}
+
+func TestTypedDataDerivedDomain(t *testing.T) {
+	td, err := eth.ParseTypedData([]byte(mailJSON))
+	require.NoError(t, err)
+	want, err := td.DomainSeparator()
+	require.NoError(t, err)
+
+	// Without an explicit EIP712Domain type the fields present are used in
+	// the canonical order.
+	delete(td.Types, "EIP712Domain")
+	got, err := td.DomainSeparator()
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+}
+
+func TestTypedDataArraysAndInts(t *testing.T) {
+	td := &eth.TypedData{
+		Types: eth.Types{
+			"Batch": {
+				{Name: "amounts", Type: "int64[]"},
+				{Name: "tag", Type: "bytes4"},
+				{Name: "ok", Type: "bool"},
+			},
+		},
+		PrimaryType: "Batch",
+		Domain:      map[string]interface{}{"name": "test"},
+		Message: map[string]interface{}{
+			"amounts": []interface{}{1, "-2", "0x03"},
+			"tag":     "0xdeadbeef",
+			"ok":      true,
+		},
+	}
+	_, err := td.Hash()
+	require.NoError(t, err)
+
+	td.Message["tag"] = "0xdead"
+	_, err = td.Hash()
+	require.Error(t, err)
+	td.Message["tag"] = "0xdeadbeef"
+	td.Message["amounts"] = []interface{}{"0x8000000000000000"}
+	_, err = td.Hash()
+	require.Error(t, err)
+}
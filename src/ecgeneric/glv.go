@@ -0,0 +1,158 @@
+package ecgeneric
+
+import (
+	"math/big"
+	"sync"
+)
+
+// GLV scalar multiplication on secp256k1.
+//
+// secp256k1 has a = 0 and p ≡ 1 mod 3, so φ(x, y) = (β·x, y) with β³ = 1 mod p
+// is an endomorphism acting on the group as multiplication by λ, λ³ = 1 mod n.
+// Writing k = k1 + k2·λ mod n with k1, k2 of about 128 bits each turns k·P into
+// k1·P + k2·φ(P), which needs half as many doublings.
+//
+// The decomposition uses the short lattice basis {(a1, b1), (a2, b2)} of
+// {(x, y) : x + y·λ ≡ 0 mod n} from Guide to Elliptic Curve Cryptography,
+// Algorithm 3.74.
+var (
+	glvBeta   = bigFromHex("7ae96a2b657c07106e64479eac3434e99cf0497512f58995c1396c28719501ee")
+	glvLambda = bigFromHex("5363ad4cc05c30e0a5261c028812645a122e22ea20816678df02967c1b23bd72")
+	glvA1     = bigFromHex("3086d221a7d46bcde86c90e49284eb15")
+	glvB1     = new(big.Int).Neg(bigFromHex("e4437ed6010e88286f547fa90abfe4c3"))
+	glvA2     = bigFromHex("114ca50f7a8e2f3f657c1108d9d44cfd8")
+	glvB2     = glvA1
+)
+
+const (
+	// glvWindow is the wNAF width used for points only seen once.
+	glvWindow = 5
+	// glvBaseWindow is the wNAF width of the cached generator tables.
+	glvBaseWindow = 7
+)
+
+// glvBase caches the odd multiples of G and φ(G) on secp256k1.
+var glvBase struct {
+	once     sync.Once
+	g, endoG *wnafTable
+}
+
+// HasGLV reports whether curve is secp256k1, whose endomorphism is used by
+// ScalarMultGLV, ScalarBaseMultGLV and CombinedMult.
+func (curve *CurveParams) HasGLV() bool {
+	return curve.A.Sign() == 0 && curve.P.Cmp(secp256k1.P) == 0 &&
+		curve.N.Cmp(secp256k1.N) == 0 && curve.B.Cmp(secp256k1.B) == 0 &&
+		curve.Gx.Cmp(secp256k1.Gx) == 0 && curve.Gy.Cmp(secp256k1.Gy) == 0
+}
+
+// GLVDecompose splits a secp256k1 scalar k into k1 and k2 with
+// k ≡ k1 + k2·λ mod n. Both halves may be negative and are at most about 129
+// bits long.
+func GLVDecompose(k *big.Int) (k1, k2 *big.Int) {
+	n := secp256k1.N
+	k = new(big.Int).Mod(k, n)
+	// c1 = round(b2·k/n), c2 = round(-b1·k/n)
+	c1 := roundDiv(new(big.Int).Mul(glvB2, k), n)
+	c2 := roundDiv(new(big.Int).Neg(new(big.Int).Mul(glvB1, k)), n)
+
+	k1 = new(big.Int).Sub(k, new(big.Int).Mul(c1, glvA1))
+	k1.Sub(k1, new(big.Int).Mul(c2, glvA2))
+	k2 = new(big.Int).Neg(new(big.Int).Mul(c1, glvB1))
+	k2.Sub(k2, new(big.Int).Mul(c2, glvB2))
+	return k1, k2
+}
+
+// roundDiv returns x/n rounded to the nearest integer for x ≥ 0.
+func roundDiv(x, n *big.Int) *big.Int {
+	q := new(big.Int).Lsh(x, 1)
+	q.Add(q, n)
+	return q.Div(q, new(big.Int).Lsh(n, 1))
+}
+
+// negY returns -y mod p.
+func (curve *CurveParams) negY(y *big.Int) *big.Int {
+	if y.Sign() == 0 {
+		return new(big.Int)
+	}
+	return new(big.Int).Sub(curve.P, y)
+}
+
+// endoTable returns the table of φ(P) given the table of P. φ is a group
+// homomorphism, so φ((2j+1)·P) = (2j+1)·φ(P) and the odd multiples only need
+// their x coordinate multiplied by β.
+func (curve *CurveParams) endoTable(t *wnafTable) *wnafTable {
+	e := &wnafTable{w: t.w, pos: make([]Point, len(t.pos)), neg: make([]Point, len(t.neg))}
+	for i := range t.pos {
+		bx := new(big.Int).Mul(glvBeta, t.pos[i].X)
+		bx.Mod(bx, curve.P)
+		e.pos[i] = Point{bx, t.pos[i].Y}
+		e.neg[i] = Point{bx, t.neg[i].Y}
+	}
+	return e
+}
+
+func glvBaseTables() (*wnafTable, *wnafTable) {
+	glvBase.once.Do(func() {
+		glvBase.g = secp256k1.newWNAFTable(secp256k1.Gx, secp256k1.Gy, glvBaseWindow)
+		glvBase.endoG = secp256k1.endoTable(glvBase.g)
+	})
+	return glvBase.g, glvBase.endoG
+}
+
+// ScalarMultGLV returns k·(x, y). On secp256k1 it splits k with GLVDecompose
+// and evaluates k1·P + k2·φ(P) with a single chain of about 128 doublings;
+// on other curves it is the same as ScalarMultJ.
+func (curve *CurveParams) ScalarMultGLV(x, y *big.Int, k []byte) (*big.Int, *big.Int) {
+	if !curve.HasGLV() {
+		return curve.ScalarMultJ(x, y, k)
+	}
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return new(big.Int), new(big.Int)
+	}
+	t := curve.newWNAFTable(x, y, glvWindow)
+	k1, k2 := GLVDecompose(new(big.Int).SetBytes(k))
+	return curve.strausMult([]*wnafTable{t, curve.endoTable(t)}, []*big.Int{k1, k2})
+}
+
+// ScalarBaseMultGLV returns k·G like ScalarBaseMultJ, using precomputed
+// tables for G and φ(G) on secp256k1.
+func (curve *CurveParams) ScalarBaseMultGLV(k []byte) (*big.Int, *big.Int) {
+	if !curve.HasGLV() {
+		return curve.ScalarBaseMultJ(k)
+	}
+	g, endoG := glvBaseTables()
+	k1, k2 := GLVDecompose(new(big.Int).SetBytes(k))
+	return curve.strausMult([]*wnafTable{g, endoG}, []*big.Int{k1, k2})
+}
+
+// CombinedMult returns baseScalar·G + scalar·(x, y), the sum computed by
+// ECDSA verification and public key recovery. Both products share one chain
+// of doublings; on secp256k1 each scalar is also split with GLVDecompose, so
+// four half-length scalars are processed together.
+func (curve *CurveParams) CombinedMult(x, y *big.Int, baseScalar, scalar []byte) (*big.Int, *big.Int) {
+	u1 := new(big.Int).SetBytes(baseScalar)
+	u2 := new(big.Int).SetBytes(scalar)
+	inf := x.Sign() == 0 && y.Sign() == 0
+
+	if !curve.HasGLV() {
+		tables := []*wnafTable{curve.newWNAFTable(curve.Gx, curve.Gy, glvWindow)}
+		scalars := []*big.Int{u1}
+		if !inf {
+			tables = append(tables, curve.newWNAFTable(x, y, glvWindow))
+			scalars = append(scalars, u2)
+		}
+		return curve.strausMult(tables, scalars)
+	}
+
+	g, endoG := glvBaseTables()
+	k1, k2 := GLVDecompose(u1)
+	tables := []*wnafTable{g, endoG}
+	scalars := []*big.Int{k1, k2}
+	if !inf {
+		t := curve.newWNAFTable(x, y, glvWindow)
+		k3, k4 := GLVDecompose(u2)
+		tables = append(tables, t, curve.endoTable(t))
+		scalars = append(scalars, k3, k4)
+	}
+	return curve.strausMult(tables, scalars)
+}
@@ -0,0 +1,115 @@
+package ecgeneric_test
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/gost"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/nist"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGLVDecompose(t *testing.T) {
+	n := nist.Secp256k1.N
+	lambda := ecgeneric.BigFromHex("5363ad4cc05c30e0a5261c028812645a122e22ea20816678df02967c1b23bd72")
+	edge := []*big.Int{
+		big.NewInt(0),
+		big.NewInt(1),
+		new(big.Int).Sub(n, big.NewInt(1)),
+		new(big.Int).Set(lambda),
+		new(big.Int).Rsh(n, 1),
+	}
+	for i := 0; i < 200; i++ {
+		k, err := rand.Int(rand.Reader, n)
+		require.NoError(t, err)
+		edge = append(edge, k)
+	}
+
+	for _, k := range edge {
+		k1, k2 := ecgeneric.GLVDecompose(k)
+		require.LessOrEqual(t, k1.BitLen(), 129)
+		require.LessOrEqual(t, k2.BitLen(), 129)
+		sum := new(big.Int).Mul(k2, lambda)
+		sum.Add(sum, k1)
+		require.Zero(t, sum.Mod(sum, n).Cmp(k))
+	}
+}
+
+func TestScalarMultGLV(t *testing.T) {
+	curve := &nist.Secp256k1
+	require.True(t, curve.HasGLV())
+	require.False(t, gost.Gost34102001paramSetA.HasGLV())
+
+	for i := 0; i < 50; i++ {
+		k := make([]byte, 32)
+		_, err := rand.Read(k)
+		require.NoError(t, err)
+
+		x, y := curve.ScalarBaseMultGLV(k)
+		ex, ey := curve.ScalarBaseMultJ(k)
+		require.Equal(t, ex, x)
+		require.Equal(t, ey, y)
+
+		x2, y2 := curve.ScalarMultGLV(x, y, k)
+		ex2, ey2 := curve.ScalarMultJ(x, y, k)
+		require.Equal(t, ex2, x2)
+		require.Equal(t, ey2, y2)
+	}
+}
+
+func TestCombinedMult(t *testing.T) {
+	curves := []*ecgeneric.CurveParams{&nist.Secp256k1, &gost.Gost34102001paramSetA, &gost.Gost341012512paramSetA}
+	for _, curve := range curves {
+		size := (curve.N.BitLen() + 7) / 8
+		for i := 0; i < 10; i++ {
+			k := make([]byte, size)
+			u1 := make([]byte, size)
+			u2 := make([]byte, size)
+			for _, b := range [][]byte{k, u1, u2} {
+				_, err := rand.Read(b)
+				require.NoError(t, err)
+			}
+			x, y := curve.ScalarBaseMultJ(k)
+
+			gx, gy := curve.CombinedMult(x, y, u1, u2)
+			ax, ay := curve.ScalarBaseMultJ(u1)
+			bx, by := curve.ScalarMultJ(x, y, u2)
+			ex, ey := curve.AddJ(ax, ay, bx, by)
+			require.Equal(t, ex, gx, curve.Name)
+			require.Equal(t, ey, gy, curve.Name)
+
+			// A zero scalar or the point at infinity drops the second term.
+			gx, gy = curve.CombinedMult(x, y, u1, []byte{})
+			require.Equal(t, ax, gx)
+			require.Equal(t, ay, gy)
+			gx, gy = curve.CombinedMult(new(big.Int), new(big.Int), u1, u2)
+			require.Equal(t, ax, gx)
+			require.Equal(t, ay, gy)
+		}
+	}
+}
+
+func TestNistSecp256k1(t *testing.T) {
+	priv, err := rand.Int(rand.Reader, nist.Secp256k1.N)
+	require.NoError(t, err)
+	X, Y := nist.Secp256k1.ScalarBaseMultGLV(priv.Bytes())
+	hash := sha256.Sum256([]byte("Hello signature!"))
+
+	r, s, err := nist.Sign(priv, hash[:], &nist.Secp256k1, rand.Reader)
+	require.NoError(t, err)
+	valid, err := nist.Verify(hash[:], r, s, X, Y)
+	require.NoError(t, err)
+	require.True(t, valid)
+
+	ecRecX, ecRecY := nist.Ecrecover(hash[:], r, s, X, Y)
+	require.Equal(t, X, ecRecX)
+	require.Equal(t, Y, ecRecY)
+
+	hash[0] ^= 1
+	valid, err = nist.Verify(hash[:], r, s, X, Y)
+	require.NoError(t, err)
+	require.False(t, valid)
+}
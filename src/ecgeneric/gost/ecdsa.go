@@ -96,6 +96,12 @@ var	Gost34102001paramSetA  = ecgeneric.CurveParams{
 	Name:   "id-gostR3410-2001-CryptoPro-A-ParamSet",
 }
 
+func init() {
+	ecgeneric.RegisterCurve(&Gost34102001paramSetA)
+	ecgeneric.RegisterCurve(&Gost341012512paramSetA)
+	ecgeneric.RegisterCurve(&Gost341012512paramSetB)
+}
+
 func Hash(m []byte) ([32]byte) {
 	b := sha256.Sum256(m)
 	return b
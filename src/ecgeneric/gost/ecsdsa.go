@@ -0,0 +1,153 @@
+package gost
+
+import (
+	"bytes"
+	"errors"
+	"hash"
+	"io"
+	"math/big"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/streebog"
+)
+
+// EC-SDSA, the elliptic curve Schnorr digital signature algorithm of
+// ISO/IEC 14888-3, instantiated with Streebog on the GOST parameter sets.
+//
+// The key pair is the one used by SignSTD: a private scalar d and the public
+// point Q = d·G. A signature is (r, s) with
+//
+//	W = k·G,  r = H(W.x || W.y || M),  s = k + r·d mod N
+//
+// and it is checked by recomputing W' = s·G - r·Q. Unlike GOST R 34.10 the
+// response s is linear in both k and d, which is what makes aggregation and
+// multisignatures possible. The "optimized" variant hashes W.x only.
+
+var errSDSAChallenge = errors.New("ecsdsa: challenge is zero")
+
+// SDSAHash returns the hash used by EC-SDSA on curve: Streebog-512 for curves
+// whose order exceeds 256 bits and Streebog-256 otherwise.
+func SDSAHash(curve ecgeneric.Curve) func() hash.Hash {
+	if curve.Params().N.BitLen() > 256 {
+		return streebog.New512
+	}
+	return streebog.New256
+}
+
+// SignSDSA returns an EC-SDSA signature of msg by priv.
+func SignSDSA(rand io.Reader, priv *ecgeneric.PrivateKey, msg []byte) (r []byte, s *big.Int, err error) {
+	return signSDSA(rand, priv, msg, false)
+}
+
+// SignSDSAOpt returns an EC-SDSA-opt signature of msg by priv.
+func SignSDSAOpt(rand io.Reader, priv *ecgeneric.PrivateKey, msg []byte) (r []byte, s *big.Int, err error) {
+	return signSDSA(rand, priv, msg, true)
+}
+
+// VerifySDSA reports whether (r, s) is a valid EC-SDSA signature of msg by
+// pub. Public keys at infinity or off the curve are rejected.
+func VerifySDSA(pub *ecgeneric.PublicKey, msg, r []byte, s *big.Int) bool {
+	return verifySDSA(pub, msg, r, s, false)
+}
+
+// VerifySDSAOpt reports whether (r, s) is a valid EC-SDSA-opt signature of
+// msg by pub.
+func VerifySDSAOpt(pub *ecgeneric.PublicKey, msg, r []byte, s *big.Int) bool {
+	return verifySDSA(pub, msg, r, s, true)
+}
+
+func signSDSA(rand io.Reader, priv *ecgeneric.PrivateKey, msg []byte, opt bool) (r []byte, s *big.Int, err error) {
+	c := priv.Curve
+	N := c.Params().N
+	if N.Sign() == 0 {
+		return nil, nil, errZeroParam
+	}
+	for {
+		k, err := randScalar(c, rand)
+		if err != nil {
+			return nil, nil, err
+		}
+		Wx, Wy := c.ScalarBaseMultJ(k.Bytes())
+		r = sdsaChallenge(c, Wx, Wy, msg, opt)
+		e := new(big.Int).SetBytes(r)
+		e.Mod(e, N)
+		if e.Sign() == 0 {
+			continue
+		}
+
+		s = e.Mul(e, priv.D)
+		s.Add(s, k)
+		s.Mod(s, N)
+		if s.Sign() != 0 {
+			return r, s, nil
+		}
+	}
+}
+
+// randScalar returns a nonce in [1, N-1] reduced from N.BitLen()+64 random
+// bits as in FIPS 186-4, Appendix B.5.1. Unlike randFieldElement it does not
+// go by BitSize, which is smaller than the order on curves such as GostEx2.
+func randScalar(c ecgeneric.Curve, rand io.Reader) (*big.Int, error) {
+	N := c.Params().N
+	b := make([]byte, (N.BitLen()+64+7)/8)
+	if _, err := io.ReadFull(rand, b); err != nil {
+		return nil, err
+	}
+	k := new(big.Int).SetBytes(b)
+	n := new(big.Int).Sub(N, one)
+	k.Mod(k, n)
+	return k.Add(k, one), nil
+}
+
+func verifySDSA(pub *ecgeneric.PublicKey, msg, r []byte, s *big.Int, opt bool) bool {
+	c := pub.Curve
+	N := c.Params().N
+	if pub.X == nil || pub.Y == nil || (pub.X.Sign() == 0 && pub.Y.Sign() == 0) || !c.Params().IsOnCurve(pub.X, pub.Y) {
+		return false
+	}
+	if len(r) != SDSAHash(c)().Size() {
+		return false
+	}
+	if s.Sign() <= 0 || s.Cmp(N) >= 0 {
+		return false
+	}
+	e := new(big.Int).SetBytes(r)
+	e.Mod(e, N)
+	if e.Sign() == 0 {
+		return false
+	}
+
+	// W' = s·G - e·Q
+	e.Sub(N, e)
+	x1, y1 := c.ScalarBaseMultJ(s.Bytes())
+	x2, y2 := c.ScalarMultJ(pub.X, pub.Y, e.Bytes())
+	Wx, Wy := c.AddJ(x1, y1, x2, y2)
+	if Wx.Sign() == 0 && Wy.Sign() == 0 {
+		return false
+	}
+	return bytes.Equal(r, sdsaChallenge(c, Wx, Wy, msg, opt))
+}
+
+// SDSAChallenge returns the EC-SDSA challenge r = H(W.x || W.y || M) for
+// the commitment W, for protocols that assemble signatures outside SignSDSA,
+// such as blind signing.
+func SDSAChallenge(c ecgeneric.Curve, Wx, Wy *big.Int, msg []byte) []byte {
+	return sdsaChallenge(c, Wx, Wy, msg, false)
+}
+
+// sdsaChallenge returns H(FE2OS(W.x) || FE2OS(W.y) || M), or H(FE2OS(W.x) ||
+// M) for the optimized variant.
+func sdsaChallenge(c ecgeneric.Curve, Wx, Wy *big.Int, msg []byte, opt bool) []byte {
+	byteLen := (c.Params().P.BitLen() + 7) / 8
+	buf := make([]byte, byteLen)
+	h := SDSAHash(c)()
+
+	Wx.FillBytes(buf)
+	h.Write(buf)
+	if !opt {
+		Wy.FillBytes(buf)
+		h.Write(buf)
+	}
+	h.Write(msg)
+	return h.Sum(nil)
+}
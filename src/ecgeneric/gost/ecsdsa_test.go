@@ -0,0 +1,140 @@
+package gost_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/gost"
+	"github.com/stretchr/testify/require"
+)
+
+var gostCurves = []*ecgeneric.CurveParams{
+	&gost.GostEx1,
+	&gost.GostEx2,
+	&gost.Gost34102001paramSetA,
+	&gost.Gost341012512paramSetA,
+	&gost.Gost341012512paramSetB,
+}
+
+func TestSDSASignVerify(t *testing.T) {
+	msg := []byte("Hello signature!")
+	for _, curve := range gostCurves {
+		priv, err := ecgeneric.GenerateKey(curve, rand.Reader)
+		require.NoError(t, err)
+
+		r, s, err := gost.SignSDSA(rand.Reader, priv, msg)
+		require.NoError(t, err)
+		require.Len(t, r, gost.SDSAHash(curve)().Size())
+		require.True(t, gost.VerifySDSA(&priv.PublicKey, msg, r, s), curve.Name)
+		require.False(t, gost.VerifySDSAOpt(&priv.PublicKey, msg, r, s), curve.Name)
+		require.False(t, gost.VerifySDSA(&priv.PublicKey, []byte("Hello signature?"), r, s), curve.Name)
+		require.False(t, gost.VerifySDSA(&priv.PublicKey, msg, r, new(big.Int).Add(s, big.NewInt(1))), curve.Name)
+
+		r, s, err = gost.SignSDSAOpt(rand.Reader, priv, msg)
+		require.NoError(t, err)
+		require.True(t, gost.VerifySDSAOpt(&priv.PublicKey, msg, r, s), curve.Name)
+		require.False(t, gost.VerifySDSA(&priv.PublicKey, msg, r, s), curve.Name)
+	}
+}
+
+// Regression values for the Streebog instantiation, message "abc". They are
+// not ISO/IEC 14888-3 vectors: the private keys, public keys and nonces are
+// those of the GOST R 34.10-2012 examples A.1 and A.2, but r and s were
+// computed with this package from W = k·G, the Streebog hash of
+// FE2OS(W.x) || FE2OS(W.y) || M and s = k + r·d mod N.
+var sdsaVectors = []struct {
+	curve *ecgeneric.CurveParams
+	opt   bool
+	d, k  string
+	x, y  string
+	r, s  string
+}{
+	{
+		&gost.GostEx1, false,
+		"7a929ade789bb9be10ed359dd39a72c11b60961f49397eee1d19ce9891ec3b28",
+		"77105c9b20bcd3122823c8cf6fcc7b956de33814e95b7fe64fed924594dceab3",
+		"7f2b49e270db6d90d8595bec458b50c58585ba1d4e9b788f6689dbd8e56fd80b",
+		"26f1b489d6701dd185c8413a977b3cbbaf64d1c593d26627dffb101a87ff77da",
+		"8ec07e11fc40d594a7ae0551a76b9891e4df4bae0fbcb56912cb5843852f1f10",
+		"658b9dddb3f04e44a3065dfa039b9a548582f963f4f31e270b19bc911cf303d2",
+	},
+	{
+		&gost.GostEx1, true,
+		"7a929ade789bb9be10ed359dd39a72c11b60961f49397eee1d19ce9891ec3b28",
+		"77105c9b20bcd3122823c8cf6fcc7b956de33814e95b7fe64fed924594dceab3",
+		"7f2b49e270db6d90d8595bec458b50c58585ba1d4e9b788f6689dbd8e56fd80b",
+		"26f1b489d6701dd185c8413a977b3cbbaf64d1c593d26627dffb101a87ff77da",
+		"31944ac11873f95bab5ebc0f5ab91a355b01b0631414aa9e05d090ce7e0e247c",
+		"15b705e70bf697d27e598dae04ef58f35d8bb994a98c45209e4796e389badb5f",
+	},
+	{
+		&gost.GostEx2, false,
+		"0ba6048aadae241ba40936d47756d7c93091a0e8514669700ee7508e508b102072e8123b2200a0563322dad2827e2714a2636b7bfd18aadfc62967821fa18dd4",
+		"0359e7f4b1410feacc570456c6801496946312120b39d019d455986e364f365886748ed7a44b3e794434006011842286212273a6d14cf70ea3af71bb1ae679f1",
+		"115dc5bc96760c7b48598d8ab9e740d4c4a85a65be33c1815b5c320c854621dd5a515856d13314af69bc5b924c8b4ddff75c45415c1d9dd9dd33612cd530efe1",
+		"37c7c90cd40b0f5621dc3ac1b751cfa0e2634fa0503b3d52639f5d7fb72afd61ea199441d943ffe7f0c70a2759a3cdb84c114e1f9339fdf27f35eca93677beec",
+		"87adaa09364df9ffd3be49a335018e73c2ec61a6d8039e3d0b0ea0ccee368b22ce9f3a13d7bed0f6c1b82f17fa95773382e22776353ebbcc963d311d878be295",
+		"3a19f7f476b061037c44d5da0db413f3c0261250fc87faa1735e674931f7b2f99e8b31277a95999dbfdab144d5cdda5878ffbc58e53bfff335f42e6ab77298a0",
+	},
+	{
+		&gost.GostEx2, true,
+		"0ba6048aadae241ba40936d47756d7c93091a0e8514669700ee7508e508b102072e8123b2200a0563322dad2827e2714a2636b7bfd18aadfc62967821fa18dd4",
+		"0359e7f4b1410feacc570456c6801496946312120b39d019d455986e364f365886748ed7a44b3e794434006011842286212273a6d14cf70ea3af71bb1ae679f1",
+		"115dc5bc96760c7b48598d8ab9e740d4c4a85a65be33c1815b5c320c854621dd5a515856d13314af69bc5b924c8b4ddff75c45415c1d9dd9dd33612cd530efe1",
+		"37c7c90cd40b0f5621dc3ac1b751cfa0e2634fa0503b3d52639f5d7fb72afd61ea199441d943ffe7f0c70a2759a3cdb84c114e1f9339fdf27f35eca93677beec",
+		"83490adce4d6895197042d7c7269ef59c7b5bdbcf28df2b8a99c92e587474619c0ccff871ea8bca5f835184f500a0ebf455073402eb897540394204bbf20f6cc",
+		"0e5fd86142039c8c26831e01cd748e13284746541f69279d0e96e6ce6a8f81c9e856a088fc7aa76bdd39958e861240aab057494600e67c2c16ad9d6d94c94cfc",
+	},
+}
+
+// nonceReader returns the bytes from which SignSDSA draws the nonce k.
+func nonceReader(curve *ecgeneric.CurveParams, k *big.Int) *bytes.Reader {
+	b := make([]byte, (curve.N.BitLen()+64+7)/8)
+	new(big.Int).Sub(k, big.NewInt(1)).FillBytes(b)
+	return bytes.NewReader(b)
+}
+
+func TestSDSAVectors(t *testing.T) {
+	msg := []byte("abc")
+	for _, v := range sdsaVectors {
+		d := ecgeneric.BigFromHex(v.d)
+		x, y := v.curve.ScalarBaseMultJ(d.Bytes())
+		require.Equal(t, v.x, hex.EncodeToString(x.Bytes()), v.curve.Name)
+		require.Equal(t, v.y, hex.EncodeToString(y.Bytes()), v.curve.Name)
+		priv := &ecgeneric.PrivateKey{PublicKey: ecgeneric.PublicKey{Curve: v.curve, X: x, Y: y}, D: d}
+
+		sign, verify := gost.SignSDSA, gost.VerifySDSA
+		if v.opt {
+			sign, verify = gost.SignSDSAOpt, gost.VerifySDSAOpt
+		}
+		r, s, err := sign(nonceReader(v.curve, ecgeneric.BigFromHex(v.k)), priv, msg)
+		require.NoError(t, err)
+		require.Equal(t, v.r, hex.EncodeToString(r), v.curve.Name)
+		require.Equal(t, v.s, hex.EncodeToString(s.Bytes()), v.curve.Name)
+		require.True(t, verify(&priv.PublicKey, msg, r, s), v.curve.Name)
+	}
+}
+
+func TestSDSAInvalidPublicKey(t *testing.T) {
+	curve := &gost.Gost34102001paramSetA
+	priv, err := ecgeneric.GenerateKey(curve, rand.Reader)
+	require.NoError(t, err)
+	msg := []byte("Hello signature!")
+	r, s, err := gost.SignSDSA(rand.Reader, priv, msg)
+	require.NoError(t, err)
+
+	bad := []*ecgeneric.PublicKey{
+		{Curve: curve, X: new(big.Int), Y: new(big.Int)},
+		{Curve: curve, X: priv.X, Y: new(big.Int).Add(priv.Y, big.NewInt(1))},
+		{Curve: curve, X: priv.X, Y: new(big.Int).Add(priv.Y, curve.P)},
+		{Curve: curve},
+	}
+	for _, pub := range bad {
+		require.False(t, gost.VerifySDSA(pub, msg, r, s))
+		require.False(t, gost.VerifySDSAOpt(pub, msg, r, s))
+	}
+}
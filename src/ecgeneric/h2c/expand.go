@@ -0,0 +1,59 @@
+package h2c
+
+import (
+	"errors"
+	"hash"
+)
+
+var (
+	ErrDST = errors.New("h2c: domain separation tag must not be empty")
+
+	errExpandLength = errors.New("h2c: requested output is too long")
+)
+
+// maxDSTLength is the longest tag used as is; longer tags are hashed first.
+const maxDSTLength = 255
+
+// ExpandMessageXMD implements expand_message_xmd of RFC 9380, Section 5.3.1,
+// producing n uniformly random bytes from msg and the domain separation tag
+// dst. newHash must be a Merkle–Damgård hash such as SHA-256 or Streebog.
+// Tags longer than 255 bytes are shortened as in Section 5.3.3.
+func ExpandMessageXMD(newHash func() hash.Hash, msg, dst []byte, n int) ([]byte, error) {
+	if len(dst) == 0 {
+		return nil, ErrDST
+	}
+	h := newHash()
+	if len(dst) > maxDSTLength {
+		h.Write([]byte("H2C-OVERSIZE-DST-"))
+		h.Write(dst)
+		dst = h.Sum(nil)
+		h.Reset()
+	}
+	b := h.Size()
+	ell := (n + b - 1) / b
+	if n <= 0 || n > 0xffff || ell > 255 {
+		return nil, errExpandLength
+	}
+	dstPrime := append(append([]byte{}, dst...), byte(len(dst)))
+
+	h.Write(make([]byte, h.BlockSize()))
+	h.Write(msg)
+	h.Write([]byte{byte(n >> 8), byte(n), 0})
+	h.Write(dstPrime)
+	b0 := h.Sum(nil)
+
+	out := make([]byte, 0, ell*b)
+	bi := make([]byte, b)
+	for i := 1; i <= ell; i++ {
+		for j := range bi {
+			bi[j] ^= b0[j]
+		}
+		h.Reset()
+		h.Write(bi)
+		h.Write([]byte{byte(i)})
+		h.Write(dstPrime)
+		bi = h.Sum(bi[:0])
+		out = append(out, bi...)
+	}
+	return out[:n], nil
+}
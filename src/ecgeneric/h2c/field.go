@@ -0,0 +1,115 @@
+package h2c
+
+import "math/big"
+
+// field is arithmetic modulo a prime. Results are always reduced.
+type field struct {
+	p *big.Int
+}
+
+func (f field) elem(x *big.Int) *big.Int { return new(big.Int).Mod(x, f.p) }
+
+func (f field) add(a, b *big.Int) *big.Int { return f.elem(new(big.Int).Add(a, b)) }
+
+func (f field) sub(a, b *big.Int) *big.Int { return f.elem(new(big.Int).Sub(a, b)) }
+
+func (f field) mul(a, b *big.Int) *big.Int { return f.elem(new(big.Int).Mul(a, b)) }
+
+func (f field) neg(a *big.Int) *big.Int { return f.elem(new(big.Int).Neg(a)) }
+
+// inv0 returns 1/a, or 0 for a = 0.
+func (f field) inv0(a *big.Int) *big.Int {
+	if f.elem(a).Sign() == 0 {
+		return new(big.Int)
+	}
+	return new(big.Int).ModInverse(a, f.p)
+}
+
+func (f field) isSquare(a *big.Int) bool {
+	return big.Jacobi(f.elem(a), f.p) >= 0
+}
+
+// sqrt returns a square root of a square a.
+func (f field) sqrt(a *big.Int) *big.Int {
+	return new(big.Int).ModSqrt(f.elem(a), f.p)
+}
+
+// sgn0 is the sign of a field element, RFC 9380 Section 4.1.
+func sgn0(a *big.Int) uint {
+	return a.Bit(0)
+}
+
+// g evaluates x³ + A·x + B.
+func (f field) g(A, B, x *big.Int) *big.Int {
+	y := f.mul(f.mul(x, x), x)
+	return f.add(f.add(y, f.mul(A, x)), B)
+}
+
+// poly is a polynomial over the field with coefficients from the constant
+// term up.
+type poly []*big.Int
+
+func (f field) trim(a poly) poly {
+	for len(a) > 0 && a[len(a)-1].Sign() == 0 {
+		a = a[:len(a)-1]
+	}
+	return a
+}
+
+// polyMod returns a mod b for a nonzero b.
+func (f field) polyMod(a, b poly) poly {
+	r := make(poly, len(a))
+	for i := range a {
+		r[i] = f.elem(a[i])
+	}
+	r = f.trim(r)
+	lead := f.inv0(b[len(b)-1])
+	for len(r) >= len(b) {
+		c := f.mul(r[len(r)-1], lead)
+		shift := len(r) - len(b)
+		for i := range b {
+			r[shift+i] = f.sub(r[shift+i], f.mul(c, b[i]))
+		}
+		r = f.trim(r)
+	}
+	return r
+}
+
+func (f field) polyMul(a, b poly) poly {
+	if len(a) == 0 || len(b) == 0 {
+		return nil
+	}
+	r := make(poly, len(a)+len(b)-1)
+	for i := range r {
+		r[i] = new(big.Int)
+	}
+	for i := range a {
+		for j := range b {
+			r[i+j] = f.add(r[i+j], f.mul(a[i], b[j]))
+		}
+	}
+	return r
+}
+
+// hasRoot reports whether the polynomial m of positive degree has a root in
+// the field, that is whether gcd(m, x^p - x) is not constant.
+func (f field) hasRoot(m poly) bool {
+	// x^p mod m by square-and-multiply.
+	x := poly{new(big.Int), big.NewInt(1)}
+	r := poly{big.NewInt(1)}
+	for i := f.p.BitLen() - 1; i >= 0; i-- {
+		r = f.polyMod(f.polyMul(r, r), m)
+		if f.p.Bit(i) == 1 {
+			r = f.polyMod(f.polyMul(r, x), m)
+		}
+	}
+	for len(r) < 2 {
+		r = append(r, new(big.Int))
+	}
+	r[1] = f.sub(r[1], big.NewInt(1))
+	a, b := m, f.trim(r)
+	for len(b) > 0 {
+		a, b = b, f.polyMod(a, b)
+	}
+	return len(a) > 1
+}
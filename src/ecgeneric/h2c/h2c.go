@@ -0,0 +1,106 @@
+// Package h2c implements hashing to elliptic curves as specified in RFC 9380
+// for short-Weierstrass ecgeneric curves.
+//
+// A Suite combines expand_message_xmd over a Merkle–Damgård hash, such as
+// SHA-256 or Streebog, with a map from field elements to points. The
+// simplified SWU map is used where the curve allows it, including secp256k1
+// through its 3-isogeny, so that NewSuite(&nist.Secp256k1, sha256.New, 128)
+// is the standard secp256k1_XMD:SHA-256_SSWU_RO_ and _NU_ suite. Any other
+// curve falls back to the Shallue–van de Woestijne map.
+//
+// The maps use math/big and are not constant time.
+package h2c
+
+import (
+	"hash"
+	"math/big"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+)
+
+// A Mapper is a deterministic map from field elements to curve points,
+// map_to_curve in RFC 9380, Section 6.
+type Mapper interface {
+	MapToCurve(u *big.Int) ecgeneric.Point
+}
+
+// Suite is a hash-to-curve suite for one curve.
+type Suite struct {
+	Curve *ecgeneric.CurveParams
+	// Hash is the hash used by expand_message_xmd.
+	Hash func() hash.Hash
+	// K is the target security level in bits.
+	K   int
+	Map Mapper
+}
+
+// NewSuite returns the suite for curve with expand_message_xmd over newHash
+// and the security level k, or half the bit length of the curve order if k
+// is zero. The map is simplified SWU if NewSSWU supports the curve and
+// Shallue–van de Woestijne otherwise.
+func NewSuite(curve *ecgeneric.CurveParams, newHash func() hash.Hash, k int) (*Suite, error) {
+	if k <= 0 {
+		k = curve.N.BitLen() / 2
+	}
+	m, err := NewSSWU(curve)
+	if err != nil {
+		if m, err = NewSvdW(curve); err != nil {
+			return nil, err
+		}
+	}
+	return &Suite{Curve: curve, Hash: newHash, K: k, Map: m}, nil
+}
+
+// HashToField hashes msg to count elements of the base field, as
+// hash_to_field in RFC 9380, Section 5.2.
+func (s *Suite) HashToField(msg, dst []byte, count int) ([]*big.Int, error) {
+	p := s.Curve.P
+	L := (p.BitLen() + s.K + 7) / 8
+	uniform, err := ExpandMessageXMD(s.Hash, msg, dst, count*L)
+	if err != nil {
+		return nil, err
+	}
+	u := make([]*big.Int, count)
+	for i := range u {
+		u[i] = new(big.Int).SetBytes(uniform[i*L : (i+1)*L])
+		u[i].Mod(u[i], p)
+	}
+	return u, nil
+}
+
+// HashToCurve hashes msg to a point with the random-oracle construction
+// hash_to_curve, RFC 9380 Section 3. The result may be the point at
+// infinity, (0, 0), though only with negligible probability.
+func (s *Suite) HashToCurve(msg, dst []byte) (ecgeneric.Point, error) {
+	u, err := s.HashToField(msg, dst, 2)
+	if err != nil {
+		return ecgeneric.Point{}, err
+	}
+	q0, q1 := s.Map.MapToCurve(u[0]), s.Map.MapToCurve(u[1])
+	x, y := s.Curve.AddJ(q0.X, q0.Y, q1.X, q1.Y)
+	return s.clearCofactor(ecgeneric.Point{X: x, Y: y}), nil
+}
+
+// EncodeToCurve hashes msg to a point with the nonuniform encoding
+// encode_to_curve, RFC 9380 Section 3. It is cheaper than HashToCurve, but
+// its output is distinguishable from a random point.
+func (s *Suite) EncodeToCurve(msg, dst []byte) (ecgeneric.Point, error) {
+	u, err := s.HashToField(msg, dst, 1)
+	if err != nil {
+		return ecgeneric.Point{}, err
+	}
+	return s.clearCofactor(s.Map.MapToCurve(u[0])), nil
+}
+
+func (s *Suite) clearCofactor(p ecgeneric.Point) ecgeneric.Point {
+	h := s.Curve.Cofactor()
+	if h.Cmp(big.NewInt(1)) == 0 || (p.X.Sign() == 0 && p.Y.Sign() == 0) {
+		return p
+	}
+	x, y := s.Curve.ScalarMultJ(p.X, p.Y, h.Bytes())
+	return ecgeneric.Point{X: x, Y: y}
+}
+
+func sameCurve(a, b *ecgeneric.CurveParams) bool {
+	return a == b || (a.P.Cmp(b.P) == 0 && a.A.Cmp(b.A) == 0 && a.B.Cmp(b.B) == 0)
+}
@@ -0,0 +1,199 @@
+package h2c_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/gost"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/h2c"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/nist"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/streebog"
+	"github.com/stretchr/testify/require"
+)
+
+// Vectors from RFC 9380, Appendices J and K.
+
+type xmdVector struct {
+	msg     string
+	n       int
+	uniform string
+}
+
+// DST "QUUX-V01-CS02-with-expander-SHA256-128".
+var xmdSHA256 = []xmdVector{
+	{"", 32, "68a985b87eb6b46952128911f2a4412bbc302a9d759667f87f7a21d803f07235"},
+	{"abc", 32, "d8ccab23b5985ccea865c6c97b6e5b8350e794e603b4b97902f53a8a0d605615"},
+	{"abcdef0123456789", 32, "eff31487c770a893cfb36f912fbfcbff40d5661771ca4b2cb4eafe524333f5c1"},
+	{"q128_" + strings.Repeat("q", 128), 32, "b23a1d2b4d97b2ef7785562a7e8bac7eed54ed6e97e29aa51bfe3f12ddad1ff9"},
+	{"a512_" + strings.Repeat("a", 512), 32, "4623227bcc01293b8c130bf771da8c298dede7383243dc0993d2d94823958c4c"},
+	{"", 128, "af84c27ccfd45d41914fdff5df25293e221afc53d8ad2ac06d5e3e29485dadbee0d121587713a3e0dd4d5e69e93eb7cd4f5df4cd103e188cf60cb02edc3edf18eda8576c412b18ffb658e3dd6ec849469b979d444cf7b26911a08e63cf31f9dcc541708d3491184472c2c29bb749d4286b004ceb5ee6b9a7fa5b646c993f0ced"},
+	{"abc", 128, "abba86a6129e366fc877aab32fc4ffc70120d8996c88aee2fe4b32d6c7b6437a647e6c3163d40b76a73cf6a5674ef1d890f95b664ee0afa5359a5c4e07985635bbecbac65d747d3d2da7ec2b8221b17b0ca9dc8a1ac1c07ea6a1e60583e2cb00058e77b7b72a298425cd1b941ad4ec65e8afc50303a22c0f99b0509b4c895f40"},
+	{"abcdef0123456789", 128, "ef904a29bffc4cf9ee82832451c946ac3c8f8058ae97d8d629831a74c6572bd9ebd0df635cd1f208e2038e760c4994984ce73f0d55ea9f22af83ba4734569d4bc95e18350f740c07eef653cbb9f87910d833751825f0ebefa1abe5420bb52be14cf489b37fe1a72f7de2d10be453b2c9d9eb20c7e3f6edc5a60629178d9478df"},
+	{"q128_" + strings.Repeat("q", 128), 128, "80be107d0884f0d881bb460322f0443d38bd222db8bd0b0a5312a6fedb49c1bbd88fd75d8b9a09486c60123dfa1d73c1cc3169761b17476d3c6b7cbbd727acd0e2c942f4dd96ae3da5de368d26b32286e32de7e5a8cb2949f866a0b80c58116b29fa7fabb3ea7d520ee603e0c25bcaf0b9a5e92ec6a1fe4e0391d1cdbce8c68a"},
+	{"a512_" + strings.Repeat("a", 512), 128, "546aff5444b5b79aa6148bd81728704c32decb73a3ba76e9e75885cad9def1d06d6792f8a7d12794e90efed817d96920d728896a4510864370c207f99bd4a608ea121700ef01ed879745ee3e4ceef777eda6d9e5e38b90c86ea6fb0b36504ba4a45d22e86f6db5dd43d98a294bebb9125d5b794e9d2a81181066eb954966a487"},
+}
+
+// The same DST followed by "-long-DST-" and 208 ones, which is hashed first.
+var xmdSHA256LongDST = []xmdVector{
+	{"", 32, "e8dc0c8b686b7ef2074086fbdd2f30e3f8bfbd3bdf177f73f04b97ce618a3ed3"},
+	{"abc", 32, "52dbf4f36cf560fca57dedec2ad924ee9c266341d8f3d6afe5171733b16bbb12"},
+	{"abcdef0123456789", 32, "35387dcf22618f3728e6c686490f8b431f76550b0b2c61cbc1ce7001536f4521"},
+	{"q128_" + strings.Repeat("q", 128), 32, "01b637612bb18e840028be900a833a74414140dde0c4754c198532c3a0ba42bc"},
+	{"a512_" + strings.Repeat("a", 512), 32, "20cce7033cabc5460743180be6fa8aac5a103f56d481cf369a8accc0c374431b"},
+	{"", 128, "14604d85432c68b757e485c8894db3117992fc57e0e136f71ad987f789a0abc287c47876978e2388a02af86b1e8d1342e5ce4f7aaa07a87321e691f6fba7e0072eecc1218aebb89fb14a0662322d5edbd873f0eb35260145cd4e64f748c5dfe60567e126604bcab1a3ee2dc0778102ae8a5cfd1429ebc0fa6bf1a53c36f55dfc"},
+	{"abc", 128, "1a30a5e36fbdb87077552b9d18b9f0aee16e80181d5b951d0471d55b66684914aef87dbb3626eaabf5ded8cd0686567e503853e5c84c259ba0efc37f71c839da2129fe81afdaec7fbdc0ccd4c794727a17c0d20ff0ea55e1389d6982d1241cb8d165762dbc39fb0cee4474d2cbbd468a835ae5b2f20e4f959f56ab24cd6fe267"},
+	{"abcdef0123456789", 128, "d2ecef3635d2397f34a9f86438d772db19ffe9924e28a1caf6f1c8f15603d4028f40891044e5c7e39ebb9b31339979ff33a4249206f67d4a1e7c765410bcd249ad78d407e303675918f20f26ce6d7027ed3774512ef5b00d816e51bfcc96c3539601fa48ef1c07e494bdc37054ba96ecb9dbd666417e3de289d4f424f502a982"},
+	{"q128_" + strings.Repeat("q", 128), 128, "ed6e8c036df90111410431431a232d41a32c86e296c05d426e5f44e75b9a50d335b2412bc6c91e0a6dc131de09c43110d9180d0a70f0d6289cb4e43b05f7ee5e9b3f42a1fad0f31bac6a625b3b5c50e3a83316783b649e5ecc9d3b1d9471cb5024b7ccf40d41d1751a04ca0356548bc6e703fca02ab521b505e8e45600508d32"},
+	{"a512_" + strings.Repeat("a", 512), 128, "78b53f2413f3c688f07732c10e5ced29a17c6a16f717179ffbe38d92d6c9ec296502eb9889af83a1928cd162e845b0d3c5424e83280fed3d10cffb2f8431f14e7a23f4c68819d40617589e4c41169d0b56e0e3535be1fd71fbb08bb70c5b5ffed953d6c14bf7618b35fc1f4c4b30538236b4b08c9fbf90462447a8ada60be495"},
+}
+
+type curveVector struct {
+	msg  string
+	x, y string
+	u    []string
+}
+
+var secp256k1RO = []curveVector{
+	{"", "c1cae290e291aee617ebaef1be6d73861479c48b841eaba9b7b5852ddfeb1346", "64fa678e07ae116126f08b022a94af6de15985c996c3a91b64c406a960e51067", []string{"6b0f9910dd2ba71c78f2ee9f04d73b5f4c5f7fc773a701abea1e573cab002fb3", "1ae6c212e08fe1a5937f6202f929a2cc8ef4ee5b9782db68b0d5799fd8f09e16"}},
+	{"abc", "3377e01eab42db296b512293120c6cee72b6ecf9f9205760bd9ff11fb3cb2c4b", "7f95890f33efebd1044d382a01b1bee0900fb6116f94688d487c6c7b9c8371f6", []string{"128aab5d3679a1f7601e3bdf94ced1f43e491f544767e18a4873f397b08a2b61", "5897b65da3b595a813d0fdcc75c895dc531be76a03518b044daaa0f2e4689e00"}},
+	{"abcdef0123456789", "bac54083f293f1fe08e4a70137260aa90783a5cb84d3f35848b324d0674b0e3a", "4436476085d4c3c4508b60fcf4389c40176adce756b398bdee27bca19758d828", []string{"ea67a7c02f2cd5d8b87715c169d055a22520f74daeb080e6180958380e2f98b9", "7434d0d1a500d38380d1f9615c021857ac8d546925f5f2355319d823a478da18"}},
+	{"q128_" + strings.Repeat("q", 128), "e2167bc785333a37aa562f021f1e881defb853839babf52a7f72b102e41890e9", "f2401dd95cc35867ffed4f367cd564763719fbc6a53e969fb8496a1e6685d873", []string{"eda89a5024fac0a8207a87e8cc4e85aa3bce10745d501a30deb87341b05bcdf5", "dfe78cd116818fc2c16f3837fedbe2639fab012c407eac9dfe9245bf650ac51d"}},
+	{"a512_" + strings.Repeat("a", 512), "e3c8d35aaaf0b9b647e88a0a0a7ee5d5bed5ad38238152e4e6fd8c1f8cb7c998", "8446eeb6181bf12f56a9d24e262221cc2f0c4725c7e3803024b5888ee5823aa6", []string{"8d862e7e7e23d7843fe16d811d46d7e6480127a6b78838c277bca17df6900e9f", "68071d2530f040f081ba818d3c7188a94c900586761e9115efa47ae9bd847938"}},
+}
+
+var secp256k1NU = []curveVector{
+	{"", "a4792346075feae77ac3b30026f99c1441b4ecf666ded19b7522cf65c4c55c5b", "62c59e2a6aeed1b23be5883e833912b08ba06be7f57c0e9cdc663f31639ff3a7", []string{"0137fcd23bc3da962e8808f97474d097a6c8aa2881fceef4514173635872cf3b"}},
+	{"abc", "3f3b5842033fff837d504bb4ce2a372bfeadbdbd84a1d2b678b6e1d7ee426b9d", "902910d1fef15d8ae2006fc84f2a5a7bda0e0407dc913062c3a493c4f5d876a5", []string{"e03f894b4d7caf1a50d6aa45cac27412c8867a25489e32c5ddeb503229f63a2e"}},
+	{"abcdef0123456789", "07644fa6281c694709f53bdd21bed94dab995671e4a8cd1904ec4aa50c59bfdf", "c79f8d1dad79b6540426922f7fbc9579c3018dafeffcd4552b1626b506c21e7b", []string{"e7a6525ae7069ff43498f7f508b41c57f80563c1fe4283510b322446f32af41b"}},
+	{"q128_" + strings.Repeat("q", 128), "b734f05e9b9709ab631d960fa26d669c4aeaea64ae62004b9d34f483aa9acc33", "03fc8a4a5a78632e2eb4d8460d69ff33c1d72574b79a35e402e801f2d0b1d6ee", []string{"d97cf3d176a2f26b9614a704d7d434739d194226a706c886c5c3c39806bc323c"}},
+	{"a512_" + strings.Repeat("a", 512), "17d22b867658977b5002dbe8d0ee70a8cfddec3eec50fb93f36136070fd9fa6c", "e9178ff02f4dab73480f8dd590328aea99856a7b6cc8e5a6cdf289ecc2a51718", []string{"a9ffbeee1d6e41ac33c248fb3364612ff591b502386c1bf6ac4aaf1ea51f8c3b"}},
+}
+
+var p256RO = []curveVector{
+	{"", "2c15230b26dbc6fc9a37051158c95b79656e17a1a920b11394ca91c44247d3e4", "8a7a74985cc5c776cdfe4b1f19884970453912e9d31528c060be9ab5c43e8415", []string{"ad5342c66a6dd0ff080df1da0ea1c04b96e0330dd89406465eeba11582515009", "8c0f1d43204bd6f6ea70ae8013070a1518b43873bcd850aafa0a9e220e2eea5a"}},
+	{"abc", "0bb8b87485551aa43ed54f009230450b492fead5f1cc91658775dac4a3388a0f", "5c41b3d0731a27a7b14bc0bf0ccded2d8751f83493404c84a88e71ffd424212e", []string{"afe47f2ea2b10465cc26ac403194dfb68b7f5ee865cda61e9f3e07a537220af1", "379a27833b0bfe6f7bdca08e1e83c760bf9a338ab335542704edcd69ce9e46e0"}},
+	{"abcdef0123456789", "65038ac8f2b1def042a5df0b33b1f4eca6bff7cb0f9c6c1526811864e544ed80", "cad44d40a656e7aff4002a8de287abc8ae0482b5ae825822bb870d6df9b56ca3", []string{"0fad9d125a9477d55cf9357105b0eb3a5c4259809bf87180aa01d651f53d312c", "b68597377392cd3419d8fcc7d7660948c8403b19ea78bbca4b133c9d2196c0fb"}},
+	{"q128_" + strings.Repeat("q", 128), "4be61ee205094282ba8a2042bcb48d88dfbb609301c49aa8b078533dc65a0b5d", "98f8df449a072c4721d241a3b1236d3caccba603f916ca680f4539d2bfb3c29e", []string{"3bbc30446f39a7befad080f4d5f32ed116b9534626993d2cc5033f6f8d805919", "76bb02db019ca9d3c1e02f0c17f8baf617bbdae5c393a81d9ce11e3be1bf1d33"}},
+	{"a512_" + strings.Repeat("a", 512), "457ae2981f70ca85d8e24c308b14db22f3e3862c5ea0f652ca38b5e49cd64bc5", "ecb9f0eadc9aeed232dabc53235368c1394c78de05dd96893eefa62b0f4757dc", []string{"4ebc95a6e839b1ae3c63b847798e85cb3c12d3817ec6ebc10af6ee51adb29fec", "4e21af88e22ea80156aff790750121035b3eefaa96b425a8716e0d20b4e269ee"}},
+}
+
+var p256NU = []curveVector{
+	{"", "f871caad25ea3b59c16cf87c1894902f7e7b2c822c3d3f73596c5ace8ddd14d1", "87b9ae23335bee057b99bac1e68588b18b5691af476234b8971bc4f011ddc99b", []string{"b22d487045f80e9edcb0ecc8d4bf77833e2bf1f3a54004d7df1d57f4802d311f"}},
+	{"abc", "fc3f5d734e8dce41ddac49f47dd2b8a57257522a865c124ed02b92b5237befa4", "fe4d197ecf5a62645b9690599e1d80e82c500b22ac705a0b421fac7b47157866", []string{"c7f96eadac763e176629b09ed0c11992225b3a5ae99479760601cbd69c221e58"}},
+	{"abcdef0123456789", "f164c6674a02207e414c257ce759d35eddc7f55be6d7f415e2cc177e5d8faa84", "3aa274881d30db70485368c0467e97da0e73c18c1d00f34775d012b6fcee7f97", []string{"314e8585fa92068b3ea2c3bab452d4257b38be1c097d58a21890456c2929614d"}},
+	{"q128_" + strings.Repeat("q", 128), "324532006312be4f162614076460315f7a54a6f85544da773dc659aca0311853", "8d8197374bcd52de2acfefc8a54fe2c8d8bebd2a39f16be9b710e4b1af6ef883", []string{"752d8eaa38cd785a799a31d63d99c2ae4261823b4a367b133b2c6627f48858ab"}},
+	{"a512_" + strings.Repeat("a", 512), "5c4bad52f81f39c8e8de1260e9a06d72b8b00a0829a8ea004a610b0691bea5d9", "c801e7c0782af1f74f24fc385a8555da0582032a3ce038de637ccdcb16f7ef7b", []string{"0e1527840b9df2dfbef966678ff167140f2b27c4dccd884c25014dce0e41dfa3"}},
+}
+
+func TestExpandMessageXMD(t *testing.T) {
+	dst := "QUUX-V01-CS02-with-expander-SHA256-128"
+	long := dst + "-long-DST-" + strings.Repeat("1", 208)
+	for _, tc := range []struct {
+		dst     string
+		vectors []xmdVector
+	}{{dst, xmdSHA256}, {long, xmdSHA256LongDST}} {
+		for _, v := range tc.vectors {
+			out, err := h2c.ExpandMessageXMD(sha256.New, []byte(v.msg), []byte(tc.dst), v.n)
+			require.NoError(t, err)
+			require.Equal(t, v.uniform, hex.EncodeToString(out), v.msg)
+		}
+	}
+
+	_, err := h2c.ExpandMessageXMD(sha256.New, nil, nil, 32)
+	require.ErrorIs(t, err, h2c.ErrDST)
+	_, err = h2c.ExpandMessageXMD(sha256.New, nil, []byte(dst), 255*32+1)
+	require.Error(t, err)
+	out, err := h2c.ExpandMessageXMD(streebog.New512, []byte("abc"), []byte(dst), 255*64)
+	require.NoError(t, err)
+	require.Len(t, out, 255*64)
+}
+
+func checkSuite(t *testing.T, suite *h2c.Suite, dst string, ro, nu []curveVector) {
+	for _, tc := range []struct {
+		dst     string
+		vectors []curveVector
+		hash    func(msg, dst []byte) (ecgeneric.Point, error)
+	}{
+		{dst + "RO_", ro, suite.HashToCurve},
+		{dst + "NU_", nu, suite.EncodeToCurve},
+	} {
+		for _, v := range tc.vectors {
+			u, err := suite.HashToField([]byte(v.msg), []byte(tc.dst), len(v.u))
+			require.NoError(t, err)
+			for i := range u {
+				require.Equal(t, ecgeneric.BigFromHex(v.u[i]), u[i], v.msg)
+			}
+			p, err := tc.hash([]byte(v.msg), []byte(tc.dst))
+			require.NoError(t, err)
+			require.Equal(t, ecgeneric.BigFromHex(v.x), p.X, tc.dst+v.msg)
+			require.Equal(t, ecgeneric.BigFromHex(v.y), p.Y, tc.dst+v.msg)
+		}
+	}
+}
+
+func TestSecp256k1(t *testing.T) {
+	suite, err := h2c.NewSuite(&nist.Secp256k1, sha256.New, 128)
+	require.NoError(t, err)
+	checkSuite(t, suite, "QUUX-V01-CS02-with-secp256k1_XMD:SHA-256_SSWU_", secp256k1RO, secp256k1NU)
+}
+
+// TestP256 checks the generic simplified SWU map, including the choice of Z.
+func TestP256(t *testing.T) {
+	suite, err := h2c.NewSuite(&nist.P256, sha256.New, 128)
+	require.NoError(t, err)
+	checkSuite(t, suite, "QUUX-V01-CS02-with-P256_XMD:SHA-256_SSWU_", p256RO, p256NU)
+}
+
+func TestOtherCurves(t *testing.T) {
+	dst := []byte("h2c-test")
+	for _, curve := range []*ecgeneric.CurveParams{
+		&gost.GostEx1,
+		&gost.Gost34102001paramSetA,
+		&gost.Gost341012512paramSetA,
+		&gost.Gost341012512paramSetB,
+	} {
+		for _, newMap := range []func(*ecgeneric.CurveParams) (h2c.Mapper, error){h2c.NewSSWU, h2c.NewSvdW} {
+			m, err := newMap(curve)
+			require.NoError(t, err, curve.Name)
+			suite := &h2c.Suite{Curve: curve, Hash: gost.SDSAHash(curve), K: curve.N.BitLen() / 2, Map: m}
+			seen := map[string]bool{}
+			for _, msg := range []string{"", "abc", "abcdef0123456789"} {
+				p, err := suite.HashToCurve([]byte(msg), dst)
+				require.NoError(t, err)
+				require.True(t, curve.IsOnCurve(p.X, p.Y), curve.Name)
+				again, err := suite.HashToCurve([]byte(msg), dst)
+				require.NoError(t, err)
+				require.Equal(t, p, again)
+				other, err := suite.HashToCurve([]byte(msg), []byte("h2c-other"))
+				require.NoError(t, err)
+				require.NotEqual(t, p, other)
+
+				q, err := suite.EncodeToCurve([]byte(msg), dst)
+				require.NoError(t, err)
+				require.True(t, curve.IsOnCurve(q.X, q.Y), curve.Name)
+				seen[p.X.String()], seen[q.X.String()] = true, true
+			}
+			require.Len(t, seen, 6, curve.Name)
+		}
+	}
+}
+
+// TestSvdW maps secp256k1, where A = 0, with the generic fallback.
+func TestSvdW(t *testing.T) {
+	curve := &nist.Secp256k1
+	m, err := h2c.NewSvdW(curve)
+	require.NoError(t, err)
+	for i := int64(0); i < 64; i++ {
+		p := m.MapToCurve(big.NewInt(i))
+		require.True(t, curve.IsOnCurve(p.X, p.Y), i)
+		require.Equal(t, uint(i&1), p.Y.Bit(0), i)
+	}
+
+	suite, err := h2c.NewSuite(&nist.Secp256k1, sha256.New, 0)
+	require.NoError(t, err)
+	require.Equal(t, 128, suite.K)
+}
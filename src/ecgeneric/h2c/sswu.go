@@ -0,0 +1,151 @@
+package h2c
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/nist"
+)
+
+var errSSWUCurve = errors.New("h2c: simplified SWU needs a curve with A and B nonzero or a known isogeny")
+
+// sswu is the simplified Shallue–van de Woestijne–Ulas map of RFC 9380,
+// Section 6.6.2, to the curve y² = x³ + A·x + B with A·B ≠ 0.
+type sswu struct {
+	f    field
+	A, B *big.Int
+	Z    *big.Int
+}
+
+// NewSSWU returns the simplified SWU map to curve. Curves with A·B ≠ 0 are
+// mapped directly, with Z chosen as in RFC 9380, Appendix H.2. Secp256k1,
+// where A = 0, is reached through the 3-isogeny of Section 8.7. Other curves
+// with A = 0 or B = 0 need NewSvdW.
+func NewSSWU(curve *ecgeneric.CurveParams) (Mapper, error) {
+	if sameCurve(curve, &nist.Secp256k1) {
+		return secp256k1SSWU, nil
+	}
+	f := field{curve.P}
+	A, B := f.elem(curve.A), f.elem(curve.B)
+	if A.Sign() == 0 || B.Sign() == 0 {
+		return nil, errSSWUCurve
+	}
+	return &sswu{f: f, A: A, B: B, Z: findZSSWU(f, A, B)}, nil
+}
+
+func (m *sswu) MapToCurve(u *big.Int) ecgeneric.Point {
+	f, A, B, Z := m.f, m.A, m.B, m.Z
+	u = f.elem(u)
+	zu2 := f.mul(Z, f.mul(u, u))
+	tv1 := f.inv0(f.add(f.mul(zu2, zu2), zu2))
+	var x1 *big.Int
+	if tv1.Sign() == 0 {
+		x1 = f.mul(B, f.inv0(f.mul(Z, A)))
+	} else {
+		x1 = f.mul(f.neg(f.mul(B, f.inv0(A))), f.add(big.NewInt(1), tv1))
+	}
+	x, y := x1, (*big.Int)(nil)
+	if gx1 := f.g(A, B, x1); f.isSquare(gx1) {
+		y = f.sqrt(gx1)
+	} else {
+		x = f.mul(zu2, x1)
+		y = f.sqrt(f.g(A, B, x))
+	}
+	if sgn0(u) != sgn0(y) {
+		y = f.neg(y)
+	}
+	return ecgeneric.Point{X: x, Y: y}
+}
+
+// findZSSWU is find_z_sswu of RFC 9380, Appendix H.2.
+func findZSSWU(f field, A, B *big.Int) *big.Int {
+	minusOne := f.neg(big.NewInt(1))
+	for ctr := int64(1); ; ctr++ {
+		for _, Z := range []*big.Int{f.elem(big.NewInt(ctr)), f.elem(big.NewInt(-ctr))} {
+			if f.isSquare(Z) || Z.Cmp(minusOne) == 0 {
+				continue
+			}
+			// g(x) - Z must be irreducible; for a cubic that means rootless.
+			if f.hasRoot(poly{f.sub(B, Z), A, new(big.Int), big.NewInt(1)}) {
+				continue
+			}
+			if f.isSquare(f.g(A, B, f.mul(B, f.inv0(f.mul(Z, A))))) {
+				return Z
+			}
+		}
+	}
+}
+
+// isoSSWU maps to a curve through simplified SWU on an isogenous curve E'
+// followed by the isogeny, RFC 9380 Section 6.6.3.
+type isoSSWU struct {
+	sswu
+	xNum, xDen, yNum, yDen []*big.Int
+}
+
+func (m *isoSSWU) MapToCurve(u *big.Int) ecgeneric.Point {
+	p := m.sswu.MapToCurve(u)
+	f := m.f
+	xDen, yDen := f.eval(m.xDen, p.X), f.eval(m.yDen, p.X)
+	if xDen.Sign() == 0 || yDen.Sign() == 0 {
+		// Only the kernel of the isogeny maps here; its image is the
+		// identity.
+		return ecgeneric.Point{X: new(big.Int), Y: new(big.Int)}
+	}
+	x := f.mul(f.eval(m.xNum, p.X), f.inv0(xDen))
+	y := f.mul(p.Y, f.mul(f.eval(m.yNum, p.X), f.inv0(yDen)))
+	return ecgeneric.Point{X: x, Y: y}
+}
+
+// eval evaluates the polynomial with coefficients c, constant term first,
+// at x.
+func (f field) eval(c []*big.Int, x *big.Int) *big.Int {
+	r := new(big.Int)
+	for i := len(c) - 1; i >= 0; i-- {
+		r = f.add(f.mul(r, x), c[i])
+	}
+	return r
+}
+
+func hexList(ss ...string) []*big.Int {
+	out := make([]*big.Int, len(ss))
+	for i, s := range ss {
+		out[i] = ecgeneric.BigFromHex(s)
+	}
+	return out
+}
+
+// secp256k1SSWU is the map for secp256k1_XMD:SHA-256_SSWU_RO_ and _NU_, with
+// the constants of RFC 9380, Sections 8.7 and E.1.
+var secp256k1SSWU = &isoSSWU{
+	sswu: sswu{
+		f: field{nist.Secp256k1.P},
+		A: ecgeneric.BigFromHex("3f8731abdd661adca08a5558f0f5d272e953d363cb6f0e5d405447c01a444533"),
+		B: big.NewInt(1771),
+		Z: new(big.Int).Sub(nist.Secp256k1.P, big.NewInt(11)),
+	},
+	xNum: hexList(
+		"8e38e38e38e38e38e38e38e38e38e38e38e38e38e38e38e38e38e38daaaaa8c7",
+		"07d3d4c80bc321d5b9f315cea7fd44c5d595d2fc0bf63b92dfff1044f17c6581",
+		"534c328d23f234e6e2a413deca25caece4506144037c40314ecbd0b53d9dd262",
+		"8e38e38e38e38e38e38e38e38e38e38e38e38e38e38e38e38e38e38daaaaa88c",
+	),
+	xDen: hexList(
+		"d35771193d94918a9ca34ccbb7b640dd86cd409542f8487d9fe6b745781eb49b",
+		"edadc6f64383dc1df7c4b2d51b54225406d36b641f5e41bbc52a56612a8c6d14",
+		"01",
+	),
+	yNum: hexList(
+		"4bda12f684bda12f684bda12f684bda12f684bda12f684bda12f684b8e38e23c",
+		"c75e0c32d5cb7c0fa9d0a54b12a0a6d5647ab046d686da6fdffc90fc201d71a3",
+		"29a6194691f91a73715209ef6512e576722830a201be2018a765e85a9ecee931",
+		"2f684bda12f684bda12f684bda12f684bda12f684bda12f684bda12f38e38d84",
+	),
+	yDen: hexList(
+		"fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffff93b",
+		"7a06534bb8bdb49fd5e9e6632722c2989467c1bfc8e8d978dfb425d2685c2573",
+		"6484aa716545ca2cf3a70c3fa8fe337e0a3d21162f0d6299a7bf8192bfd2a76f",
+		"01",
+	),
+}
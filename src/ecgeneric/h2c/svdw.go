@@ -0,0 +1,82 @@
+package h2c
+
+import (
+	"math/big"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+)
+
+// svdw is the Shallue–van de Woestijne map of RFC 9380, Section 6.6.1, which
+// works for any short-Weierstrass curve.
+type svdw struct {
+	f              field
+	A, B, Z        *big.Int
+	c1, c2, c3, c4 *big.Int
+}
+
+// NewSvdW returns the Shallue–van de Woestijne map to curve, with Z chosen
+// as in RFC 9380, Appendix H.1.
+func NewSvdW(curve *ecgeneric.CurveParams) (Mapper, error) {
+	f := field{curve.P}
+	m := &svdw{f: f, A: f.elem(curve.A), B: f.elem(curve.B)}
+	m.Z = findZSvdW(f, m.A, m.B)
+	gz := f.g(m.A, m.B, m.Z)
+	t := f.add(f.mul(big.NewInt(3), f.mul(m.Z, m.Z)), f.mul(big.NewInt(4), m.A))
+	m.c1 = gz
+	m.c2 = f.neg(f.mul(m.Z, f.inv0(big.NewInt(2))))
+	m.c3 = f.sqrt(f.neg(f.mul(gz, t)))
+	if sgn0(m.c3) != 0 {
+		m.c3 = f.neg(m.c3)
+	}
+	m.c4 = f.neg(f.mul(f.mul(big.NewInt(4), gz), f.inv0(t)))
+	return m, nil
+}
+
+func (m *svdw) MapToCurve(u *big.Int) ecgeneric.Point {
+	f, A, B := m.f, m.A, m.B
+	u = f.elem(u)
+	one := big.NewInt(1)
+	tv1 := f.mul(f.mul(u, u), m.c1)
+	tv2 := f.add(one, tv1)
+	tv1 = f.sub(one, tv1)
+	tv3 := f.inv0(f.mul(tv1, tv2))
+	tv4 := f.mul(f.mul(f.mul(u, tv1), tv3), m.c3)
+
+	x := f.sub(m.c2, tv4)
+	if !f.isSquare(f.g(A, B, x)) {
+		x = f.add(m.c2, tv4)
+		if !f.isSquare(f.g(A, B, x)) {
+			x = f.mul(tv2, tv2)
+			x = f.mul(x, tv3)
+			x = f.mul(x, x)
+			x = f.add(f.mul(x, m.c4), m.Z)
+		}
+	}
+	y := f.sqrt(f.g(A, B, x))
+	if sgn0(u) != sgn0(y) {
+		y = f.neg(y)
+	}
+	return ecgeneric.Point{X: x, Y: y}
+}
+
+// findZSvdW is find_z_svdw of RFC 9380, Appendix H.1.
+func findZSvdW(f field, A, B *big.Int) *big.Int {
+	h := func(Z *big.Int) *big.Int {
+		num := f.add(f.mul(big.NewInt(3), f.mul(Z, Z)), f.mul(big.NewInt(4), A))
+		return f.neg(f.mul(num, f.inv0(f.mul(big.NewInt(4), f.g(A, B, Z)))))
+	}
+	for ctr := int64(1); ; ctr++ {
+		for _, Z := range []*big.Int{f.elem(big.NewInt(ctr)), f.elem(big.NewInt(-ctr))} {
+			gz := f.g(A, B, Z)
+			if gz.Sign() == 0 {
+				continue
+			}
+			if hz := h(Z); hz.Sign() == 0 || !f.isSquare(hz) {
+				continue
+			}
+			if f.isSquare(gz) || f.isSquare(f.g(A, B, f.neg(f.mul(Z, f.inv0(big.NewInt(2)))))) {
+				return Z
+			}
+		}
+	}
+}
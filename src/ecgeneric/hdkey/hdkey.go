@@ -0,0 +1,416 @@
+// Package hdkey implements BIP-32 hierarchical deterministic keys on
+// secp256k1, generalized in the style of SLIP-10 to any ecgeneric curve.
+//
+// A Params value fixes the curve, the HMAC hash, the HMAC key used for the
+// master node and the serialization version bytes. Bitcoin is plain BIP-32.
+// The GOST parameter sets derive with HMAC-Streebog-512; on the 512-bit
+// curves the chain code is 64 bytes and the HMAC output is stretched to
+// 128 bytes by running HMAC in counter mode.
+package hdkey
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/gost"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/nist"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/streebog"
+	"golang.org/x/crypto/ripemd160"
+)
+
+// HardenedOffset is the first hardened child index.
+const HardenedOffset uint32 = 0x80000000
+
+var (
+	ErrHardenedPublic = errors.New("hdkey: cannot derive a hardened child from a public key")
+	ErrDepth          = errors.New("hdkey: maximum depth exceeded")
+	ErrSeedLength     = errors.New("hdkey: seed must be between 16 and 64 bytes")
+	ErrInvalidKey     = errors.New("hdkey: invalid serialized key")
+	ErrInvalidPath    = errors.New("hdkey: invalid derivation path")
+)
+
+// Params selects the curve and the hashing and serialization conventions of
+// a key tree.
+type Params struct {
+	Curve *ecgeneric.CurveParams
+	// Hash is the hash function of the derivation HMAC.
+	Hash func() hash.Hash
+	// SeedKey is the HMAC key used to derive the master node from a seed.
+	SeedKey []byte
+	// PrivateVersion and PublicVersion prefix the serialized keys.
+	PrivateVersion, PublicVersion [4]byte
+}
+
+// Bitcoin is BIP-32 on secp256k1, serializing to xprv/xpub.
+var Bitcoin = &Params{
+	Curve:          &nist.Secp256k1,
+	Hash:           sha512.New,
+	SeedKey:        []byte("Bitcoin seed"),
+	PrivateVersion: [4]byte{0x04, 0x88, 0xad, 0xe4},
+	PublicVersion:  [4]byte{0x04, 0x88, 0xb2, 0x1e},
+}
+
+// The GOST parameter sets use HMAC-Streebog-512 and "<curve name> seed" as
+// the master key. Their version bytes are not registered anywhere; they are
+// only chosen so that keys of different curves cannot be confused.
+var (
+	Gost34102001paramSetA = &Params{
+		Curve:          &gost.Gost34102001paramSetA,
+		Hash:           streebog.New512,
+		SeedKey:        []byte(gost.Gost34102001paramSetA.Name + " seed"),
+		PrivateVersion: [4]byte{0x04, 0x4a, 0x50, 0x11},
+		PublicVersion:  [4]byte{0x04, 0x4a, 0x50, 0x12},
+	}
+	Gost341012512paramSetA = &Params{
+		Curve:          &gost.Gost341012512paramSetA,
+		Hash:           streebog.New512,
+		SeedKey:        []byte(gost.Gost341012512paramSetA.Name + " seed"),
+		PrivateVersion: [4]byte{0x04, 0x4a, 0x51, 0x21},
+		PublicVersion:  [4]byte{0x04, 0x4a, 0x51, 0x22},
+	}
+	Gost341012512paramSetB = &Params{
+		Curve:          &gost.Gost341012512paramSetB,
+		Hash:           streebog.New512,
+		SeedKey:        []byte(gost.Gost341012512paramSetB.Name + " seed"),
+		PrivateVersion: [4]byte{0x04, 0x4a, 0x51, 0x31},
+		PublicVersion:  [4]byte{0x04, 0x4a, 0x51, 0x32},
+	}
+)
+
+var knownParams = []*Params{Bitcoin, Gost34102001paramSetA, Gost341012512paramSetA, Gost341012512paramSetB}
+
+// ParamsForCurve returns the predefined Params for curve if there are any.
+// Otherwise it returns SLIP-10 style parameters using HMAC-SHA512 with
+// "<curve name> seed" as the master key and no version bytes.
+func ParamsForCurve(curve *ecgeneric.CurveParams) *Params {
+	for _, p := range knownParams {
+		if p.Curve == curve {
+			return p
+		}
+	}
+	return &Params{
+		Curve:   curve,
+		Hash:    sha512.New,
+		SeedKey: []byte(curve.Name + " seed"),
+	}
+}
+
+// keyLen is the length of a serialized scalar and of the chain code.
+func (p *Params) keyLen() int {
+	return (p.Curve.N.BitLen() + 7) / 8
+}
+
+func (p *Params) pointLen() int {
+	return 1 + (p.Curve.P.BitLen()+7)/8
+}
+
+// hmacExpand returns n bytes of HMAC output. If the hash is long enough this
+// is just HMAC(key, data), as BIP-32 requires; otherwise the blocks
+// HMAC(key, data || 1), HMAC(key, data || 2), ... are concatenated.
+func (p *Params) hmacExpand(key, data []byte, n int) []byte {
+	mac := hmac.New(p.Hash, key)
+	if mac.Size() >= n {
+		mac.Write(data)
+		return mac.Sum(nil)[:n]
+	}
+	var out []byte
+	for ctr := byte(1); len(out) < n; ctr++ {
+		mac.Reset()
+		mac.Write(data)
+		mac.Write([]byte{ctr})
+		out = mac.Sum(out)
+	}
+	return out[:n]
+}
+
+// ExtendedKey is a node of the key tree, holding either a private key or only
+// the public key.
+type ExtendedKey struct {
+	Params            *Params
+	Depth             uint8
+	ParentFingerprint [4]byte
+	ChildNumber       uint32
+	ChainCode         []byte
+
+	d    *big.Int // nil for public nodes
+	x, y *big.Int
+}
+
+// NewMaster derives the master node from a seed of 16 to 64 bytes. If the
+// left half of I = HMAC(SeedKey, seed) is not a valid scalar, SLIP-10 has I
+// re-hashed as I = HMAC(SeedKey, I) until it is.
+func NewMaster(seed []byte, params *Params) (*ExtendedKey, error) {
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, ErrSeedLength
+	}
+	l := params.keyLen()
+	I := params.hmacExpand(params.SeedKey, seed, 2*l)
+	for {
+		d := new(big.Int).SetBytes(I[:l])
+		if d.Sign() != 0 && d.Cmp(params.Curve.N) < 0 {
+			x, y := params.Curve.ScalarBaseMultJ(d.Bytes())
+			return &ExtendedKey{
+				Params:    params,
+				ChainCode: I[l:],
+				d:         d,
+				x:         x,
+				y:         y,
+			}, nil
+		}
+		I = params.hmacExpand(params.SeedKey, I, 2*l)
+	}
+}
+
+// IsPrivate reports whether k holds a private key.
+func (k *ExtendedKey) IsPrivate() bool {
+	return k.d != nil
+}
+
+// PrivateKey returns the node's private key.
+func (k *ExtendedKey) PrivateKey() (*ecgeneric.PrivateKey, error) {
+	if k.d == nil {
+		return nil, errors.New("hdkey: public node has no private key")
+	}
+	priv := new(ecgeneric.PrivateKey)
+	priv.PublicKey = *k.PublicKey()
+	priv.D = new(big.Int).Set(k.d)
+	return priv, nil
+}
+
+// PublicKey returns the node's public key.
+func (k *ExtendedKey) PublicKey() *ecgeneric.PublicKey {
+	return &ecgeneric.PublicKey{Curve: k.Params.Curve, X: new(big.Int).Set(k.x), Y: new(big.Int).Set(k.y)}
+}
+
+// Neuter returns the public node corresponding to k.
+func (k *ExtendedKey) Neuter() *ExtendedKey {
+	n := *k
+	n.d = nil
+	return &n
+}
+
+// Fingerprint returns the first four bytes of HASH160 of the compressed
+// public key.
+func (k *ExtendedKey) Fingerprint() [4]byte {
+	sha := sha256.Sum256(ecgeneric.MarshalCompressed(k.Params.Curve, k.x, k.y))
+	r := ripemd160.New()
+	r.Write(sha[:])
+	var fp [4]byte
+	copy(fp[:], r.Sum(nil))
+	return fp
+}
+
+// Child derives the child node with index i; indexes from HardenedOffset on
+// are hardened.
+//
+// Where BIP-32 gives up on an index whose I_L is not below the group order,
+// the derivation is retried as in SLIP-10 with I = HMAC(c, 0x01 || I_R ||
+// ser32(i)). On secp256k1 this never happens in practice, so the results are
+// those of BIP-32, but on Gost341012512paramSetB, whose order is close to
+// 2^511, half of all indexes would otherwise be unusable.
+func (k *ExtendedKey) Child(i uint32) (*ExtendedKey, error) {
+	if k.Depth == 0xff {
+		return nil, ErrDepth
+	}
+	curve := k.Params.Curve
+	l := k.Params.keyLen()
+
+	var data []byte
+	if i >= HardenedOffset {
+		if k.d == nil {
+			return nil, ErrHardenedPublic
+		}
+		data = make([]byte, 1+l)
+		k.d.FillBytes(data[1:])
+	} else {
+		data = ecgeneric.MarshalCompressed(curve, k.x, k.y)
+	}
+	data = appendUint32(data, i)
+
+	child := &ExtendedKey{
+		Params:            k.Params,
+		Depth:             k.Depth + 1,
+		ParentFingerprint: k.Fingerprint(),
+		ChildNumber:       i,
+	}
+	I := k.Params.hmacExpand(k.ChainCode, data, 2*l)
+	for {
+		il := new(big.Int).SetBytes(I[:l])
+		if il.Cmp(curve.N) < 0 {
+			if k.d != nil {
+				d := il.Add(il, k.d)
+				d.Mod(d, curve.N)
+				if d.Sign() != 0 {
+					child.d = d
+					child.x, child.y = curve.ScalarBaseMultJ(d.Bytes())
+					break
+				}
+			} else {
+				ilx, ily := curve.ScalarBaseMultJ(il.Bytes())
+				child.x, child.y = curve.AddJ(ilx, ily, k.x, k.y)
+				if child.x.Sign() != 0 || child.y.Sign() != 0 {
+					break
+				}
+			}
+		}
+		retry := append([]byte{0x01}, I[l:]...)
+		I = k.Params.hmacExpand(k.ChainCode, appendUint32(retry, i), 2*l)
+	}
+	child.ChainCode = I[l:]
+	return child, nil
+}
+
+// Derive follows a path such as "m/44'/60'/0'/0/0" from k, which must be the
+// master node when the path starts with "m".
+func (k *ExtendedKey) Derive(path string) (*ExtendedKey, error) {
+	indexes, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(path, "m") && k.Depth != 0 {
+		return nil, fmt.Errorf("hdkey: absolute path %q applied to a non-master key", path)
+	}
+	for _, i := range indexes {
+		if k, err = k.Child(i); err != nil {
+			return nil, err
+		}
+	}
+	return k, nil
+}
+
+// ParsePath parses a BIP-32 path. Hardened components are marked with ', h
+// or H; the leading "m/" is optional.
+func ParsePath(path string) ([]uint32, error) {
+	path = strings.TrimSpace(path)
+	if path == "m" || path == "" {
+		return nil, nil
+	}
+	path = strings.TrimPrefix(path, "m/")
+	var out []uint32
+	for _, part := range strings.Split(path, "/") {
+		hardened := false
+		if n := len(part); n > 0 && (part[n-1] == '\'' || part[n-1] == 'h' || part[n-1] == 'H') {
+			hardened = true
+			part = part[:n-1]
+		}
+		i, err := strconv.ParseUint(part, 10, 32)
+		if err != nil || uint32(i) >= HardenedOffset {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidPath, path)
+		}
+		if hardened {
+			i += uint64(HardenedOffset)
+		}
+		out = append(out, uint32(i))
+	}
+	return out, nil
+}
+
+// String returns the Base58Check serialization of k.
+func (k *ExtendedKey) String() string {
+	l := k.Params.keyLen()
+	b := make([]byte, 0, 13+l+k.Params.pointLen()+4)
+	if k.d != nil {
+		b = append(b, k.Params.PrivateVersion[:]...)
+	} else {
+		b = append(b, k.Params.PublicVersion[:]...)
+	}
+	b = append(b, k.Depth)
+	b = append(b, k.ParentFingerprint[:]...)
+	b = appendUint32(b, k.ChildNumber)
+	b = append(b, k.ChainCode...)
+	if k.d != nil {
+		key := make([]byte, 1+l)
+		k.d.FillBytes(key[1:])
+		b = append(b, key...)
+	} else {
+		b = append(b, ecgeneric.MarshalCompressed(k.Params.Curve, k.x, k.y)...)
+	}
+	return base58CheckEncode(b)
+}
+
+// Parse decodes a key serialized by String. The version bytes select one of
+// params, or, if none are given, one of Bitcoin, Gost34102001paramSetA,
+// Gost341012512paramSetA or Gost341012512paramSetB.
+func Parse(s string, params ...*Params) (*ExtendedKey, error) {
+	b, err := base58CheckDecode(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < 4 {
+		return nil, ErrInvalidKey
+	}
+	if len(params) == 0 {
+		params = knownParams
+	}
+	var version [4]byte
+	copy(version[:], b)
+	for _, p := range params {
+		if version != p.PrivateVersion && version != p.PublicVersion {
+			continue
+		}
+		private := version == p.PrivateVersion
+		if p.PrivateVersion == p.PublicVersion {
+			// Without version bytes only the zero byte in front of a
+			// private key, as in BIP-32, tells the two apart.
+			l := 13 + p.keyLen()
+			private = len(b) > l && b[l] == 0
+		}
+		return parse(b, p, private)
+	}
+	return nil, ErrInvalidKey
+}
+
+func parse(b []byte, p *Params, private bool) (*ExtendedKey, error) {
+	l := p.keyLen()
+	keyLen := p.pointLen()
+	if private {
+		keyLen = 1 + l
+	}
+	if len(b) != 13+l+keyLen {
+		return nil, ErrInvalidKey
+	}
+	k := &ExtendedKey{
+		Params:      p,
+		Depth:       b[4],
+		ChildNumber: binary.BigEndian.Uint32(b[9:13]),
+		ChainCode:   append([]byte{}, b[13:13+l]...),
+	}
+	copy(k.ParentFingerprint[:], b[5:9])
+	if k.Depth == 0 && (k.ChildNumber != 0 || k.ParentFingerprint != [4]byte{}) {
+		return nil, ErrInvalidKey
+	}
+
+	key := b[13+l:]
+	if private {
+		if key[0] != 0 {
+			return nil, ErrInvalidKey
+		}
+		d := new(big.Int).SetBytes(key[1:])
+		if d.Sign() == 0 || d.Cmp(p.Curve.N) >= 0 {
+			return nil, ErrInvalidKey
+		}
+		k.d = d
+		k.x, k.y = p.Curve.ScalarBaseMultJ(d.Bytes())
+	} else {
+		k.x, k.y = ecgeneric.UnmarshalCompressed(p.Curve, key)
+		if k.x == nil {
+			return nil, ErrInvalidKey
+		}
+	}
+	return k, nil
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	var buf [4]byte
+	binary.BigEndian.PutUint32(buf[:], v)
+	return append(b, buf[:]...)
+}
@@ -0,0 +1,99 @@
+package hdkey_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/gost"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/hdkey"
+	"github.com/stretchr/testify/require"
+)
+
+// BIP-32 test vector 1.
+func TestBIP32Vector1(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	master, err := hdkey.NewMaster(seed, hdkey.Bitcoin)
+	require.NoError(t, err)
+
+	vectors := []struct {
+		path, xprv, xpub string
+	}{
+		{"m",
+			"xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi",
+			"xpub661MyMwAqRbcFtXgS5sYJABqqG9YLmC4Q1Rdap9gSE8NqtwybGhePY2gZ29ESFjqJoCu1Rupje8YtGqsefD265TMg7usUDFdp6W1EGMcet8"},
+		{"m/0H",
+			"xprv9uHRZZhk6KAJC1avXpDAp4MDc3sQKNxDiPvvkX8Br5ngLNv1TxvUxt4cV1rGL5hj6KCesnDYUhd7oWgT11eZG7XnxHrnYeSvkzY7d2bhkJ7",
+			"xpub68Gmy5EdvgibQVfPdqkBBCHxA5htiqg55crXYuXoQRKfDBFA1WEjWgP6LHhwBZeNK1VTsfTFUHCdrfp1bgwQ9xv5ski8PX9rL2dZXvgGDnw"},
+		{"m/0H/1",
+			"xprv9wTYmMFdV23N2TdNG573QoEsfRrWKQgWeibmLntzniatZvR9BmLnvSxqu53Kw1UmYPxLgboyZQaXwTCg8MSY3H2EU4pWcQDnRnrVA1xe8fs",
+			"xpub6ASuArnXKPbfEwhqN6e3mwBcDTgzisQN1wXN9BJcM47sSikHjJf3UFHKkNAWbWMiGj7Wf5uMash7SyYq527Hqck2AxYysAA7xmALppuCkwQ"},
+	}
+	for _, v := range vectors {
+		k, err := master.Derive(v.path)
+		require.NoError(t, err)
+		require.Equal(t, v.xprv, k.String(), v.path)
+		require.Equal(t, v.xpub, k.Neuter().String(), v.path)
+
+		parsed, err := hdkey.Parse(v.xprv)
+		require.NoError(t, err)
+		require.Equal(t, v.xprv, parsed.String())
+		parsed, err = hdkey.Parse(v.xpub)
+		require.NoError(t, err)
+		require.False(t, parsed.IsPrivate())
+		require.Equal(t, v.xpub, parsed.String())
+	}
+}
+
+func TestGostDerivation(t *testing.T) {
+	seed, _ := hex.DecodeString("fffcf9f6f3f0edeae7e4e1dedbd8d5d2cfccc9c6c3c0bdbab7b4b1aeaba8a5a29f9c999693908d8a8784817e7b7875726f6c696663605d5a5754514e4b484542")
+	for _, params := range []*hdkey.Params{hdkey.Gost34102001paramSetA, hdkey.Gost341012512paramSetA, hdkey.Gost341012512paramSetB} {
+		master, err := hdkey.NewMaster(seed, params)
+		require.NoError(t, err)
+
+		account, err := master.Derive("m/44'/0'")
+		require.NoError(t, err)
+		child, err := account.Derive("1/2")
+		require.NoError(t, err)
+
+		// Non-hardened children can be derived from the public key alone.
+		pubChild, err := account.Neuter().Derive("1/2")
+		require.NoError(t, err)
+		require.Equal(t, child.Neuter().String(), pubChild.String())
+		require.Equal(t, child.PublicKey().X, pubChild.PublicKey().X)
+
+		_, err = account.Neuter().Child(hdkey.HardenedOffset)
+		require.ErrorIs(t, err, hdkey.ErrHardenedPublic)
+
+		parsed, err := hdkey.Parse(child.String())
+		require.NoError(t, err)
+		require.Equal(t, child.String(), parsed.String())
+		require.Len(t, parsed.ChainCode, (params.Curve.N.BitLen()+7)/8)
+	}
+}
+
+// GostEx2 has a BitSize of 256 but a 511-bit prime, and public keys are
+// serialized at the width of the prime.
+func TestFieldWidth(t *testing.T) {
+	params := hdkey.ParamsForCurve(&gost.GostEx2)
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	master, err := hdkey.NewMaster(seed, params)
+	require.NoError(t, err)
+	for _, k := range []*hdkey.ExtendedKey{master, master.Neuter()} {
+		parsed, err := hdkey.Parse(k.String(), params)
+		require.NoError(t, err)
+		require.Equal(t, k.String(), parsed.String())
+		require.Equal(t, k.PublicKey().X, parsed.PublicKey().X)
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	p, err := hdkey.ParsePath("m/44'/60h/0H/0/7")
+	require.NoError(t, err)
+	h := hdkey.HardenedOffset
+	require.Equal(t, []uint32{44 + h, 60 + h, h, 0, 7}, p)
+
+	for _, bad := range []string{"m/", "m/x", "m/2147483648", "m/1//2"} {
+		_, err := hdkey.ParsePath(bad)
+		require.ErrorIs(t, err, hdkey.ErrInvalidPath, bad)
+	}
+}
@@ -0,0 +1,45 @@
+package ecgeneric_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/gost"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/nist"
+	"github.com/stretchr/testify/require"
+)
+
+// affineDouble doubles (x, y) with λ = (3x² + a) / 2y.
+func affineDouble(curve *ecgeneric.CurveParams, x, y *big.Int) (*big.Int, *big.Int) {
+	l := new(big.Int).Mul(x, x)
+	l.Mul(l, big.NewInt(3))
+	l.Add(l, curve.A)
+	l.Mul(l, new(big.Int).ModInverse(new(big.Int).Lsh(y, 1), curve.P))
+	l.Mod(l, curve.P)
+	x3 := new(big.Int).Mul(l, l)
+	x3.Sub(x3, new(big.Int).Lsh(x, 1))
+	x3.Mod(x3, curve.P)
+	y3 := new(big.Int).Sub(x, x3)
+	y3.Mul(y3, l)
+	y3.Sub(y3, y)
+	y3.Mod(y3, curve.P)
+	return x3, y3
+}
+
+func TestDoubleJ(t *testing.T) {
+	// secp256k1, GostEx1 and paramSetB have a ≠ -3; CryptoPro-A takes the
+	// dbl-2001-b path.
+	curves := []*ecgeneric.CurveParams{&nist.Secp256k1, &gost.GostEx1, &gost.Gost341012512paramSetB, &gost.Gost34102001paramSetA}
+	for _, curve := range curves {
+		x, y := curve.Gx, curve.Gy
+		for i := 0; i < 16; i++ {
+			wantX, wantY := affineDouble(curve, x, y)
+			gotX, gotY := curve.DoubleJ(x, y)
+			require.Equal(t, wantX, gotX, "%s 2^%d·G", curve.Name, i+1)
+			require.Equal(t, wantY, gotY, "%s 2^%d·G", curve.Name, i+1)
+			require.True(t, curve.IsOnCurve(gotX, gotY))
+			x, y = wantX, wantY
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package keybackend
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+)
+
+// Client is a connection to a Token. Its methods may be called from
+// several goroutines; requests are sent one at a time.
+type Client struct {
+	mu   sync.Mutex
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// Dial connects to the token listening at address, as net.Dial does;
+// network is typically "unix" or "tcp".
+func Dial(network, address string) (*Client, error) {
+	conn, err := net.Dial(network, address)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(conn), nil
+}
+
+// NewClient returns a client talking to a token over conn.
+func NewClient(conn net.Conn) *Client {
+	return &Client{conn: conn, r: bufio.NewReader(conn)}
+}
+
+// Close closes the connection. Keys obtained from the client stop working.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Login unlocks signing on this connection.
+func (c *Client) Login(pin string) error {
+	if pin == "" || strings.ContainsAny(pin, " \r\n") {
+		return ErrPIN
+	}
+	_, err := c.call("LOGIN", pin)
+	return err
+}
+
+// List returns the ids of the keys on the token in sorted order.
+func (c *Client) List() ([]string, error) {
+	return c.call("LIST")
+}
+
+// Key returns the key with the given id.
+func (c *Client) Key(id string) (*RemoteKey, error) {
+	if id == "" || strings.ContainsAny(id, " \r\n") {
+		return nil, errNoKey
+	}
+	res, err := c.call("PUB", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(res) != 3 {
+		return nil, errResponse
+	}
+	m, err := ParseMechanism(res[0])
+	if err != nil {
+		return nil, err
+	}
+	curve, ok := ecgeneric.CurveByName(res[1])
+	if !ok {
+		return nil, fmt.Errorf("keybackend: unknown curve %q", res[1])
+	}
+	enc, err := hex.DecodeString(res[2])
+	if err != nil {
+		return nil, errResponse
+	}
+	x, y := ecgeneric.Unmarshal(curve, enc)
+	if x == nil {
+		return nil, errResponse
+	}
+	pub := &ecgeneric.PublicKey{Curve: curve, X: x, Y: y}
+	return &RemoteKey{c: c, id: id, mech: m, pub: pub}, nil
+}
+
+var errResponse = errors.New("keybackend: malformed response from token")
+
+func (c *Client) call(req ...string) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := c.conn.Write([]byte(strings.Join(req, " ") + "\n")); err != nil {
+		return nil, err
+	}
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	res := strings.Split(strings.TrimSuffix(line, "\n"), " ")
+	switch res[0] {
+	case "OK":
+		return res[1:], nil
+	case "ERR":
+		msg := strings.Join(res[1:], " ")
+		for _, err := range tokenErrors {
+			if msg == err.Error() {
+				return nil, err
+			}
+		}
+		return nil, fmt.Errorf("keybackend: token: %s", strings.TrimPrefix(msg, "keybackend: "))
+	}
+	return nil, errResponse
+}
+
+// RemoteKey is a KeyBackend on a Token, reached through a Client.
+type RemoteKey struct {
+	c    *Client
+	id   string
+	mech Mechanism
+	pub  *ecgeneric.PublicKey
+}
+
+// ID returns the id of the key on the token.
+func (k *RemoteKey) ID() string { return k.id }
+
+func (k *RemoteKey) Public() *ecgeneric.PublicKey { return publicCopy(k.pub) }
+
+func (k *RemoteKey) Mechanism() Mechanism { return k.mech }
+
+// Sign has the token sign digest. The signature is checked against the
+// public key before it is returned.
+func (k *RemoteKey) Sign(digest []byte) (r, s *big.Int, err error) {
+	if len(digest) == 0 {
+		return nil, nil, errDigest
+	}
+	res, err := k.c.call("SIGN", k.id, hex.EncodeToString(digest))
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(res) != 2 {
+		return nil, nil, errResponse
+	}
+	r, ok1 := new(big.Int).SetString(res[0], 16)
+	s, ok2 := new(big.Int).SetString(res[1], 16)
+	if !ok1 || !ok2 || !Verify(k.pub, k.mech, digest, r, s) {
+		return nil, nil, errResponse
+	}
+	return r, s, nil
+}
@@ -0,0 +1,133 @@
+// Package keybackend signs with private keys that are never handed to the
+// caller, in the manner of a PKCS#11 token.
+//
+// A KeyBackend holds one key and exposes its public half and a Sign
+// operation over a digest; there is no way to read the private scalar back.
+// Three backends are provided:
+//
+//   - Memory keeps the key in process memory.
+//   - Keystore decrypts a file written by the keystore package and can be
+//     locked again, dropping the key.
+//   - RemoteKey forwards signing to a Token, a software token that serves
+//     any backends over a simple line protocol on a socket, so that the key
+//     lives in another process. cmd/softtoken runs one over a keystore
+//     directory.
+//
+// Each backend signs with a fixed Mechanism, GOST R 34.10 or ECDSA, and the
+// signatures are those of gost.SignJ and gost.SignSTD: they check with
+// gost.VerifyJ and with gost.VerifySTD or nist.VerifyJ respectively, or with
+// Verify.
+package keybackend
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/gost"
+)
+
+// Mechanism selects the signature scheme of a key, as CKM_GOSTR3410 and
+// CKM_ECDSA do in PKCS#11.
+type Mechanism int
+
+const (
+	// GOST is GOST R 34.10-2012, s = rd + ke.
+	GOST Mechanism = iota
+	// ECDSA is SEC 1 ECDSA, s = k⁻¹(e + rd).
+	ECDSA
+)
+
+var mechanismNames = [...]string{GOST: "gost", ECDSA: "ecdsa"}
+
+func (m Mechanism) String() string {
+	if m < 0 || int(m) >= len(mechanismNames) {
+		return fmt.Sprintf("Mechanism(%d)", int(m))
+	}
+	return mechanismNames[m]
+}
+
+// ParseMechanism returns the mechanism named by s, as printed by String.
+func ParseMechanism(s string) (Mechanism, error) {
+	for m, name := range mechanismNames {
+		if s == name {
+			return Mechanism(m), nil
+		}
+	}
+	return 0, fmt.Errorf("keybackend: unknown mechanism %q", s)
+}
+
+// KeyBackend is a private key that can sign but not be exported.
+type KeyBackend interface {
+	// Public returns the public key. The caller may modify the result.
+	Public() *ecgeneric.PublicKey
+	// Mechanism returns the signature scheme used by Sign.
+	Mechanism() Mechanism
+	// Sign signs a message digest. The digest is used as is, so it should
+	// come from a hash of the size of the curve order.
+	Sign(digest []byte) (r, s *big.Int, err error)
+}
+
+var (
+	// ErrLocked is returned by a backend whose key is not available.
+	ErrLocked = errors.New("keybackend: key is locked")
+
+	errKey    = errors.New("keybackend: invalid private key")
+	errDigest = errors.New("keybackend: empty digest")
+)
+
+// Verify reports whether (r, s) is a valid signature of digest under pub
+// with mechanism m.
+func Verify(pub *ecgeneric.PublicKey, m Mechanism, digest []byte, r, s *big.Int) bool {
+	N := pub.Params().N
+	if r.Sign() <= 0 || s.Sign() <= 0 || r.Cmp(N) >= 0 || s.Cmp(N) >= 0 {
+		return false
+	}
+	switch m {
+	case GOST:
+		ok, err := gost.VerifyJ(digest, r, s, pub.X, pub.Y, pub.Params())
+		return ok && err == nil
+	case ECDSA:
+		return gost.VerifySTD(pub, digest, r, s)
+	}
+	return false
+}
+
+// checkKey returns a copy of priv, with the public key recomputed from D
+// when it is missing, after checking that the two agree.
+func checkKey(priv *ecgeneric.PrivateKey) (*ecgeneric.PrivateKey, error) {
+	if priv == nil || priv.Curve == nil || priv.D == nil {
+		return nil, errKey
+	}
+	curve := priv.Params()
+	if priv.D.Sign() <= 0 || priv.D.Cmp(curve.N) >= 0 {
+		return nil, errKey
+	}
+	x, y := curve.ScalarBaseMultJ(priv.D.Bytes())
+	if priv.X != nil && (priv.X.Cmp(x) != 0 || priv.Y == nil || priv.Y.Cmp(y) != 0) {
+		return nil, errKey
+	}
+	return &ecgeneric.PrivateKey{
+		PublicKey: ecgeneric.PublicKey{Curve: priv.Curve, X: x, Y: y},
+		D:         new(big.Int).Set(priv.D),
+	}, nil
+}
+
+func publicCopy(pub *ecgeneric.PublicKey) *ecgeneric.PublicKey {
+	return &ecgeneric.PublicKey{Curve: pub.Curve, X: new(big.Int).Set(pub.X), Y: new(big.Int).Set(pub.Y)}
+}
+
+func sign(priv *ecgeneric.PrivateKey, m Mechanism, digest []byte) (r, s *big.Int, err error) {
+	if len(digest) == 0 {
+		return nil, nil, errDigest
+	}
+	switch m {
+	case GOST:
+		return gost.SignJ(priv.D, digest, priv.Params(), rand.Reader)
+	case ECDSA:
+		return gost.SignSTD(rand.Reader, priv, digest)
+	}
+	return nil, nil, fmt.Errorf("keybackend: unknown mechanism %v", m)
+}
@@ -0,0 +1,292 @@
+package keybackend_test
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+	"net"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/gost"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/keybackend"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/keystore"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/nist"
+	"github.com/stretchr/testify/require"
+)
+
+var light = &keystore.Options{ScryptN: keystore.LightScryptN, ScryptP: 1}
+
+func digest(msg string) []byte {
+	h := sha256.Sum256([]byte(msg))
+	return h[:]
+}
+
+func checkBackend(t *testing.T, k keybackend.KeyBackend) {
+	pub := k.Public()
+	d := digest("message")
+	r, s, err := k.Sign(d)
+	require.NoError(t, err)
+	require.True(t, keybackend.Verify(pub, k.Mechanism(), d, r, s))
+	require.False(t, keybackend.Verify(pub, k.Mechanism(), digest("other"), r, s))
+
+	switch k.Mechanism() {
+	case keybackend.GOST:
+		ok, err := gost.VerifyJ(d, r, s, pub.X, pub.Y, pub.Params())
+		require.NoError(t, err)
+		require.True(t, ok)
+	case keybackend.ECDSA:
+		ok, err := nist.VerifyJ(d, r, s, pub.X, pub.Y, pub.Params())
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+
+	// The public key handed out is a copy.
+	pub.X.SetInt64(1)
+	require.NotEqual(t, pub.X, k.Public().X)
+
+	_, _, err = k.Sign(nil)
+	require.Error(t, err)
+}
+
+func TestMemory(t *testing.T) {
+	cases := []struct {
+		curve *ecgeneric.CurveParams
+		mech  keybackend.Mechanism
+	}{
+		{&gost.Gost34102001paramSetA, keybackend.GOST},
+		{&gost.Gost341012512paramSetA, keybackend.GOST},
+		{&nist.Secp256k1, keybackend.ECDSA},
+		{&nist.P256, keybackend.ECDSA},
+		{&gost.Gost34102001paramSetA, keybackend.ECDSA},
+	}
+	for _, c := range cases {
+		priv, err := ecgeneric.GenerateKey(c.curve, rand.Reader)
+		require.NoError(t, err)
+		k, err := keybackend.NewMemory(priv, c.mech)
+		require.NoError(t, err)
+		require.Equal(t, priv.X, k.Public().X)
+		checkBackend(t, k)
+
+		// Changing the caller's key does not change the backend's.
+		priv.D.Add(priv.D, big.NewInt(1))
+		checkBackend(t, k)
+	}
+}
+
+func TestMemoryInvalidKey(t *testing.T) {
+	curve := &gost.Gost34102001paramSetA
+	priv, err := ecgeneric.GenerateKey(curve, rand.Reader)
+	require.NoError(t, err)
+
+	bad := *priv
+	bad.X = new(big.Int).Add(priv.X, big.NewInt(1))
+	_, err = keybackend.NewMemory(&bad, keybackend.GOST)
+	require.Error(t, err)
+
+	bad = *priv
+	bad.D = new(big.Int).Set(curve.N)
+	_, err = keybackend.NewMemory(&bad, keybackend.GOST)
+	require.Error(t, err)
+
+	// The public key is filled in when it is missing.
+	bare := &ecgeneric.PrivateKey{PublicKey: ecgeneric.PublicKey{Curve: curve}, D: priv.D}
+	k, err := keybackend.NewMemory(bare, keybackend.GOST)
+	require.NoError(t, err)
+	require.Equal(t, priv.Y, k.Public().Y)
+}
+
+func TestMechanism(t *testing.T) {
+	for _, m := range []keybackend.Mechanism{keybackend.GOST, keybackend.ECDSA} {
+		got, err := keybackend.ParseMechanism(m.String())
+		require.NoError(t, err)
+		require.Equal(t, m, got)
+	}
+	_, err := keybackend.ParseMechanism("rsa")
+	require.Error(t, err)
+}
+
+func TestKeystore(t *testing.T) {
+	store, err := keystore.Open(t.TempDir(), light)
+	require.NoError(t, err)
+	priv, err := ecgeneric.GenerateKey(&gost.Gost341012512paramSetA, rand.Reader)
+	require.NoError(t, err)
+	e, err := store.Save(rand.Reader, priv, "secret")
+	require.NoError(t, err)
+
+	_, err = keybackend.OpenKeystore(e.Path, "wrong", keybackend.GOST)
+	require.ErrorIs(t, err, keystore.ErrDecrypt)
+
+	k, err := keybackend.OpenKeystore(e.Path, "secret", keybackend.GOST)
+	require.NoError(t, err)
+	require.Equal(t, priv.X, k.Public().X)
+	checkBackend(t, k)
+
+	k.Lock()
+	_, _, err = k.Sign(digest("message"))
+	require.ErrorIs(t, err, keybackend.ErrLocked)
+	require.Equal(t, priv.X, k.Public().X)
+
+	require.ErrorIs(t, k.Unlock("wrong"), keystore.ErrDecrypt)
+	require.NoError(t, k.Unlock("secret"))
+	checkBackend(t, k)
+}
+
+func serve(t *testing.T, token *keybackend.Token) string {
+	addr := filepath.Join(t.TempDir(), "token.sock")
+	l, err := net.Listen("unix", addr)
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+	go token.Serve(l)
+	return addr
+}
+
+func TestToken(t *testing.T) {
+	gostKey, err := ecgeneric.GenerateKey(&gost.Gost341012512paramSetB, rand.Reader)
+	require.NoError(t, err)
+	ethKey, err := ecgeneric.GenerateKey(&nist.Secp256k1, rand.Reader)
+	require.NoError(t, err)
+
+	token := keybackend.NewToken("1234")
+	k1, err := keybackend.NewMemory(gostKey, keybackend.GOST)
+	require.NoError(t, err)
+	require.NoError(t, token.Add("gost", k1))
+
+	path := filepath.Join(t.TempDir(), "keys")
+	store, err := keystore.Open(path, light)
+	require.NoError(t, err)
+	e, err := store.Save(rand.Reader, ethKey, "secret")
+	require.NoError(t, err)
+	k2, err := keybackend.OpenKeystore(e.Path, "secret", keybackend.ECDSA)
+	require.NoError(t, err)
+	require.NoError(t, token.Add("eth", k2))
+
+	require.Error(t, token.Add("eth", k2))
+	require.Error(t, token.Add("two words", k2))
+
+	c, err := keybackend.Dial("unix", serve(t, token))
+	require.NoError(t, err)
+	defer c.Close()
+
+	ids, err := c.List()
+	require.NoError(t, err)
+	require.Equal(t, []string{"eth", "gost"}, ids)
+
+	rk, err := c.Key("gost")
+	require.NoError(t, err)
+	require.Equal(t, "gost", rk.ID())
+	require.Equal(t, keybackend.GOST, rk.Mechanism())
+	require.Equal(t, gostKey.X, rk.Public().X)
+	require.Equal(t, gostKey.Params().Name, rk.Public().Params().Name)
+
+	_, _, err = rk.Sign(digest("message"))
+	require.ErrorIs(t, err, keybackend.ErrNotLoggedIn)
+	require.ErrorIs(t, c.Login("0000"), keybackend.ErrPIN)
+	require.NoError(t, c.Login("1234"))
+	checkBackend(t, rk)
+
+	rk2, err := c.Key("eth")
+	require.NoError(t, err)
+	require.Equal(t, keybackend.ECDSA, rk2.Mechanism())
+	checkBackend(t, rk2)
+
+	// Locking the key on the token is seen by the client.
+	k2.Lock()
+	_, _, err = rk2.Sign(digest("message"))
+	require.ErrorIs(t, err, keybackend.ErrLocked)
+
+	_, err = c.Key("missing")
+	require.Error(t, err)
+
+	// A connection's login does not carry over to another.
+	c2, err := keybackend.Dial("unix", serve(t, token))
+	require.NoError(t, err)
+	defer c2.Close()
+	rk, err = c2.Key("gost")
+	require.NoError(t, err)
+	_, _, err = rk.Sign(digest("message"))
+	require.ErrorIs(t, err, keybackend.ErrNotLoggedIn)
+}
+
+func TestTokenConcurrent(t *testing.T) {
+	priv, err := ecgeneric.GenerateKey(&gost.Gost34102001paramSetA, rand.Reader)
+	require.NoError(t, err)
+	k, err := keybackend.NewMemory(priv, keybackend.GOST)
+	require.NoError(t, err)
+	token := keybackend.NewToken("")
+	require.NoError(t, token.Add("k", k))
+
+	server, client := net.Pipe()
+	go token.ServeConn(server)
+	c := keybackend.NewClient(client)
+	defer c.Close()
+	rk, err := c.Key("k")
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, err := rk.Sign(digest("message"))
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+}
+
+func TestTokenMalformedRequest(t *testing.T) {
+	token := keybackend.NewToken("")
+	server, client := net.Pipe()
+	go token.ServeConn(server)
+	defer client.Close()
+
+	_, err := client.Write([]byte("FORMAT c:\n"))
+	require.NoError(t, err)
+	buf := make([]byte, 256)
+	n, err := client.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, "ERR keybackend: malformed request\n", string(buf[:n]))
+
+	// The token hangs up after a malformed request.
+	_, err = client.Read(buf)
+	require.Error(t, err)
+}
+
+func TestTokenLoginLimit(t *testing.T) {
+	token := keybackend.NewToken("1234")
+	addr := serve(t, token)
+	login := func(pin string) error {
+		c, err := keybackend.Dial("unix", addr)
+		require.NoError(t, err)
+		defer c.Close()
+		return c.Login(pin)
+	}
+
+	// A correct PIN resets the count of wrong ones.
+	require.ErrorIs(t, login("0000"), keybackend.ErrPIN)
+	require.ErrorIs(t, login("1111"), keybackend.ErrPIN)
+	require.NoError(t, login("1234"))
+
+	// Wrong PINs are counted across connections, so reconnecting does not
+	// allow more guesses.
+	for _, pin := range []string{"0000", "1111", "2222"} {
+		require.ErrorIs(t, login(pin), keybackend.ErrPIN)
+	}
+	require.ErrorIs(t, login("1234"), keybackend.ErrPINLocked)
+
+	// The token hangs up on a LOGIN once the PIN is locked.
+	c, err := keybackend.Dial("unix", addr)
+	require.NoError(t, err)
+	defer c.Close()
+	require.ErrorIs(t, c.Login("1234"), keybackend.ErrPINLocked)
+	_, err = c.List()
+	require.Error(t, err)
+}
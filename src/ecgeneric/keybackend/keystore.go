@@ -0,0 +1,74 @@
+package keybackend
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/keystore"
+)
+
+// Keystore is a KeyBackend over a file written by the keystore package. The
+// key is decrypted by OpenKeystore and Unlock and held until Lock; the
+// public key stays available while the key is locked.
+type Keystore struct {
+	Path string
+
+	mech Mechanism
+	pub  *ecgeneric.PublicKey
+
+	mu   sync.RWMutex
+	priv *ecgeneric.PrivateKey
+}
+
+// OpenKeystore decrypts the key in the file at path and returns a backend
+// signing with it under mechanism m.
+func OpenKeystore(path, passphrase string, m Mechanism) (*Keystore, error) {
+	k := &Keystore{Path: path, mech: m}
+	if err := k.Unlock(passphrase); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Unlock decrypts the key again after Lock.
+func (k *Keystore) Unlock(passphrase string) error {
+	priv, err := keystore.LoadFile(k.Path, passphrase)
+	if err != nil {
+		return err
+	}
+	if priv, err = checkKey(priv); err != nil {
+		return err
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.pub != nil && (k.pub.X.Cmp(priv.X) != 0 || k.pub.Y.Cmp(priv.Y) != 0) {
+		return keystore.ErrMetadata
+	}
+	k.pub, k.priv = &priv.PublicKey, priv
+	return nil
+}
+
+// Lock drops the decrypted key. Sign returns ErrLocked until Unlock is
+// called.
+func (k *Keystore) Lock() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.priv != nil {
+		k.priv.D.SetInt64(0)
+		k.priv = nil
+	}
+}
+
+func (k *Keystore) Public() *ecgeneric.PublicKey { return publicCopy(k.pub) }
+
+func (k *Keystore) Mechanism() Mechanism { return k.mech }
+
+func (k *Keystore) Sign(digest []byte) (r, s *big.Int, err error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	if k.priv == nil {
+		return nil, nil, ErrLocked
+	}
+	return sign(k.priv, k.mech, digest)
+}
@@ -0,0 +1,32 @@
+package keybackend
+
+import (
+	"math/big"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+)
+
+// Memory is a KeyBackend holding its key in process memory.
+type Memory struct {
+	priv *ecgeneric.PrivateKey
+	mech Mechanism
+}
+
+// NewMemory returns a backend signing with a copy of priv under mechanism
+// m. The public key of priv is computed if X and Y are nil, and checked
+// otherwise.
+func NewMemory(priv *ecgeneric.PrivateKey, m Mechanism) (*Memory, error) {
+	k, err := checkKey(priv)
+	if err != nil {
+		return nil, err
+	}
+	return &Memory{priv: k, mech: m}, nil
+}
+
+func (k *Memory) Public() *ecgeneric.PublicKey { return publicCopy(&k.priv.PublicKey) }
+
+func (k *Memory) Mechanism() Mechanism { return k.mech }
+
+func (k *Memory) Sign(digest []byte) (r, s *big.Int, err error) {
+	return sign(k.priv, k.mech, digest)
+}
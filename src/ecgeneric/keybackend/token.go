@@ -0,0 +1,194 @@
+package keybackend
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+)
+
+// The token protocol is line based. Each request is a line of words
+// separated by single spaces and is answered by one line, either
+// "OK" followed by the results or "ERR" followed by a message:
+//
+//	LOGIN <pin>            OK
+//	LIST                   OK <id>...
+//	PUB <id>               OK <mechanism> <curve> <public key>
+//	SIGN <id> <digest>     OK <r> <s>
+//
+// Public keys are uncompressed SEC 1 points and digests and signature
+// values are big-endian integers, all in hex. Listing and public keys are
+// open to anyone; SIGN needs a LOGIN on the connection first when the token
+// has a PIN. After maxLogins wrong PINs in a row, counted over all
+// connections, the PIN is locked: every further LOGIN fails with
+// ErrPINLocked and the token hangs up, until it is restarted.
+
+var (
+	// ErrPIN is returned when logging in to a token with the wrong PIN.
+	ErrPIN = errors.New("keybackend: incorrect PIN")
+	// ErrNotLoggedIn is returned when signing on a token that has a PIN
+	// before logging in.
+	ErrNotLoggedIn = errors.New("keybackend: not logged in")
+	// ErrPINLocked is returned when logging in to a token whose PIN was
+	// locked by too many wrong guesses.
+	ErrPINLocked = errors.New("keybackend: PIN locked")
+
+	errNoKey   = errors.New("keybackend: no such key")
+	errRequest = errors.New("keybackend: malformed request")
+)
+
+// maxLogins is the number of wrong PINs in a row that locks the PIN.
+const maxLogins = 3
+
+// tokenErrors are the errors that keep their identity across the protocol.
+var tokenErrors = []error{ErrLocked, ErrPIN, ErrNotLoggedIn, ErrPINLocked}
+
+// Token is a software token serving KeyBackends to other processes.
+type Token struct {
+	pin string
+
+	loginMu sync.Mutex
+	failed  int // wrong PINs since the last successful LOGIN
+
+	mu   sync.RWMutex
+	keys map[string]KeyBackend
+}
+
+// NewToken returns an empty token. An empty pin lets every connection sign.
+func NewToken(pin string) *Token {
+	return &Token{pin: pin, keys: make(map[string]KeyBackend)}
+}
+
+// Add makes k available under id, which must be a non-empty word without
+// spaces.
+func (t *Token) Add(id string, k KeyBackend) error {
+	if id == "" || strings.ContainsAny(id, " \t\r\n") {
+		return fmt.Errorf("keybackend: invalid key id %q", id)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.keys[id]; ok {
+		return fmt.Errorf("keybackend: duplicate key id %q", id)
+	}
+	t.keys[id] = k
+	return nil
+}
+
+// Serve accepts connections on l and serves each in its own goroutine. It
+// returns the error from Accept, for example once l is closed.
+func (t *Token) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go t.ServeConn(conn)
+	}
+}
+
+// ServeConn answers requests on conn until the peer closes it, sends a line
+// that is not a request or tries to log in once the PIN is locked, and then
+// closes conn.
+func (t *Token) ServeConn(conn net.Conn) {
+	defer conn.Close()
+	sc := bufio.NewScanner(conn)
+	w := bufio.NewWriter(conn)
+	loggedIn := t.pin == ""
+	for sc.Scan() {
+		res, err := t.handle(strings.Split(sc.Text(), " "), &loggedIn)
+		if err != nil {
+			res = "ERR " + strings.ReplaceAll(err.Error(), "\n", " ")
+		}
+		if _, err := w.WriteString(res + "\n"); err != nil {
+			return
+		}
+		if err := w.Flush(); err != nil {
+			return
+		}
+		if err == errRequest || err == ErrPINLocked {
+			return
+		}
+	}
+}
+
+func (t *Token) handle(req []string, loggedIn *bool) (string, error) {
+	switch {
+	case req[0] == "LOGIN" && len(req) == 2:
+		if err := t.login(req[1]); err != nil {
+			return "", err
+		}
+		*loggedIn = true
+		return "OK", nil
+
+	case req[0] == "LIST" && len(req) == 1:
+		t.mu.RLock()
+		ids := make([]string, 0, len(t.keys))
+		for id := range t.keys {
+			ids = append(ids, id)
+		}
+		t.mu.RUnlock()
+		sort.Strings(ids)
+		return strings.Join(append([]string{"OK"}, ids...), " "), nil
+
+	case req[0] == "PUB" && len(req) == 2:
+		k, err := t.key(req[1])
+		if err != nil {
+			return "", err
+		}
+		pub := k.Public()
+		enc := hex.EncodeToString(ecgeneric.Marshal(pub.Curve, pub.X, pub.Y))
+		return fmt.Sprintf("OK %v %s %s", k.Mechanism(), pub.Params().Name, enc), nil
+
+	case req[0] == "SIGN" && len(req) == 3:
+		if !*loggedIn {
+			return "", ErrNotLoggedIn
+		}
+		k, err := t.key(req[1])
+		if err != nil {
+			return "", err
+		}
+		digest, err := hex.DecodeString(req[2])
+		if err != nil {
+			return "", errRequest
+		}
+		r, s, err := k.Sign(digest)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("OK %x %x", r, s), nil
+	}
+	return "", errRequest
+}
+
+// login checks pin, counting wrong guesses over all connections so that
+// reconnecting does not buy more of them.
+func (t *Token) login(pin string) error {
+	t.loginMu.Lock()
+	defer t.loginMu.Unlock()
+	if t.failed >= maxLogins {
+		return ErrPINLocked
+	}
+	if subtle.ConstantTimeCompare([]byte(pin), []byte(t.pin)) != 1 {
+		t.failed++
+		return ErrPIN
+	}
+	t.failed = 0
+	return nil
+}
+
+func (t *Token) key(id string) (KeyBackend, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	k, ok := t.keys[id]
+	if !ok {
+		return nil, errNoKey
+	}
+	return k, nil
+}
@@ -0,0 +1,154 @@
+package keystore
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/nist"
+)
+
+// Store is a directory of encrypted keys, one file per key, named as geth
+// names them: UTC--<creation time>--<address or id>. A geth keystore
+// directory can be opened as a Store and the other way around.
+type Store struct {
+	Dir string
+	// Options are used by Save; nil takes the defaults.
+	Options *Options
+}
+
+// Entry describes a stored key without decrypting it.
+type Entry struct {
+	Path  string
+	ID    string
+	Curve string
+	// PublicKey is nil for files that do not record it, such as those
+	// written by Ethereum wallets, and for curves that are not registered.
+	PublicKey *ecgeneric.PublicKey
+	// Address is the Ethereum address of secp256k1 keys, in lower-case hex
+	// without the 0x prefix.
+	Address string
+}
+
+// Open returns the store in dir, creating the directory if it does not
+// exist.
+func Open(dir string, opts *Options) (*Store, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &Store{Dir: dir, Options: opts}, nil
+}
+
+// Save encrypts priv under passphrase into a new file in the store.
+func (s *Store) Save(rand io.Reader, priv *ecgeneric.PrivateKey, passphrase string) (*Entry, error) {
+	k, err := encrypt(rand, priv, passphrase, s.Options)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(k, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	name := k.Address
+	if name == "" {
+		name = k.ID
+	}
+	ts := time.Now().UTC().Format("2006-01-02T15-04-05.000000000Z")
+	path := filepath.Join(s.Dir, "UTC--"+ts+"--"+name)
+	if err := writeFileAtomic(path, data); err != nil {
+		return nil, err
+	}
+	e := k.entry(path)
+	return &e, nil
+}
+
+// List returns the keys in the store in file name order, which for files
+// named by Save is the order of creation. Hidden files, backups ending in
+// "~" and files that are not keys are skipped.
+func (s *Store) List() ([]Entry, error) {
+	files, err := os.ReadDir(s.Dir)
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() || strings.HasPrefix(name, ".") || strings.HasSuffix(name, "~") {
+			continue
+		}
+		path := filepath.Join(s.Dir, name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var k encryptedKey
+		if json.Unmarshal(data, &k) != nil || k.Version != version || k.Crypto.CipherText == "" {
+			continue
+		}
+		entries = append(entries, k.entry(path))
+	}
+	return entries, nil
+}
+
+// Load decrypts the key whose ID or address is id. Addresses may carry the
+// 0x prefix and any letter case.
+func (s *Store) Load(id, passphrase string) (*ecgeneric.PrivateKey, error) {
+	entries, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	addr := strings.ToLower(strings.TrimPrefix(id, "0x"))
+	for _, e := range entries {
+		if e.ID == id || (e.Address != "" && e.Address == addr) {
+			return LoadFile(e.Path, passphrase)
+		}
+	}
+	return nil, fmt.Errorf("keystore: no key %q in %s", id, s.Dir)
+}
+
+// LoadFile decrypts the key in the file at path.
+func LoadFile(path, passphrase string) (*ecgeneric.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Decrypt(data, passphrase)
+}
+
+func (k *encryptedKey) entry(path string) Entry {
+	e := Entry{
+		Path:    path,
+		ID:      k.ID,
+		Curve:   k.Curve,
+		Address: strings.ToLower(strings.TrimPrefix(k.Address, "0x")),
+	}
+	if e.Curve == "" {
+		e.Curve = nist.Secp256k1.Name
+	}
+	e.PublicKey, _ = k.publicKey()
+	return e
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames
+// it into place, so that a crash never leaves a truncated key behind.
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
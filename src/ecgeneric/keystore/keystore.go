@@ -0,0 +1,505 @@
+// Package keystore stores ecgeneric private keys encrypted under a
+// passphrase, in the JSON format of the Ethereum Web3 Secret Storage
+// (keystore v3) extended to other curves and ciphers.
+//
+// A passphrase is stretched by scrypt, Argon2id or PBKDF2 into a key that
+// encrypts the private scalar with AES-128-CTR, AES-256-GCM or
+// Kuznyechik-MGM. As in v3, the file carries a MAC of the ciphertext under
+// the last 16 bytes of the derived key, so a wrong passphrase is detected
+// before anything is decrypted: Keccak-256 for the AES ciphers and
+// Streebog-256 for Kuznyechik. The curve name and public key are stored in
+// the clear, so keys can be listed without a passphrase; the AEAD ciphers
+// authenticate them, and every cipher checks on decryption that they match
+// the decrypted key.
+//
+// secp256k1 keys encrypted with scrypt or PBKDF2 and AES-128-CTR are
+// ordinary v3 files that geth and other Ethereum wallets open, and v3 files
+// written by those wallets decrypt as secp256k1 keys. Keys on other curves
+// need the curve registered with ecgeneric.RegisterCurve, as importing the
+// gost package does for the GOST curves.
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/eth"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/kuznyechik"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/mgm"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/nist"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/streebog"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// version is the keystore format version, that of Ethereum keystore v3.
+const version = 3
+
+// Key derivation functions.
+const (
+	KDFScrypt   = "scrypt"
+	KDFArgon2id = "argon2id"
+	KDFPBKDF2   = "pbkdf2"
+)
+
+// Ciphers.
+const (
+	CipherAES128CTR     = "aes-128-ctr"
+	CipherAES256GCM     = "aes-256-gcm"
+	CipherKuznyechikMGM = "kuznyechik-mgm"
+)
+
+// Default KDF costs. The standard scrypt parameters are those of geth;
+// the light ones take a fraction of the time and memory, for tests and weak
+// devices. The Argon2id defaults are the second recommendation of RFC 9106.
+const (
+	StandardScryptN = 1 << 18
+	StandardScryptP = 1
+	LightScryptN    = 1 << 12
+	LightScryptP    = 6
+
+	scryptR = 8
+
+	DefaultArgon2Time    = 3
+	DefaultArgon2Memory  = 64 * 1024
+	DefaultArgon2Threads = 4
+
+	DefaultPBKDF2Iterations = 262144
+)
+
+var (
+	// ErrDecrypt is returned for a wrong passphrase or a corrupted file.
+	ErrDecrypt = errors.New("keystore: could not decrypt key with given passphrase")
+	// ErrMetadata is returned when the stored curve, public key or address
+	// do not belong to the decrypted key.
+	ErrMetadata = errors.New("keystore: key does not match its metadata")
+)
+
+// Options select how a key is encrypted. Zero fields take defaults.
+type Options struct {
+	// KDF is KDFScrypt, KDFArgon2id or KDFPBKDF2. The default is scrypt.
+	KDF string
+	// Cipher is CipherAES128CTR, CipherAES256GCM or CipherKuznyechikMGM.
+	// The default is AES-128-CTR for secp256k1 keys, which keeps the file
+	// readable by Ethereum wallets, and AES-256-GCM otherwise.
+	Cipher string
+
+	// ScryptN and ScryptP default to StandardScryptN and StandardScryptP.
+	ScryptN, ScryptP int
+	// Argon2Time, Argon2Memory (in KiB) and Argon2Threads default to the
+	// DefaultArgon2 constants.
+	Argon2Time, Argon2Memory uint32
+	Argon2Threads            uint8
+	// PBKDF2Iterations defaults to DefaultPBKDF2Iterations.
+	PBKDF2Iterations int
+}
+
+// encryptedKey is the JSON form of a key.
+type encryptedKey struct {
+	Address   string     `json:"address,omitempty"`
+	Curve     string     `json:"curve,omitempty"`
+	PublicKey string     `json:"publickey,omitempty"`
+	Crypto    cryptoJSON `json:"crypto"`
+	ID        string     `json:"id"`
+	Version   int        `json:"version"`
+}
+
+type cryptoJSON struct {
+	Cipher       string       `json:"cipher"`
+	CipherText   string       `json:"ciphertext"`
+	CipherParams cipherParams `json:"cipherparams"`
+	KDF          string       `json:"kdf"`
+	KDFParams    kdfParams    `json:"kdfparams"`
+	MAC          string       `json:"mac"`
+}
+
+type cipherParams struct {
+	IV string `json:"iv"`
+}
+
+// kdfParams holds the parameters of every KDF: n, r, p for scrypt; t, m, p
+// for Argon2id; c and prf for PBKDF2.
+type kdfParams struct {
+	DKLen int    `json:"dklen"`
+	Salt  string `json:"salt"`
+	N     int    `json:"n,omitempty"`
+	R     int    `json:"r,omitempty"`
+	P     int    `json:"p,omitempty"`
+	T     uint32 `json:"t,omitempty"`
+	M     uint32 `json:"m,omitempty"`
+	C     int    `json:"c,omitempty"`
+	PRF   string `json:"prf,omitempty"`
+}
+
+// Encrypt returns the JSON encoding of priv encrypted under passphrase.
+// A nil opts takes all defaults.
+func Encrypt(rand io.Reader, priv *ecgeneric.PrivateKey, passphrase string, opts *Options) ([]byte, error) {
+	k, err := encrypt(rand, priv, passphrase, opts)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(k, "", "  ")
+}
+
+// Decrypt decrypts a key encoded by Encrypt or by an Ethereum wallet.
+func Decrypt(data []byte, passphrase string) (*ecgeneric.PrivateKey, error) {
+	var k encryptedKey
+	if err := json.Unmarshal(data, &k); err != nil {
+		return nil, err
+	}
+	return k.decrypt(passphrase)
+}
+
+func encrypt(rand io.Reader, priv *ecgeneric.PrivateKey, passphrase string, opts *Options) (*encryptedKey, error) {
+	if priv == nil || priv.Curve == nil || priv.D == nil {
+		return nil, errors.New("keystore: invalid private key")
+	}
+	curve := priv.Params()
+	if curve.Name == "" || priv.D.Sign() <= 0 || priv.D.Cmp(curve.N) >= 0 {
+		return nil, errors.New("keystore: invalid private key")
+	}
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	if o.Cipher == "" {
+		o.Cipher = CipherAES256GCM
+		if curve.Name == nist.Secp256k1.Name {
+			o.Cipher = CipherAES128CTR
+		}
+	}
+	spec, err := newCipherSpec(o.Cipher)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, 32)
+	if _, err := io.ReadFull(rand, salt); err != nil {
+		return nil, err
+	}
+	params, err := o.kdfParams(spec.keyLen+16, salt)
+	if err != nil {
+		return nil, err
+	}
+	dk, err := deriveKey(o.KDF, params, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	k := &encryptedKey{
+		Curve:     curve.Name,
+		PublicKey: hex.EncodeToString(ecgeneric.Marshal(curve, priv.X, priv.Y)),
+		Version:   version,
+	}
+	if curve.Name == nist.Secp256k1.Name {
+		addr, err := eth.PublicKeyToAddress(&priv.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		k.Address = hex.EncodeToString(addr[:])
+	}
+	if k.ID, err = newUUID(rand); err != nil {
+		return nil, err
+	}
+
+	iv := make([]byte, spec.ivLen)
+	if _, err := io.ReadFull(rand, iv); err != nil {
+		return nil, err
+	}
+	if o.Cipher == CipherKuznyechikMGM {
+		// MGM nonces have the most significant bit clear.
+		iv[0] &= 0x7f
+	}
+	plaintext := priv.D.FillBytes(make([]byte, (curve.N.BitLen()+7)/8))
+	ciphertext, err := spec.seal(dk[:spec.keyLen], iv, plaintext, k.additionalData())
+	if err != nil {
+		return nil, err
+	}
+	k.Crypto = cryptoJSON{
+		Cipher:       o.Cipher,
+		CipherText:   hex.EncodeToString(ciphertext),
+		CipherParams: cipherParams{IV: hex.EncodeToString(iv)},
+		KDF:          o.KDF,
+		KDFParams:    *params,
+		MAC:          hex.EncodeToString(spec.mac(dk, ciphertext)),
+	}
+	return k, nil
+}
+
+func (k *encryptedKey) decrypt(passphrase string) (*ecgeneric.PrivateKey, error) {
+	if k.Version != version {
+		return nil, fmt.Errorf("keystore: unsupported version %d", k.Version)
+	}
+	curve, err := k.curve()
+	if err != nil {
+		return nil, err
+	}
+	spec, err := newCipherSpec(k.Crypto.Cipher)
+	if err != nil {
+		return nil, err
+	}
+	if k.Crypto.KDFParams.DKLen < spec.keyLen+16 {
+		return nil, errors.New("keystore: derived key too short for cipher")
+	}
+	mac, err := hex.DecodeString(k.Crypto.MAC)
+	if err != nil {
+		return nil, err
+	}
+	iv, err := hex.DecodeString(k.Crypto.CipherParams.IV)
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != spec.ivLen {
+		return nil, errors.New("keystore: invalid iv")
+	}
+	ciphertext, err := hex.DecodeString(k.Crypto.CipherText)
+	if err != nil {
+		return nil, err
+	}
+
+	dk, err := deriveKey(k.Crypto.KDF, &k.Crypto.KDFParams, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare(spec.mac(dk, ciphertext), mac) != 1 {
+		return nil, ErrDecrypt
+	}
+	plaintext, err := spec.open(dk[:spec.keyLen], iv, ciphertext, k.additionalData())
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+
+	d := new(big.Int).SetBytes(plaintext)
+	if d.Sign() == 0 || d.Cmp(curve.N) >= 0 {
+		return nil, ErrDecrypt
+	}
+	x, y := curve.ScalarBaseMultJ(d.Bytes())
+	priv := &ecgeneric.PrivateKey{PublicKey: ecgeneric.PublicKey{Curve: curve, X: x, Y: y}, D: d}
+	if err := k.checkMetadata(&priv.PublicKey); err != nil {
+		return nil, err
+	}
+	return priv, nil
+}
+
+// curve returns the curve named in k; v3 files without one hold secp256k1
+// keys.
+func (k *encryptedKey) curve() (*ecgeneric.CurveParams, error) {
+	if k.Curve == "" {
+		return &nist.Secp256k1, nil
+	}
+	curve, ok := ecgeneric.CurveByName(k.Curve)
+	if !ok {
+		return nil, fmt.Errorf("keystore: unknown curve %q", k.Curve)
+	}
+	return curve, nil
+}
+
+// publicKey returns the public key stored in k, or nil if there is none.
+func (k *encryptedKey) publicKey() (*ecgeneric.PublicKey, error) {
+	if k.PublicKey == "" {
+		return nil, nil
+	}
+	curve, err := k.curve()
+	if err != nil {
+		return nil, err
+	}
+	b, err := hex.DecodeString(k.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	x, y := ecgeneric.Unmarshal(curve, b)
+	if x == nil {
+		return nil, errors.New("keystore: invalid public key")
+	}
+	return &ecgeneric.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+func (k *encryptedKey) checkMetadata(pub *ecgeneric.PublicKey) error {
+	stored, err := k.publicKey()
+	if err != nil {
+		return err
+	}
+	if stored != nil && (stored.X.Cmp(pub.X) != 0 || stored.Y.Cmp(pub.Y) != 0) {
+		return ErrMetadata
+	}
+	if k.Address != "" && pub.Params().Name == nist.Secp256k1.Name {
+		addr, err := eth.PublicKeyToAddress(pub)
+		if err != nil {
+			return err
+		}
+		if !strings.EqualFold(strings.TrimPrefix(k.Address, "0x"), hex.EncodeToString(addr[:])) {
+			return ErrMetadata
+		}
+	}
+	return nil
+}
+
+// additionalData is what the AEAD ciphers authenticate besides the key: the
+// curve name and the public key.
+func (k *encryptedKey) additionalData() []byte {
+	return []byte(k.Curve + "\x00" + k.PublicKey)
+}
+
+func (o *Options) kdfParams(dkLen int, salt []byte) (*kdfParams, error) {
+	p := &kdfParams{DKLen: dkLen, Salt: hex.EncodeToString(salt)}
+	switch o.KDF {
+	case "":
+		o.KDF = KDFScrypt
+		fallthrough
+	case KDFScrypt:
+		p.N, p.R, p.P = o.ScryptN, scryptR, o.ScryptP
+		if p.N == 0 {
+			p.N = StandardScryptN
+		}
+		if p.P == 0 {
+			p.P = StandardScryptP
+		}
+	case KDFArgon2id:
+		p.T, p.M, p.P = o.Argon2Time, o.Argon2Memory, int(o.Argon2Threads)
+		if p.T == 0 {
+			p.T = DefaultArgon2Time
+		}
+		if p.M == 0 {
+			p.M = DefaultArgon2Memory
+		}
+		if p.P == 0 {
+			p.P = DefaultArgon2Threads
+		}
+	case KDFPBKDF2:
+		p.C, p.PRF = o.PBKDF2Iterations, "hmac-sha256"
+		if p.C == 0 {
+			p.C = DefaultPBKDF2Iterations
+		}
+	default:
+		return nil, fmt.Errorf("keystore: unsupported KDF %q", o.KDF)
+	}
+	return p, nil
+}
+
+func deriveKey(kdf string, p *kdfParams, passphrase string) ([]byte, error) {
+	salt, err := hex.DecodeString(p.Salt)
+	if err != nil {
+		return nil, err
+	}
+	switch kdf {
+	case KDFScrypt:
+		return scrypt.Key([]byte(passphrase), salt, p.N, p.R, p.P, p.DKLen)
+	case KDFArgon2id:
+		if p.T == 0 || p.M == 0 || p.P <= 0 || p.P > 255 || p.DKLen <= 0 {
+			return nil, errors.New("keystore: invalid argon2id parameters")
+		}
+		return argon2.IDKey([]byte(passphrase), salt, p.T, p.M, uint8(p.P), uint32(p.DKLen)), nil
+	case KDFPBKDF2:
+		if p.PRF != "hmac-sha256" {
+			return nil, fmt.Errorf("keystore: unsupported PBKDF2 PRF %q", p.PRF)
+		}
+		if p.C <= 0 || p.DKLen <= 0 {
+			return nil, errors.New("keystore: invalid pbkdf2 parameters")
+		}
+		return pbkdf2.Key([]byte(passphrase), salt, p.C, p.DKLen, sha256.New), nil
+	}
+	return nil, fmt.Errorf("keystore: unsupported KDF %q", kdf)
+}
+
+// cipherSpec describes a cipher: its key and IV lengths, how it seals the
+// private key and which hash computes the MAC.
+type cipherSpec struct {
+	name          string
+	keyLen, ivLen int
+}
+
+func newCipherSpec(name string) (cipherSpec, error) {
+	switch name {
+	case CipherAES128CTR:
+		return cipherSpec{name, 16, aes.BlockSize}, nil
+	case CipherAES256GCM:
+		return cipherSpec{name, 32, 12}, nil
+	case CipherKuznyechikMGM:
+		return cipherSpec{name, 32, mgm.BlockSize}, nil
+	}
+	return cipherSpec{}, fmt.Errorf("keystore: unsupported cipher %q", name)
+}
+
+// mac is H(dk[len-16:] || ciphertext), which for AES-128-CTR and a 32-byte
+// derived key is the v3 MAC.
+func (c cipherSpec) mac(dk, ciphertext []byte) []byte {
+	key := dk[len(dk)-16:]
+	if c.name == CipherKuznyechikMGM {
+		sum := streebog.Sum256(append(append([]byte{}, key...), ciphertext...))
+		return sum[:]
+	}
+	return eth.Keccak256(key, ciphertext)
+}
+
+func (c cipherSpec) seal(key, iv, plaintext, ad []byte) ([]byte, error) {
+	if c.name == CipherAES128CTR {
+		return ctr(key, iv, plaintext)
+	}
+	aead, err := c.aead(key)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(nil, iv, plaintext, ad), nil
+}
+
+func (c cipherSpec) open(key, iv, ciphertext, ad []byte) ([]byte, error) {
+	if c.name == CipherAES128CTR {
+		return ctr(key, iv, ciphertext)
+	}
+	aead, err := c.aead(key)
+	if err != nil {
+		return nil, err
+	}
+	if c.name == CipherKuznyechikMGM && iv[0]&0x80 != 0 {
+		return nil, ErrDecrypt
+	}
+	return aead.Open(nil, iv, ciphertext, ad)
+}
+
+func (c cipherSpec) aead(key []byte) (cipher.AEAD, error) {
+	if c.name == CipherKuznyechikMGM {
+		block, err := kuznyechik.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		return mgm.NewMGM(block, mgm.BlockSize)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func ctr(key, iv, in []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, len(in))
+	cipher.NewCTR(block, iv).XORKeyStream(out, in)
+	return out, nil
+}
+
+// newUUID returns a random (version 4) UUID.
+func newUUID(rand io.Reader) (string, error) {
+	var u [16]byte
+	if _, err := io.ReadFull(rand, u[:]); err != nil {
+		return "", err
+	}
+	u[6] = u[6]&0x0f | 0x40
+	u[8] = u[8]&0x3f | 0x80
+	h := hex.EncodeToString(u[:])
+	return strings.Join([]string{h[:8], h[8:12], h[12:16], h[16:20], h[20:]}, "-"), nil
+}
@@ -0,0 +1,194 @@
+package keystore_test
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/gost"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/keystore"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/nist"
+	"github.com/stretchr/testify/require"
+)
+
+// Vectors from the Web3 Secret Storage definition and the go-ethereum
+// keystore tests.
+var v3Vectors = []struct {
+	name, json, password, priv string
+	heavy                      bool
+}{
+	{
+		"scrypt",
+		`{"crypto":{"cipher":"aes-128-ctr","cipherparams":{"iv":"83dbcc02d8ccb40e466191a123791e0e"},"ciphertext":"d172bf743a674da9cdad04534d56926ef8358534d458fffccd4e6ad2fbde479c","kdf":"scrypt","kdfparams":{"dklen":32,"n":262144,"r":1,"p":8,"salt":"ab0c7876052600dd703518d6fc3fe8984592145b591fc8fb5c6d43190334ba19"},"mac":"2103ac29920d71da29f15d75b4a16dbe95cfd7ff8faea1056c33131d846e3097"},"id":"3198bc9c-6672-5ab3-d995-4942343ae5b6","version":3}`,
+		"testpassword",
+		"7a28b5ba57c53603b0b07b56bba752f7784bf506fa95edc395f5cf6c7514fe9d",
+		true,
+	},
+	{
+		"pbkdf2",
+		`{"crypto":{"cipher":"aes-128-ctr","cipherparams":{"iv":"6087dab2f9fdbbfaddc31a909735c1e6"},"ciphertext":"5318b4d5bcd28de64ee5559e671353e16f075ecae9f99c7a79a38af5f869aa46","kdf":"pbkdf2","kdfparams":{"c":262144,"dklen":32,"prf":"hmac-sha256","salt":"ae3cd4e7013836a3df6bd7241b12db061dbe2c6785853cce422d148a624ce0bd"},"mac":"517ead924a9d0dc3124507e3393d175ce3ff7c1e96529c6c555ce9e51205e9b2"},"id":"3198bc9c-6672-5ab3-d995-4942343ae5b6","version":3}`,
+		"testpassword",
+		"7a28b5ba57c53603b0b07b56bba752f7784bf506fa95edc395f5cf6c7514fe9d",
+		false,
+	},
+	{
+		"31 byte key",
+		`{"crypto":{"cipher":"aes-128-ctr","cipherparams":{"iv":"e0c41130a323adc1446fc82f724bca2f"},"ciphertext":"9517cd5bdbe69076f9bf5057248c6c050141e970efa36ce53692d5d59a3984","kdf":"scrypt","kdfparams":{"dklen":32,"n":2,"r":8,"p":1,"salt":"711f816911c92d649fb4c84b047915679933555030b3552c1212609b38208c63"},"mac":"d5e116151c6aa71470e67a7d42c9620c75c4d23229847dcc127794f0732b0db5"},"id":"fecfc4ce-e956-48fd-953b-30f8b52ed66c","version":3}`,
+		"foo",
+		"fa7b3db73dc7dfdf8c5fbdb796d741e4488628c41fc4febd9160a866ba0f35",
+		false,
+	},
+	{
+		"30 byte key",
+		`{"crypto":{"cipher":"aes-128-ctr","cipherparams":{"iv":"3ca92af36ad7c2cd92454c59cea5ef00"},"ciphertext":"108b7d34f3442fc26ab1ab90ca91476ba6bfa8c00975a49ef9051dc675aa","kdf":"scrypt","kdfparams":{"dklen":32,"n":2,"r":8,"p":1,"salt":"d0769e608fb86cda848065642a9c6fa046845c928175662b8e356c77f914cd3b"},"mac":"75d0e6759f7b3cefa319c3be41680ab6beea7d8328653474bd06706d4cc67420"},"id":"a37e1559-5955-450d-8075-7b8931b392b2","version":3}`,
+		"foo",
+		"81c29e8142bb6a81bef5a92bda7a8328a5c85bb2f9542e76f9b0f94fc018",
+		false,
+	},
+}
+
+var light = &keystore.Options{
+	ScryptN:          keystore.LightScryptN,
+	ScryptP:          1,
+	Argon2Time:       1,
+	Argon2Memory:     1024,
+	Argon2Threads:    1,
+	PBKDF2Iterations: 1000,
+}
+
+func TestV3Vectors(t *testing.T) {
+	for _, v := range v3Vectors {
+		if v.heavy && testing.Short() {
+			continue
+		}
+		priv, err := keystore.Decrypt([]byte(v.json), v.password)
+		require.NoError(t, err, v.name)
+		require.Equal(t, v.priv, hex.EncodeToString(priv.D.Bytes()), v.name)
+		require.Equal(t, nist.Secp256k1.Name, priv.Params().Name)
+
+		_, err = keystore.Decrypt([]byte(v.json), v.password+"x")
+		require.ErrorIs(t, err, keystore.ErrDecrypt, v.name)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	curves := []*ecgeneric.CurveParams{&nist.Secp256k1, &gost.Gost34102001paramSetA, &gost.Gost341012512paramSetA}
+	for _, curve := range curves {
+		priv, err := ecgeneric.GenerateKey(curve, rand.Reader)
+		require.NoError(t, err)
+		for _, kdf := range []string{keystore.KDFScrypt, keystore.KDFArgon2id, keystore.KDFPBKDF2} {
+			for _, c := range []string{keystore.CipherAES128CTR, keystore.CipherAES256GCM, keystore.CipherKuznyechikMGM} {
+				opts := *light
+				opts.KDF, opts.Cipher = kdf, c
+				data, err := keystore.Encrypt(rand.Reader, priv, "secret", &opts)
+				require.NoError(t, err)
+
+				got, err := keystore.Decrypt(data, "secret")
+				require.NoError(t, err, "%s %s %s", curve.Name, kdf, c)
+				require.Equal(t, priv.D, got.D)
+				require.Equal(t, priv.X, got.X)
+				require.Equal(t, curve.Name, got.Params().Name)
+
+				_, err = keystore.Decrypt(data, "wrong")
+				require.ErrorIs(t, err, keystore.ErrDecrypt)
+			}
+		}
+	}
+}
+
+func TestEthereumCompatible(t *testing.T) {
+	priv, err := ecgeneric.GenerateKey(&nist.Secp256k1, rand.Reader)
+	require.NoError(t, err)
+	data, err := keystore.Encrypt(rand.Reader, priv, "secret", light)
+	require.NoError(t, err)
+
+	// The default for secp256k1 is a plain v3 file with an address.
+	var f struct {
+		Address string `json:"address"`
+		Version int    `json:"version"`
+		Crypto  struct {
+			Cipher    string                 `json:"cipher"`
+			KDF       string                 `json:"kdf"`
+			KDFParams map[string]interface{} `json:"kdfparams"`
+		} `json:"crypto"`
+	}
+	require.NoError(t, json.Unmarshal(data, &f))
+	require.Equal(t, 3, f.Version)
+	require.Equal(t, "aes-128-ctr", f.Crypto.Cipher)
+	require.Equal(t, "scrypt", f.Crypto.KDF)
+	require.EqualValues(t, 32, f.Crypto.KDFParams["dklen"])
+	require.Len(t, f.Address, 40)
+}
+
+func TestMetadata(t *testing.T) {
+	for _, c := range []string{keystore.CipherAES128CTR, keystore.CipherKuznyechikMGM} {
+		priv, err := ecgeneric.GenerateKey(&gost.Gost34102001paramSetA, rand.Reader)
+		require.NoError(t, err)
+		other, err := ecgeneric.GenerateKey(&gost.Gost34102001paramSetA, rand.Reader)
+		require.NoError(t, err)
+		opts := *light
+		opts.Cipher = c
+		data, err := keystore.Encrypt(rand.Reader, priv, "secret", &opts)
+		require.NoError(t, err)
+
+		var f map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &f))
+		f["publickey"] = hex.EncodeToString(ecgeneric.Marshal(other.Curve, other.X, other.Y))
+		tampered, err := json.Marshal(f)
+		require.NoError(t, err)
+		_, err = keystore.Decrypt(tampered, "secret")
+		require.Error(t, err, c)
+	}
+}
+
+func TestStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "keys")
+	store, err := keystore.Open(dir, light)
+	require.NoError(t, err)
+
+	ethKey, err := ecgeneric.GenerateKey(&nist.Secp256k1, rand.Reader)
+	require.NoError(t, err)
+	gostKey, err := ecgeneric.GenerateKey(&gost.Gost341012512paramSetA, rand.Reader)
+	require.NoError(t, err)
+
+	e1, err := store.Save(rand.Reader, ethKey, "one")
+	require.NoError(t, err)
+	e2, err := store.Save(rand.Reader, gostKey, "two")
+	require.NoError(t, err)
+	require.NotEmpty(t, e1.Address)
+	require.Empty(t, e2.Address)
+	require.True(t, strings.HasSuffix(e1.Path, "--"+e1.Address))
+	require.True(t, strings.HasSuffix(e2.Path, "--"+e2.ID))
+
+	info, err := os.Stat(e1.Path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// Files that are not keys are skipped.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "garbage"), []byte("{"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".hidden"), []byte("{}"), 0600))
+
+	entries, err := store.List()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, *e1, entries[0])
+	require.Equal(t, gostKey.X, entries[1].PublicKey.X)
+	require.Equal(t, gostKey.Params().Name, entries[1].Curve)
+
+	got, err := store.Load("0x"+strings.ToUpper(e1.Address), "one")
+	require.NoError(t, err)
+	require.Equal(t, ethKey.D, got.D)
+	got, err = store.Load(e2.ID, "two")
+	require.NoError(t, err)
+	require.Equal(t, gostKey.D, got.D)
+
+	_, err = store.Load(e2.ID, "one")
+	require.ErrorIs(t, err, keystore.ErrDecrypt)
+	_, err = store.Load("missing", "one")
+	require.Error(t, err)
+}
@@ -0,0 +1,201 @@
+// Package kuznyechik implements the GOST R 34.12-2015 128-bit block cipher
+// (Kuznyechik), as specified in RFC 7801.
+//
+// Blocks and keys are in the byte order of the standard's test vectors, most
+// significant byte first.
+package kuznyechik
+
+import (
+	"crypto/cipher"
+	"strconv"
+)
+
+const (
+	// BlockSize is the Kuznyechik block size in bytes.
+	BlockSize = 16
+	// KeySize is the Kuznyechik key size in bytes.
+	KeySize = 32
+)
+
+// KeySizeError is returned by NewCipher for keys of the wrong length.
+type KeySizeError int
+
+func (k KeySizeError) Error() string {
+	return "kuznyechik: invalid key size " + strconv.Itoa(int(k))
+}
+
+// pi is the substitution π shared with the Streebog hash function.
+var pi = [256]byte{
+	252, 238, 221, 17, 207, 110, 49, 22, 251, 196, 250, 218, 35, 197, 4, 77,
+	233, 119, 240, 219, 147, 46, 153, 186, 23, 54, 241, 187, 20, 205, 95, 193,
+	249, 24, 101, 90, 226, 92, 239, 33, 129, 28, 60, 66, 139, 1, 142, 79,
+	5, 132, 2, 174, 227, 106, 143, 160, 6, 11, 237, 152, 127, 212, 211, 31,
+	235, 52, 44, 81, 234, 200, 72, 171, 242, 42, 104, 162, 253, 58, 206, 204,
+	181, 112, 14, 86, 8, 12, 118, 18, 191, 114, 19, 71, 156, 183, 93, 135,
+	21, 161, 150, 41, 16, 123, 154, 199, 243, 145, 120, 111, 157, 158, 178, 177,
+	50, 117, 25, 61, 255, 53, 138, 126, 109, 84, 198, 128, 195, 189, 13, 87,
+	223, 245, 36, 169, 62, 168, 67, 201, 215, 121, 214, 246, 124, 34, 185, 3,
+	224, 15, 236, 222, 122, 148, 176, 188, 220, 232, 40, 80, 78, 51, 10, 74,
+	167, 151, 96, 115, 30, 0, 98, 68, 26, 184, 56, 130, 100, 159, 38, 65,
+	173, 69, 70, 146, 39, 94, 85, 47, 140, 163, 165, 125, 105, 213, 149, 59,
+	7, 88, 179, 64, 134, 172, 29, 247, 48, 55, 107, 228, 136, 217, 231, 137,
+	225, 27, 131, 73, 76, 63, 248, 254, 141, 83, 170, 144, 202, 216, 133, 97,
+	32, 113, 103, 164, 45, 43, 9, 91, 203, 155, 37, 208, 190, 229, 108, 82,
+	89, 166, 116, 210, 230, 244, 180, 192, 209, 102, 175, 194, 57, 75, 99, 182,
+}
+
+// lCoeffs are the coefficients of the linear function ℓ, applied to the
+// bytes a₁₅…a₀ of a block.
+var lCoeffs = [BlockSize]byte{148, 32, 133, 16, 194, 192, 1, 251, 1, 192, 194, 16, 133, 32, 148, 1}
+
+var (
+	piInv [256]byte
+	// ls[i][b] is L∘S applied to the block with byte b at position i and
+	// zeroes elsewhere; L is linear, so L(S(a)) = ⊕ ls[i][a[i]].
+	ls [BlockSize][256][BlockSize]byte
+	// lInv[i][b] is L⁻¹ of the block with byte b at position i.
+	lInv [BlockSize][256][BlockSize]byte
+)
+
+func init() {
+	for i, v := range pi {
+		piInv[v] = byte(i)
+	}
+	for i := 0; i < BlockSize; i++ {
+		for b := 0; b < 256; b++ {
+			var blk [BlockSize]byte
+			blk[i] = pi[b]
+			ls[i][b] = l(blk)
+			blk[i] = byte(b)
+			lInv[i][b] = lInverse(blk)
+		}
+	}
+}
+
+// gfMul multiplies in GF(2⁸) modulo x⁸+x⁷+x⁶+x+1.
+func gfMul(a, b byte) byte {
+	var p byte
+	for b != 0 {
+		if b&1 == 1 {
+			p ^= a
+		}
+		hi := a & 0x80
+		a <<= 1
+		if hi != 0 {
+			a ^= 0xc3
+		}
+		b >>= 1
+	}
+	return p
+}
+
+func lFunc(a *[BlockSize]byte) byte {
+	var r byte
+	for i, c := range lCoeffs {
+		r ^= gfMul(a[i], c)
+	}
+	return r
+}
+
+// l applies the transformation R sixteen times.
+func l(a [BlockSize]byte) [BlockSize]byte {
+	for round := 0; round < BlockSize; round++ {
+		x := lFunc(&a)
+		copy(a[1:], a[:BlockSize-1])
+		a[0] = x
+	}
+	return a
+}
+
+// lInverse applies R⁻¹ sixteen times.
+func lInverse(a [BlockSize]byte) [BlockSize]byte {
+	for round := 0; round < BlockSize; round++ {
+		first := a[0]
+		copy(a[:BlockSize-1], a[1:])
+		// R⁻¹(a₁₅…a₀) = a₁₄…a₀ ‖ ℓ(a₁₄…a₀, a₁₅)
+		a[BlockSize-1] = first
+		a[BlockSize-1] = lFunc(&a)
+	}
+	return a
+}
+
+func lsx(a, k *[BlockSize]byte) [BlockSize]byte {
+	var out [BlockSize]byte
+	for i := 0; i < BlockSize; i++ {
+		row := &ls[i][a[i]^k[i]]
+		for j := range out {
+			out[j] ^= row[j]
+		}
+	}
+	return out
+}
+
+type kuznyechikCipher struct {
+	rk [10][BlockSize]byte
+}
+
+// NewCipher returns a Kuznyechik cipher.Block for a 32-byte key.
+func NewCipher(key []byte) (cipher.Block, error) {
+	if len(key) != KeySize {
+		return nil, KeySizeError(len(key))
+	}
+	c := new(kuznyechikCipher)
+	copy(c.rk[0][:], key[:BlockSize])
+	copy(c.rk[1][:], key[BlockSize:])
+
+	k1, k2 := c.rk[0], c.rk[1]
+	for i := 0; i < 4; i++ {
+		for j := 1; j <= 8; j++ {
+			var ci [BlockSize]byte
+			ci[BlockSize-1] = byte(8*i + j)
+			ci = l(ci)
+			// F[C](a₁, a₀) = (LSX[C](a₁) ⊕ a₀, a₁)
+			t := lsx(&k1, &ci)
+			for b := range t {
+				t[b] ^= k2[b]
+			}
+			k1, k2 = t, k1
+		}
+		c.rk[2*i+2], c.rk[2*i+3] = k1, k2
+	}
+	return c, nil
+}
+
+func (c *kuznyechikCipher) BlockSize() int { return BlockSize }
+
+func (c *kuznyechikCipher) Encrypt(dst, src []byte) {
+	if len(src) < BlockSize || len(dst) < BlockSize {
+		panic("kuznyechik: input not full block")
+	}
+	var a [BlockSize]byte
+	copy(a[:], src)
+	for i := 0; i < 9; i++ {
+		a = lsx(&a, &c.rk[i])
+	}
+	for i := range a {
+		dst[i] = a[i] ^ c.rk[9][i]
+	}
+}
+
+func (c *kuznyechikCipher) Decrypt(dst, src []byte) {
+	if len(src) < BlockSize || len(dst) < BlockSize {
+		panic("kuznyechik: input not full block")
+	}
+	var a [BlockSize]byte
+	for i := range a {
+		a[i] = src[i] ^ c.rk[9][i]
+	}
+	for r := 8; r >= 0; r-- {
+		var t [BlockSize]byte
+		for i := 0; i < BlockSize; i++ {
+			row := &lInv[i][a[i]]
+			for j := range t {
+				t[j] ^= row[j]
+			}
+		}
+		for i := range a {
+			a[i] = piInv[t[i]] ^ c.rk[r][i]
+		}
+	}
+	copy(dst, a[:])
+}
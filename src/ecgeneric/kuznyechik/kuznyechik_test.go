@@ -0,0 +1,51 @@
+package kuznyechik_test
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/kuznyechik"
+	"github.com/stretchr/testify/require"
+)
+
+func unhex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// GOST R 34.12-2015, Appendix A.1 (RFC 7801, section 5).
+func TestKuznyechikVector(t *testing.T) {
+	key := unhex("8899aabbccddeeff0011223344556677fedcba98765432100123456789abcdef")
+	pt := unhex("1122334455667700ffeeddccbbaa9988")
+	ct := unhex("7f679d90bebc24305a468d42b9d4edcd")
+
+	c, err := kuznyechik.NewCipher(key)
+	require.NoError(t, err)
+	got := make([]byte, kuznyechik.BlockSize)
+	c.Encrypt(got, pt)
+	require.Equal(t, ct, got)
+	c.Decrypt(got, ct)
+	require.Equal(t, pt, got)
+}
+
+func TestKuznyechikRoundTrip(t *testing.T) {
+	key := make([]byte, kuznyechik.KeySize)
+	pt := make([]byte, kuznyechik.BlockSize)
+	_, _ = rand.Read(key)
+	_, _ = rand.Read(pt)
+
+	c, err := kuznyechik.NewCipher(key)
+	require.NoError(t, err)
+	ct := make([]byte, kuznyechik.BlockSize)
+	c.Encrypt(ct, pt)
+	got := make([]byte, kuznyechik.BlockSize)
+	c.Decrypt(got, ct)
+	require.Equal(t, pt, got)
+
+	_, err = kuznyechik.NewCipher(key[:16])
+	require.Error(t, err)
+}
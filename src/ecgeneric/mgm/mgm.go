@@ -0,0 +1,185 @@
+// Package mgm implements the Multilinear Galois Mode of R 1323565.1.026-2019
+// (RFC 9058), an AEAD mode for 128-bit block ciphers such as Kuznyechik.
+package mgm
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+)
+
+// BlockSize is the only cipher block size supported by this package.
+const BlockSize = 16
+
+var errOpen = errors.New("mgm: message authentication failed")
+
+type mgm struct {
+	block   cipher.Block
+	tagSize int
+}
+
+// NewMGM returns block, which must have a 16-byte block size, wrapped in
+// MGM with a tagSize-byte authentication tag. Nonces are one block long and
+// their most significant bit must be zero.
+func NewMGM(block cipher.Block, tagSize int) (cipher.AEAD, error) {
+	if block.BlockSize() != BlockSize {
+		return nil, errors.New("mgm: block size must be 16 bytes")
+	}
+	if tagSize < 4 || tagSize > BlockSize {
+		return nil, errors.New("mgm: tag size must be between 4 and 16 bytes")
+	}
+	return &mgm{block: block, tagSize: tagSize}, nil
+}
+
+func (m *mgm) NonceSize() int { return BlockSize }
+
+func (m *mgm) Overhead() int { return m.tagSize }
+
+func (m *mgm) checkNonce(nonce []byte) {
+	if len(nonce) != BlockSize {
+		panic("mgm: incorrect nonce length")
+	}
+	if nonce[0]&0x80 != 0 {
+		panic("mgm: nonce must have its most significant bit cleared")
+	}
+}
+
+func (m *mgm) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	m.checkNonce(nonce)
+	ret, out := sliceForAppend(dst, len(plaintext)+m.tagSize)
+	ct := out[:len(plaintext)]
+	m.crypt(ct, plaintext, nonce)
+	tag := m.tag(nonce, additionalData, ct)
+	copy(out[len(plaintext):], tag[:m.tagSize])
+	return ret
+}
+
+func (m *mgm) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	m.checkNonce(nonce)
+	if len(ciphertext) < m.tagSize {
+		return nil, errOpen
+	}
+	ct := ciphertext[:len(ciphertext)-m.tagSize]
+	tag := m.tag(nonce, additionalData, ct)
+	if subtle.ConstantTimeCompare(tag[:m.tagSize], ciphertext[len(ct):]) != 1 {
+		return nil, errOpen
+	}
+	ret, out := sliceForAppend(dst, len(ct))
+	m.crypt(out, ct, nonce)
+	return ret, nil
+}
+
+// crypt XORs src with the keystream E(Y₁), E(Y₂), ... where Y₁ = E(0‖nonce)
+// and each following counter increments the right half.
+func (m *mgm) crypt(dst, src, nonce []byte) {
+	var y, ks [BlockSize]byte
+	copy(y[:], nonce)
+	y[0] &= 0x7f
+	m.block.Encrypt(y[:], y[:])
+	for len(src) > 0 {
+		m.block.Encrypt(ks[:], y[:])
+		n := xorBytes(dst, src, ks[:])
+		dst, src = dst[n:], src[n:]
+		incr(y[BlockSize/2:])
+	}
+}
+
+// tag computes E(Σ Hᵢ⊗Aᵢ ⊕ Σ Hⱼ⊗Cⱼ ⊕ H⊗(len(A)‖len(C))), where the Hᵢ are
+// E(Zᵢ) for Z₁ = E(1‖nonce) with the left half incremented each step.
+func (m *mgm) tag(nonce, ad, ct []byte) [BlockSize]byte {
+	var z, h, sum, blk [BlockSize]byte
+	copy(z[:], nonce)
+	z[0] |= 0x80
+	m.block.Encrypt(z[:], z[:])
+
+	absorb := func(data []byte) {
+		for len(data) > 0 {
+			blk = [BlockSize]byte{}
+			n := copy(blk[:], data)
+			data = data[n:]
+			m.block.Encrypt(h[:], z[:])
+			p := gfMul(h, blk)
+			xorBytes(sum[:], sum[:], p[:])
+			incr(z[:BlockSize/2])
+		}
+	}
+	absorb(ad)
+	absorb(ct)
+
+	binary.BigEndian.PutUint64(blk[:8], uint64(len(ad))*8)
+	binary.BigEndian.PutUint64(blk[8:], uint64(len(ct))*8)
+	m.block.Encrypt(h[:], z[:])
+	p := gfMul(h, blk)
+	xorBytes(sum[:], sum[:], p[:])
+
+	m.block.Encrypt(sum[:], sum[:])
+	return sum
+}
+
+// xorBytes sets dst[i] = a[i] ^ b[i] for the length of the shorter input and
+// returns that length.
+func xorBytes(dst, a, b []byte) int {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	for i := 0; i < n; i++ {
+		dst[i] = a[i] ^ b[i]
+	}
+	return n
+}
+
+// incr adds one to the big-endian counter b, modulo 2^(8·len(b)).
+func incr(b []byte) {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+		if b[i] != 0 {
+			return
+		}
+	}
+}
+
+// gfMul multiplies big-endian elements of GF(2¹²⁸) modulo
+// x¹²⁸+x⁷+x²+x+1.
+func gfMul(a, b [BlockSize]byte) [BlockSize]byte {
+	xh, xl := binary.BigEndian.Uint64(a[:8]), binary.BigEndian.Uint64(a[8:])
+	yh, yl := binary.BigEndian.Uint64(b[:8]), binary.BigEndian.Uint64(b[8:])
+	var zh, zl uint64
+	for i := 0; i < 128; i++ {
+		var bit uint64
+		if i < 64 {
+			bit = yl >> uint(i) & 1
+		} else {
+			bit = yh >> uint(i-64) & 1
+		}
+		if bit == 1 {
+			zh ^= xh
+			zl ^= xl
+		}
+		carry := xh >> 63
+		xh = xh<<1 | xl>>63
+		xl <<= 1
+		if carry == 1 {
+			xl ^= 0x87
+		}
+	}
+	var out [BlockSize]byte
+	binary.BigEndian.PutUint64(out[:8], zh)
+	binary.BigEndian.PutUint64(out[8:], zl)
+	return out
+}
+
+// sliceForAppend takes a slice and a requested number of bytes. It returns a
+// slice with the contents of the given slice followed by that many bytes and a
+// second slice that aliases into it and contains only the extra bytes.
+func sliceForAppend(in []byte, n int) (head, tail []byte) {
+	if total := len(in) + n; cap(in) >= total {
+		head = in[:total]
+	} else {
+		head = make([]byte, total)
+		copy(head, in)
+	}
+	tail = head[len(in):]
+	return
+}
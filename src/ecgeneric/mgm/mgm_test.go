@@ -0,0 +1,44 @@
+package mgm_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/kuznyechik"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/mgm"
+	"github.com/stretchr/testify/require"
+)
+
+func unhex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// R 1323565.1.026-2019, Appendix A.1 (RFC 9058, Appendix A).
+func TestMGMKuznyechikVector(t *testing.T) {
+	key := unhex("8899aabbccddeeff0011223344556677fedcba98765432100123456789abcdef")
+	nonce := unhex("1122334455667700ffeeddccbbaa9988")
+	ad := unhex("0202020202020202010101010101010104040404040404040303030303030303ea0505050505050505")
+	pt := unhex("1122334455667700ffeeddccbbaa998800112233445566778899aabbcceeff0a112233445566778899aabbcceeff0a002233445566778899aabbcceeff0a0011aabbcc")
+	ct := unhex("a9757b8147956e9055b8a33de89f42fc8075d2212bf9fd5bd3f7069aadc16b39497ab15915a6ba85936b5d0ea9f6851cc60c14d4d3f883d0ab94420695c76deb2c7552")
+	tag := unhex("cf5d656f40c34f5c46e8bb0e29fcdb4c")
+
+	block, err := kuznyechik.NewCipher(key)
+	require.NoError(t, err)
+	aead, err := mgm.NewMGM(block, 16)
+	require.NoError(t, err)
+
+	sealed := aead.Seal(nil, nonce, pt, ad)
+	require.Equal(t, append(ct, tag...), sealed)
+
+	opened, err := aead.Open(nil, nonce, sealed, ad)
+	require.NoError(t, err)
+	require.Equal(t, pt, opened)
+
+	sealed[0] ^= 1
+	_, err = aead.Open(nil, nonce, sealed, ad)
+	require.Error(t, err)
+}
@@ -0,0 +1,147 @@
+package ecgeneric
+
+import (
+	"math/big"
+	"math/bits"
+	"sync"
+)
+
+// Point is an affine point on a curve. As elsewhere in this package, (0, 0)
+// stands for the point at infinity.
+type Point struct {
+	X, Y *big.Int
+}
+
+// pippengerThreshold is the number of terms below which MultiScalarMult
+// falls back to summing individual scalar multiplications.
+const pippengerThreshold = 4
+
+// MultiScalarMult returns Σ scalars[i]·points[i]. Scalars are reduced modulo
+// N and may be negative or larger than N; the points must be on the curve.
+//
+// It uses Pippenger's bucket method with a window size chosen from the number
+// of terms, so it is much faster than a loop over ScalarMultJ for anything
+// beyond a handful of terms.
+func (curve *CurveParams) MultiScalarMult(points []Point, scalars []*big.Int) (x, y *big.Int) {
+	return curve.MultiScalarMultParallel(points, scalars, 1)
+}
+
+// MultiScalarMultParallel is like MultiScalarMult but spreads the Pippenger
+// windows over up to workers goroutines.
+func (curve *CurveParams) MultiScalarMultParallel(points []Point, scalars []*big.Int, workers int) (x, y *big.Int) {
+	if len(points) != len(scalars) {
+		panic("ecgeneric: MultiScalarMult called with mismatched points and scalars")
+	}
+
+	ps := make([]Point, 0, len(points))
+	ks := make([]*big.Int, 0, len(points))
+	for i := range points {
+		if points[i].X.Sign() == 0 && points[i].Y.Sign() == 0 {
+			continue
+		}
+		k := new(big.Int).Mod(scalars[i], curve.N)
+		if k.Sign() == 0 {
+			continue
+		}
+		ps = append(ps, points[i])
+		ks = append(ks, k)
+	}
+
+	if len(ps) < pippengerThreshold {
+		x, y, z := new(big.Int), new(big.Int), new(big.Int)
+		for i := range ps {
+			px, py := curve.ScalarMultJ(ps[i].X, ps[i].Y, ks[i].Bytes())
+			x, y, z = curve.addJacobian(x, y, z, px, py, zForAffine(px, py))
+		}
+		return curve.affineFromJacobian(x, y, z)
+	}
+
+	c := pippengerWindow(len(ps))
+	windows := (curve.N.BitLen() + c - 1) / c
+	sums := make([][3]*big.Int, windows)
+
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > windows {
+		workers = windows
+	}
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				sx, sy, sz := curve.pippengerWindowSum(ps, ks, i*c, c)
+				sums[i] = [3]*big.Int{sx, sy, sz}
+			}
+		}()
+	}
+	for i := 0; i < windows; i++ {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	// result = Σ 2^(i·c)·sums[i], evaluated from the top window down.
+	x, y, z := new(big.Int), new(big.Int), new(big.Int)
+	for i := windows - 1; i >= 0; i-- {
+		for j := 0; j < c && z.Sign() != 0; j++ {
+			x, y, z = curve.doubleJacobian(x, y, z)
+		}
+		x, y, z = curve.addJacobian(x, y, z, sums[i][0], sums[i][1], sums[i][2])
+	}
+	return curve.affineFromJacobian(x, y, z)
+}
+
+// pippengerWindow picks a window size of roughly log₂(n) - log₂(log₂(n)),
+// which balances the n bucket insertions against the 2^c bucket additions
+// done per window.
+func pippengerWindow(n int) int {
+	l := bits.Len(uint(n))
+	c := l - bits.Len(uint(l)) + 1
+	if c < 2 {
+		c = 2
+	}
+	if c > 16 {
+		c = 16
+	}
+	return c
+}
+
+// pippengerWindowSum returns Σ dᵢ·pointsᵢ in Jacobian coordinates, where dᵢ
+// is the c-bit digit of scalarsᵢ starting at bit offset.
+func (curve *CurveParams) pippengerWindowSum(points []Point, scalars []*big.Int, offset, c int) (*big.Int, *big.Int, *big.Int) {
+	buckets := make([][3]*big.Int, 1<<c)
+	one := big.NewInt(1)
+
+	for i, k := range scalars {
+		d := 0
+		for j := c - 1; j >= 0; j-- {
+			d = d<<1 | int(k.Bit(offset+j))
+		}
+		if d == 0 {
+			continue
+		}
+		b := &buckets[d]
+		if b[0] == nil {
+			*b = [3]*big.Int{points[i].X, points[i].Y, one}
+			continue
+		}
+		b[0], b[1], b[2] = curve.addJacobian(b[0], b[1], b[2], points[i].X, points[i].Y, one)
+	}
+
+	// Σ d·bucket[d] = Σ_d (bucket[top] + ... + bucket[d]).
+	rx, ry, rz := new(big.Int), new(big.Int), new(big.Int)
+	sx, sy, sz := new(big.Int), new(big.Int), new(big.Int)
+	for d := len(buckets) - 1; d > 0; d-- {
+		if b := buckets[d]; b[0] != nil {
+			rx, ry, rz = curve.addJacobian(rx, ry, rz, b[0], b[1], b[2])
+		}
+		if rz.Sign() != 0 {
+			sx, sy, sz = curve.addJacobian(sx, sy, sz, rx, ry, rz)
+		}
+	}
+	return sx, sy, sz
+}
@@ -35,6 +35,23 @@ var	Secp256k1 = ecgeneric.CurveParams{
 	Name:    "secp256k1",
 }
 
+// P256 is NIST P-256 (secp256r1), with a = -3 stored as P - 3.
+var P256 = ecgeneric.CurveParams{
+	P:       ecgeneric.BigFromHex("ffffffff00000001000000000000000000000000ffffffffffffffffffffffff"),
+	N:       ecgeneric.BigFromHex("ffffffff00000000ffffffffffffffffbce6faada7179e84f3b9cac2fc632551"),
+	A:       ecgeneric.BigFromHex("ffffffff00000001000000000000000000000000fffffffffffffffffffffffc"),
+	B:       ecgeneric.BigFromHex("5ac635d8aa3a93e7b3ebbd55769886bc651d06b0cc53b0f63bce3c3e27d2604b"),
+	Gx:      ecgeneric.BigFromHex("6b17d1f2e12c4247f8bce6e563a440f277037d812deb33a0f4a13945d898c296"),
+	Gy:      ecgeneric.BigFromHex("4fe342e2fe1a7f9b8ee7eb4a7c0f9e162bce33576b315ececbb6406837bf51f5"),
+	BitSize: 256,
+	Name:    "P-256",
+}
+
+func init() {
+	ecgeneric.RegisterCurve(&Secp256k1)
+	ecgeneric.RegisterCurve(&P256)
+}
+
 var mask = []byte{0xff, 0x1, 0x3, 0x7, 0xf, 0x1f, 0x3f, 0x7f}
 
 func Hash(m []byte) ([32]byte) {
@@ -64,7 +81,7 @@ func Sign(private_key *big.Int, m []byte, curve *ecgeneric.CurveParams, rand io.
 			continue
 		}
 		kModInv := new(big.Int).ModInverse(new(big.Int).SetBytes(k[:]), curve.N)
-		x, _= curve.ScalarBaseMult(new(big.Int).SetBytes(k[:]))
+		x, _ = curve.ScalarBaseMultGLV(k[:])
 		r.Set(x.Mod(x, curve.N))
 		s.Mul(r, private_key)
 		s.Add(hash, s)
@@ -85,9 +102,7 @@ func Verify(m []byte, r, s, pubX, pubY *big.Int) (bool, error) {
 	u2.Mul(r, w)
 	u2.Mod(u2, Secp256k1.N)
 
-	u1gX, u1gY := Secp256k1.ScalarBaseMult(u1)
-	u2mulPubX, u2mulPubY := Secp256k1.ScalarMultGeneric(pubX, pubY, u2)
-	x, _ := Secp256k1.AddPointsGeneric(u1gX, u1gY, u2mulPubX, u2mulPubY)
+	x, _ := Secp256k1.CombinedMult(pubX, pubY, u1.Bytes(), u2.Bytes())
 
 	if new(big.Int).Mod(r, Secp256k1.N).Cmp(new(big.Int).Mod(x, Secp256k1.N)) == 0 {
 		return true, nil
@@ -96,6 +111,31 @@ func Verify(m []byte, r, s, pubX, pubY *big.Int) (bool, error) {
 	}
 }
 
+func VerifyJ(m []byte, r, s, pubX, pubY *big.Int, curve *ecgeneric.CurveParams) (bool, error) {
+	z := ecgeneric.HashToInt(m, curve)
+	var w, u1, u2 = new(big.Int), new(big.Int), new(big.Int)
+
+	w.ModInverse(s, curve.N)
+	u1.Mul(z, w)
+	u1.Mod(u1, curve.N)
+
+	u2.Mul(r, w)
+	u2.Mod(u2, curve.N)
+
+	u1gX, u1gY := curve.ScalarBaseMultJ(u1.Bytes())
+	u2mulPubX, u2mulPubY := curve.ScalarMultJ(pubX, pubY, u2.Bytes())
+	x, y := curve.AddJ(u1gX, u1gY, u2mulPubX, u2mulPubY)
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return false, nil
+	}
+
+	if new(big.Int).Mod(r, curve.N).Cmp(new(big.Int).Mod(x, curve.N)) == 0 {
+		return true, nil
+	} else {
+		return false, nil
+	}
+}
+
 func Ecrecover(m []byte, r, s, pubX, pubY *big.Int) (*big.Int, *big.Int) {
 	z := new(big.Int).SetBytes(m[:])
 	var w, u1, u2 = new(big.Int), new(big.Int), new(big.Int)
@@ -141,15 +181,11 @@ func Ecrecover(m []byte, r, s, pubX, pubY *big.Int) (*big.Int, *big.Int) {
 	u2.Mul(s, w)
 	u2.Mod(u2, Secp256k1.N)
 
-	u1Gx, u1Gy := Secp256k1.ScalarBaseMult(u1)
-	u2Rx, u2Ry := Secp256k1.ScalarMultGeneric(r, y0, u2)
-
-	Qx, Qy := Secp256k1.AddPointsGeneric(u1Gx, u1Gy, u2Rx, u2Ry)
+	Qx, Qy := Secp256k1.CombinedMult(r, y0, u1.Bytes(), u2.Bytes())
 	if Qx.Cmp(pubX) == 0 && Qy.Cmp(pubY) == 0 {
 			return Qx, Qy
 		} 
-	u2Rx_, u2Ry_ := Secp256k1.ScalarMultGeneric(r, y1, u2)
-	Qx, Qy = Secp256k1.AddPointsGeneric(u1Gx, u1Gy, u2Rx_, u2Ry_)
+	Qx, Qy = Secp256k1.CombinedMult(r, y1, u1.Bytes(), u2.Bytes())
 	if Qx.Cmp(pubX) == 0 && Qy.Cmp(pubY) == 0 {
 			return Qx, Qy
 		} else {
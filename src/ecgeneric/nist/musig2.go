@@ -0,0 +1,485 @@
+package nist
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+)
+
+// BIP-327 MuSig2 multi-signatures over Secp256k1.
+//
+// The signers' plain public keys, 33-byte compressed points, are aggregated
+// into a single x-only key. Signing takes two rounds: every signer publishes a
+// 66-byte public nonce, then, once the aggregate nonce is known, a 32-byte
+// partial signature. The partial signatures sum to an ordinary BIP-340
+// signature that SchnorrVerify accepts. See
+// https://github.com/bitcoin/bips/blob/master/bip-0327.mediawiki.
+
+const (
+	MuSigPubKeyLength     = 33
+	MuSigPubNonceLength   = 66
+	MuSigSecNonceLength   = 97
+	MuSigPartialSigLength = 32
+)
+
+var (
+	errMuSigNoKeys         = errors.New("musig2: no public keys")
+	errMuSigNoNonces       = errors.New("musig2: no public nonces")
+	errMuSigInfinity       = errors.New("musig2: aggregate key is the point at infinity")
+	errMuSigTweak          = errors.New("musig2: tweak must be 32 bytes and less than the group order")
+	errMuSigTweakInfinity  = errors.New("musig2: tweaked key is the point at infinity")
+	errMuSigTweakCount     = errors.New("musig2: tweaks and their x-only flags differ in length")
+	errMuSigPrivateKey     = errors.New("musig2: private key out of range")
+	errMuSigNonceArgs      = errors.New("musig2: malformed nonce generation input")
+	errMuSigNonce          = errors.New("musig2: derived nonce is zero")
+	errMuSigSecNonce       = errors.New("musig2: secret nonce is invalid or was already used")
+	errMuSigSecNonceKey    = errors.New("musig2: secret nonce was generated for a different key")
+	errMuSigSigner         = errors.New("musig2: signer's public key is not in the session")
+	errMuSigSelfCheck      = errors.New("musig2: produced partial signature does not verify")
+	errMuSigSignerOutRange = errors.New("musig2: signer index out of range")
+)
+
+// MuSigContributionError reports a malformed value contributed by one signer,
+// so that the caller can tell who to blame. Signer is -1 when the value is the
+// aggregate nonce, which no single signer contributed.
+type MuSigContributionError struct {
+	Signer  int
+	Contrib string // "pubkey", "pubnonce", "aggnonce" or "psig"
+}
+
+func (e *MuSigContributionError) Error() string {
+	if e.Signer < 0 {
+		return "musig2: invalid " + e.Contrib
+	}
+	return fmt.Sprintf("musig2: invalid %s from signer %d", e.Contrib, e.Signer)
+}
+
+// MuSigKeyAggContext is an aggregate public key together with the sign and
+// tweak accumulated by MuSigKeyAggContext.Tweak.
+type MuSigKeyAggContext struct {
+	q    ecgeneric.Point
+	gacc *big.Int
+	tacc *big.Int
+}
+
+// MuSigKeySort returns the public keys in lexicographic order, which makes the
+// aggregate key independent of the order the signers were listed in.
+func MuSigKeySort(pubKeys [][]byte) [][]byte {
+	out := append([][]byte(nil), pubKeys...)
+	sort.Slice(out, func(i, j int) bool { return bytes.Compare(out[i], out[j]) < 0 })
+	return out
+}
+
+// MuSigKeyAgg aggregates compressed public keys as Σ aᵢ·Pᵢ, where the
+// coefficients aᵢ bind every key to the whole list. The order of pubKeys
+// matters; use MuSigKeySort for a canonical one.
+func MuSigKeyAgg(pubKeys [][]byte) (*MuSigKeyAggContext, error) {
+	if len(pubKeys) == 0 {
+		return nil, errMuSigNoKeys
+	}
+	l := muSigHashKeys(pubKeys)
+	pk2 := muSigSecondKey(pubKeys)
+	points := make([]ecgeneric.Point, len(pubKeys))
+	scalars := make([]*big.Int, len(pubKeys))
+	for i, pk := range pubKeys {
+		p, ok := muSigPoint(pk)
+		if !ok {
+			return nil, &MuSigContributionError{Signer: i, Contrib: "pubkey"}
+		}
+		points[i], scalars[i] = p, muSigKeyAggCoeff(l, pk, pk2)
+	}
+	x, y := Secp256k1.MultiScalarMult(points, scalars)
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return nil, errMuSigInfinity
+	}
+	return &MuSigKeyAggContext{q: ecgeneric.Point{X: x, Y: y}, gacc: big.NewInt(1), tacc: new(big.Int)}, nil
+}
+
+// PubKey returns the 32-byte x-only aggregate key that signatures verify
+// under.
+func (c *MuSigKeyAggContext) PubKey() []byte {
+	return bytes32(c.q.X)
+}
+
+// PlainPubKey returns the aggregate key as a 33-byte compressed point.
+func (c *MuSigKeyAggContext) PlainPubKey() []byte {
+	return ecgeneric.MarshalCompressed(&Secp256k1, c.q.X, c.q.Y)
+}
+
+// Tweak returns the context for the aggregate key plus t·G, as used for
+// BIP-32 derivation (a plain tweak) or a Taproot output key (an x-only tweak,
+// which first negates the key if its y coordinate is odd).
+func (c *MuSigKeyAggContext) Tweak(tweak []byte, xOnly bool) (*MuSigKeyAggContext, error) {
+	N := Secp256k1.N
+	if len(tweak) != 32 {
+		return nil, errMuSigTweak
+	}
+	t := new(big.Int).SetBytes(tweak)
+	if t.Cmp(N) >= 0 {
+		return nil, errMuSigTweak
+	}
+	g := big.NewInt(1)
+	if xOnly && c.q.Y.Bit(0) != 0 {
+		g.Sub(N, g)
+	}
+	x, y := Secp256k1.MultiScalarMult(
+		[]ecgeneric.Point{c.q, {X: Secp256k1.Gx, Y: Secp256k1.Gy}},
+		[]*big.Int{g, t},
+	)
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return nil, errMuSigTweakInfinity
+	}
+	gacc := new(big.Int).Mul(g, c.gacc)
+	tacc := new(big.Int).Mul(g, c.tacc)
+	tacc.Add(tacc, t)
+	return &MuSigKeyAggContext{
+		q:    ecgeneric.Point{X: x, Y: y},
+		gacc: gacc.Mod(gacc, N),
+		tacc: tacc.Mod(tacc, N),
+	}, nil
+}
+
+// MuSigNonceGen generates a signer's nonces for one signing session. It
+// returns the secret nonce, which stays with the signer until
+// MuSigSession.Sign, and the public nonce for the other signers. Only pubKey,
+// the signer's compressed public key, is required. The private key d, the
+// x-only aggregate key, the message and extraIn may be nil; whatever is known
+// is mixed in as a safeguard against a weak rand. A nil msg and an empty one
+// are different inputs.
+//
+// A secret nonce must never be used for two signatures.
+func MuSigNonceGen(d *big.Int, pubKey, aggPubKey, msg, extraIn []byte, rand io.Reader) (secNonce, pubNonce []byte, err error) {
+	N := Secp256k1.N
+	if len(pubKey) != MuSigPubKeyLength || (aggPubKey != nil && len(aggPubKey) != SchnorrPubKeyLength) || uint64(len(extraIn)) > 1<<32-1 {
+		return nil, nil, errMuSigNonceArgs
+	}
+	r := make([]byte, 32)
+	if _, err := io.ReadFull(rand, r); err != nil {
+		return nil, nil, err
+	}
+	if d != nil {
+		if d.Sign() <= 0 || d.Cmp(N) >= 0 {
+			return nil, nil, errMuSigPrivateKey
+		}
+		h := TaggedHash("MuSig/aux", r)
+		db := bytes32(d)
+		for i := range r {
+			r[i] = db[i] ^ h[i]
+		}
+	}
+	msgPrefixed := []byte{0}
+	if msg != nil {
+		msgPrefixed = make([]byte, 9, 9+len(msg))
+		msgPrefixed[0] = 1
+		binary.BigEndian.PutUint64(msgPrefixed[1:], uint64(len(msg)))
+		msgPrefixed = append(msgPrefixed, msg...)
+	}
+	extraLen := make([]byte, 4)
+	binary.BigEndian.PutUint32(extraLen, uint32(len(extraIn)))
+
+	secNonce = make([]byte, 0, MuSigSecNonceLength)
+	pubNonce = make([]byte, 0, MuSigPubNonceLength)
+	for i := 0; i < 2; i++ {
+		h := TaggedHash("MuSig/nonce", r, []byte{byte(len(pubKey))}, pubKey,
+			[]byte{byte(len(aggPubKey))}, aggPubKey, msgPrefixed, extraLen, extraIn, []byte{byte(i)})
+		k := new(big.Int).SetBytes(h[:])
+		k.Mod(k, N)
+		if k.Sign() == 0 {
+			return nil, nil, errMuSigNonce
+		}
+		secNonce = append(secNonce, bytes32(k)...)
+		x, y := Secp256k1.ScalarBaseMultJ(k.Bytes())
+		pubNonce = append(pubNonce, ecgeneric.MarshalCompressed(&Secp256k1, x, y)...)
+	}
+	return append(secNonce, pubKey...), pubNonce, nil
+}
+
+// MuSigNonceAgg sums the public nonces of all signers into the aggregate
+// nonce. Either half of the result may be the point at infinity, encoded as
+// 33 zero bytes.
+func MuSigNonceAgg(pubNonces [][]byte) ([]byte, error) {
+	if len(pubNonces) == 0 {
+		return nil, errMuSigNoNonces
+	}
+	one := big.NewInt(1)
+	agg := make([]byte, 0, MuSigPubNonceLength)
+	for j := 0; j < 2; j++ {
+		points := make([]ecgeneric.Point, len(pubNonces))
+		scalars := make([]*big.Int, len(pubNonces))
+		for i, n := range pubNonces {
+			if len(n) != MuSigPubNonceLength {
+				return nil, &MuSigContributionError{Signer: i, Contrib: "pubnonce"}
+			}
+			p, ok := muSigPoint(n[33*j : 33*(j+1)])
+			if !ok {
+				return nil, &MuSigContributionError{Signer: i, Contrib: "pubnonce"}
+			}
+			points[i], scalars[i] = p, one
+		}
+		x, y := Secp256k1.MultiScalarMult(points, scalars)
+		agg = append(agg, muSigPointBytesExt(x, y)...)
+	}
+	return agg, nil
+}
+
+// MuSigSession is what the signers must agree on before creating partial
+// signatures: the aggregate nonce, the public keys in the order given to
+// MuSigKeyAgg, the tweaks applied to the aggregate key in order, and the
+// message.
+type MuSigSession struct {
+	AggNonce []byte
+	PubKeys  [][]byte
+	Tweaks   [][]byte
+	IsXOnly  []bool
+	Msg      []byte
+}
+
+// muSigValues are the values derived from a session that signing,
+// verification and aggregation need.
+type muSigValues struct {
+	q    ecgeneric.Point
+	gacc *big.Int
+	tacc *big.Int
+	b    *big.Int
+	r    ecgeneric.Point
+	e    *big.Int
+}
+
+func (s *MuSigSession) values() (*muSigValues, error) {
+	if len(s.Tweaks) != len(s.IsXOnly) {
+		return nil, errMuSigTweakCount
+	}
+	ctx, err := MuSigKeyAgg(s.PubKeys)
+	if err != nil {
+		return nil, err
+	}
+	for i, t := range s.Tweaks {
+		if ctx, err = ctx.Tweak(t, s.IsXOnly[i]); err != nil {
+			return nil, err
+		}
+	}
+	errNonce := &MuSigContributionError{Signer: -1, Contrib: "aggnonce"}
+	if len(s.AggNonce) != MuSigPubNonceLength {
+		return nil, errNonce
+	}
+	r1, ok1 := muSigPointExt(s.AggNonce[:33])
+	r2, ok2 := muSigPointExt(s.AggNonce[33:])
+	if !ok1 || !ok2 {
+		return nil, errNonce
+	}
+	qx := bytes32(ctx.q.X)
+	h := TaggedHash("MuSig/noncecoef", s.AggNonce, qx, s.Msg)
+	b := new(big.Int).SetBytes(h[:])
+	b.Mod(b, Secp256k1.N)
+
+	// R = R₁ + b·R₂, replaced by G if it is the point at infinity.
+	x, y := Secp256k1.MultiScalarMult([]ecgeneric.Point{r1, r2}, []*big.Int{big.NewInt(1), b})
+	if x.Sign() == 0 && y.Sign() == 0 {
+		x, y = Secp256k1.Gx, Secp256k1.Gy
+	}
+	return &muSigValues{
+		q:    ctx.q,
+		gacc: ctx.gacc,
+		tacc: ctx.tacc,
+		b:    b,
+		r:    ecgeneric.Point{X: x, Y: y},
+		e:    schnorrChallenge(bytes32(x), qx, s.Msg),
+	}, nil
+}
+
+// keyAggCoeff returns the key aggregation coefficient of pk in the session.
+func (s *MuSigSession) keyAggCoeff(pk []byte) (*big.Int, error) {
+	for _, p := range s.PubKeys {
+		if bytes.Equal(p, pk) {
+			return muSigKeyAggCoeff(muSigHashKeys(s.PubKeys), pk, muSigSecondKey(s.PubKeys)), nil
+		}
+	}
+	return nil, errMuSigSigner
+}
+
+// Sign creates the signer's partial signature with the private key d and the
+// secret nonce from MuSigNonceGen. It zeroes the secret nonce, so a second
+// call with the same one fails.
+func (s *MuSigSession) Sign(secNonce []byte, d *big.Int) ([]byte, error) {
+	N := Secp256k1.N
+	v, err := s.values()
+	if err != nil {
+		return nil, err
+	}
+	if len(secNonce) != MuSigSecNonceLength {
+		return nil, errMuSigSecNonce
+	}
+	k1 := new(big.Int).SetBytes(secNonce[:32])
+	k2 := new(big.Int).SetBytes(secNonce[32:64])
+	for i := range secNonce[:64] {
+		secNonce[i] = 0
+	}
+	if k1.Sign() == 0 || k1.Cmp(N) >= 0 || k2.Sign() == 0 || k2.Cmp(N) >= 0 {
+		return nil, errMuSigSecNonce
+	}
+	if d.Sign() <= 0 || d.Cmp(N) >= 0 {
+		return nil, errMuSigPrivateKey
+	}
+	Px, Py := Secp256k1.ScalarBaseMultJ(d.Bytes())
+	pk := ecgeneric.MarshalCompressed(&Secp256k1, Px, Py)
+	if !bytes.Equal(pk, secNonce[64:]) {
+		return nil, errMuSigSecNonceKey
+	}
+	a, err := s.keyAggCoeff(pk)
+	if err != nil {
+		return nil, err
+	}
+	R1x, R1y := Secp256k1.ScalarBaseMultJ(k1.Bytes())
+	R2x, R2y := Secp256k1.ScalarBaseMultJ(k2.Bytes())
+	pubNonce := append(ecgeneric.MarshalCompressed(&Secp256k1, R1x, R1y),
+		ecgeneric.MarshalCompressed(&Secp256k1, R2x, R2y)...)
+
+	// s = k₁ + b·k₂ + e·a·d, with the nonces negated if R has an odd y and
+	// d negated so that it matches the even-y aggregate key.
+	k1, k2 = evenY(k1, v.r.Y), evenY(k2, v.r.Y)
+	dd := new(big.Int).Mul(evenY(d, v.q.Y), v.gacc)
+	sig := dd.Mul(dd, a)
+	sig.Mul(sig, v.e)
+	sig.Add(sig, k2.Mul(k2, v.b))
+	sig.Add(sig, k1)
+	psig := bytes32(sig.Mod(sig, N))
+
+	if ok, err := s.partialSigVerify(v, psig, pubNonce, pk, -1); err != nil || !ok {
+		return nil, errMuSigSelfCheck
+	}
+	return psig, nil
+}
+
+// PartialSigVerify reports whether psig is a valid partial signature from the
+// signer at index i of PubKeys, whose public nonce is pubNonce. It returns an
+// error if the session or the nonce is malformed.
+func (s *MuSigSession) PartialSigVerify(psig, pubNonce []byte, i int) (bool, error) {
+	if i < 0 || i >= len(s.PubKeys) {
+		return false, errMuSigSignerOutRange
+	}
+	v, err := s.values()
+	if err != nil {
+		return false, err
+	}
+	return s.partialSigVerify(v, psig, pubNonce, s.PubKeys[i], i)
+}
+
+func (s *MuSigSession) partialSigVerify(v *muSigValues, psig, pubNonce, pk []byte, i int) (bool, error) {
+	N := Secp256k1.N
+	if len(psig) != MuSigPartialSigLength {
+		return false, nil
+	}
+	sig := new(big.Int).SetBytes(psig)
+	if sig.Cmp(N) >= 0 {
+		return false, nil
+	}
+	if len(pubNonce) != MuSigPubNonceLength {
+		return false, &MuSigContributionError{Signer: i, Contrib: "pubnonce"}
+	}
+	r1, ok1 := muSigPoint(pubNonce[:33])
+	r2, ok2 := muSigPoint(pubNonce[33:])
+	if !ok1 || !ok2 {
+		return false, &MuSigContributionError{Signer: i, Contrib: "pubnonce"}
+	}
+	P, ok := muSigPoint(pk)
+	if !ok {
+		return false, &MuSigContributionError{Signer: i, Contrib: "pubkey"}
+	}
+	a, err := s.keyAggCoeff(pk)
+	if err != nil {
+		return false, err
+	}
+
+	// s·G = ±(R₁ + b·R₂) + e·a·g·gacc·P, checked as a sum that must vanish.
+	ne := evenY(big.NewInt(1), v.r.Y)
+	c := new(big.Int).Mul(v.e, a)
+	c.Mul(c, evenY(v.gacc, v.q.Y))
+	x, y := Secp256k1.MultiScalarMult(
+		[]ecgeneric.Point{r1, r2, P, {X: Secp256k1.Gx, Y: Secp256k1.Gy}},
+		[]*big.Int{ne, new(big.Int).Mul(ne, v.b), c.Mod(c, N), sig.Sub(N, sig)},
+	)
+	return x.Sign() == 0 && y.Sign() == 0, nil
+}
+
+// PartialSigAgg sums the partial signatures of all signers into a BIP-340
+// signature under the (tweaked) aggregate key. Partial signatures are not
+// checked individually; use PartialSigVerify to find a faulty signer if the
+// result does not verify.
+func (s *MuSigSession) PartialSigAgg(psigs [][]byte) ([]byte, error) {
+	N := Secp256k1.N
+	v, err := s.values()
+	if err != nil {
+		return nil, err
+	}
+	sum := new(big.Int)
+	for i, p := range psigs {
+		si := new(big.Int).SetBytes(p)
+		if len(p) != MuSigPartialSigLength || si.Cmp(N) >= 0 {
+			return nil, &MuSigContributionError{Signer: i, Contrib: "psig"}
+		}
+		sum.Add(sum, si)
+	}
+	t := new(big.Int).Mul(v.e, evenY(v.tacc, v.q.Y))
+	sum.Add(sum, t)
+	sum.Mod(sum, N)
+
+	sig := make([]byte, SchnorrSignatureLength)
+	v.r.X.FillBytes(sig[:32])
+	sum.FillBytes(sig[32:])
+	return sig, nil
+}
+
+func muSigHashKeys(pubKeys [][]byte) []byte {
+	h := TaggedHash("KeyAgg list", pubKeys...)
+	return h[:]
+}
+
+// muSigSecondKey returns the first key that differs from the first one, or
+// 33 zero bytes. Its coefficient is 1, which saves a scalar multiplication.
+func muSigSecondKey(pubKeys [][]byte) []byte {
+	for _, pk := range pubKeys[1:] {
+		if !bytes.Equal(pk, pubKeys[0]) {
+			return pk
+		}
+	}
+	return make([]byte, MuSigPubKeyLength)
+}
+
+func muSigKeyAggCoeff(l, pk, pk2 []byte) *big.Int {
+	if bytes.Equal(pk, pk2) {
+		return big.NewInt(1)
+	}
+	h := TaggedHash("KeyAgg coefficient", l, pk)
+	a := new(big.Int).SetBytes(h[:])
+	return a.Mod(a, Secp256k1.N)
+}
+
+func muSigPoint(b []byte) (ecgeneric.Point, bool) {
+	x, y := ecgeneric.UnmarshalCompressed(&Secp256k1, b)
+	if x == nil {
+		return ecgeneric.Point{}, false
+	}
+	return ecgeneric.Point{X: x, Y: y}, true
+}
+
+// muSigPointExt is muSigPoint that also accepts 33 zero bytes as the point
+// at infinity.
+func muSigPointExt(b []byte) (ecgeneric.Point, bool) {
+	if bytes.Equal(b, make([]byte, 33)) {
+		return ecgeneric.Point{X: new(big.Int), Y: new(big.Int)}, true
+	}
+	return muSigPoint(b)
+}
+
+func muSigPointBytesExt(x, y *big.Int) []byte {
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return make([]byte, 33)
+	}
+	return ecgeneric.MarshalCompressed(&Secp256k1, x, y)
+}
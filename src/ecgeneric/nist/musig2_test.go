@@ -0,0 +1,527 @@
+package nist_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/nist"
+	"github.com/stretchr/testify/require"
+)
+
+// Vectors from bip-0327/vectors/*.json.
+
+func hexList(t *testing.T, ss ...string) [][]byte {
+	out := make([][]byte, len(ss))
+	for i, s := range ss {
+		out[i] = mustHex(t, s)
+	}
+	return out
+}
+
+func pick(list [][]byte, indices ...int) [][]byte {
+	out := make([][]byte, len(indices))
+	for i, j := range indices {
+		out[i] = list[j]
+	}
+	return out
+}
+
+// optHex decodes s, treating "null" as an absent input.
+func optHex(t *testing.T, s string) []byte {
+	if s == "null" {
+		return nil
+	}
+	return append([]byte{}, mustHex(t, s)...)
+}
+
+func requireContribution(t *testing.T, err error, signer int, contrib string, msgAndArgs ...interface{}) {
+	var ce *nist.MuSigContributionError
+	require.True(t, errors.As(err, &ce), msgAndArgs...)
+	require.Equal(t, signer, ce.Signer, msgAndArgs...)
+	require.Equal(t, contrib, ce.Contrib, msgAndArgs...)
+}
+
+func TestMuSigKeySort(t *testing.T) {
+	pubKeys := hexList(t,
+		"02DD308AFEC5777E13121FA72B9CC1B7CC0139715309B086C960E18FD969774EB8",
+		"02F9308A019258C31049344F85F89D5229B531C845836F99B08601F113BCE036F9",
+		"03DFF1D77F2A671C5F36183726DB2341BE58FEAE1DA2DECED843240F7B502BA659",
+		"023590A94E768F8E1815C2F24B4D80A8E3149316C3518CE7B7AD338368D038CA66",
+		"02DD308AFEC5777E13121FA72B9CC1B7CC0139715309B086C960E18FD969774EB8",
+	)
+	sorted := hexList(t,
+		"023590A94E768F8E1815C2F24B4D80A8E3149316C3518CE7B7AD338368D038CA66",
+		"02DD308AFEC5777E13121FA72B9CC1B7CC0139715309B086C960E18FD969774EB8",
+		"02DD308AFEC5777E13121FA72B9CC1B7CC0139715309B086C960E18FD969774EB8",
+		"02F9308A019258C31049344F85F89D5229B531C845836F99B08601F113BCE036F9",
+		"03DFF1D77F2A671C5F36183726DB2341BE58FEAE1DA2DECED843240F7B502BA659",
+	)
+	require.Equal(t, sorted, nist.MuSigKeySort(pubKeys))
+	require.Equal(t, mustHex(t, "02DD308AFEC5777E13121FA72B9CC1B7CC0139715309B086C960E18FD969774EB8"), pubKeys[0])
+}
+
+func TestMuSigKeyAggVectors(t *testing.T) {
+	pubKeys := hexList(t,
+		"02F9308A019258C31049344F85F89D5229B531C845836F99B08601F113BCE036F9",
+		"03DFF1D77F2A671C5F36183726DB2341BE58FEAE1DA2DECED843240F7B502BA659",
+		"023590A94E768F8E1815C2F24B4D80A8E3149316C3518CE7B7AD338368D038CA66",
+		"020000000000000000000000000000000000000000000000000000000000000005",
+		"02FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEFFFFFC30",
+		"04F9308A019258C31049344F85F89D5229B531C845836F99B08601F113BCE036F9",
+		"03935F972DA013F80AE011890FA89B67A27B7BE6CCB24D3274D18B2D4067F261A9",
+	)
+	tweaks := hexList(t,
+		"FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEBAAEDCE6AF48A03BBFD25E8CD0364141",
+		"252E4BD67410A76CDF933D30EAA1608214037F1B105A013ECCD3C5C184A6110B",
+	)
+
+	valid := []struct {
+		keys     []int
+		expected string
+	}{
+		{[]int{0, 1, 2}, "90539EEDE565F5D054F32CC0C220126889ED1E5D193BAF15AEF344FE59D4610C"},
+		{[]int{2, 1, 0}, "6204DE8B083426DC6EAF9502D27024D53FC826BF7D2012148A0575435DF54B2B"},
+		{[]int{0, 0, 0}, "B436E3BAD62B8CD409969A224731C193D051162D8C5AE8B109306127DA3AA935"},
+		{[]int{0, 0, 1, 1}, "69BC22BFA5D106306E48A20679DE1D7389386124D07571D0D872686028C26A3E"},
+	}
+	for i, v := range valid {
+		ctx, err := nist.MuSigKeyAgg(pick(pubKeys, v.keys...))
+		require.NoError(t, err, "vector %d", i)
+		require.Equal(t, mustHex(t, v.expected), ctx.PubKey(), "vector %d", i)
+	}
+
+	// Invalid public keys are blamed on their signer.
+	for i, v := range []struct {
+		keys   []int
+		signer int
+	}{
+		{[]int{0, 3}, 1}, // not on the curve
+		{[]int{0, 4}, 1}, // exceeds the field size
+		{[]int{5, 0}, 0}, // first byte is not 2 or 3
+	} {
+		_, err := nist.MuSigKeyAgg(pick(pubKeys, v.keys...))
+		requireContribution(t, err, v.signer, "pubkey", "error vector %d", i)
+	}
+
+	// Tweak out of range.
+	ctx, err := nist.MuSigKeyAgg(pick(pubKeys, 0, 1))
+	require.NoError(t, err)
+	_, err = ctx.Tweak(tweaks[0], true)
+	require.Error(t, err)
+
+	// The intermediate tweaking result is the point at infinity.
+	ctx, err = nist.MuSigKeyAgg(pick(pubKeys, 6))
+	require.NoError(t, err)
+	_, err = ctx.Tweak(tweaks[1], false)
+	require.Error(t, err)
+}
+
+func TestMuSigNonceGenVectors(t *testing.T) {
+	vectors := []struct {
+		sk, pk, aggPK, msg, extraIn, expected string
+	}{
+		{
+			"0202020202020202020202020202020202020202020202020202020202020202",
+			"024D4B6CD1361032CA9BD2AEB9D900AA4D45D9EAD80AC9423374C451A7254D0766",
+			"0707070707070707070707070707070707070707070707070707070707070707",
+			"0101010101010101010101010101010101010101010101010101010101010101",
+			"0808080808080808080808080808080808080808080808080808080808080808",
+			"227243DCB40EF2A13A981DB188FA433717B506BDFA14B1AE47D5DC027C9C3B9EF2370B2AD206E724243215137C86365699361126991E6FEC816845F837BDDAC3024D4B6CD1361032CA9BD2AEB9D900AA4D45D9EAD80AC9423374C451A7254D0766",
+		},
+		{
+			"0202020202020202020202020202020202020202020202020202020202020202",
+			"024D4B6CD1361032CA9BD2AEB9D900AA4D45D9EAD80AC9423374C451A7254D0766",
+			"0707070707070707070707070707070707070707070707070707070707070707",
+			"",
+			"0808080808080808080808080808080808080808080808080808080808080808",
+			"CD0F47FE471D6788FF3243F47345EA0A179AEF69476BE8348322EF39C2723318870C2065AFB52DEDF02BF4FDBF6D2F442E608692F50C2374C08FFFE57042A61C024D4B6CD1361032CA9BD2AEB9D900AA4D45D9EAD80AC9423374C451A7254D0766",
+		},
+		{
+			"0202020202020202020202020202020202020202020202020202020202020202",
+			"024D4B6CD1361032CA9BD2AEB9D900AA4D45D9EAD80AC9423374C451A7254D0766",
+			"0707070707070707070707070707070707070707070707070707070707070707",
+			"2626262626262626262626262626262626262626262626262626262626262626262626262626",
+			"0808080808080808080808080808080808080808080808080808080808080808",
+			"011F8BC60EF061DEEF4D72A0A87200D9994B3F0CD9867910085C38D5366E3E6B9FF03BC0124E56B24069E91EC3F162378983F194E8BD0ED89BE3059649EAE262024D4B6CD1361032CA9BD2AEB9D900AA4D45D9EAD80AC9423374C451A7254D0766",
+		},
+		{
+			"null",
+			"02F9308A019258C31049344F85F89D5229B531C845836F99B08601F113BCE036F9",
+			"null",
+			"null",
+			"null",
+			"890E83616A3BC4640AB9B6374F21C81FF89CDDDBAFAA7475AE2A102A92E3EDB29FD7E874E23342813A60D9646948242646B7951CA046B4B36D7D6078506D3C9402F9308A019258C31049344F85F89D5229B531C845836F99B08601F113BCE036F9",
+		},
+	}
+	for i, v := range vectors {
+		var d *big.Int
+		if sk := optHex(t, v.sk); sk != nil {
+			d = new(big.Int).SetBytes(sk)
+		}
+		// All test vectors use an all-zero rand'.
+		zero := bytes.NewReader(make([]byte, 32))
+		sec, pub, err := nist.MuSigNonceGen(d, mustHex(t, v.pk), optHex(t, v.aggPK), optHex(t, v.msg), optHex(t, v.extraIn), zero)
+		require.NoError(t, err, "vector %d", i)
+		require.Equal(t, mustHex(t, v.expected), sec, "vector %d", i)
+		require.Len(t, pub, nist.MuSigPubNonceLength)
+	}
+}
+
+func TestMuSigNonceAggVectors(t *testing.T) {
+	pubNonces := hexList(t,
+		"020151C80F435648DF67A22B749CD798CE54E0321D034B92B709B567D60A42E66603BA47FBC1834437B3212E89A84D8425E7BF12E0245D98262268EBDCB385D50641",
+		"03FF406FFD8ADB9CD29877E4985014F66A59F6CD01C0E88CAA8E5F3166B1F676A60248C264CDD57D3C24D79990B0F865674EB62A0F9018277A95011B41BFC193B833",
+		"020151C80F435648DF67A22B749CD798CE54E0321D034B92B709B567D60A42E6660279BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798",
+		"03FF406FFD8ADB9CD29877E4985014F66A59F6CD01C0E88CAA8E5F3166B1F676A60379BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798",
+		"04FF406FFD8ADB9CD29877E4985014F66A59F6CD01C0E88CAA8E5F3166B1F676A60248C264CDD57D3C24D79990B0F865674EB62A0F9018277A95011B41BFC193B833",
+		"03FF406FFD8ADB9CD29877E4985014F66A59F6CD01C0E88CAA8E5F3166B1F676A60248C264CDD57D3C24D79990B0F865674EB62A0F9018277A95011B41BFC193B831",
+		"03FF406FFD8ADB9CD29877E4985014F66A59F6CD01C0E88CAA8E5F3166B1F676A602FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEFFFFFC30",
+	)
+	agg, err := nist.MuSigNonceAgg(pick(pubNonces, 0, 1))
+	require.NoError(t, err)
+	require.Equal(t, mustHex(t, "035FE1873B4F2967F52FEA4A06AD5A8ECCBE9D0FD73068012C894E2E87CCB5804B024725377345BDE0E9C33AF3C43C0A29A9249F2F2956FA8CFEB55C8573D0262DC8"), agg)
+
+	// The second halves sum to the point at infinity.
+	agg, err = nist.MuSigNonceAgg(pick(pubNonces, 2, 3))
+	require.NoError(t, err)
+	require.Equal(t, mustHex(t, "035FE1873B4F2967F52FEA4A06AD5A8ECCBE9D0FD73068012C894E2E87CCB5804B000000000000000000000000000000000000000000000000000000000000000000"), agg)
+
+	for i, v := range []struct {
+		nonces []int
+		signer int
+	}{
+		{[]int{0, 4}, 1}, // wrong tag in the first half
+		{[]int{5, 1}, 0}, // second half is not an x coordinate
+		{[]int{6, 1}, 0}, // second half exceeds the field size
+	} {
+		_, err := nist.MuSigNonceAgg(pick(pubNonces, v.nonces...))
+		requireContribution(t, err, v.signer, "pubnonce", "error vector %d", i)
+	}
+}
+
+func TestMuSigSignVerifyVectors(t *testing.T) {
+	sk := new(big.Int).SetBytes(mustHex(t, "7FB9E0E687ADA1EEBF7ECFE2F21E73EBDB51A7D450948DFE8D76D7F2D1007671"))
+	pubKeys := hexList(t,
+		"03935F972DA013F80AE011890FA89B67A27B7BE6CCB24D3274D18B2D4067F261A9",
+		"02F9308A019258C31049344F85F89D5229B531C845836F99B08601F113BCE036F9",
+		"02DFF1D77F2A671C5F36183726DB2341BE58FEAE1DA2DECED843240F7B502BA661",
+		"020000000000000000000000000000000000000000000000000000000000000007",
+	)
+	secNonces := hexList(t,
+		"508B81A611F100A6B2B6B29656590898AF488BCF2E1F55CF22E5CFB84421FE61FA27FD49B1D50085B481285E1CA205D55C82CC1B31FF5CD54A489829355901F703935F972DA013F80AE011890FA89B67A27B7BE6CCB24D3274D18B2D4067F261A9",
+		"0000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000003935F972DA013F80AE011890FA89B67A27B7BE6CCB24D3274D18B2D4067F261A9",
+	)
+	pubNonces := hexList(t,
+		"0337C87821AFD50A8644D820A8F3E02E499C931865C2360FB43D0A0D20DAFE07EA0287BF891D2A6DEAEBADC909352AA9405D1428C15F4B75F04DAE642A95C2548480",
+		"0279BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F817980279BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798",
+		"032DE2662628C90B03F5E720284EB52FF7D71F4284F627B68A853D78C78E1FFE9303E4C5524E83FFE1493B9077CF1CA6BEB2090C93D930321071AD40B2F44E599046",
+		"0237C87821AFD50A8644D820A8F3E02E499C931865C2360FB43D0A0D20DAFE07EA0387BF891D2A6DEAEBADC909352AA9405D1428C15F4B75F04DAE642A95C2548480",
+		"020000000000000000000000000000000000000000000000000000000000000009",
+	)
+	aggNonces := hexList(t,
+		"028465FCF0BBDBCF443AABCCE533D42B4B5A10966AC09A49655E8C42DAAB8FCD61037496A3CC86926D452CAFCFD55D25972CA1675D549310DE296BFF42F72EEEA8C9",
+		"000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+		"048465FCF0BBDBCF443AABCCE533D42B4B5A10966AC09A49655E8C42DAAB8FCD61037496A3CC86926D452CAFCFD55D25972CA1675D549310DE296BFF42F72EEEA8C9",
+		"028465FCF0BBDBCF443AABCCE533D42B4B5A10966AC09A49655E8C42DAAB8FCD61020000000000000000000000000000000000000000000000000000000000000009",
+		"028465FCF0BBDBCF443AABCCE533D42B4B5A10966AC09A49655E8C42DAAB8FCD6102FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEFFFFFC30",
+	)
+	msgs := hexList(t,
+		"F95466D086770E689964664219266FE5ED215C92AE20BAB5C9D79ADDDDF3C0CF",
+		"",
+		"2626262626262626262626262626262626262626262626262626262626262626262626262626",
+	)
+	secNonce := func(i int) []byte { return append([]byte{}, secNonces[i]...) }
+
+	valid := []struct {
+		keys, nonces       []int
+		aggNonce, msg, idx int
+		expected           string
+	}{
+		{[]int{0, 1, 2}, []int{0, 1, 2}, 0, 0, 0, "012ABBCB52B3016AC03AD82395A1A415C48B93DEF78718E62A7A90052FE224FB"},
+		{[]int{1, 0, 2}, []int{1, 0, 2}, 0, 0, 1, "9FF2F7AAA856150CC8819254218D3ADEEB0535269051897724F9DB3789513A52"},
+		{[]int{1, 2, 0}, []int{1, 2, 0}, 0, 0, 2, "FA23C359F6FAC4E7796BB93BC9F0532A95468C539BA20FF86D7C76ED92227900"},
+		// Both halves of the aggregate nonce are the point at infinity.
+		{[]int{0, 1}, []int{0, 3}, 1, 0, 0, "AE386064B26105404798F75DE2EB9AF5EDA5387B064B83D049CB7C5E08879531"},
+	}
+	for i, v := range valid {
+		agg, err := nist.MuSigNonceAgg(pick(pubNonces, v.nonces...))
+		require.NoError(t, err, "vector %d", i)
+		require.Equal(t, aggNonces[v.aggNonce], agg, "vector %d", i)
+
+		s := &nist.MuSigSession{AggNonce: agg, PubKeys: pick(pubKeys, v.keys...), Msg: msgs[v.msg]}
+		sn := secNonce(0)
+		psig, err := s.Sign(sn, sk)
+		require.NoError(t, err, "vector %d", i)
+		require.Equal(t, mustHex(t, v.expected), psig, "vector %d", i)
+
+		ok, err := s.PartialSigVerify(psig, pubNonces[v.nonces[v.idx]], v.idx)
+		require.NoError(t, err, "vector %d", i)
+		require.True(t, ok, "vector %d", i)
+
+		// The secret nonce is consumed.
+		_, err = s.Sign(sn, sk)
+		require.Error(t, err, "vector %d", i)
+	}
+
+	signErrors := []struct {
+		keys               []int
+		aggNonce, secNonce int
+		signer             int
+		contrib            string
+	}{
+		{[]int{1, 2}, 0, 0, 0, ""},             // signer's key is not in the list
+		{[]int{1, 0, 3}, 0, 0, 2, "pubkey"},    // signer 2 has an invalid key
+		{[]int{1, 2, 0}, 2, 0, -1, "aggnonce"}, // wrong tag in the first half
+		{[]int{1, 2, 0}, 3, 0, -1, "aggnonce"}, // second half is not an x coordinate
+		{[]int{1, 2, 0}, 4, 0, -1, "aggnonce"}, // second half exceeds the field size
+		{[]int{0, 1, 2}, 0, 1, 0, ""},          // zeroed secret nonce, as after a use
+	}
+	for i, v := range signErrors {
+		s := &nist.MuSigSession{AggNonce: aggNonces[v.aggNonce], PubKeys: pick(pubKeys, v.keys...), Msg: msgs[0]}
+		_, err := s.Sign(secNonce(v.secNonce), sk)
+		if v.contrib == "" {
+			require.Error(t, err, "sign error vector %d", i)
+			continue
+		}
+		requireContribution(t, err, v.signer, v.contrib, "sign error vector %d", i)
+	}
+
+	verifyFails := []struct {
+		sig string
+		idx int
+	}{
+		// The negation of a valid signature.
+		{"97AC833ADCB1AFA42EBF9E0725616F3C9A0D5B614F6FE283CEAAA37A8FFAF406", 0},
+		// The wrong signer.
+		{"68537CC5234E505BD14061F8DA9E90C220A181855FD8BDB7F127BB12403B4D3B", 1},
+		// Exceeds the group order.
+		{"FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEBAAEDCE6AF48A03BBFD25E8CD0364141", 0},
+	}
+	for i, v := range verifyFails {
+		nonces := pick(pubNonces, 0, 1, 2)
+		agg, err := nist.MuSigNonceAgg(nonces)
+		require.NoError(t, err)
+		s := &nist.MuSigSession{AggNonce: agg, PubKeys: pick(pubKeys, 0, 1, 2), Msg: msgs[0]}
+		ok, err := s.PartialSigVerify(mustHex(t, v.sig), nonces[v.idx], v.idx)
+		require.NoError(t, err, "verify fail vector %d", i)
+		require.False(t, ok, "verify fail vector %d", i)
+	}
+
+	// An invalid public nonce is caught when aggregating the nonces.
+	_, err := nist.MuSigNonceAgg(pick(pubNonces, 4, 1, 2))
+	requireContribution(t, err, 0, "pubnonce")
+
+	// An invalid public key is caught when aggregating the keys.
+	s := &nist.MuSigSession{AggNonce: aggNonces[0], PubKeys: pick(pubKeys, 3, 1, 2), Msg: msgs[0]}
+	_, err = s.PartialSigVerify(mustHex(t, "68537CC5234E505BD14061F8DA9E90C220A181855FD8BDB7F127BB12403B4D3B"), pubNonces[0], 0)
+	requireContribution(t, err, 0, "pubkey")
+}
+
+func TestMuSigTweakVectors(t *testing.T) {
+	sk := new(big.Int).SetBytes(mustHex(t, "7FB9E0E687ADA1EEBF7ECFE2F21E73EBDB51A7D450948DFE8D76D7F2D1007671"))
+	pubKeys := hexList(t,
+		"03935F972DA013F80AE011890FA89B67A27B7BE6CCB24D3274D18B2D4067F261A9",
+		"02F9308A019258C31049344F85F89D5229B531C845836F99B08601F113BCE036F9",
+		"02DFF1D77F2A671C5F36183726DB2341BE58FEAE1DA2DECED843240F7B502BA659",
+	)
+	secNonce := mustHex(t, "508B81A611F100A6B2B6B29656590898AF488BCF2E1F55CF22E5CFB84421FE61FA27FD49B1D50085B481285E1CA205D55C82CC1B31FF5CD54A489829355901F703935F972DA013F80AE011890FA89B67A27B7BE6CCB24D3274D18B2D4067F261A9")
+	pubNonces := hexList(t,
+		"0337C87821AFD50A8644D820A8F3E02E499C931865C2360FB43D0A0D20DAFE07EA0287BF891D2A6DEAEBADC909352AA9405D1428C15F4B75F04DAE642A95C2548480",
+		"0279BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F817980279BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798",
+		"032DE2662628C90B03F5E720284EB52FF7D71F4284F627B68A853D78C78E1FFE9303E4C5524E83FFE1493B9077CF1CA6BEB2090C93D930321071AD40B2F44E599046",
+	)
+	aggNonce := mustHex(t, "028465FCF0BBDBCF443AABCCE533D42B4B5A10966AC09A49655E8C42DAAB8FCD61037496A3CC86926D452CAFCFD55D25972CA1675D549310DE296BFF42F72EEEA8C9")
+	tweaks := hexList(t,
+		"E8F791FF9225A2AF0102AFFF4A9A723D9612A682A25EBE79802B263CDFCD83BB",
+		"AE2EA797CC0FE72AC5B97B97F3C6957D7E4199A167A58EB08BCAFFDA70AC0455",
+		"F52ECBC565B3D8BEA2DFD5B75A4F457E54369809322E4120831626F290FA87E0",
+		"1969AD73CC177FA0B4FCED6DF1F7BF9907E665FDE9BA196A74FED0A3CF5AEF9D",
+		"FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEBAAEDCE6AF48A03BBFD25E8CD0364141",
+	)
+	msg := mustHex(t, "F95466D086770E689964664219266FE5ED215C92AE20BAB5C9D79ADDDDF3C0CF")
+
+	// Every vector signs for keys and nonces [1, 2, 0] as signer 2.
+	order := []int{1, 2, 0}
+	vectors := []struct {
+		tweaks   []int
+		xOnly    []bool
+		expected string
+	}{
+		{[]int{0}, []bool{true}, "E28A5C66E61E178C2BA19DB77B6CF9F7E2F0F56C17918CD13135E60CC848FE91"},
+		{[]int{0}, []bool{false}, "38B0767798252F21BF5702C48028B095428320F73A4B14DB1E25DE58543D2D2D"},
+		{[]int{0, 1}, []bool{false, true}, "408A0A21C4A0F5DACAF9646AD6EB6FECD7F7A11F03ED1F48DFFF2185BC2C2408"},
+		{[]int{0, 1, 2, 3}, []bool{false, false, true, true}, "45ABD206E61E3DF2EC9E264A6FEC8292141A633C28586388235541F9ADE75435"},
+		{[]int{0, 1, 2, 3}, []bool{true, false, true, false}, "B255FDCAC27B40C7CE7848E2D3B7BF5EA0ED756DA81565AC804CCCA3E1D5D239"},
+	}
+	for i, v := range vectors {
+		s := &nist.MuSigSession{
+			AggNonce: aggNonce,
+			PubKeys:  pick(pubKeys, order...),
+			Tweaks:   pick(tweaks, v.tweaks...),
+			IsXOnly:  v.xOnly,
+			Msg:      msg,
+		}
+		psig, err := s.Sign(append([]byte{}, secNonce...), sk)
+		require.NoError(t, err, "vector %d", i)
+		require.Equal(t, mustHex(t, v.expected), psig, "vector %d", i)
+		ok, err := s.PartialSigVerify(psig, pubNonces[0], 2)
+		require.NoError(t, err, "vector %d", i)
+		require.True(t, ok, "vector %d", i)
+	}
+
+	// The tweak exceeds the group order.
+	s := &nist.MuSigSession{
+		AggNonce: aggNonce,
+		PubKeys:  pick(pubKeys, order...),
+		Tweaks:   pick(tweaks, 4),
+		IsXOnly:  []bool{false},
+		Msg:      msg,
+	}
+	_, err := s.Sign(append([]byte{}, secNonce...), sk)
+	require.Error(t, err)
+}
+
+func TestMuSigSigAggVectors(t *testing.T) {
+	pubKeys := hexList(t,
+		"03935F972DA013F80AE011890FA89B67A27B7BE6CCB24D3274D18B2D4067F261A9",
+		"02D2DC6F5DF7C56ACF38C7FA0AE7A759AE30E19B37359DFDE015872324C7EF6E05",
+		"03C7FB101D97FF930ACD0C6760852EF64E69083DE0B06AC6335724754BB4B0522C",
+		"02352433B21E7E05D3B452B81CAE566E06D2E003ECE16D1074AABA4289E0E3D581",
+	)
+	pubNonces := hexList(t,
+		"036E5EE6E28824029FEA3E8A9DDD2C8483F5AF98F7177C3AF3CB6F47CAF8D94AE902DBA67E4A1F3680826172DA15AFB1A8CA85C7C5CC88900905C8DC8C328511B53E",
+		"03E4F798DA48A76EEC1C9CC5AB7A880FFBA201A5F064E627EC9CB0031D1D58FC5103E06180315C5A522B7EC7C08B69DCD721C313C940819296D0A7AB8E8795AC1F00",
+		"02C0068FD25523A31578B8077F24F78F5BD5F2422AFF47C1FADA0F36B3CEB6C7D202098A55D1736AA5FCC21CF0729CCE852575C06C081125144763C2C4C4A05C09B6",
+		"031F5C87DCFBFCF330DEE4311D85E8F1DEA01D87A6F1C14CDFC7E4F1D8C441CFA40277BF176E9F747C34F81B0D9F072B1B404A86F402C2D86CF9EA9E9C69876EA3B9",
+		"023F7042046E0397822C4144A17F8B63D78748696A46C3B9F0A901D296EC3406C302022B0B464292CF9751D699F10980AC764E6F671EFCA15069BBE62B0D1C62522A",
+		"02D97DDA5988461DF58C5897444F116A7C74E5711BF77A9446E27806563F3B6C47020CBAD9C363A7737F99FA06B6BE093CEAFF5397316C5AC46915C43767AE867C00",
+	)
+	tweaks := hexList(t,
+		"B511DA492182A91B0FFB9A98020D55F260AE86D7ECBD0399C7383D59A5F2AF7C",
+		"A815FE049EE3C5AAB66310477FBC8BCCCAC2F3395F59F921C364ACD78A2F48DC",
+		"75448A87274B056468B977BE06EB1E9F657577B7320B0A3376EA51FD420D18A8",
+	)
+	psigs := hexList(t,
+		"B15D2CD3C3D22B04DAE438CE653F6B4ECF042F42CFDED7C41B64AAF9B4AF53FB",
+		"6193D6AC61B354E9105BBDC8937A3454A6D705B6D57322A5A472A02CE99FCB64",
+		"9A87D3B79EC67228CB97878B76049B15DBD05B8158D17B5B9114D3C226887505",
+		"66F82EA90923689B855D36C6B7E032FB9970301481B99E01CDB4D6AC7C347A15",
+		"4F5AEE41510848A6447DCD1BBC78457EF69024944C87F40250D3EF2C25D33EFE",
+		"DDEF427BBB847CC027BEFF4EDB01038148917832253EBC355FC33F4A8E2FCCE4",
+		"97B890A26C981DA8102D3BC294159D171D72810FDF7C6A691DEF02F0F7AF3FDC",
+		"53FA9E08BA5243CBCB0D797C5EE83BC6728E539EB76C2D0BF0F971EE4E909971",
+		"FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEBAAEDCE6AF48A03BBFD25E8CD0364141",
+	)
+	msg := mustHex(t, "599C67EA410D005B9DA90817CF03ED3B1C868E4DA4EDF00A5880B0082C237869")
+
+	vectors := []struct {
+		aggNonce     string
+		nonces, keys []int
+		tweaks       []int
+		xOnly        []bool
+		psigs        []int
+		expected     string
+	}{
+		{
+			"0341432722C5CD0268D829C702CF0D1CBCE57033EED201FD335191385227C3210C03D377F2D258B64AADC0E16F26462323D701D286046A2EA93365656AFD9875982B",
+			[]int{0, 1}, []int{0, 1}, nil, nil, []int{0, 1},
+			"041DA22223CE65C92C9A0D6C2CAC828AAF1EEE56304FEC371DDF91EBB2B9EF0912F1038025857FEDEB3FF696F8B99FA4BB2C5812F6095A2E0004EC99CE18DE1E",
+		},
+		{
+			"0224AFD36C902084058B51B5D36676BBA4DC97C775873768E58822F87FE437D792028CB15929099EEE2F5DAE404CD39357591BA32E9AF4E162B8D3E7CB5EFE31CB20",
+			[]int{0, 2}, []int{0, 2}, nil, nil, []int{2, 3},
+			"1069B67EC3D2F3C7C08291ACCB17A9C9B8F2819A52EB5DF8726E17E7D6B52E9F01800260A7E9DAC450F4BE522DE4CE12BA91AEAF2B4279219EF74BE1D286ADD9",
+		},
+		{
+			"0208C5C438C710F4F96A61E9FF3C37758814B8C3AE12BFEA0ED2C87FF6954FF186020B1816EA104B4FCA2D304D733E0E19CEAD51303FF6420BFD222335CAA402916D",
+			[]int{0, 3}, []int{0, 2}, []int{0}, []bool{false}, []int{4, 5},
+			"5C558E1DCADE86DA0B2F02626A512E30A22CF5255CAEA7EE32C38E9A71A0E9148BA6C0E6EC7683B64220F0298696F1B878CD47B107B81F7188812D593971E0CC",
+		},
+		{
+			"02B5AD07AFCD99B6D92CB433FBD2A28FDEB98EAE2EB09B6014EF0F8197CD58403302E8616910F9293CF692C49F351DB86B25E352901F0E237BAFDA11F1C1CEF29FFD",
+			[]int{0, 4}, []int{0, 3}, []int{0, 1, 2}, []bool{true, false, true}, []int{6, 7},
+			"839B08820B681DBA8DAF4CC7B104E8F2638F9388F8D7A555DC17B6E6971D7426CE07BF6AB01F1DB50E4E33719295F4094572B79868E440FB3DEFD3FAC1DB589E",
+		},
+	}
+	for i, v := range vectors {
+		agg, err := nist.MuSigNonceAgg(pick(pubNonces, v.nonces...))
+		require.NoError(t, err, "vector %d", i)
+		require.Equal(t, mustHex(t, v.aggNonce), agg, "vector %d", i)
+
+		keys := pick(pubKeys, v.keys...)
+		tw := pick(tweaks, v.tweaks...)
+		s := &nist.MuSigSession{AggNonce: agg, PubKeys: keys, Tweaks: tw, IsXOnly: v.xOnly, Msg: msg}
+		sig, err := s.PartialSigAgg(pick(psigs, v.psigs...))
+		require.NoError(t, err, "vector %d", i)
+		require.Equal(t, mustHex(t, v.expected), sig, "vector %d", i)
+
+		ctx, err := nist.MuSigKeyAgg(keys)
+		require.NoError(t, err, "vector %d", i)
+		for j := range tw {
+			ctx, err = ctx.Tweak(tw[j], v.xOnly[j])
+			require.NoError(t, err, "vector %d", i)
+		}
+		require.True(t, nist.SchnorrVerify(ctx.PubKey(), msg, sig), "vector %d", i)
+	}
+
+	// The second partial signature exceeds the group order.
+	v := vectors[3]
+	s := &nist.MuSigSession{AggNonce: mustHex(t, v.aggNonce), PubKeys: pick(pubKeys, v.keys...), Tweaks: pick(tweaks, v.tweaks...), IsXOnly: v.xOnly, Msg: msg}
+	_, err := s.PartialSigAgg(pick(psigs, 7, 8))
+	requireContribution(t, err, 1, "psig")
+}
+
+func TestMuSigSign(t *testing.T) {
+	const n = 3
+	msg := []byte("MuSig2 over secp256k1")
+	keys := make([]*big.Int, n)
+	pubKeys := make([][]byte, n)
+	for i := range keys {
+		priv, x, y, err := ecgeneric.GenerateKeyPair(&nist.Secp256k1, rand.Reader)
+		require.NoError(t, err)
+		keys[i] = new(big.Int).SetBytes(priv)
+		pubKeys[i] = ecgeneric.MarshalCompressed(&nist.Secp256k1, x, y)
+	}
+	pubKeys = nist.MuSigKeySort(pubKeys)
+	ctx, err := nist.MuSigKeyAgg(pubKeys)
+	require.NoError(t, err)
+	tweak := mustHex(t, "E8F791FF9225A2AF0102AFFF4A9A723D9612A682A25EBE79802B263CDFCD83BB")
+	tweaked, err := ctx.Tweak(tweak, true)
+	require.NoError(t, err)
+
+	// Round 1: nonces.
+	secNonces := make([][]byte, n)
+	pubNonces := make([][]byte, n)
+	for i, d := range keys {
+		x, y := nist.Secp256k1.ScalarBaseMultJ(d.Bytes())
+		pk := ecgeneric.MarshalCompressed(&nist.Secp256k1, x, y)
+		secNonces[i], pubNonces[i], err = nist.MuSigNonceGen(d, pk, ctx.PubKey(), msg, nil, rand.Reader)
+		require.NoError(t, err)
+	}
+	aggNonce, err := nist.MuSigNonceAgg(pubNonces)
+	require.NoError(t, err)
+
+	// Round 2: partial signatures, verified by index in pubKeys.
+	s := &nist.MuSigSession{AggNonce: aggNonce, PubKeys: pubKeys, Tweaks: [][]byte{tweak}, IsXOnly: []bool{true}, Msg: msg}
+	psigs := make([][]byte, n)
+	for i, d := range keys {
+		psigs[i], err = s.Sign(secNonces[i], d)
+		require.NoError(t, err)
+		x, y := nist.Secp256k1.ScalarBaseMultJ(d.Bytes())
+		idx := 0
+		for bytes.Compare(pubKeys[idx], ecgeneric.MarshalCompressed(&nist.Secp256k1, x, y)) != 0 {
+			idx++
+		}
+		ok, err := s.PartialSigVerify(psigs[i], pubNonces[i], idx)
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+	sig, err := s.PartialSigAgg(psigs)
+	require.NoError(t, err)
+	require.True(t, nist.SchnorrVerify(tweaked.PubKey(), msg, sig))
+	require.False(t, nist.SchnorrVerify(ctx.PubKey(), msg, sig))
+}
@@ -0,0 +1,224 @@
+package nist
+
+import (
+	"crypto/sha256"
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+)
+
+// BIP-340 Schnorr signatures over Secp256k1.
+//
+// Public keys are the 32-byte x coordinate of a point with an even y
+// coordinate, and signatures are the 64-byte R.x || s. See
+// https://github.com/bitcoin/bips/blob/master/bip-0340.mediawiki.
+
+const (
+	SchnorrPubKeyLength    = 32
+	SchnorrSignatureLength = 64
+)
+
+var (
+	errSchnorrPrivateKey = errors.New("schnorr: private key out of range")
+	errSchnorrPublicKey  = errors.New("schnorr: invalid public key")
+	errSchnorrAuxRand    = errors.New("schnorr: auxiliary randomness must be 32 bytes")
+	errSchnorrNonce      = errors.New("schnorr: derived nonce is zero")
+	errSchnorrSelfCheck  = errors.New("schnorr: produced signature does not verify")
+)
+
+// TaggedHash returns SHA256(SHA256(tag) || SHA256(tag) || msgs...).
+func TaggedHash(tag string, msgs ...[]byte) [32]byte {
+	tagHash := sha256.Sum256([]byte(tag))
+	h := sha256.New()
+	h.Write(tagHash[:])
+	h.Write(tagHash[:])
+	for _, m := range msgs {
+		h.Write(m)
+	}
+	var out [32]byte
+	h.Sum(out[:0])
+	return out
+}
+
+// LiftX returns the point on Secp256k1 with the given x coordinate and an
+// even y coordinate.
+func LiftX(x *big.Int) (*big.Int, *big.Int, error) {
+	if x.Sign() < 0 || x.Cmp(Secp256k1.P) >= 0 {
+		return nil, nil, errSchnorrPublicKey
+	}
+	y := new(big.Int).ModSqrt(Secp256k1.PolynomialGeneric(x), Secp256k1.P)
+	if y == nil {
+		return nil, nil, errSchnorrPublicKey
+	}
+	if y.Bit(0) != 0 {
+		y.Sub(Secp256k1.P, y)
+	}
+	return new(big.Int).Set(x), y, nil
+}
+
+// SchnorrPubKey returns the x-only public key of the private key d.
+func SchnorrPubKey(d *big.Int) ([]byte, error) {
+	if d.Sign() <= 0 || d.Cmp(Secp256k1.N) >= 0 {
+		return nil, errSchnorrPrivateKey
+	}
+	Px, _ := Secp256k1.ScalarBaseMultJ(d.Bytes())
+	return bytes32(Px), nil
+}
+
+// SchnorrSign signs msg with the private key d. auxRand is 32 bytes of fresh
+// randomness; passing all zeros yields the deterministic variant.
+func SchnorrSign(d *big.Int, msg, auxRand []byte) ([]byte, error) {
+	N := Secp256k1.N
+	if d.Sign() <= 0 || d.Cmp(N) >= 0 {
+		return nil, errSchnorrPrivateKey
+	}
+	if len(auxRand) != 32 {
+		return nil, errSchnorrAuxRand
+	}
+
+	Px, Py := Secp256k1.ScalarBaseMultJ(d.Bytes())
+	d = evenY(d, Py)
+	pk := bytes32(Px)
+
+	t := TaggedHash("BIP0340/aux", auxRand)
+	db := bytes32(d)
+	for i := range t {
+		t[i] ^= db[i]
+	}
+	rand := TaggedHash("BIP0340/nonce", t[:], pk, msg)
+	k := new(big.Int).SetBytes(rand[:])
+	k.Mod(k, N)
+	if k.Sign() == 0 {
+		return nil, errSchnorrNonce
+	}
+
+	Rx, Ry := Secp256k1.ScalarBaseMultJ(k.Bytes())
+	k = evenY(k, Ry)
+	rb := bytes32(Rx)
+
+	e := schnorrChallenge(rb, pk, msg)
+	s := e.Mul(e, d)
+	s.Add(s, k)
+	s.Mod(s, N)
+
+	sig := make([]byte, SchnorrSignatureLength)
+	copy(sig, rb)
+	s.FillBytes(sig[32:])
+
+	if !SchnorrVerify(pk, msg, sig) {
+		return nil, errSchnorrSelfCheck
+	}
+	return sig, nil
+}
+
+// SchnorrVerify reports whether sig is a valid BIP-340 signature of msg under
+// the x-only public key pubKey.
+func SchnorrVerify(pubKey, msg, sig []byte) bool {
+	if len(pubKey) != SchnorrPubKeyLength || len(sig) != SchnorrSignatureLength {
+		return false
+	}
+	Px, Py, err := LiftX(new(big.Int).SetBytes(pubKey))
+	if err != nil {
+		return false
+	}
+	r := new(big.Int).SetBytes(sig[:32])
+	s := new(big.Int).SetBytes(sig[32:])
+	if r.Cmp(Secp256k1.P) >= 0 || s.Cmp(Secp256k1.N) >= 0 {
+		return false
+	}
+	e := schnorrChallenge(sig[:32], pubKey, msg)
+
+	// R = s·G - e·P
+	e.Sub(Secp256k1.N, e)
+	Rx, Ry := Secp256k1.MultiScalarMult(
+		[]ecgeneric.Point{{X: Secp256k1.Gx, Y: Secp256k1.Gy}, {X: Px, Y: Py}},
+		[]*big.Int{s, e},
+	)
+	if Rx.Sign() == 0 && Ry.Sign() == 0 {
+		return false
+	}
+	return Ry.Bit(0) == 0 && Rx.Cmp(r) == 0
+}
+
+// SchnorrBatchVerify reports whether all signatures are valid, checking them
+// with one randomized multi-scalar multiplication
+//
+//	(Σ aᵢsᵢ)·G - Σ aᵢ·Rᵢ - Σ aᵢeᵢ·Pᵢ = O
+//
+// as described in BIP-340. It does not tell which signature is invalid. The
+// coefficients aᵢ are read from rand, which must not be nil.
+func SchnorrBatchVerify(pubKeys, msgs, sigs [][]byte, rand io.Reader) (bool, error) {
+	if len(pubKeys) != len(msgs) || len(pubKeys) != len(sigs) {
+		return false, errors.New("schnorr: batch inputs differ in length")
+	}
+	if rand == nil {
+		return false, errors.New("schnorr: no randomness source")
+	}
+	N := Secp256k1.N
+	points := make([]ecgeneric.Point, 0, 2*len(sigs)+1)
+	scalars := make([]*big.Int, 0, 2*len(sigs)+1)
+	sSum := new(big.Int)
+	buf := make([]byte, 32)
+
+	for i := range sigs {
+		if len(pubKeys[i]) != SchnorrPubKeyLength || len(sigs[i]) != SchnorrSignatureLength {
+			return false, nil
+		}
+		Px, Py, err := LiftX(new(big.Int).SetBytes(pubKeys[i]))
+		if err != nil {
+			return false, nil
+		}
+		Rx, Ry, err := LiftX(new(big.Int).SetBytes(sigs[i][:32]))
+		if err != nil {
+			return false, nil
+		}
+		s := new(big.Int).SetBytes(sigs[i][32:])
+		if s.Cmp(N) >= 0 {
+			return false, nil
+		}
+		e := schnorrChallenge(sigs[i][:32], pubKeys[i], msgs[i])
+
+		a := big.NewInt(1)
+		if i > 0 {
+			if _, err := io.ReadFull(rand, buf); err != nil {
+				return false, err
+			}
+			a.SetBytes(buf)
+			a.Mod(a, N)
+		}
+
+		sSum.Add(sSum, s.Mul(s, a))
+		points = append(points, ecgeneric.Point{X: Rx, Y: new(big.Int).Sub(Secp256k1.P, Ry)})
+		scalars = append(scalars, a)
+		points = append(points, ecgeneric.Point{X: Px, Y: new(big.Int).Sub(Secp256k1.P, Py)})
+		scalars = append(scalars, e.Mul(e, a))
+	}
+	points = append(points, ecgeneric.Point{X: Secp256k1.Gx, Y: Secp256k1.Gy})
+	scalars = append(scalars, sSum.Mod(sSum, N))
+
+	x, y := Secp256k1.MultiScalarMult(points, scalars)
+	return x.Sign() == 0 && y.Sign() == 0, nil
+}
+
+func schnorrChallenge(r, pk, msg []byte) *big.Int {
+	h := TaggedHash("BIP0340/challenge", r, pk, msg)
+	e := new(big.Int).SetBytes(h[:])
+	return e.Mod(e, Secp256k1.N)
+}
+
+// evenY returns k if y is even and N - k otherwise, so that k·G has an even
+// y coordinate.
+func evenY(k, y *big.Int) *big.Int {
+	if y.Bit(0) == 0 {
+		return k
+	}
+	return new(big.Int).Sub(Secp256k1.N, k)
+}
+
+func bytes32(x *big.Int) []byte {
+	b := make([]byte, 32)
+	x.FillBytes(b)
+	return b
+}
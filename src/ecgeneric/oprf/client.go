@@ -0,0 +1,93 @@
+package oprf
+
+import (
+	"io"
+	"math/big"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/vss"
+)
+
+// Client blinds inputs for a server and finalizes its evaluations.
+type Client struct {
+	Suite *Suite
+	Mode  Mode
+	// PublicKey is the server's key, against which the proofs are checked
+	// in ModeVOPRF.
+	PublicKey *ecgeneric.PublicKey
+}
+
+// NewClient returns a client for mode. The server's public key is required
+// in ModeVOPRF and ignored otherwise.
+func NewClient(suite *Suite, mode Mode, pub *ecgeneric.PublicKey) (*Client, error) {
+	if !validMode(mode) {
+		return nil, errMode
+	}
+	if mode == ModeVOPRF && !suite.validKey(pub) {
+		return nil, errKey
+	}
+	return &Client{Suite: suite, Mode: mode, PublicKey: pub}, nil
+}
+
+// FinalizeData is the client state kept between Blind and Finalize. It holds
+// the blinding scalars and must stay secret.
+type FinalizeData struct {
+	inputs  [][]byte
+	blinds  []*big.Int
+	blinded []ecgeneric.Point
+}
+
+// Blind hashes each input to the curve and blinds it with a fresh scalar.
+// The blinded elements go to the server; the returned state is needed to
+// finalize its answer.
+func (c *Client) Blind(rand io.Reader, inputs [][]byte) (*FinalizeData, []ecgeneric.Point, error) {
+	s := c.Suite
+	fd := &FinalizeData{
+		inputs:  make([][]byte, len(inputs)),
+		blinds:  make([]*big.Int, len(inputs)),
+		blinded: make([]ecgeneric.Point, len(inputs)),
+	}
+	for i, input := range inputs {
+		if len(input) > 0xffff {
+			return nil, nil, ErrInvalidInput
+		}
+		P, err := s.hashToGroup(c.Mode, input)
+		if err != nil {
+			return nil, nil, err
+		}
+		r, err := vss.RandomScalar(rand, s.Curve)
+		if err != nil {
+			return nil, nil, err
+		}
+		fd.inputs[i] = append([]byte{}, input...)
+		fd.blinds[i] = r
+		fd.blinded[i] = s.mul(P, r)
+	}
+	return fd, append([]ecgeneric.Point{}, fd.blinded...), nil
+}
+
+// Finalize checks the server's proof in ModeVOPRF, unblinds the evaluated
+// elements and returns the PRF outputs in the order of the inputs.
+func (c *Client) Finalize(fd *FinalizeData, evaluated []ecgeneric.Point, proof *Proof) ([][]byte, error) {
+	s := c.Suite
+	if len(evaluated) != len(fd.blinded) {
+		return nil, errBatch
+	}
+	for _, e := range evaluated {
+		if !s.validElement(e) {
+			return nil, errPoint
+		}
+	}
+	if c.Mode == ModeVOPRF {
+		B := ecgeneric.Point{X: c.PublicKey.X, Y: c.PublicKey.Y}
+		if !s.verifyProof(c.Mode, B, fd.blinded, evaluated, proof) {
+			return nil, ErrVerify
+		}
+	}
+	outputs := make([][]byte, len(evaluated))
+	for i, e := range evaluated {
+		inv := new(big.Int).ModInverse(fd.blinds[i], s.Curve.N)
+		outputs[i] = s.finalizeHash(fd.inputs[i], s.mul(e, inv))
+	}
+	return outputs, nil
+}
@@ -0,0 +1,135 @@
+package oprf
+
+import (
+	"io"
+	"math/big"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/vss"
+)
+
+// Proof is a batched proof that D_i = k·C_i for every pair in a batch,
+// where B = k·G is the server's public key, RFC 9497 Section 2.2.
+type Proof struct {
+	C, S *big.Int
+}
+
+// MarshalProof encodes p as two scalars of the byte length of the curve
+// order.
+func (s *Suite) MarshalProof(p *Proof) ([]byte, error) {
+	if !s.validScalar(p.C) || !s.validScalar(p.S) {
+		return nil, errScalar
+	}
+	size := s.scalarLen()
+	b := make([]byte, 2*size)
+	p.C.FillBytes(b[:size])
+	p.S.FillBytes(b[size:])
+	return b, nil
+}
+
+// UnmarshalProof decodes a proof encoded by MarshalProof.
+func (s *Suite) UnmarshalProof(data []byte) (*Proof, error) {
+	size := s.scalarLen()
+	if len(data) != 2*size {
+		return nil, errScalar
+	}
+	p := &Proof{C: new(big.Int).SetBytes(data[:size]), S: new(big.Int).SetBytes(data[size:])}
+	if !s.validScalar(p.C) || !s.validScalar(p.S) {
+		return nil, errScalar
+	}
+	return p, nil
+}
+
+// generateProof proves that every D_i is k times C_i, with B = k·G.
+func (s *Suite) generateProof(rand io.Reader, mode Mode, k *big.Int, B ecgeneric.Point, C, D []ecgeneric.Point) (*Proof, error) {
+	M, Z, err := s.computeComposites(mode, k, B, C, D)
+	if err != nil {
+		return nil, err
+	}
+	r, err := vss.RandomScalar(rand, s.Curve)
+	if err != nil {
+		return nil, err
+	}
+	t2 := s.mul(ecgeneric.Point{X: s.Curve.Gx, Y: s.Curve.Gy}, r)
+	t3 := s.mul(M, r)
+	c, err := s.challenge(mode, B, M, Z, t2, t3)
+	if err != nil {
+		return nil, err
+	}
+	// s = r - c·k
+	sk := new(big.Int).Mul(c, k)
+	sk.Sub(r, sk).Mod(sk, s.Curve.N)
+	return &Proof{C: c, S: sk}, nil
+}
+
+func (s *Suite) verifyProof(mode Mode, B ecgeneric.Point, C, D []ecgeneric.Point, p *Proof) bool {
+	if p == nil || !s.validScalar(p.C) || !s.validScalar(p.S) {
+		return false
+	}
+	M, Z, err := s.computeComposites(mode, nil, B, C, D)
+	if err != nil {
+		return false
+	}
+	G := ecgeneric.Point{X: s.Curve.Gx, Y: s.Curve.Gy}
+	t2 := s.combine(G, p.S, B, p.C)
+	t3 := s.combine(M, p.S, Z, p.C)
+	c, err := s.challenge(mode, B, M, Z, t2, t3)
+	return err == nil && c.Cmp(p.C) == 0
+}
+
+// computeComposites folds the pairs into M = Σ d_i·C_i and Z = Σ d_i·D_i
+// with weights d_i hashed from the whole batch. The server, which knows k,
+// takes the shortcut Z = k·M.
+func (s *Suite) computeComposites(mode Mode, k *big.Int, B ecgeneric.Point, C, D []ecgeneric.Point) (M, Z ecgeneric.Point, err error) {
+	ctx := s.contextString(mode)
+	Bm := s.MarshalElement(B)
+	h := s.Hash()
+	writeField(h, Bm)
+	writeField(h, append([]byte("Seed-"), ctx...))
+	seed := h.Sum(nil)
+
+	dst := append([]byte("HashToScalar-"), ctx...)
+	d := make([]*big.Int, len(C))
+	for i := range C {
+		var in []byte
+		in = append(append(in, i2osp2(len(seed))...), seed...)
+		in = append(in, i2osp2(i)...)
+		Ci, Di := s.MarshalElement(C[i]), s.MarshalElement(D[i])
+		in = append(append(in, i2osp2(len(Ci))...), Ci...)
+		in = append(append(in, i2osp2(len(Di))...), Di...)
+		in = append(in, "Composite"...)
+		if d[i], err = s.hashToScalar(in, dst); err != nil {
+			return M, Z, err
+		}
+	}
+	mx, my := s.Curve.MultiScalarMult(C, d)
+	M = ecgeneric.Point{X: mx, Y: my}
+	if k != nil {
+		return M, s.mul(M, k), nil
+	}
+	zx, zy := s.Curve.MultiScalarMult(D, d)
+	return M, ecgeneric.Point{X: zx, Y: zy}, nil
+}
+
+func (s *Suite) challenge(mode Mode, B, M, Z, t2, t3 ecgeneric.Point) (*big.Int, error) {
+	var in []byte
+	for _, p := range []ecgeneric.Point{B, M, Z, t2, t3} {
+		if isIdentity(p) {
+			return nil, errPoint
+		}
+		e := s.MarshalElement(p)
+		in = append(append(in, i2osp2(len(e))...), e...)
+	}
+	in = append(in, "Challenge"...)
+	return s.hashToScalar(in, append([]byte("HashToScalar-"), s.contextString(mode)...))
+}
+
+// combine returns a·P + b·Q.
+func (s *Suite) combine(P ecgeneric.Point, a *big.Int, Q ecgeneric.Point, b *big.Int) ecgeneric.Point {
+	x, y := s.Curve.MultiScalarMult([]ecgeneric.Point{P, Q}, []*big.Int{a, b})
+	return ecgeneric.Point{X: x, Y: y}
+}
+
+func (s *Suite) validScalar(k *big.Int) bool {
+	return k != nil && k.Sign() >= 0 && k.Cmp(s.Curve.N) < 0
+}
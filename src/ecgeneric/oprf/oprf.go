@@ -0,0 +1,225 @@
+// Package oprf implements the oblivious pseudorandom functions of RFC 9497
+// over ecgeneric curves, in the OPRF and VOPRF modes.
+//
+// A client blinds its inputs, the server evaluates them under its private
+// key without learning them, and the client unblinds the results and hashes
+// them to the PRF outputs. In the verifiable mode the server also returns a
+// batched DLEQ proof that every evaluation used the key behind its public
+// key, so a client can tell that it is not being singled out with another
+// key. This is the basis of anonymous tokens such as Privacy Pass: the
+// server issues evaluations and later recognises outputs it cannot link to
+// the issuance.
+//
+// P256SHA256 is the P256-SHA256 ciphersuite of RFC 9497. The same
+// construction is offered on secp256k1 and, with Streebog in place of
+// SHA-256, on the GOST curves; those suites are not standardized and their
+// identifiers are our own.
+//
+// Server.BlindEvaluate multiplies points the client chooses by the private
+// key with math/big, whose running time depends on the key. A client that
+// can time many evaluations may learn the key, so servers should not answer
+// where response times can be measured precisely.
+package oprf
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"math/big"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/gost"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/h2c"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/nist"
+)
+
+var (
+	// ErrInvalidInput is returned for an input that hashes to the identity.
+	ErrInvalidInput = errors.New("oprf: input hashes to the identity")
+	// ErrVerify is returned when the server's proof does not verify.
+	ErrVerify = errors.New("oprf: proof verification failed")
+	// ErrDeriveKeyPair is returned when no key can be derived from a seed.
+	ErrDeriveKeyPair = errors.New("oprf: cannot derive key pair")
+
+	errMode   = errors.New("oprf: unknown mode")
+	errKey    = errors.New("oprf: invalid key")
+	errPoint  = errors.New("oprf: invalid element")
+	errScalar = errors.New("oprf: invalid scalar")
+	errBatch  = errors.New("oprf: batch sizes do not match")
+)
+
+// Mode is the protocol variant, which is bound into every hash.
+type Mode byte
+
+const (
+	// ModeOPRF is the base mode, in which the client cannot check the
+	// server's evaluations.
+	ModeOPRF Mode = 0x00
+	// ModeVOPRF is the verifiable mode, in which each batch of evaluations
+	// comes with a proof against the server's public key.
+	ModeVOPRF Mode = 0x01
+)
+
+// Suite is an OPRF ciphersuite: a prime-order curve with hash-to-curve and
+// a hash function.
+type Suite struct {
+	// ID is the suite identifier in the context string.
+	ID    string
+	Curve *ecgeneric.CurveParams
+	Hash  func() hash.Hash
+
+	h2c *h2c.Suite
+}
+
+// NewSuite returns the ciphersuite identified by id on curve, hashing with
+// newHash. Elements are hashed to the curve with RFC 9380 hash_to_curve over
+// the same hash at half the bit length of the curve order as security level.
+func NewSuite(id string, curve *ecgeneric.CurveParams, newHash func() hash.Hash) (*Suite, error) {
+	if curve.Cofactor().Cmp(big.NewInt(1)) != 0 {
+		return nil, errors.New("oprf: curve is not of prime order")
+	}
+	hs, err := h2c.NewSuite(curve, newHash, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &Suite{ID: id, Curve: curve, Hash: newHash, h2c: hs}, nil
+}
+
+func mustSuite(id string, curve *ecgeneric.CurveParams, newHash func() hash.Hash) *Suite {
+	s, err := NewSuite(id, curve, newHash)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+var (
+	// P256SHA256 is the P256-SHA256 ciphersuite, RFC 9497 Section 4.3.
+	P256SHA256 = mustSuite("P256-SHA256", &nist.P256, sha256.New)
+	// Secp256k1SHA256 is the P256-SHA256 construction on secp256k1, with
+	// secp256k1_XMD:SHA-256_SSWU_RO_ as hash-to-curve.
+	Secp256k1SHA256 = mustSuite("secp256k1-SHA256", &nist.Secp256k1, sha256.New)
+)
+
+// GOSTSuite returns the ciphersuite on a GOST curve, hashing with Streebog
+// sized to the curve as in gost.SDSAHash.
+func GOSTSuite(curve *ecgeneric.CurveParams) (*Suite, error) {
+	newHash := gost.SDSAHash(curve)
+	id := curve.Name + "-STREEBOG256"
+	if newHash().Size() == 64 {
+		id = curve.Name + "-STREEBOG512"
+	}
+	return NewSuite(id, curve, newHash)
+}
+
+// contextString is "OPRFV1-" || I2OSP(mode, 1) || "-" || identifier.
+func (s *Suite) contextString(mode Mode) []byte {
+	return append([]byte{'O', 'P', 'R', 'F', 'V', '1', '-', byte(mode), '-'}, s.ID...)
+}
+
+func (s *Suite) hashToGroup(mode Mode, input []byte) (ecgeneric.Point, error) {
+	p, err := s.h2c.HashToCurve(input, append([]byte("HashToGroup-"), s.contextString(mode)...))
+	if err != nil {
+		return ecgeneric.Point{}, err
+	}
+	if isIdentity(p) {
+		return ecgeneric.Point{}, ErrInvalidInput
+	}
+	return p, nil
+}
+
+// hashToScalar is hash_to_field with the curve order as modulus, RFC 9497
+// Section 4.
+func (s *Suite) hashToScalar(msg, dst []byte) (*big.Int, error) {
+	L := (s.Curve.N.BitLen() + s.h2c.K + 7) / 8
+	uniform, err := h2c.ExpandMessageXMD(s.Hash, msg, dst, L)
+	if err != nil {
+		return nil, err
+	}
+	k := new(big.Int).SetBytes(uniform)
+	return k.Mod(k, s.Curve.N), nil
+}
+
+// DeriveKeyPair deterministically derives a server key for mode from seed
+// and the public info, RFC 9497 Section 3.2.1.
+func (s *Suite) DeriveKeyPair(mode Mode, seed, info []byte) (*ecgeneric.PrivateKey, error) {
+	if len(info) > 0xffff {
+		return nil, ErrDeriveKeyPair
+	}
+	input := append(append(append([]byte{}, seed...), i2osp2(len(info))...), info...)
+	dst := append([]byte("DeriveKeyPair"), s.contextString(mode)...)
+	for counter := 0; counter < 256; counter++ {
+		k, err := s.hashToScalar(append(input, byte(counter)), dst)
+		if err != nil {
+			return nil, err
+		}
+		if k.Sign() != 0 {
+			x, y := s.Curve.ScalarBaseMultJ(k.Bytes())
+			return &ecgeneric.PrivateKey{PublicKey: ecgeneric.PublicKey{Curve: s.Curve, X: x, Y: y}, D: k}, nil
+		}
+	}
+	return nil, ErrDeriveKeyPair
+}
+
+// MarshalElement encodes a point in compressed SEC 1 form.
+func (s *Suite) MarshalElement(p ecgeneric.Point) []byte {
+	return ecgeneric.MarshalCompressed(s.Curve, p.X, p.Y)
+}
+
+// UnmarshalElement decodes a point encoded by MarshalElement. The identity
+// has no encoding and is never returned.
+func (s *Suite) UnmarshalElement(data []byte) (ecgeneric.Point, error) {
+	x, y := ecgeneric.UnmarshalCompressed(s.Curve, data)
+	if x == nil {
+		return ecgeneric.Point{}, errPoint
+	}
+	return ecgeneric.Point{X: x, Y: y}, nil
+}
+
+// finalizeHash is Hash(len(input) || input || len(element) || element ||
+// "Finalize"), the PRF output for an unblinded element.
+func (s *Suite) finalizeHash(input []byte, element ecgeneric.Point) []byte {
+	h := s.Hash()
+	writeField(h, input)
+	writeField(h, s.MarshalElement(element))
+	h.Write([]byte("Finalize"))
+	return h.Sum(nil)
+}
+
+func (s *Suite) scalarLen() int {
+	return (s.Curve.N.BitLen() + 7) / 8
+}
+
+func (s *Suite) mul(p ecgeneric.Point, k *big.Int) ecgeneric.Point {
+	x, y := s.Curve.ScalarMultJ(p.X, p.Y, k.Bytes())
+	return ecgeneric.Point{X: x, Y: y}
+}
+
+func (s *Suite) validElement(p ecgeneric.Point) bool {
+	return p.X != nil && p.Y != nil && !isIdentity(p) && s.Curve.IsOnCurve(p.X, p.Y)
+}
+
+func (s *Suite) validKey(pub *ecgeneric.PublicKey) bool {
+	return pub != nil && pub.Curve != nil && pub.Curve.Params().Name == s.Curve.Name &&
+		s.validElement(ecgeneric.Point{X: pub.X, Y: pub.Y})
+}
+
+func validMode(mode Mode) bool {
+	return mode == ModeOPRF || mode == ModeVOPRF
+}
+
+func isIdentity(p ecgeneric.Point) bool {
+	return p.X.Sign() == 0 && p.Y.Sign() == 0
+}
+
+func i2osp2(n int) []byte {
+	var b [2]byte
+	binary.BigEndian.PutUint16(b[:], uint16(n))
+	return b[:]
+}
+
+func writeField(h hash.Hash, b []byte) {
+	h.Write(i2osp2(len(b)))
+	h.Write(b)
+}
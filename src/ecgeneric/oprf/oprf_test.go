@@ -0,0 +1,241 @@
+package oprf_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/gost"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/oprf"
+	"github.com/stretchr/testify/require"
+)
+
+func mustHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
+
+func hexList(t *testing.T, s string) [][]byte {
+	var out [][]byte
+	for _, v := range strings.Split(s, ",") {
+		out = append(out, mustHex(t, v))
+	}
+	return out
+}
+
+// scalarReader makes vss.RandomScalar, which returns one more than the
+// integer it reads, return the given scalars in turn.
+func scalarReader(scalars ...[]byte) io.Reader {
+	var buf bytes.Buffer
+	for _, k := range scalars {
+		v := new(big.Int).SetBytes(k)
+		buf.Write(v.Sub(v, big.NewInt(1)).FillBytes(make([]byte, len(k))))
+	}
+	return &buf
+}
+
+type vector struct {
+	input, blind, blinded, evaluated, output, proof, r string
+}
+
+// Vectors from RFC 9497, Appendix A.3.
+var p256Vectors = []struct {
+	mode    oprf.Mode
+	sk, pk  string
+	vectors []vector
+}{
+	{
+		oprf.ModeOPRF,
+		"159749d750713afe245d2d39ccfaae8381c53ce92d098a9375ee70739c7ac0bf", "",
+		[]vector{
+			{
+				"00",
+				"3338fa65ec36e0290022b48eb562889d89dbfa691d1cde91517fa222ed7ad364",
+				"03723a1e5c09b8b9c18d1dcbca29e8007e95f14f4732d9346d490ffc195110368d",
+				"030de02ffec47a1fd53efcdd1c6faf5bdc270912b8749e783c7ca75bb412958832",
+				"a0b34de5fa4c5b6da07e72af73cc507cceeb48981b97b7285fc375345fe495dd",
+				"", "",
+			},
+			{
+				"5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a",
+				"3338fa65ec36e0290022b48eb562889d89dbfa691d1cde91517fa222ed7ad364",
+				"03cc1df781f1c2240a64d1c297b3f3d16262ef5d4cf102734882675c26231b0838",
+				"03a0395fe3828f2476ffcd1f4fe540e5a8489322d398be3c4e5a869db7fcb7c52c",
+				"c748ca6dd327f0ce85f4ae3a8cd6d4d5390bbb804c9e12dcf94f853fece3dcce",
+				"", "",
+			},
+		},
+	},
+	{
+		oprf.ModeVOPRF,
+		"ca5d94c8807817669a51b196c34c1b7f8442fde4334a7121ae4736364312fca6",
+		"03e17e70604bcabe198882c0a1f27a92441e774224ed9c702e51dd17038b102462",
+		[]vector{
+			{
+				"00",
+				"3338fa65ec36e0290022b48eb562889d89dbfa691d1cde91517fa222ed7ad364",
+				"02dd05901038bb31a6fae01828fd8d0e49e35a486b5c5d4b4994013648c01277da",
+				"0209f33cab60cf8fe69239b0afbcfcd261af4c1c5632624f2e9ba29b90ae83e4a2",
+				"0412e8f78b02c415ab3a288e228978376f99927767ff37c5718d420010a645a1",
+				"e7c2b3c5c954c035949f1f74e6bce2ed539a3be267d1481e9ddb178533df4c2664f69d065c604a4fd953e100b856ad83804eb3845189babfa5a702090d6fc5fa",
+				"f9db001266677f62c095021db018cd8cbb55941d4073698ce45c405d1348b7b1",
+			},
+			{
+				"5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a",
+				"3338fa65ec36e0290022b48eb562889d89dbfa691d1cde91517fa222ed7ad364",
+				"03cd0f033e791c4d79dfa9c6ed750f2ac009ec46cd4195ca6fd3800d1e9b887dbd",
+				"030d2985865c693bf7af47ba4d3a3813176576383d19aff003ef7b0784a0d83cf1",
+				"771e10dcd6bcd3664e23b8f2a710cfaaa8357747c4a8cbba03133967b5c24f18",
+				"2787d729c57e3d9512d3aa9e8708ad226bc48e0f1750b0767aaff73482c44b8d2873d74ec88aebd3504961acea16790a05c542d9fbff4fe269a77510db00abab",
+				"f9db001266677f62c095021db018cd8cbb55941d4073698ce45c405d1348b7b1",
+			},
+			{
+				"00,5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a",
+				"3338fa65ec36e0290022b48eb562889d89dbfa691d1cde91517fa222ed7ad364,f9db001266677f62c095021db018cd8cbb55941d4073698ce45c405d1348b7b1",
+				"02dd05901038bb31a6fae01828fd8d0e49e35a486b5c5d4b4994013648c01277da,03462e9ae64cae5b83ba98a6b360d942266389ac369b923eb3d557213b1922f8ab",
+				"0209f33cab60cf8fe69239b0afbcfcd261af4c1c5632624f2e9ba29b90ae83e4a2,02bb24f4d838414aef052a8f044a6771230ca69c0a5677540fff738dd31bb69771",
+				"0412e8f78b02c415ab3a288e228978376f99927767ff37c5718d420010a645a1,771e10dcd6bcd3664e23b8f2a710cfaaa8357747c4a8cbba03133967b5c24f18",
+				"bdcc351707d02a72ce49511c7db990566d29d6153ad6f8982fad2b435d6ce4d60da1e6b3fa740811bde34dd4fe0aa1b5fe6600d0440c9ddee95ea7fad7a60cf2",
+				"350e8040f828bf6ceca27405420cdf3d63cb3aef005f40ba51943c8026877963",
+			},
+		},
+	},
+}
+
+func TestP256SHA256Vectors(t *testing.T) {
+	suite := oprf.P256SHA256
+	seed := bytes.Repeat([]byte{0xa3}, 32)
+	for _, kv := range p256Vectors {
+		priv, err := suite.DeriveKeyPair(kv.mode, seed, []byte("test key"))
+		require.NoError(t, err)
+		require.Equal(t, kv.sk, hex.EncodeToString(priv.D.FillBytes(make([]byte, 32))))
+		if kv.pk != "" {
+			require.Equal(t, kv.pk, hex.EncodeToString(suite.MarshalElement(ecgeneric.Point{X: priv.X, Y: priv.Y})))
+		}
+		server, err := oprf.NewServer(suite, kv.mode, priv)
+		require.NoError(t, err)
+		client, err := oprf.NewClient(suite, kv.mode, server.PublicKey())
+		require.NoError(t, err)
+
+		for _, v := range kv.vectors {
+			inputs := hexList(t, v.input)
+			fd, blinded, err := client.Blind(scalarReader(hexList(t, v.blind)...), inputs)
+			require.NoError(t, err)
+			for i, want := range hexList(t, v.blinded) {
+				require.Equal(t, want, suite.MarshalElement(blinded[i]))
+			}
+
+			var proofRand io.Reader
+			if v.r != "" {
+				proofRand = scalarReader(mustHex(t, v.r))
+			}
+			evaluated, proof, err := server.BlindEvaluate(proofRand, blinded)
+			require.NoError(t, err)
+			for i, want := range hexList(t, v.evaluated) {
+				require.Equal(t, want, suite.MarshalElement(evaluated[i]))
+			}
+			if v.proof != "" {
+				b, err := suite.MarshalProof(proof)
+				require.NoError(t, err)
+				require.Equal(t, v.proof, hex.EncodeToString(b))
+			} else {
+				require.Nil(t, proof)
+			}
+
+			outputs, err := client.Finalize(fd, evaluated, proof)
+			require.NoError(t, err)
+			for i, want := range hexList(t, v.output) {
+				require.Equal(t, want, outputs[i])
+				direct, err := server.Evaluate(inputs[i])
+				require.NoError(t, err)
+				require.Equal(t, want, direct)
+			}
+		}
+	}
+}
+
+func TestVOPRF(t *testing.T) {
+	suites := []*oprf.Suite{oprf.P256SHA256, oprf.Secp256k1SHA256}
+	gs, err := oprf.GOSTSuite(&gost.Gost34102001paramSetA)
+	require.NoError(t, err)
+	suites = append(suites, gs)
+
+	for _, suite := range suites {
+		priv, err := ecgeneric.GenerateKey(suite.Curve, rand.Reader)
+		require.NoError(t, err)
+		server, err := oprf.NewServer(suite, oprf.ModeVOPRF, priv)
+		require.NoError(t, err)
+		client, err := oprf.NewClient(suite, oprf.ModeVOPRF, server.PublicKey())
+		require.NoError(t, err)
+
+		inputs := [][]byte{[]byte("token 1"), []byte("token 2"), []byte("token 3")}
+		fd, blinded, err := client.Blind(rand.Reader, inputs)
+		require.NoError(t, err)
+		evaluated, proof, err := server.BlindEvaluate(rand.Reader, blinded)
+		require.NoError(t, err)
+
+		// Elements and proofs survive the wire.
+		for i := range blinded {
+			p, err := suite.UnmarshalElement(suite.MarshalElement(blinded[i]))
+			require.NoError(t, err)
+			require.Equal(t, blinded[i], p)
+		}
+		b, err := suite.MarshalProof(proof)
+		require.NoError(t, err)
+		proof, err = suite.UnmarshalProof(b)
+		require.NoError(t, err)
+
+		outputs, err := client.Finalize(fd, evaluated, proof)
+		require.NoError(t, err, suite.ID)
+		for i, input := range inputs {
+			direct, err := server.Evaluate(input)
+			require.NoError(t, err)
+			require.Equal(t, direct, outputs[i], suite.ID)
+		}
+
+		// Evaluations under another key are caught, even for a single
+		// element of the batch.
+		other, err := ecgeneric.GenerateKey(suite.Curve, rand.Reader)
+		require.NoError(t, err)
+		cheat, err := oprf.NewServer(suite, oprf.ModeVOPRF, other)
+		require.NoError(t, err)
+		bad, _, err := cheat.BlindEvaluate(rand.Reader, blinded[1:2])
+		require.NoError(t, err)
+		tampered := append([]ecgeneric.Point{}, evaluated...)
+		tampered[1] = bad[0]
+		_, err = client.Finalize(fd, tampered, proof)
+		require.ErrorIs(t, err, oprf.ErrVerify)
+		_, proof2, err := cheat.BlindEvaluate(rand.Reader, blinded)
+		require.NoError(t, err)
+		_, err = client.Finalize(fd, evaluated, proof2)
+		require.ErrorIs(t, err, oprf.ErrVerify)
+	}
+}
+
+func TestModesDiffer(t *testing.T) {
+	suite := oprf.Secp256k1SHA256
+	priv, err := ecgeneric.GenerateKey(suite.Curve, rand.Reader)
+	require.NoError(t, err)
+	var outputs [][]byte
+	for _, mode := range []oprf.Mode{oprf.ModeOPRF, oprf.ModeVOPRF} {
+		server, err := oprf.NewServer(suite, mode, priv)
+		require.NoError(t, err)
+		out, err := server.Evaluate([]byte("input"))
+		require.NoError(t, err)
+		outputs = append(outputs, out)
+	}
+	require.NotEqual(t, outputs[0], outputs[1])
+
+	_, err = oprf.NewClient(suite, oprf.ModeVOPRF, nil)
+	require.Error(t, err)
+	_, err = oprf.NewServer(suite, oprf.Mode(2), priv)
+	require.Error(t, err)
+	_, err = suite.UnmarshalElement([]byte{0})
+	require.Error(t, err)
+}
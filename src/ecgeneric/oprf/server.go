@@ -0,0 +1,68 @@
+package oprf
+
+import (
+	"io"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+)
+
+// Server evaluates blinded elements under its private key.
+type Server struct {
+	Suite *Suite
+	Mode  Mode
+	Key   *ecgeneric.PrivateKey
+}
+
+// NewServer returns a server for mode holding priv, which may come from
+// Suite.DeriveKeyPair or ecgeneric.GenerateKey on the suite's curve.
+func NewServer(suite *Suite, mode Mode, priv *ecgeneric.PrivateKey) (*Server, error) {
+	if !validMode(mode) {
+		return nil, errMode
+	}
+	if priv == nil || !suite.validScalar(priv.D) || priv.D.Sign() == 0 || !suite.validKey(&priv.PublicKey) {
+		return nil, errKey
+	}
+	return &Server{Suite: suite, Mode: mode, Key: priv}, nil
+}
+
+// PublicKey returns the key clients check proofs against.
+func (s *Server) PublicKey() *ecgeneric.PublicKey {
+	return &s.Key.PublicKey
+}
+
+// BlindEvaluate multiplies each blinded element by the private key. In
+// ModeVOPRF it also returns one proof for the whole batch; in ModeOPRF the
+// proof is nil and rand is not used.
+func (s *Server) BlindEvaluate(rand io.Reader, blinded []ecgeneric.Point) ([]ecgeneric.Point, *Proof, error) {
+	suite := s.Suite
+	evaluated := make([]ecgeneric.Point, len(blinded))
+	for i, b := range blinded {
+		if !suite.validElement(b) {
+			return nil, nil, errPoint
+		}
+		evaluated[i] = suite.mul(b, s.Key.D)
+	}
+	if s.Mode != ModeVOPRF {
+		return evaluated, nil, nil
+	}
+	B := ecgeneric.Point{X: s.Key.X, Y: s.Key.Y}
+	proof, err := suite.generateProof(rand, s.Mode, s.Key.D, B, blinded, evaluated)
+	if err != nil {
+		return nil, nil, err
+	}
+	return evaluated, proof, nil
+}
+
+// Evaluate computes the PRF output for input directly, as a client would
+// after a blind evaluation. A server uses it to recognise outputs it has
+// issued.
+func (s *Server) Evaluate(input []byte) ([]byte, error) {
+	if len(input) > 0xffff {
+		return nil, ErrInvalidInput
+	}
+	P, err := s.Suite.hashToGroup(s.Mode, input)
+	if err != nil {
+		return nil, err
+	}
+	return s.Suite.finalizeHash(input, s.Suite.mul(P, s.Key.D)), nil
+}
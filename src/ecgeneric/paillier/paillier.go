@@ -0,0 +1,209 @@
+// Package paillier implements the Paillier cryptosystem together with the
+// ring-Pedersen commitments and zero-knowledge proofs that threshold ECDSA
+// protocols build on.
+//
+// Keys use Blum moduli N = p·q with p ≡ q ≡ 3 (mod 4) and the generator
+// 1+N, so encryption of m with randomness ρ is (1+N)^m·ρ^N mod N². The same
+// modulus doubles as the ring-Pedersen modulus of its owner. The proofs follow
+// Canetti, Gennaro, Goldfeder, Makriyannis, Peled, "UC Non-Interactive,
+// Proactive, Threshold ECDSA with Identifiable Aborts" (CCS 2020).
+package paillier
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+)
+
+var (
+	ErrMessageRange    = errors.New("paillier: message is out of range")
+	ErrCiphertextRange = errors.New("paillier: ciphertext is out of range")
+)
+
+var one = big.NewInt(1)
+
+// PublicKey is a Paillier public key.
+type PublicKey struct {
+	N *big.Int
+
+	n2 *big.Int
+}
+
+// PrivateKey is a Paillier private key.
+type PrivateKey struct {
+	PublicKey
+	P, Q *big.Int
+
+	phi *big.Int
+	mu  *big.Int
+}
+
+// NewPublicKey returns the public key with modulus n.
+func NewPublicKey(n *big.Int) *PublicKey {
+	return &PublicKey{N: n, n2: new(big.Int).Mul(n, n)}
+}
+
+// NSquare returns N².
+func (pk *PublicKey) NSquare() *big.Int {
+	if pk.n2 == nil {
+		pk.n2 = new(big.Int).Mul(pk.N, pk.N)
+	}
+	return pk.n2
+}
+
+// GenerateKey returns a key whose modulus is the product of two Blum primes
+// of bits/2 bits each.
+func GenerateKey(random io.Reader, bits int) (*PrivateKey, error) {
+	for {
+		p, err := blumPrime(random, bits/2)
+		if err != nil {
+			return nil, err
+		}
+		q, err := blumPrime(random, bits-bits/2)
+		if err != nil {
+			return nil, err
+		}
+		if p.Cmp(q) == 0 {
+			continue
+		}
+		sk, err := NewPrivateKey(p, q)
+		if err != nil {
+			return nil, err
+		}
+		if sk.N.BitLen() == bits {
+			return sk, nil
+		}
+	}
+}
+
+// blumPrime returns a prime p ≡ 3 (mod 4) of the given size.
+func blumPrime(random io.Reader, bits int) (*big.Int, error) {
+	for {
+		p, err := rand.Prime(random, bits)
+		if err != nil {
+			return nil, err
+		}
+		if p.Bit(0) == 1 && p.Bit(1) == 1 {
+			return p, nil
+		}
+	}
+}
+
+// NewPrivateKey returns the key with the prime factors p and q.
+func NewPrivateKey(p, q *big.Int) (*PrivateKey, error) {
+	n := new(big.Int).Mul(p, q)
+	pm1 := new(big.Int).Sub(p, one)
+	qm1 := new(big.Int).Sub(q, one)
+	phi := new(big.Int).Mul(pm1, qm1)
+	mu := new(big.Int).ModInverse(phi, n)
+	if mu == nil {
+		return nil, errors.New("paillier: invalid prime factors")
+	}
+	return &PrivateKey{
+		PublicKey: *NewPublicKey(n),
+		P:         new(big.Int).Set(p),
+		Q:         new(big.Int).Set(q),
+		phi:       phi,
+		mu:        mu,
+	}, nil
+}
+
+// Phi returns φ(N).
+func (sk *PrivateKey) Phi() *big.Int {
+	return new(big.Int).Set(sk.phi)
+}
+
+// Nonce returns a random element of Z*_N.
+func (pk *PublicKey) Nonce(random io.Reader) (*big.Int, error) {
+	for {
+		r, err := rand.Int(random, pk.N)
+		if err != nil {
+			return nil, err
+		}
+		if r.Sign() > 0 && new(big.Int).GCD(nil, nil, r, pk.N).Cmp(one) == 0 {
+			return r, nil
+		}
+	}
+}
+
+// EncryptWithNonce returns (1+N)^m·ρ^N mod N². m may be negative; it is
+// taken modulo N.
+func (pk *PublicKey) EncryptWithNonce(m, rho *big.Int) *big.Int {
+	n2 := pk.NSquare()
+	// (1+N)^m = 1 + m·N mod N²
+	c := new(big.Int).Mod(m, pk.N)
+	c.Mul(c, pk.N)
+	c.Add(c, one)
+	c.Mul(c, new(big.Int).Exp(rho, pk.N, n2))
+	return c.Mod(c, n2)
+}
+
+// Encrypt encrypts m with fresh randomness, which it also returns. m may be
+// negative but must be less than N in absolute value; otherwise Encrypt
+// returns ErrMessageRange.
+func (pk *PublicKey) Encrypt(random io.Reader, m *big.Int) (c, rho *big.Int, err error) {
+	if m.CmpAbs(pk.N) >= 0 {
+		return nil, nil, ErrMessageRange
+	}
+	rho, err = pk.Nonce(random)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pk.EncryptWithNonce(m, rho), rho, nil
+}
+
+// ValidateCiphertext reports whether c is a unit modulo N².
+func (pk *PublicKey) ValidateCiphertext(c *big.Int) bool {
+	if c == nil || c.Sign() <= 0 || c.Cmp(pk.NSquare()) >= 0 {
+		return false
+	}
+	return new(big.Int).GCD(nil, nil, c, pk.N).Cmp(one) == 0
+}
+
+// Add returns an encryption of the sum of the plaintexts of a and b.
+func (pk *PublicKey) Add(a, b *big.Int) *big.Int {
+	c := new(big.Int).Mul(a, b)
+	return c.Mod(c, pk.NSquare())
+}
+
+// Mul returns an encryption of k times the plaintext of c. k may be negative.
+func (pk *PublicKey) Mul(c, k *big.Int) *big.Int {
+	return expSigned(c, k, pk.NSquare())
+}
+
+// Decrypt returns the plaintext of c in [0, N).
+func (sk *PrivateKey) Decrypt(c *big.Int) (*big.Int, error) {
+	if !sk.ValidateCiphertext(c) {
+		return nil, ErrCiphertextRange
+	}
+	m := new(big.Int).Exp(c, sk.phi, sk.NSquare())
+	m.Sub(m, one)
+	m.Div(m, sk.N)
+	m.Mul(m, sk.mu)
+	return m.Mod(m, sk.N), nil
+}
+
+// DecryptSigned returns the plaintext of c in (-N/2, N/2].
+func (sk *PrivateKey) DecryptSigned(c *big.Int) (*big.Int, error) {
+	m, err := sk.Decrypt(c)
+	if err != nil {
+		return nil, err
+	}
+	if m.Cmp(new(big.Int).Rsh(sk.N, 1)) > 0 {
+		m.Sub(m, sk.N)
+	}
+	return m, nil
+}
+
+// expSigned returns b^e mod m, inverting b for negative e.
+func expSigned(b, e, m *big.Int) *big.Int {
+	if e.Sign() >= 0 {
+		return new(big.Int).Exp(b, e, m)
+	}
+	inv := new(big.Int).ModInverse(b, m)
+	if inv == nil {
+		return new(big.Int)
+	}
+	return inv.Exp(inv, new(big.Int).Neg(e), m)
+}
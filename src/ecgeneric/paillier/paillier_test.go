@@ -0,0 +1,142 @@
+package paillier_test
+
+import (
+	"crypto/rand"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/nist"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/paillier"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	keysOnce sync.Once
+	keys     [2]*paillier.PrivateKey
+)
+
+func testKeys(t *testing.T) (*paillier.PrivateKey, *paillier.PrivateKey) {
+	keysOnce.Do(func() {
+		for i := range keys {
+			sk, err := paillier.GenerateKey(rand.Reader, paillier.ModulusBits)
+			require.NoError(t, err)
+			keys[i] = sk
+		}
+	})
+	return keys[0], keys[1]
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	sk, _ := testKeys(t)
+	require.Equal(t, paillier.ModulusBits, sk.N.BitLen())
+
+	a, b := big.NewInt(123456789), big.NewInt(-987654321)
+	ca, _, err := sk.Encrypt(rand.Reader, a)
+	require.NoError(t, err)
+	cb, _, err := sk.Encrypt(rand.Reader, b)
+	require.NoError(t, err)
+
+	m, err := sk.DecryptSigned(ca)
+	require.NoError(t, err)
+	require.Equal(t, a, m)
+	m, err = sk.DecryptSigned(sk.Add(ca, cb))
+	require.NoError(t, err)
+	require.Equal(t, new(big.Int).Add(a, b), m)
+	m, err = sk.DecryptSigned(sk.Mul(ca, big.NewInt(-3)))
+	require.NoError(t, err)
+	require.Equal(t, new(big.Int).Mul(a, big.NewInt(-3)), m)
+	m, err = sk.Decrypt(cb)
+	require.NoError(t, err)
+	require.Equal(t, new(big.Int).Add(sk.N, b), m)
+
+	_, err = sk.Decrypt(sk.N)
+	require.ErrorIs(t, err, paillier.ErrCiphertextRange)
+	_, _, err = sk.Encrypt(rand.Reader, sk.N)
+	require.ErrorIs(t, err, paillier.ErrMessageRange)
+	_, _, err = sk.Encrypt(rand.Reader, new(big.Int).Neg(sk.N))
+	require.ErrorIs(t, err, paillier.ErrMessageRange)
+}
+
+func TestModPrmProofs(t *testing.T) {
+	sk, other := testKeys(t)
+	ctx := []byte("test")
+
+	mod, err := paillier.ProveMod(rand.Reader, ctx, sk)
+	require.NoError(t, err)
+	require.True(t, mod.Verify(ctx, &sk.PublicKey))
+	require.False(t, mod.Verify([]byte("other"), &sk.PublicKey))
+	require.False(t, mod.Verify(ctx, &other.PublicKey))
+
+	rp, lambda, err := sk.RingPedersen(rand.Reader)
+	require.NoError(t, err)
+	require.NoError(t, rp.Validate())
+	prm, err := paillier.ProvePrm(rand.Reader, ctx, rp, lambda, sk.Phi())
+	require.NoError(t, err)
+	require.True(t, prm.Verify(ctx, rp))
+	bad := *rp
+	bad.S = new(big.Int).Mul(rp.S, rp.S)
+	require.False(t, prm.Verify(ctx, &bad))
+}
+
+func TestRangeProofs(t *testing.T) {
+	prover, verifier := testKeys(t)
+	curve := &nist.Secp256k1
+	g := ecgeneric.Point{X: curve.Gx, Y: curve.Gy}
+	rp, _, err := verifier.RingPedersen(rand.Reader)
+	require.NoError(t, err)
+	ctx := []byte("test")
+
+	x, err := rand.Int(rand.Reader, curve.N)
+	require.NoError(t, err)
+	k, rho, err := prover.Encrypt(rand.Reader, x)
+	require.NoError(t, err)
+
+	enc, err := paillier.ProveEnc(rand.Reader, ctx, &prover.PublicKey, k, x, rho, rp)
+	require.NoError(t, err)
+	require.True(t, enc.Verify(ctx, &prover.PublicKey, k, rp))
+	require.False(t, enc.Verify(ctx, &prover.PublicKey, prover.Add(k, k), rp))
+
+	// A value far outside the range cannot be proven.
+	huge := new(big.Int).Lsh(big.NewInt(1), paillier.Ell+paillier.Epsilon+8)
+	kh, rh, err := prover.Encrypt(rand.Reader, huge)
+	require.NoError(t, err)
+	enc, err = paillier.ProveEnc(rand.Reader, ctx, &prover.PublicKey, kh, huge, rh, rp)
+	require.NoError(t, err)
+	require.False(t, enc.Verify(ctx, &prover.PublicKey, kh, rp))
+
+	X := ecgeneric.Point{}
+	X.X, X.Y = curve.ScalarBaseMultGLV(x.Bytes())
+	ls, err := paillier.ProveLogStar(rand.Reader, ctx, curve, &prover.PublicKey, k, X, g, x, rho, rp)
+	require.NoError(t, err)
+	require.True(t, ls.Verify(ctx, curve, &prover.PublicKey, k, X, g, rp))
+	require.False(t, ls.Verify(ctx, curve, &prover.PublicKey, k, g, g, rp))
+
+	// The prover of Π^aff-g is the respondent, who owns verifier's key here:
+	// D = C^w·Enc₀(β) for C under prover's key, Y = Enc₁(β) under its own.
+	w, err := rand.Int(rand.Reader, curve.N)
+	require.NoError(t, err)
+	W := ecgeneric.Point{}
+	W.X, W.Y = curve.ScalarBaseMultGLV(w.Bytes())
+	beta, err := paillier.SampleSigned(rand.Reader, paillier.EllPrime)
+	require.NoError(t, err)
+	eb, s, err := prover.Encrypt(rand.Reader, beta)
+	require.NoError(t, err)
+	d := prover.Add(prover.Mul(k, w), eb)
+	y, r, err := verifier.Encrypt(rand.Reader, beta)
+	require.NoError(t, err)
+	rpP, _, err := prover.RingPedersen(rand.Reader)
+	require.NoError(t, err)
+
+	aff, err := paillier.ProveAffG(rand.Reader, ctx, curve, &prover.PublicKey, &verifier.PublicKey, k, d, y, W, w, beta, s, r, rpP)
+	require.NoError(t, err)
+	require.True(t, aff.Verify(ctx, curve, &prover.PublicKey, &verifier.PublicKey, k, d, y, W, rpP))
+	require.False(t, aff.Verify(ctx, curve, &prover.PublicKey, &verifier.PublicKey, k, prover.Add(d, k), y, W, rpP))
+	require.False(t, aff.Verify(ctx, curve, &prover.PublicKey, &verifier.PublicKey, k, d, y, X, rpP))
+
+	got, err := prover.DecryptSigned(d)
+	require.NoError(t, err)
+	want := new(big.Int).Mul(x, w)
+	require.Equal(t, want.Add(want, beta), got)
+}
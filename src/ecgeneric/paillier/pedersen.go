@@ -0,0 +1,61 @@
+package paillier
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+)
+
+// RingPedersen holds the public parameters of ring-Pedersen commitments
+// s^x·t^y mod N, where s = t^λ for a λ known only to the owner of N.
+type RingPedersen struct {
+	N, S, T *big.Int
+}
+
+// RingPedersen derives fresh ring-Pedersen parameters over the key's modulus
+// and returns them with the trapdoor λ.
+func (sk *PrivateKey) RingPedersen(random io.Reader) (*RingPedersen, *big.Int, error) {
+	r, err := sk.Nonce(random)
+	if err != nil {
+		return nil, nil, err
+	}
+	t := r.Mul(r, r)
+	t.Mod(t, sk.N)
+	lambda, err := rand.Int(random, sk.phi)
+	if err != nil {
+		return nil, nil, err
+	}
+	s := new(big.Int).Exp(t, lambda, sk.N)
+	return &RingPedersen{N: new(big.Int).Set(sk.N), S: s, T: t}, lambda, nil
+}
+
+// Commit returns s^x·t^y mod N. Either exponent may be negative.
+func (rp *RingPedersen) Commit(x, y *big.Int) *big.Int {
+	c := expSigned(rp.S, x, rp.N)
+	c.Mul(c, expSigned(rp.T, y, rp.N))
+	return c.Mod(c, rp.N)
+}
+
+// Validate performs the basic sanity checks on the parameters; the proof of
+// their well-formedness is PrmProof.
+func (rp *RingPedersen) Validate() error {
+	if rp == nil || rp.N == nil || rp.S == nil || rp.T == nil {
+		return errors.New("paillier: missing ring-Pedersen parameters")
+	}
+	if rp.N.Bit(0) == 0 || rp.N.BitLen() < ModulusBits {
+		return errors.New("paillier: ring-Pedersen modulus is too small")
+	}
+	if !isUnit(rp.S, rp.N) || !isUnit(rp.T, rp.N) || rp.S.Cmp(rp.T) == 0 {
+		return errors.New("paillier: invalid ring-Pedersen parameters")
+	}
+	return nil
+}
+
+// isUnit reports whether 0 < x < n and gcd(x, n) = 1.
+func isUnit(x, n *big.Int) bool {
+	if x == nil || x.Sign() <= 0 || x.Cmp(n) >= 0 {
+		return false
+	}
+	return new(big.Int).GCD(nil, nil, x, n).Cmp(one) == 0
+}
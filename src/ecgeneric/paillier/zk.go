@@ -0,0 +1,569 @@
+package paillier
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/transcript"
+)
+
+// Range proof parameters for curves with 256-bit orders.
+const (
+	// Ell is the bit size of the secrets the range proofs are about.
+	Ell = 256
+	// EllPrime bounds the masks added to products in MtA.
+	EllPrime = 5 * Ell
+	// Epsilon is the slack of the range proofs.
+	Epsilon = 2 * Ell
+	// ModulusBits is the size of the Paillier moduli the proofs need.
+	ModulusBits = 8 * Ell
+
+	// iterations is the number of repetitions of the proofs with binary
+	// or otherwise weak challenges.
+	iterations = 80
+)
+
+// newTranscript returns the Fiat-Shamir transcript of a proof, on the
+// SHA-256 backend.
+func newTranscript(label string, context []byte) *transcript.Transcript {
+	t := transcript.New(transcript.SHA256, label)
+	t.AppendMessage("context", context)
+	return t
+}
+
+// appendInts records signed integers as a sign byte and the magnitude.
+func appendInts(t *transcript.Transcript, xs ...*big.Int) {
+	for _, x := range xs {
+		sign := byte(0)
+		if x.Sign() < 0 {
+			sign = 1
+		}
+		t.AppendMessage("int", append([]byte{sign}, x.Bytes()...))
+	}
+}
+
+// challenge returns an Ell-bit challenge.
+func challenge(t *transcript.Transcript) *big.Int {
+	return new(big.Int).SetBytes(t.ChallengeBytes("challenge", Ell/8))
+}
+
+// challengeModN returns the i-th challenge modulo n. It draws 128 bits more
+// than n has, which makes the bias of the reduction negligible.
+func challengeModN(t *transcript.Transcript, i int, n *big.Int) *big.Int {
+	t.AppendUint64("index", uint64(i))
+	b := t.ChallengeBytes("y", (n.BitLen()+7)/8+16)
+	return new(big.Int).Mod(new(big.Int).SetBytes(b), n)
+}
+
+// sampleSigned returns a uniform integer in [-2^bits·m, 2^bits·m]; a nil m
+// is taken as 1.
+func sampleSigned(random io.Reader, bits int, m *big.Int) (*big.Int, error) {
+	bound := new(big.Int).Lsh(one, uint(bits))
+	if m != nil {
+		bound.Mul(bound, m)
+	}
+	width := new(big.Int).Lsh(bound, 1)
+	x, err := rand.Int(random, width.Add(width, one))
+	if err != nil {
+		return nil, err
+	}
+	return x.Sub(x, bound), nil
+}
+
+// SampleSigned returns a uniform integer in [-2^bits, 2^bits].
+func SampleSigned(random io.Reader, bits int) (*big.Int, error) {
+	return sampleSigned(random, bits, nil)
+}
+
+// inRange reports whether |x| ≤ 2^bits.
+func inRange(x *big.Int, bits int) bool {
+	return x.CmpAbs(new(big.Int).Lsh(one, uint(bits))) <= 0
+}
+
+func nonNil(xs ...*big.Int) bool {
+	for _, x := range xs {
+		if x == nil {
+			return false
+		}
+	}
+	return true
+}
+
+func mulPoint(curve *ecgeneric.CurveParams, p ecgeneric.Point, k *big.Int) ecgeneric.Point {
+	km := new(big.Int).Mod(k, curve.N)
+	x, y := curve.ScalarMultGLV(p.X, p.Y, km.Bytes())
+	return ecgeneric.Point{X: x, Y: y}
+}
+
+func addPoint(curve *ecgeneric.CurveParams, a, b ecgeneric.Point) ecgeneric.Point {
+	x, y := curve.AddJ(a.X, a.Y, b.X, b.Y)
+	return ecgeneric.Point{X: x, Y: y}
+}
+
+func validPoint(curve *ecgeneric.CurveParams, p ecgeneric.Point) bool {
+	return p.X != nil && p.Y != nil && curve.IsOnCurve(p.X, p.Y)
+}
+
+func equalPoint(a, b ecgeneric.Point) bool {
+	return a.X.Cmp(b.X) == 0 && a.Y.Cmp(b.Y) == 0
+}
+
+// ModProof proves that a Paillier modulus is a product of two Blum primes
+// with gcd(N, φ(N)) = 1 (Π^mod).
+type ModProof struct {
+	W    *big.Int
+	X, Z []*big.Int
+	A, B []bool
+}
+
+// fourthRootExp returns the exponent that maps a quadratic residue modulo
+// the Blum prime p to its fourth root that is itself a quadratic residue.
+func fourthRootExp(p *big.Int) *big.Int {
+	e := new(big.Int).Add(p, one)
+	e.Rsh(e, 2)
+	e.Mul(e, e)
+	return e.Mod(e, new(big.Int).Sub(p, one))
+}
+
+// ProveMod proves that sk's modulus is a Paillier-Blum modulus.
+func ProveMod(random io.Reader, context []byte, sk *PrivateKey) (*ModProof, error) {
+	n := sk.N
+	var w *big.Int
+	for {
+		var err error
+		if w, err = sk.Nonce(random); err != nil {
+			return nil, err
+		}
+		if big.Jacobi(w, n) == -1 {
+			break
+		}
+	}
+	t := newTranscript("paillier/mod", context)
+	appendInts(t, n, w)
+
+	nInv := new(big.Int).ModInverse(n, sk.phi)
+	if nInv == nil {
+		return nil, errors.New("paillier: N is not invertible modulo φ(N)")
+	}
+	ep, eq := fourthRootExp(sk.P), fourthRootExp(sk.Q)
+	np := new(big.Int).Mod(nInv, new(big.Int).Sub(sk.P, one))
+	nq := new(big.Int).Mod(nInv, new(big.Int).Sub(sk.Q, one))
+	qInv := new(big.Int).ModInverse(sk.Q, sk.P)
+	// crt returns x ≡ xp (mod p), x ≡ xq (mod q).
+	crt := func(xp, xq *big.Int) *big.Int {
+		x := new(big.Int).Sub(xp, xq)
+		x.Mul(x, qInv)
+		x.Mod(x, sk.P)
+		x.Mul(x, sk.Q)
+		return x.Add(x, xq)
+	}
+
+	p := &ModProof{
+		W: w,
+		X: make([]*big.Int, iterations),
+		Z: make([]*big.Int, iterations),
+		A: make([]bool, iterations),
+		B: make([]bool, iterations),
+	}
+	for i := 0; i < iterations; i++ {
+		y := challengeModN(t, i, n)
+		p.Z[i] = crt(
+			new(big.Int).Exp(new(big.Int).Mod(y, sk.P), np, sk.P),
+			new(big.Int).Exp(new(big.Int).Mod(y, sk.Q), nq, sk.Q),
+		)
+	search:
+		for _, a := range []bool{false, true} {
+			for _, b := range []bool{false, true} {
+				v := new(big.Int).Set(y)
+				if a {
+					v.Neg(v)
+				}
+				if b {
+					v.Mul(v, w)
+				}
+				v.Mod(v, n)
+				vp, vq := new(big.Int).Mod(v, sk.P), new(big.Int).Mod(v, sk.Q)
+				if big.Jacobi(vp, sk.P) != 1 || big.Jacobi(vq, sk.Q) != 1 {
+					continue
+				}
+				p.X[i] = crt(vp.Exp(vp, ep, sk.P), vq.Exp(vq, eq, sk.Q))
+				p.A[i], p.B[i] = a, b
+				break search
+			}
+		}
+		if p.X[i] == nil {
+			return nil, errors.New("paillier: challenge is not a unit")
+		}
+	}
+	return p, nil
+}
+
+// Verify checks the proof for pk.
+func (p *ModProof) Verify(context []byte, pk *PublicKey) bool {
+	n := pk.N
+	if p == nil || len(p.X) != iterations || len(p.Z) != iterations || len(p.A) != iterations || len(p.B) != iterations {
+		return false
+	}
+	if n.Bit(0) == 0 || n.ProbablyPrime(20) {
+		return false
+	}
+	if !isUnit(p.W, n) || big.Jacobi(p.W, n) != -1 {
+		return false
+	}
+	t := newTranscript("paillier/mod", context)
+	appendInts(t, n, p.W)
+	four := big.NewInt(4)
+	for i := 0; i < iterations; i++ {
+		if !isUnit(p.X[i], n) || !isUnit(p.Z[i], n) {
+			return false
+		}
+		y := challengeModN(t, i, n)
+		if new(big.Int).Exp(p.Z[i], n, n).Cmp(y) != 0 {
+			return false
+		}
+		if p.A[i] {
+			y.Neg(y)
+		}
+		if p.B[i] {
+			y.Mul(y, p.W)
+		}
+		y.Mod(y, n)
+		if new(big.Int).Exp(p.X[i], four, n).Cmp(y) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// PrmProof proves that the ring-Pedersen parameter s lies in the group
+// generated by t (Π^prm).
+type PrmProof struct {
+	A, Z []*big.Int
+}
+
+func prmChallenge(context []byte, rp *RingPedersen, a []*big.Int) *big.Int {
+	t := newTranscript("paillier/prm", context)
+	appendInts(t, rp.N, rp.S, rp.T)
+	appendInts(t, a...)
+	return challenge(t)
+}
+
+// ProvePrm proves knowledge of λ with s = t^λ mod N, given φ(N).
+func ProvePrm(random io.Reader, context []byte, rp *RingPedersen, lambda, phi *big.Int) (*PrmProof, error) {
+	a := make([]*big.Int, iterations)
+	p := &PrmProof{A: make([]*big.Int, iterations), Z: make([]*big.Int, iterations)}
+	for i := range a {
+		var err error
+		if a[i], err = rand.Int(random, phi); err != nil {
+			return nil, err
+		}
+		p.A[i] = new(big.Int).Exp(rp.T, a[i], rp.N)
+	}
+	e := prmChallenge(context, rp, p.A)
+	for i := range a {
+		z := new(big.Int).Set(a[i])
+		if e.Bit(i) == 1 {
+			z.Add(z, lambda)
+		}
+		p.Z[i] = z.Mod(z, phi)
+	}
+	return p, nil
+}
+
+// Verify checks the proof for rp.
+func (p *PrmProof) Verify(context []byte, rp *RingPedersen) bool {
+	if p == nil || len(p.A) != iterations || len(p.Z) != iterations {
+		return false
+	}
+	if !nonNil(p.A...) || !nonNil(p.Z...) {
+		return false
+	}
+	e := prmChallenge(context, rp, p.A)
+	for i := range p.A {
+		if !isUnit(p.A[i], rp.N) || p.Z[i].Sign() < 0 {
+			return false
+		}
+		rhs := new(big.Int).Set(p.A[i])
+		if e.Bit(i) == 1 {
+			rhs.Mul(rhs, rp.S)
+			rhs.Mod(rhs, rp.N)
+		}
+		if new(big.Int).Exp(rp.T, p.Z[i], rp.N).Cmp(rhs) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// EncProof proves that the ciphertext K under the prover's key encrypts a
+// value in ±2^Ell (Π^enc). It is made against the verifier's ring-Pedersen
+// parameters.
+type EncProof struct {
+	S, A, C    *big.Int
+	Z1, Z2, Z3 *big.Int
+}
+
+func encChallenge(context []byte, pk *PublicKey, k *big.Int, rp *RingPedersen, s, a, c *big.Int) *big.Int {
+	t := newTranscript("paillier/enc", context)
+	appendInts(t, pk.N, k, rp.N, rp.S, rp.T, s, a, c)
+	return challenge(t)
+}
+
+// ProveEnc proves that K = Enc(x; ρ) under pk with x in range.
+func ProveEnc(random io.Reader, context []byte, pk *PublicKey, k, x, rho *big.Int, rp *RingPedersen) (*EncProof, error) {
+	alpha, err := sampleSigned(random, Ell+Epsilon, nil)
+	if err != nil {
+		return nil, err
+	}
+	mu, err := sampleSigned(random, Ell, rp.N)
+	if err != nil {
+		return nil, err
+	}
+	gamma, err := sampleSigned(random, Ell+Epsilon, rp.N)
+	if err != nil {
+		return nil, err
+	}
+	a, r, err := pk.Encrypt(random, alpha)
+	if err != nil {
+		return nil, err
+	}
+	p := &EncProof{S: rp.Commit(x, mu), A: a, C: rp.Commit(alpha, gamma)}
+	e := encChallenge(context, pk, k, rp, p.S, p.A, p.C)
+
+	p.Z1 = new(big.Int).Mul(e, x)
+	p.Z1.Add(p.Z1, alpha)
+	p.Z2 = new(big.Int).Exp(rho, e, pk.N)
+	p.Z2.Mul(p.Z2, r)
+	p.Z2.Mod(p.Z2, pk.N)
+	p.Z3 = new(big.Int).Mul(e, mu)
+	p.Z3.Add(p.Z3, gamma)
+	return p, nil
+}
+
+// Verify checks the proof for the ciphertext k under pk.
+func (p *EncProof) Verify(context []byte, pk *PublicKey, k *big.Int, rp *RingPedersen) bool {
+	if p == nil || !nonNil(p.S, p.A, p.C, p.Z1, p.Z2, p.Z3) {
+		return false
+	}
+	if !inRange(p.Z1, Ell+Epsilon) || !isUnit(p.Z2, pk.N) {
+		return false
+	}
+	if !pk.ValidateCiphertext(k) || !pk.ValidateCiphertext(p.A) || !isUnit(p.S, rp.N) || !isUnit(p.C, rp.N) {
+		return false
+	}
+	e := encChallenge(context, pk, k, rp, p.S, p.A, p.C)
+
+	if pk.EncryptWithNonce(p.Z1, p.Z2).Cmp(pk.Add(p.A, pk.Mul(k, e))) != 0 {
+		return false
+	}
+	rhs := new(big.Int).Exp(p.S, e, rp.N)
+	rhs.Mul(rhs, p.C)
+	return rp.Commit(p.Z1, p.Z3).Cmp(rhs.Mod(rhs, rp.N)) == 0
+}
+
+// LogStarProof proves that the ciphertext C under the prover's key
+// encrypts the discrete logarithm of X to the base g, in ±2^Ell (Π^log*).
+type LogStarProof struct {
+	S, A, D    *big.Int
+	Y          ecgeneric.Point
+	Z1, Z2, Z3 *big.Int
+}
+
+func logStarChallenge(context []byte, curve *ecgeneric.CurveParams, pk *PublicKey, c *big.Int, x, g ecgeneric.Point, rp *RingPedersen, p *LogStarProof) *big.Int {
+	t := newTranscript("paillier/log*", context)
+	t.AppendMessage("curve", []byte(curve.Name))
+	appendInts(t, pk.N, c, rp.N, rp.S, rp.T, p.S, p.A, p.D)
+	t.AppendPoint(curve, "point", x)
+	t.AppendPoint(curve, "point", g)
+	t.AppendPoint(curve, "point", p.Y)
+	return challenge(t)
+}
+
+// ProveLogStar proves that C = Enc(x; ρ) under pk and X = x·g.
+func ProveLogStar(random io.Reader, context []byte, curve *ecgeneric.CurveParams, pk *PublicKey, c *big.Int, X, g ecgeneric.Point, x, rho *big.Int, rp *RingPedersen) (*LogStarProof, error) {
+	alpha, err := sampleSigned(random, Ell+Epsilon, nil)
+	if err != nil {
+		return nil, err
+	}
+	mu, err := sampleSigned(random, Ell, rp.N)
+	if err != nil {
+		return nil, err
+	}
+	gamma, err := sampleSigned(random, Ell+Epsilon, rp.N)
+	if err != nil {
+		return nil, err
+	}
+	a, r, err := pk.Encrypt(random, alpha)
+	if err != nil {
+		return nil, err
+	}
+	p := &LogStarProof{
+		S: rp.Commit(x, mu),
+		A: a,
+		D: rp.Commit(alpha, gamma),
+		Y: mulPoint(curve, g, alpha),
+	}
+	e := logStarChallenge(context, curve, pk, c, X, g, rp, p)
+
+	p.Z1 = new(big.Int).Mul(e, x)
+	p.Z1.Add(p.Z1, alpha)
+	p.Z2 = new(big.Int).Exp(rho, e, pk.N)
+	p.Z2.Mul(p.Z2, r)
+	p.Z2.Mod(p.Z2, pk.N)
+	p.Z3 = new(big.Int).Mul(e, mu)
+	p.Z3.Add(p.Z3, gamma)
+	return p, nil
+}
+
+// Verify checks the proof for the ciphertext c under pk and X = x·g.
+func (p *LogStarProof) Verify(context []byte, curve *ecgeneric.CurveParams, pk *PublicKey, c *big.Int, X, g ecgeneric.Point, rp *RingPedersen) bool {
+	if p == nil || !nonNil(p.S, p.A, p.D, p.Z1, p.Z2, p.Z3) || !validPoint(curve, p.Y) {
+		return false
+	}
+	if !validPoint(curve, X) || !validPoint(curve, g) {
+		return false
+	}
+	if !inRange(p.Z1, Ell+Epsilon) || !isUnit(p.Z2, pk.N) {
+		return false
+	}
+	if !pk.ValidateCiphertext(c) || !pk.ValidateCiphertext(p.A) || !isUnit(p.S, rp.N) || !isUnit(p.D, rp.N) {
+		return false
+	}
+	e := logStarChallenge(context, curve, pk, c, X, g, rp, p)
+
+	if pk.EncryptWithNonce(p.Z1, p.Z2).Cmp(pk.Add(p.A, pk.Mul(c, e))) != 0 {
+		return false
+	}
+	if !equalPoint(mulPoint(curve, g, p.Z1), addPoint(curve, p.Y, mulPoint(curve, X, e))) {
+		return false
+	}
+	rhs := new(big.Int).Exp(p.S, e, rp.N)
+	rhs.Mul(rhs, p.D)
+	return rp.Commit(p.Z1, p.Z3).Cmp(rhs.Mod(rhs, rp.N)) == 0
+}
+
+// AffGProof proves that D = C^x·Enc₀(y; ρ) under the key pk0 of the owner
+// of C, that Y = Enc₁(y; ρy) under the prover's key pk1 and that X = x·G,
+// with x in ±2^Ell and y in ±2^EllPrime (Π^aff-g). This is the
+// respondent's proof in the multiplicative-to-additive conversion.
+type AffGProof struct {
+	A, By          *big.Int
+	E, S, F, T     *big.Int
+	Bx             ecgeneric.Point
+	Z1, Z2, Z3, Z4 *big.Int
+	W, Wy          *big.Int
+}
+
+func affGChallenge(context []byte, curve *ecgeneric.CurveParams, pk0, pk1 *PublicKey, c, d, y *big.Int, x ecgeneric.Point, rp *RingPedersen, p *AffGProof) *big.Int {
+	t := newTranscript("paillier/aff-g", context)
+	t.AppendMessage("curve", []byte(curve.Name))
+	appendInts(t, pk0.N, pk1.N, c, d, y, rp.N, rp.S, rp.T, p.A, p.By, p.E, p.S, p.F, p.T)
+	t.AppendPoint(curve, "point", x)
+	t.AppendPoint(curve, "point", p.Bx)
+	return challenge(t)
+}
+
+// ProveAffG proves the statement of AffGProof with the witness x, y, ρ, ρy.
+func ProveAffG(random io.Reader, context []byte, curve *ecgeneric.CurveParams, pk0, pk1 *PublicKey, c, d, y *big.Int, X ecgeneric.Point, x, yv, rho, rhoY *big.Int, rp *RingPedersen) (*AffGProof, error) {
+	var alpha, beta, gamma, m, delta, mu *big.Int
+	for _, s := range []struct {
+		v    **big.Int
+		bits int
+		m    *big.Int
+	}{
+		{&alpha, Ell + Epsilon, nil},
+		{&beta, EllPrime + Epsilon, nil},
+		{&gamma, Ell + Epsilon, rp.N},
+		{&m, Ell, rp.N},
+		{&delta, Ell + Epsilon, rp.N},
+		{&mu, Ell, rp.N},
+	} {
+		v, err := sampleSigned(random, s.bits, s.m)
+		if err != nil {
+			return nil, err
+		}
+		*s.v = v
+	}
+	r, err := pk0.Nonce(random)
+	if err != nil {
+		return nil, err
+	}
+	ry, err := pk1.Nonce(random)
+	if err != nil {
+		return nil, err
+	}
+	p := &AffGProof{
+		A:  pk0.Add(pk0.Mul(c, alpha), pk0.EncryptWithNonce(beta, r)),
+		By: pk1.EncryptWithNonce(beta, ry),
+		E:  rp.Commit(alpha, gamma),
+		S:  rp.Commit(x, m),
+		F:  rp.Commit(beta, delta),
+		T:  rp.Commit(yv, mu),
+		Bx: mulPoint(curve, ecgeneric.Point{X: curve.Gx, Y: curve.Gy}, alpha),
+	}
+	e := affGChallenge(context, curve, pk0, pk1, c, d, y, X, rp, p)
+
+	lin := func(a, b *big.Int) *big.Int {
+		z := new(big.Int).Mul(e, b)
+		return z.Add(z, a)
+	}
+	p.Z1, p.Z2, p.Z3, p.Z4 = lin(alpha, x), lin(beta, yv), lin(gamma, m), lin(delta, mu)
+	p.W = new(big.Int).Exp(rho, e, pk0.N)
+	p.W.Mul(p.W, r)
+	p.W.Mod(p.W, pk0.N)
+	p.Wy = new(big.Int).Exp(rhoY, e, pk1.N)
+	p.Wy.Mul(p.Wy, ry)
+	p.Wy.Mod(p.Wy, pk1.N)
+	return p, nil
+}
+
+// Verify checks the proof.
+func (p *AffGProof) Verify(context []byte, curve *ecgeneric.CurveParams, pk0, pk1 *PublicKey, c, d, y *big.Int, X ecgeneric.Point, rp *RingPedersen) bool {
+	if p == nil || !nonNil(p.A, p.By, p.E, p.S, p.F, p.T, p.Z1, p.Z2, p.Z3, p.Z4, p.W, p.Wy) {
+		return false
+	}
+	if !validPoint(curve, p.Bx) || !validPoint(curve, X) {
+		return false
+	}
+	if !inRange(p.Z1, Ell+Epsilon) || !inRange(p.Z2, EllPrime+Epsilon) {
+		return false
+	}
+	if !isUnit(p.W, pk0.N) || !isUnit(p.Wy, pk1.N) {
+		return false
+	}
+	for _, v := range []*big.Int{c, d, p.A} {
+		if !pk0.ValidateCiphertext(v) {
+			return false
+		}
+	}
+	if !pk1.ValidateCiphertext(y) || !pk1.ValidateCiphertext(p.By) {
+		return false
+	}
+	for _, v := range []*big.Int{p.E, p.S, p.F, p.T} {
+		if !isUnit(v, rp.N) {
+			return false
+		}
+	}
+	e := affGChallenge(context, curve, pk0, pk1, c, d, y, X, rp, p)
+
+	lhs := pk0.Add(pk0.Mul(c, p.Z1), pk0.EncryptWithNonce(p.Z2, p.W))
+	if lhs.Cmp(pk0.Add(p.A, pk0.Mul(d, e))) != 0 {
+		return false
+	}
+	g := ecgeneric.Point{X: curve.Gx, Y: curve.Gy}
+	if !equalPoint(mulPoint(curve, g, p.Z1), addPoint(curve, p.Bx, mulPoint(curve, X, e))) {
+		return false
+	}
+	if pk1.EncryptWithNonce(p.Z2, p.Wy).Cmp(pk1.Add(p.By, pk1.Mul(y, e))) != 0 {
+		return false
+	}
+	commitCheck := func(z1, z2, a, b *big.Int) bool {
+		rhs := new(big.Int).Exp(b, e, rp.N)
+		rhs.Mul(rhs, a)
+		return rp.Commit(z1, z2).Cmp(rhs.Mod(rhs, rp.N)) == 0
+	}
+	return commitCheck(p.Z1, p.Z3, p.E, p.S) && commitCheck(p.Z2, p.Z4, p.F, p.T)
+}
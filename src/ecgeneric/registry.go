@@ -0,0 +1,50 @@
+package ecgeneric
+
+import (
+	"sort"
+	"sync"
+)
+
+// The curve registry maps curve names to parameters so that keys, seeds and
+// serialized data can refer to a curve by name. The nist and gost packages
+// register their standard curves when they are imported; toy and example
+// curves are left out.
+var (
+	curvesMu sync.RWMutex
+	curves   = make(map[string]*CurveParams)
+)
+
+// RegisterCurve makes curve available under curve.Name. It panics if the name
+// is empty or already taken by different parameters.
+func RegisterCurve(curve *CurveParams) {
+	if curve.Name == "" {
+		panic("ecgeneric: RegisterCurve with empty name")
+	}
+	curvesMu.Lock()
+	defer curvesMu.Unlock()
+	if c, ok := curves[curve.Name]; ok && c != curve {
+		panic("ecgeneric: curve " + curve.Name + " registered twice")
+	}
+	curves[curve.Name] = curve
+}
+
+// CurveByName returns the registered curve with the given name.
+func CurveByName(name string) (*CurveParams, bool) {
+	curvesMu.RLock()
+	defer curvesMu.RUnlock()
+	c, ok := curves[name]
+	return c, ok
+}
+
+// RegisteredCurves returns the names of all registered curves in sorted
+// order.
+func RegisteredCurves() []string {
+	curvesMu.RLock()
+	defer curvesMu.RUnlock()
+	names := make([]string, 0, len(curves))
+	for name := range curves {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,75 @@
+package ecgeneric
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"hash"
+	"math/big"
+)
+
+// NonceRFC6979 derives the deterministic ECDSA nonce k of RFC 6979, section
+// 3.2, for private key x, message hash and group order q, using HMAC with
+// alg. The result is in [1, q-1].
+func NonceRFC6979(x *big.Int, hash []byte, q *big.Int, alg func() hash.Hash) *big.Int {
+	qlen := q.BitLen()
+	holen := alg().Size()
+	rolen := (qlen + 7) >> 3
+	bx := append(int2octets(x, rolen), bits2octets(hash, q, rolen)...)
+
+	// Steps B and C.
+	v := bytes.Repeat([]byte{0x01}, holen)
+	k := make([]byte, holen)
+
+	// Steps D to G.
+	k = hmacSum(alg, k, v, []byte{0x00}, bx)
+	v = hmacSum(alg, k, v)
+	k = hmacSum(alg, k, v, []byte{0x01}, bx)
+	v = hmacSum(alg, k, v)
+
+	// Step H.
+	for {
+		var t []byte
+		for len(t)*8 < qlen {
+			v = hmacSum(alg, k, v)
+			t = append(t, v...)
+		}
+		secret := bits2int(t, qlen)
+		if secret.Sign() > 0 && secret.Cmp(q) < 0 {
+			return secret
+		}
+		k = hmacSum(alg, k, v, []byte{0x00})
+		v = hmacSum(alg, k, v)
+	}
+}
+
+func hmacSum(alg func() hash.Hash, key []byte, data ...[]byte) []byte {
+	h := hmac.New(alg, key)
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
+
+// bits2int keeps the leftmost qlen bits of b.
+func bits2int(b []byte, qlen int) *big.Int {
+	v := new(big.Int).SetBytes(b)
+	if excess := len(b)*8 - qlen; excess > 0 {
+		v.Rsh(v, uint(excess))
+	}
+	return v
+}
+
+func int2octets(v *big.Int, rolen int) []byte {
+	out := make([]byte, rolen)
+	v.FillBytes(out)
+	return out
+}
+
+func bits2octets(in []byte, q *big.Int, rolen int) []byte {
+	z1 := bits2int(in, q.BitLen())
+	z2 := new(big.Int).Sub(z1, q)
+	if z2.Sign() < 0 {
+		return int2octets(z1, rolen)
+	}
+	return int2octets(z2, rolen)
+}
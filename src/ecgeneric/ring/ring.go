@@ -0,0 +1,240 @@
+// Package ring implements linkable spontaneous anonymous group (LSAG)
+// signatures over ecgeneric curves, after Liu, Wei and Wong.
+//
+// A signature shows that the signer holds the private key of one of a set of
+// public keys, the ring, without revealing which. It also carries a key image
+// I = x·Hp(P), which depends only on the signer's key pair: two signatures
+// with the same key image were made with the same key, whatever rings and
+// messages they use, so a voter can sign anonymously but only once.
+//
+// Challenges are drawn from a Streebog transcript. The key image base Hp(P)
+// is the RFC 9380 hash_to_curve of the encoded public key, over Streebog
+// sized to the curve as in gost.SDSAHash. It clears the cofactor, so the
+// scheme also works on curves whose order is not prime.
+package ring
+
+import (
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/gost"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/h2c"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/transcript"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/vss"
+)
+
+var (
+	ErrRing = errors.New("ring: invalid ring")
+
+	errPrivateKey = errors.New("ring: private key out of range")
+	errNotMember  = errors.New("ring: signer's public key is not in the ring")
+	errHashPoint  = errors.New("ring: public key hashes to the point at infinity")
+)
+
+// hashToPointDST is the hash-to-curve domain separation tag for Hp.
+const hashToPointDST = "ring/lsag/Hp"
+
+// Signature is an LSAG signature: the key image, the challenge for the first
+// ring member and one response per ring member.
+type Signature struct {
+	KeyImage ecgeneric.Point
+	C        *big.Int
+	S        []*big.Int
+}
+
+// Linked reports whether a and b were made with the same private key.
+func Linked(a, b *Signature) bool {
+	return a.KeyImage.X.Cmp(b.KeyImage.X) == 0 && a.KeyImage.Y.Cmp(b.KeyImage.Y) == 0
+}
+
+// KeyImage returns x·Hp(P) for the key pair of priv.
+func KeyImage(priv *ecgeneric.PrivateKey) (ecgeneric.Point, error) {
+	curve := priv.Curve.Params()
+	if priv.D == nil || priv.D.Sign() <= 0 || priv.D.Cmp(curve.N) >= 0 {
+		return ecgeneric.Point{}, errPrivateKey
+	}
+	suite, err := newSuite(curve)
+	if err != nil {
+		return ecgeneric.Point{}, err
+	}
+	hp, err := hashToPoint(suite, &priv.PublicKey)
+	if err != nil {
+		return ecgeneric.Point{}, err
+	}
+	x, y := curve.ScalarMultJ(hp.X, hp.Y, priv.D.Bytes())
+	return ecgeneric.Point{X: x, Y: y}, nil
+}
+
+// Sign signs msg with priv on behalf of ring, which must contain priv's
+// public key. All keys must be on priv's curve.
+func Sign(rand io.Reader, priv *ecgeneric.PrivateKey, ring []*ecgeneric.PublicKey, msg []byte) (*Signature, error) {
+	curve := priv.Curve.Params()
+	N := curve.N
+	if err := checkRing(curve, ring); err != nil {
+		return nil, err
+	}
+	signer := -1
+	for i, pub := range ring {
+		if pub.X.Cmp(priv.X) == 0 && pub.Y.Cmp(priv.Y) == 0 {
+			signer = i
+			break
+		}
+	}
+	if signer < 0 {
+		return nil, errNotMember
+	}
+	image, err := KeyImage(priv)
+	if err != nil {
+		return nil, err
+	}
+	suite, err := newSuite(curve)
+	if err != nil {
+		return nil, err
+	}
+	hps := make([]ecgeneric.Point, len(ring))
+	for i, pub := range ring {
+		if hps[i], err = hashToPoint(suite, pub); err != nil {
+			return nil, err
+		}
+	}
+	prefix := challengePrefix(curve, ring, image, msg)
+
+	alpha, err := vss.RandomScalar(rand, curve)
+	if err != nil {
+		return nil, err
+	}
+	lx, ly := curve.ScalarBaseMultJ(alpha.Bytes())
+	rx, ry := curve.ScalarMultJ(hps[signer].X, hps[signer].Y, alpha.Bytes())
+
+	n := len(ring)
+	c := make([]*big.Int, n)
+	s := make([]*big.Int, n)
+	c[(signer+1)%n] = challenge(curve, prefix, lx, ly, rx, ry)
+	for j := 1; j < n; j++ {
+		i := (signer + j) % n
+		if s[i], err = vss.RandomScalar(rand, curve); err != nil {
+			return nil, err
+		}
+		c[(i+1)%n] = step(curve, prefix, ring[i], hps[i], image, c[i], s[i])
+	}
+	sPi := new(big.Int).Mul(c[signer], priv.D)
+	sPi.Sub(alpha, sPi)
+	s[signer] = sPi.Mod(sPi, N)
+	return &Signature{KeyImage: image, C: c[0], S: s}, nil
+}
+
+// Verify reports whether sig is a valid signature of msg by a member of
+// ring.
+func Verify(ring []*ecgeneric.PublicKey, msg []byte, sig *Signature) bool {
+	if len(ring) == 0 || sig == nil || len(sig.S) != len(ring) {
+		return false
+	}
+	curve := ring[0].Curve.Params()
+	N := curve.N
+	if checkRing(curve, ring) != nil || !validScalar(curve, sig.C, false) {
+		return false
+	}
+	image := sig.KeyImage
+	if image.X == nil || image.Y == nil || (image.X.Sign() == 0 && image.Y.Sign() == 0) || !curve.IsOnCurve(image.X, image.Y) {
+		return false
+	}
+	// The key image must lie in the prime-order subgroup, or a signer
+	// could vary it by a small-order point to escape linking.
+	if x, y := curve.ScalarMultJ(image.X, image.Y, N.Bytes()); x.Sign() != 0 || y.Sign() != 0 {
+		return false
+	}
+	suite, err := newSuite(curve)
+	if err != nil {
+		return false
+	}
+	prefix := challengePrefix(curve, ring, image, msg)
+	c := sig.C
+	for i, pub := range ring {
+		if !validScalar(curve, sig.S[i], true) {
+			return false
+		}
+		hp, err := hashToPoint(suite, pub)
+		if err != nil {
+			return false
+		}
+		c = step(curve, prefix, pub, hp, image, c, sig.S[i])
+	}
+	return c.Cmp(sig.C) == 0
+}
+
+// step computes the challenge for the next ring member from the challenge c
+// and response s of member pub: H(prefix, s·G + c·P, s·Hp(P) + c·I).
+func step(curve *ecgeneric.CurveParams, prefix *transcript.Transcript, pub *ecgeneric.PublicKey, hp, image ecgeneric.Point, c, s *big.Int) *big.Int {
+	g := ecgeneric.Point{X: curve.Gx, Y: curve.Gy}
+	lx, ly := curve.MultiScalarMult([]ecgeneric.Point{g, {X: pub.X, Y: pub.Y}}, []*big.Int{s, c})
+	rx, ry := curve.MultiScalarMult([]ecgeneric.Point{hp, image}, []*big.Int{s, c})
+	return challenge(curve, prefix, lx, ly, rx, ry)
+}
+
+func checkRing(curve *ecgeneric.CurveParams, ring []*ecgeneric.PublicKey) error {
+	if len(ring) == 0 {
+		return ErrRing
+	}
+	for _, pub := range ring {
+		if pub == nil || pub.X == nil || pub.Y == nil || pub.Curve == nil {
+			return ErrRing
+		}
+		if c := pub.Curve.Params(); c != curve && c.Name != curve.Name {
+			return ErrRing
+		}
+		if !curve.IsOnCurve(pub.X, pub.Y) {
+			return ErrRing
+		}
+	}
+	return nil
+}
+
+func validScalar(curve *ecgeneric.CurveParams, k *big.Int, zeroOK bool) bool {
+	if k == nil || k.Sign() < 0 || (!zeroOK && k.Sign() == 0) {
+		return false
+	}
+	return k.Cmp(curve.N) < 0
+}
+
+// challengePrefix records what every challenge commits to: the curve, the
+// ring in order, the key image and the message.
+func challengePrefix(curve *ecgeneric.CurveParams, ring []*ecgeneric.PublicKey, image ecgeneric.Point, msg []byte) *transcript.Transcript {
+	t := transcript.New(transcript.Streebog, "ring/lsag")
+	t.AppendMessage("curve", []byte(curve.Name))
+	for _, pub := range ring {
+		t.AppendPoint(curve, "member", ecgeneric.Point{X: pub.X, Y: pub.Y})
+	}
+	t.AppendPoint(curve, "image", image)
+	t.AppendMessage("msg", msg)
+	return t
+}
+
+func challenge(curve *ecgeneric.CurveParams, prefix *transcript.Transcript, lx, ly, rx, ry *big.Int) *big.Int {
+	t := prefix.Clone()
+	t.AppendPoint(curve, "L", ecgeneric.Point{X: lx, Y: ly})
+	t.AppendPoint(curve, "R", ecgeneric.Point{X: rx, Y: ry})
+	return t.ChallengeScalar(curve, "c")
+}
+
+// hashToPoint maps a public key to the key image base Hp(P) with the
+// hash_to_curve of suite, which clears the cofactor, so that Hp(P) and the
+// key image lie in the subgroup of order N.
+func hashToPoint(suite *h2c.Suite, pub *ecgeneric.PublicKey) (ecgeneric.Point, error) {
+	curve := suite.Curve
+	hp, err := suite.HashToCurve(ecgeneric.Marshal(curve, pub.X, pub.Y), []byte(hashToPointDST))
+	if err != nil {
+		return ecgeneric.Point{}, err
+	}
+	if hp.X.Sign() == 0 && hp.Y.Sign() == 0 {
+		return ecgeneric.Point{}, errHashPoint
+	}
+	return hp, nil
+}
+
+// newSuite returns the hash-to-curve suite for Hp on curve, over Streebog
+// sized to the curve.
+func newSuite(curve *ecgeneric.CurveParams) (*h2c.Suite, error) {
+	return h2c.NewSuite(curve, gost.SDSAHash(curve), 0)
+}
@@ -0,0 +1,132 @@
+package ring_test
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/gost"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/nist"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/ring"
+	"github.com/stretchr/testify/require"
+)
+
+var curves = []*ecgeneric.CurveParams{
+	&nist.Secp256k1,
+	&gost.GostEx1,
+	&gost.Gost34102001paramSetA,
+	&gost.Gost341012512paramSetA,
+}
+
+func newRing(t *testing.T, curve *ecgeneric.CurveParams, n int) ([]*ecgeneric.PrivateKey, []*ecgeneric.PublicKey) {
+	privs := make([]*ecgeneric.PrivateKey, n)
+	pubs := make([]*ecgeneric.PublicKey, n)
+	for i := range privs {
+		priv, err := ecgeneric.GenerateKey(curve, rand.Reader)
+		require.NoError(t, err)
+		privs[i], pubs[i] = priv, &priv.PublicKey
+	}
+	return privs, pubs
+}
+
+func TestSignVerify(t *testing.T) {
+	msg := []byte("ballot: option B")
+	for _, curve := range curves {
+		privs, pubs := newRing(t, curve, 5)
+		for _, signer := range []int{0, 2, 4} {
+			sig, err := ring.Sign(rand.Reader, privs[signer], pubs, msg)
+			require.NoError(t, err, curve.Name)
+			require.True(t, ring.Verify(pubs, msg, sig), curve.Name)
+			require.False(t, ring.Verify(pubs, []byte("ballot: option C"), sig), curve.Name)
+
+			// The ring is bound in order.
+			swapped := append([]*ecgeneric.PublicKey{}, pubs...)
+			swapped[1], swapped[3] = swapped[3], swapped[1]
+			require.False(t, ring.Verify(swapped, msg, sig), curve.Name)
+
+			bad := *sig
+			bad.S = append([]*big.Int{}, sig.S...)
+			bad.S[signer] = new(big.Int).Add(sig.S[signer], big.NewInt(1))
+			require.False(t, ring.Verify(pubs, msg, &bad), curve.Name)
+		}
+	}
+}
+
+func TestLinkability(t *testing.T) {
+	curve := &gost.Gost34102001paramSetA
+	privs, pubs := newRing(t, curve, 4)
+	_, others := newRing(t, curve, 3)
+
+	a, err := ring.Sign(rand.Reader, privs[1], pubs, []byte("vote 1"))
+	require.NoError(t, err)
+	// The same key in a different ring is still linked.
+	b, err := ring.Sign(rand.Reader, privs[1], append(others, pubs[1]), []byte("vote 2"))
+	require.NoError(t, err)
+	require.True(t, ring.Verify(append(others, pubs[1]), []byte("vote 2"), b))
+	require.True(t, ring.Linked(a, b))
+
+	c, err := ring.Sign(rand.Reader, privs[2], pubs, []byte("vote 1"))
+	require.NoError(t, err)
+	require.False(t, ring.Linked(a, c))
+
+	image, err := ring.KeyImage(privs[1])
+	require.NoError(t, err)
+	require.Equal(t, image, a.KeyImage)
+
+	// A key image from another key does not verify.
+	forged := *a
+	forged.KeyImage = c.KeyImage
+	require.False(t, ring.Verify(pubs, []byte("vote 1"), &forged))
+}
+
+func TestSignErrors(t *testing.T) {
+	curve := &nist.Secp256k1
+	privs, pubs := newRing(t, curve, 3)
+	outsider, err := ecgeneric.GenerateKey(curve, rand.Reader)
+	require.NoError(t, err)
+	_, err = ring.Sign(rand.Reader, outsider, pubs, nil)
+	require.Error(t, err)
+
+	_, err = ring.Sign(rand.Reader, privs[0], nil, nil)
+	require.ErrorIs(t, err, ring.ErrRing)
+
+	// Keys on another curve are rejected.
+	_, foreign := newRing(t, &gost.Gost34102001paramSetA, 1)
+	_, err = ring.Sign(rand.Reader, privs[0], append(pubs, foreign...), nil)
+	require.ErrorIs(t, err, ring.ErrRing)
+
+	// A single-member ring is an ordinary, if linkable, signature.
+	sig, err := ring.Sign(rand.Reader, privs[0], pubs[:1], nil)
+	require.NoError(t, err)
+	require.True(t, ring.Verify(pubs[:1], nil, sig))
+}
+
+// tc26A is id-tc26-gost-3410-2012-256-paramSetA in short Weierstrass form,
+// whose group order is 4·N.
+var tc26A = &ecgeneric.CurveParams{
+	P:       ecgeneric.BigFromHex("FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFD97"),
+	N:       ecgeneric.BigFromHex("400000000000000000000000000000000FD8CDDFC87B6635C115AF556C360C67"),
+	A:       ecgeneric.BigFromHex("C2173F1513981673AF4892C23035A27CE25E2013BF95AA33B22C656F277E7335"),
+	B:       ecgeneric.BigFromHex("295F9BAE7428ED9CCC20E7C359A9D41A22FCCD9108E17BF7BA9337A6F8AE9513"),
+	Gx:      ecgeneric.BigFromHex("91E38443A5E82C0D880923425712B2BB658B9196932E02C78B2582FE742DAA28"),
+	Gy:      ecgeneric.BigFromHex("32879423AB1A0375895786C4BB46E9565FDE0B5344766740AF268ADB32322E5C"),
+	BitSize: 256,
+	Name:    "tc26-gost-3410-2012-256-paramSetA",
+}
+
+func TestCofactor(t *testing.T) {
+	require.Equal(t, big.NewInt(4), tc26A.Cofactor())
+	privs, pubs := newRing(t, tc26A, 4)
+	msg := []byte("ballot: option A")
+	for _, priv := range privs {
+		image, err := ring.KeyImage(priv)
+		require.NoError(t, err)
+		x, y := tc26A.ScalarMultJ(image.X, image.Y, tc26A.N.Bytes())
+		require.Zero(t, x.Sign()+y.Sign())
+
+		sig, err := ring.Sign(rand.Reader, priv, pubs, msg)
+		require.NoError(t, err)
+		require.True(t, ring.Verify(pubs, msg, sig))
+	}
+}
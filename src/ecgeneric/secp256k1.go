@@ -0,0 +1,69 @@
+package ecgeneric
+
+import (
+	"crypto/elliptic"
+	"math/big"
+)
+
+// secp256k1 holds the parameters used by the native signing code in
+// signature_nocgo.go. They are the same as nist.Secp256k1, which cannot be
+// imported from here.
+var secp256k1 = &CurveParams{
+	P:       bigFromHex("fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f"),
+	N:       secp256k1N,
+	A:       big.NewInt(0),
+	B:       big.NewInt(7),
+	Gx:      bigFromHex("79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"),
+	Gy:      bigFromHex("483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8"),
+	BitSize: 256,
+	Name:    "secp256k1",
+}
+
+// s256 adapts secp256k1 to crypto/elliptic so that keys can be held in
+// ecdsa.PrivateKey and ecdsa.PublicKey. The generic elliptic.CurveParams
+// methods assume a = -3 and must not be used for secp256k1.
+type s256 struct {
+	params *elliptic.CurveParams
+}
+
+var theS256 = &s256{params: &elliptic.CurveParams{
+	P:       secp256k1.P,
+	N:       secp256k1.N,
+	B:       secp256k1.B,
+	Gx:      secp256k1.Gx,
+	Gy:      secp256k1.Gy,
+	BitSize: secp256k1.BitSize,
+	Name:    secp256k1.Name,
+}}
+
+func (c *s256) Params() *elliptic.CurveParams {
+	return c.params
+}
+
+func (c *s256) IsOnCurve(x, y *big.Int) bool {
+	return secp256k1.IsOnCurve(x, y)
+}
+
+func (c *s256) Add(x1, y1, x2, y2 *big.Int) (x, y *big.Int) {
+	return secp256k1.AddJ(x1, y1, x2, y2)
+}
+
+func (c *s256) Double(x1, y1 *big.Int) (x, y *big.Int) {
+	return secp256k1.DoubleJ(x1, y1)
+}
+
+func (c *s256) ScalarMult(x1, y1 *big.Int, k []byte) (x, y *big.Int) {
+	return secp256k1.ScalarMultGLV(x1, y1, k)
+}
+
+func (c *s256) ScalarBaseMult(k []byte) (x, y *big.Int) {
+	return secp256k1.ScalarBaseMultGLV(k)
+}
+
+// isSecp256k1 reports whether c has the secp256k1 parameters, whichever
+// implementation it comes from.
+func isSecp256k1(c elliptic.Curve) bool {
+	p := c.Params()
+	return p.P.Cmp(secp256k1.P) == 0 && p.N.Cmp(secp256k1.N) == 0 &&
+		p.B.Cmp(secp256k1.B) == 0 && p.Gx.Cmp(secp256k1.Gx) == 0
+}
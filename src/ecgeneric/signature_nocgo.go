@@ -19,12 +19,12 @@ package ecgeneric
 import (
 	"crypto/ecdsa"
 	"crypto/elliptic"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"math/big"
-
-	"github.com/tendermint/btcd/btcec"
 )
+
 const SignatureLength = 64 + 1
 
 var (
@@ -38,19 +38,72 @@ func Ecrecover(hash, sig []byte) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
-	bytes := (*btcec.PublicKey)(pub).SerializeUncompressed()
-	return bytes, err
+	return Marshal(secp256k1, pub.X, pub.Y), nil
 }
 
 // SigToPub returns the public key that created the given signature.
 func SigToPub(hash, sig []byte) (*ecdsa.PublicKey, error) {
-	// Convert to btcec input format with 'recovery id' v at the beginning.
-	btcsig := make([]byte, SignatureLength)
-	btcsig[0] = sig[64] + 27
-	copy(btcsig[1:], sig)
+	if len(sig) != SignatureLength {
+		return nil, errors.New("invalid signature length")
+	}
+	r := new(big.Int).SetBytes(sig[:32])
+	s := new(big.Int).SetBytes(sig[32:64])
+	x, y, err := recoverPubkey(hash, r, s, sig[64])
+	if err != nil {
+		return nil, err
+	}
+	return &ecdsa.PublicKey{Curve: theS256, X: x, Y: y}, nil
+}
+
+// recoverPubkey returns Q = r⁻¹(s·R - e·G), where R is the point with x
+// coordinate r + (v>>1)·N and the y parity given by the low bit of v. See SEC
+// 1, Version 2.0, Section 4.1.6.
+func recoverPubkey(hash []byte, r, s *big.Int, v byte) (*big.Int, *big.Int, error) {
+	curve := secp256k1
+	if v > 3 {
+		return nil, nil, errors.New("invalid signature recovery id")
+	}
+	if r.Sign() <= 0 || r.Cmp(curve.N) >= 0 || s.Sign() <= 0 || s.Cmp(curve.N) >= 0 {
+		return nil, nil, errors.New("invalid signature values")
+	}
+
+	Rx := new(big.Int).Set(r)
+	if v&2 != 0 {
+		Rx.Add(Rx, curve.N)
+		if Rx.Cmp(curve.P) >= 0 {
+			return nil, nil, errors.New("invalid signature recovery id")
+		}
+	}
+	Ry, err := decompressY(curve, Rx, v&1 == 1)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	pub, _, err := btcec.RecoverCompact(btcec.S256(), btcsig, hash)
-	return (*ecdsa.PublicKey)(pub), err
+	rInv := new(big.Int).ModInverse(r, curve.N)
+	e := hashToInt(hash, curve)
+	u1 := new(big.Int).Mul(e, rInv)
+	u1.Neg(u1).Mod(u1, curve.N)
+	u2 := new(big.Int).Mul(s, rInv)
+	u2.Mod(u2, curve.N)
+
+	x, y := curve.CombinedMult(Rx, Ry, u1.Bytes(), u2.Bytes())
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return nil, nil, errors.New("recovered public key is the point at infinity")
+	}
+	return x, y, nil
+}
+
+// decompressY returns the y coordinate of the point with the given x and y
+// parity.
+func decompressY(curve *CurveParams, x *big.Int, odd bool) (*big.Int, error) {
+	y := curve.PolynomialGeneric(x)
+	if y.ModSqrt(y, curve.P) == nil {
+		return nil, errors.New("invalid point x coordinate")
+	}
+	if (y.Bit(0) == 1) != odd {
+		y.Sub(curve.P, y)
+	}
+	return y, nil
 }
 
 // Sign calculates an ECDSA signature.
@@ -60,21 +113,51 @@ func SigToPub(hash, sig []byte) (*ecdsa.PublicKey, error) {
 // be aware that the given hash cannot be chosen by an adversery. Common
 // solution is to hash any input before calculating the signature.
 //
+// The nonce is derived deterministically as in RFC 6979 with SHA-256 and S is
+// normalized to the lower half of the order.
+//
 // The produced signature is in the [R || S || V] format where V is 0 or 1.
 func Sign(hash []byte, prv *ecdsa.PrivateKey) ([]byte, error) {
 	if len(hash) != 32 {
 		return nil, fmt.Errorf("hash is required to be exactly 32 bytes (%d)", len(hash))
 	}
-	if prv.Curve != btcec.S256() {
+	if !isSecp256k1(prv.Curve) {
 		return nil, fmt.Errorf("private key curve is not secp256k1")
 	}
-	sig, err := btcec.SignCompact(btcec.S256(), (*btcec.PrivateKey)(prv), hash, false)
-	if err != nil {
-		return nil, err
+	curve := secp256k1
+	d := prv.D
+	if d.Sign() <= 0 || d.Cmp(curve.N) >= 0 {
+		return nil, errors.New("invalid private key")
+	}
+
+	k := NonceRFC6979(d, hash, curve.N, sha256.New)
+	Rx, Ry := curve.ScalarBaseMultGLV(k.Bytes())
+	r := new(big.Int).Mod(Rx, curve.N)
+	if r.Sign() == 0 {
+		return nil, errors.New("calculated R is zero")
+	}
+	v := byte(Ry.Bit(0))
+	if Rx.Cmp(curve.N) >= 0 {
+		v |= 2
+	}
+
+	e := hashToInt(hash, curve)
+	s := new(big.Int).Mul(d, r)
+	s.Add(s, e)
+	s.Mul(s, new(big.Int).ModInverse(k, curve.N))
+	s.Mod(s, curve.N)
+	if s.Sign() == 0 {
+		return nil, errors.New("calculated S is zero")
 	}
-	// Convert to Ethereum signature format with 'recovery id' v at the end.
-	v := sig[0] - 27
-	copy(sig, sig[1:])
+	// Negating s negates R, which flips the parity of its y coordinate.
+	if s.Cmp(secp256k1halfN) > 0 {
+		s.Sub(curve.N, s)
+		v ^= 1
+	}
+
+	sig := make([]byte, SignatureLength)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:64])
 	sig[64] = v
 	return sig, nil
 }
@@ -86,16 +169,51 @@ func VerifySignature(pubkey, hash, signature []byte) bool {
 	if len(signature) != 64 {
 		return false
 	}
-	sig := &btcec.Signature{R: new(big.Int).SetBytes(signature[:32]), S: new(big.Int).SetBytes(signature[32:])}
-	key, err := btcec.ParsePubKey(pubkey, btcec.S256())
+	key, err := ParsePubkey(pubkey)
 	if err != nil {
 		return false
 	}
-	// Reject malleable signatures. libsecp256k1 does this check but btcec doesn't.
-	if sig.S.Cmp(secp256k1halfN) > 0 {
+	curve := secp256k1
+	r := new(big.Int).SetBytes(signature[:32])
+	s := new(big.Int).SetBytes(signature[32:])
+	if r.Sign() == 0 || r.Cmp(curve.N) >= 0 || s.Sign() == 0 {
 		return false
 	}
-	return sig.Verify(hash, key)
+	// Reject malleable signatures, as libsecp256k1 does.
+	if s.Cmp(secp256k1halfN) > 0 {
+		return false
+	}
+
+	e := hashToInt(hash, curve)
+	w := new(big.Int).ModInverse(s, curve.N)
+	u1 := e.Mul(e, w)
+	u1.Mod(u1, curve.N)
+	u2 := w.Mul(r, w)
+	u2.Mod(u2, curve.N)
+
+	x, y := curve.CombinedMult(key.X, key.Y, u1.Bytes(), u2.Bytes())
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return false
+	}
+	return x.Mod(x, curve.N).Cmp(r) == 0
+}
+
+// ParsePubkey parses a secp256k1 public key in the 33-byte compressed or
+// 65-byte uncompressed SEC 1 format.
+func ParsePubkey(pubkey []byte) (*ecdsa.PublicKey, error) {
+	var x, y *big.Int
+	switch len(pubkey) {
+	case 33:
+		x, y = UnmarshalCompressed(secp256k1, pubkey)
+	case 65:
+		x, y = Unmarshal(secp256k1, pubkey)
+	default:
+		return nil, fmt.Errorf("invalid public key length %d", len(pubkey))
+	}
+	if x == nil {
+		return nil, errors.New("invalid public key")
+	}
+	return &ecdsa.PublicKey{Curve: theS256, X: x, Y: y}, nil
 }
 
 // DecompressPubkey parses a public key in the 33-byte compressed format.
@@ -103,19 +221,15 @@ func DecompressPubkey(pubkey []byte) (*ecdsa.PublicKey, error) {
 	if len(pubkey) != 33 {
 		return nil, errors.New("invalid compressed public key length")
 	}
-	key, err := btcec.ParsePubKey(pubkey, btcec.S256())
-	if err != nil {
-		return nil, err
-	}
-	return key.ToECDSA(), nil
+	return ParsePubkey(pubkey)
 }
 
 // CompressPubkey encodes a public key to the 33-byte compressed format.
 func CompressPubkey(pubkey *ecdsa.PublicKey) []byte {
-	return (*btcec.PublicKey)(pubkey).SerializeCompressed()
+	return MarshalCompressed(secp256k1, pubkey.X, pubkey.Y)
 }
 
 // S256 returns an instance of the secp256k1 curve.
 func S256() elliptic.Curve {
-	return btcec.S256()
+	return theS256
 }
@@ -0,0 +1,124 @@
+package ecgeneric_test
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/nist"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/btcd/btcec"
+)
+
+// RFC 6979, A.2.5: P-256 with SHA-256, message "sample".
+func TestNonceRFC6979(t *testing.T) {
+	x := ecgeneric.BigFromHex("C9AFA9D845BA75166B5C215767B1D6934E50C3DB36E89B127B8A622B120F6721")
+	h := sha256.Sum256([]byte("sample"))
+	k := ecgeneric.NonceRFC6979(x, h[:], elliptic.P256().Params().N, sha256.New)
+	require.Equal(t, ecgeneric.BigFromHex("A6E3C57DD01ABE90086538398355DD4C3B17AA873382B0F24D6129493D8AAD60"), k)
+}
+
+func TestS256ScalarMult(t *testing.T) {
+	c := ecgeneric.S256()
+	for i := 0; i < 50; i++ {
+		k := make([]byte, 32)
+		_, err := rand.Read(k)
+		require.NoError(t, err)
+
+		x, y := c.ScalarBaseMult(k)
+		ex, ey := nist.Secp256k1.ScalarBaseMultJ(k)
+		require.Equal(t, ex, x)
+		require.Equal(t, ey, y)
+		require.True(t, c.IsOnCurve(x, y))
+
+		x2, y2 := c.ScalarMult(x, y, k)
+		ex2, ey2 := nist.Secp256k1.ScalarMultJ(ex, ey, k)
+		require.Equal(t, ex2, x2)
+		require.Equal(t, ey2, y2)
+	}
+
+	// k = N-1 and multiples of N.
+	nm1 := new(big.Int).Sub(nist.Secp256k1.N, big.NewInt(1))
+	x, y := c.ScalarBaseMult(nm1.Bytes())
+	require.Equal(t, nist.Secp256k1.Gx, x)
+	require.Equal(t, new(big.Int).Sub(nist.Secp256k1.P, nist.Secp256k1.Gy), y)
+	x, y = c.ScalarBaseMult(nist.Secp256k1.N.Bytes())
+	require.Zero(t, x.Sign())
+	require.Zero(t, y.Sign())
+}
+
+// The native secp256k1 code must produce the same signatures, recovered keys
+// and verification results as btcec.
+func TestSignatureDifferential(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		bk, err := btcec.NewPrivateKey(btcec.S256())
+		require.NoError(t, err)
+		prv := &ecdsa.PrivateKey{
+			PublicKey: ecdsa.PublicKey{Curve: ecgeneric.S256(), X: bk.X, Y: bk.Y},
+			D:         bk.D,
+		}
+		hash := make([]byte, 32)
+		_, err = rand.Read(hash)
+		require.NoError(t, err)
+
+		sig, err := ecgeneric.Sign(hash, prv)
+		require.NoError(t, err)
+
+		// btcec's compact format is [27+V || R || S].
+		bsig, err := btcec.SignCompact(btcec.S256(), bk, hash, false)
+		require.NoError(t, err)
+		require.Equal(t, bsig[1:], sig[:64])
+		require.Equal(t, bsig[0]-27, sig[64])
+
+		pub, err := ecgeneric.Ecrecover(hash, sig)
+		require.NoError(t, err)
+		require.Equal(t, bk.PubKey().SerializeUncompressed(), pub)
+
+		compressed := ecgeneric.CompressPubkey(&prv.PublicKey)
+		require.Equal(t, bk.PubKey().SerializeCompressed(), compressed)
+		require.True(t, ecgeneric.VerifySignature(compressed, hash, sig[:64]))
+		require.True(t, ecgeneric.VerifySignature(pub, hash, sig[:64]))
+
+		// High-S twins are rejected.
+		s := new(big.Int).SetBytes(sig[32:64])
+		s.Sub(nist.Secp256k1.N, s)
+		high := append([]byte{}, sig[:64]...)
+		s.FillBytes(high[32:])
+		require.False(t, ecgeneric.VerifySignature(compressed, hash, high))
+
+		hash[0] ^= 1
+		require.False(t, ecgeneric.VerifySignature(compressed, hash, sig[:64]))
+		rec, err := ecgeneric.Ecrecover(hash, sig)
+		if err == nil {
+			require.False(t, bytes.Equal(pub, rec))
+		}
+	}
+}
+
+func TestParsePubkey(t *testing.T) {
+	bk, err := btcec.NewPrivateKey(btcec.S256())
+	require.NoError(t, err)
+
+	for _, enc := range [][]byte{bk.PubKey().SerializeCompressed(), bk.PubKey().SerializeUncompressed()} {
+		pub, err := ecgeneric.ParsePubkey(enc)
+		require.NoError(t, err)
+		require.Equal(t, bk.X, pub.X)
+		require.Equal(t, bk.Y, pub.Y)
+	}
+
+	pub, err := ecgeneric.DecompressPubkey(bk.PubKey().SerializeCompressed())
+	require.NoError(t, err)
+	require.Equal(t, bk.Y, pub.Y)
+
+	bad := bk.PubKey().SerializeUncompressed()
+	bad[64] ^= 1
+	_, err = ecgeneric.ParsePubkey(bad)
+	require.Error(t, err)
+	_, err = ecgeneric.ParsePubkey(bad[:40])
+	require.Error(t, err)
+}
@@ -0,0 +1,132 @@
+package ecgeneric
+
+import "math/big"
+
+// wnafTable holds the odd multiples P, 3P, ..., (2^(w-1)-1)·P of a point in
+// affine form, together with their negations, for use with width-w NAF
+// digits. Entries equal to the point at infinity are (0, 0).
+type wnafTable struct {
+	w   uint
+	pos []Point
+	neg []Point
+}
+
+// newWNAFTable precomputes the odd multiples of the affine point (x, y).
+func (curve *CurveParams) newWNAFTable(x, y *big.Int, w uint) *wnafTable {
+	n := 1 << (w - 2)
+	xs, ys, zs := make([]*big.Int, n), make([]*big.Int, n), make([]*big.Int, n)
+	xs[0], ys[0], zs[0] = x, y, zForAffine(x, y)
+	dx, dy, dz := curve.doubleJacobian(xs[0], ys[0], zs[0])
+	for i := 1; i < n; i++ {
+		xs[i], ys[i], zs[i] = curve.addJacobian(xs[i-1], ys[i-1], zs[i-1], dx, dy, dz)
+	}
+
+	t := &wnafTable{w: w, pos: curve.batchAffine(xs, ys, zs), neg: make([]Point, n)}
+	for i, p := range t.pos {
+		t.neg[i] = Point{p.X, curve.negY(p.Y)}
+	}
+	return t
+}
+
+// batchAffine converts Jacobian points to affine coordinates with a single
+// field inversion (Montgomery's trick). Points at infinity become (0, 0).
+func (curve *CurveParams) batchAffine(xs, ys, zs []*big.Int) []Point {
+	out := make([]Point, len(zs))
+	prefix := make([]*big.Int, len(zs))
+	acc := big.NewInt(1)
+	for i, z := range zs {
+		prefix[i] = new(big.Int).Set(acc)
+		if z.Sign() != 0 {
+			acc.Mul(acc, z)
+			acc.Mod(acc, curve.P)
+		}
+	}
+
+	inv := new(big.Int).ModInverse(acc, curve.P)
+	for i := len(zs) - 1; i >= 0; i-- {
+		z := zs[i]
+		if z.Sign() == 0 {
+			out[i] = Point{new(big.Int), new(big.Int)}
+			continue
+		}
+		// inv is (z0·...·zi)⁻¹ here, so zi⁻¹ = inv·(z0·...·zi-1).
+		zinv := new(big.Int).Mul(inv, prefix[i])
+		zinv.Mod(zinv, curve.P)
+		inv.Mul(inv, z)
+		inv.Mod(inv, curve.P)
+
+		zinv2 := new(big.Int).Mul(zinv, zinv)
+		zinv2.Mod(zinv2, curve.P)
+		x := new(big.Int).Mul(xs[i], zinv2)
+		x.Mod(x, curve.P)
+		zinv2.Mul(zinv2, zinv)
+		y := new(big.Int).Mul(ys[i], zinv2)
+		y.Mod(y, curve.P)
+		out[i] = Point{x, y}
+	}
+	return out
+}
+
+// wnaf returns the width-w non-adjacent form of k, least significant digit
+// first. Non-zero digits are odd and less than 2^(w-1) in absolute value, and
+// any w consecutive digits contain at most one of them. k may be negative.
+func wnaf(k *big.Int, w uint) []int8 {
+	neg := k.Sign() < 0
+	k = new(big.Int).Abs(k)
+	mod := 1 << w
+	d := new(big.Int)
+
+	var out []int8
+	for k.Sign() > 0 {
+		digit := 0
+		if k.Bit(0) == 1 {
+			digit = int(k.Bits()[0] & big.Word(mod-1))
+			if digit >= mod>>1 {
+				digit -= mod
+			}
+			k.Sub(k, d.SetInt64(int64(digit)))
+		}
+		if neg {
+			digit = -digit
+		}
+		out = append(out, int8(digit))
+		k.Rsh(k, 1)
+	}
+	return out
+}
+
+// strausMult returns Σ scalars[i]·Pᵢ, where tables[i] holds the odd multiples
+// of Pᵢ. This is Straus' method: all terms share one chain of doublings and
+// each scalar, written in wNAF, contributes an addition of a precomputed
+// multiple on average once every w+1 bits.
+func (curve *CurveParams) strausMult(tables []*wnafTable, scalars []*big.Int) (*big.Int, *big.Int) {
+	nafs := make([][]int8, len(scalars))
+	n := 0
+	for i, k := range scalars {
+		nafs[i] = wnaf(k, tables[i].w)
+		if len(nafs[i]) > n {
+			n = len(nafs[i])
+		}
+	}
+
+	x, y, z := new(big.Int), new(big.Int), new(big.Int)
+	for i := n - 1; i >= 0; i-- {
+		x, y, z = curve.doubleJacobian(x, y, z)
+		for j, naf := range nafs {
+			if i >= len(naf) || naf[i] == 0 {
+				continue
+			}
+			var p Point
+			if d := naf[i]; d > 0 {
+				p = tables[j].pos[d>>1]
+			} else {
+				p = tables[j].neg[(-d)>>1]
+			}
+			if p.X.Sign() == 0 && p.Y.Sign() == 0 {
+				continue
+			}
+			x, y, z = curve.addJacobian(x, y, z, p.X, p.Y, one)
+		}
+	}
+	return curve.affineFromJacobian(x, y, z)
+}
@@ -0,0 +1,278 @@
+// Package streebog implements the GOST R 34.11-2012 hash function
+// (Streebog) with 256- and 512-bit outputs, as specified in RFC 6986.
+//
+// Following the usual convention the 512-bit internal vectors are stored
+// little-endian, so digests come out in the byte order produced by other
+// implementations, e.g. Sum256("") = 3f539a21...8d9ce1bb.
+package streebog
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+)
+
+const (
+	// BlockSize is the block size of Streebog in bytes.
+	BlockSize = 64
+	// Size256 is the size of a Streebog-256 digest in bytes.
+	Size256 = 32
+	// Size512 is the size of a Streebog-512 digest in bytes.
+	Size512 = 64
+)
+
+// pi is the substitution π shared with the Kuznyechik block cipher.
+var pi = [256]byte{
+	252, 238, 221, 17, 207, 110, 49, 22, 251, 196, 250, 218, 35, 197, 4, 77,
+	233, 119, 240, 219, 147, 46, 153, 186, 23, 54, 241, 187, 20, 205, 95, 193,
+	249, 24, 101, 90, 226, 92, 239, 33, 129, 28, 60, 66, 139, 1, 142, 79,
+	5, 132, 2, 174, 227, 106, 143, 160, 6, 11, 237, 152, 127, 212, 211, 31,
+	235, 52, 44, 81, 234, 200, 72, 171, 242, 42, 104, 162, 253, 58, 206, 204,
+	181, 112, 14, 86, 8, 12, 118, 18, 191, 114, 19, 71, 156, 183, 93, 135,
+	21, 161, 150, 41, 16, 123, 154, 199, 243, 145, 120, 111, 157, 158, 178, 177,
+	50, 117, 25, 61, 255, 53, 138, 126, 109, 84, 198, 128, 195, 189, 13, 87,
+	223, 245, 36, 169, 62, 168, 67, 201, 215, 121, 214, 246, 124, 34, 185, 3,
+	224, 15, 236, 222, 122, 148, 176, 188, 220, 232, 40, 80, 78, 51, 10, 74,
+	167, 151, 96, 115, 30, 0, 98, 68, 26, 184, 56, 130, 100, 159, 38, 65,
+	173, 69, 70, 146, 39, 94, 85, 47, 140, 163, 165, 125, 105, 213, 149, 59,
+	7, 88, 179, 64, 134, 172, 29, 247, 48, 55, 107, 228, 136, 217, 231, 137,
+	225, 27, 131, 73, 76, 63, 248, 254, 141, 83, 170, 144, 202, 216, 133, 97,
+	32, 113, 103, 164, 45, 43, 9, 91, 203, 155, 37, 208, 190, 229, 108, 82,
+	89, 166, 116, 210, 230, 244, 180, 192, 209, 102, 175, 194, 57, 75, 99, 182,
+}
+
+// aRows defines the matrix A of the linear transformation l. Within each group
+// of eight rows every row is the previous one with its bytes divided by x in
+// GF(2)[x]/(x⁸+x⁴+x³+x²+1), so only the first row of each group is listed.
+var aRows = [8]uint64{
+	0x8e20faa72ba0b470, 0xa011d380818e8f40, 0x90dab52a387ae76f, 0x9d4df05d5f661451,
+	0x86275df09ce8aaa8, 0x456c34887a3805b9, 0xe4fa2054a80b329c, 0x70a6a56e2440598e,
+}
+
+// Iteration constants C₁…C₁₂ written as in RFC 6986, most significant byte
+// first.
+var cHex = [12]string{
+	"b1085bda1ecadae9ebcb2f81c0657c1f2f6a76432e45d016714eb88d7585c4fc4b7ce09192676901a2422a08a460d31505767436cc744d23dd806559f2a64507",
+	"6fa3b58aa99d2f1a4fe39d460f70b5d7f3feea720a232b9861d55e0f16b501319ab5176b12d699585cb561c2db0aa7ca55dda21bd7cbcd56e679047021b19bb7",
+	"f574dcac2bce2fc70a39fc286a3d843506f15e5f529c1f8bf2ea7514b1297b7bd3e20fe490359eb1c1c93a376062db09c2b6f443867adb31991e96f50aba0ab2",
+	"ef1fdfb3e81566d2f948e1a05d71e4dd488e857e335c3c7d9d721cad685e353fa9d72c82ed03d675d8b71333935203be3453eaa193e837f1220cbebc84e3d12e",
+	"4bea6bacad4747999a3f410c6ca923637f151c1f1686104a359e35d7800fffbdbfcd1747253af5a3dfff00b723271a167a56a27ea9ea63f5601758fd7c6cfe57",
+	"ae4faeae1d3ad3d96fa4c33b7a3039c02d66c4f95142a46c187f9ab49af08ec6cffaa6b71c9ab7b40af21f66c2bec6b6bf71c57236904f35fa68407a46647d6e",
+	"f4c70e16eeaac5ec51ac86febf240954399ec6c7e6bf87c9d3473e33197a93c90992abc52d822c3706476983284a05043517454ca23c4af38886564d3a14d493",
+	"9b1f5b424d93c9a703e7aa020c6e41414eb7f8719c36de1e89b4443b4ddbc49af4892bcb929b069069d18d2bd1a5c42f36acc2355951a8d9a47f0dd4bf02e71e",
+	"378f5a541631229b944c9ad8ec165fde3a7d3a1b258942243cd955b7e00d0984800a440bdbb2ceb17b2b8a9aa6079c540e38dc92cb1f2a607261445183235adb",
+	"abbedea680056f52382ae548b2e4f3f38941e71cff8a78db1fffe18a1b3361039fe76702af69334b7a1e6c303b7652f43698fad1153bb6c374b4c7fb98459ced",
+	"7bcd9ed0efc889fb3002c6cd635afe94d8fa6bbbebab076120018021148466798a1d71efea48b9caefbacd1d7d476e98dea2594ac06fd85d6bcaa4cd81f32d1b",
+	"378ee767f11631bad21380b00449b17acda43c32bcdf1d77f82012d430219f9b5d80ef9d1891cc86e71da4aa88e12852faf417d5d9b21b9948bc924af11bd720",
+}
+
+var (
+	// lps[j][b] is the contribution of byte b of input word j to the
+	// combined L∘P∘S transformation.
+	lps [8][256]uint64
+	c   [12][8]uint64
+)
+
+func init() {
+	var a [64]uint64
+	for g, row := range aRows {
+		a[8*g] = row
+		for i := 1; i < 8; i++ {
+			a[8*g+i] = divX(a[8*g+i-1])
+		}
+	}
+
+	// Byte j of a word covers bits 8j…8j+7, and bit 63-t selects row t.
+	for j := 0; j < 8; j++ {
+		for b := 0; b < 256; b++ {
+			v := pi[b]
+			var r uint64
+			for bit := 0; bit < 8; bit++ {
+				if v>>bit&1 == 1 {
+					r ^= a[63-(8*j+bit)]
+				}
+			}
+			lps[j][b] = r
+		}
+	}
+
+	for i, s := range cHex {
+		be, err := hex.DecodeString(s)
+		if err != nil {
+			panic(err)
+		}
+		var le [BlockSize]byte
+		for k := range be {
+			le[k] = be[BlockSize-1-k]
+		}
+		c[i] = load(le[:])
+	}
+}
+
+// divX divides each byte of row by x modulo x⁸+x⁴+x³+x²+1.
+func divX(row uint64) uint64 {
+	var out uint64
+	for k := 0; k < 8; k++ {
+		b := uint16(row >> (8 * k) & 0xff)
+		if b&1 == 1 {
+			b ^= 0x11d
+		}
+		out |= uint64(b>>1) << (8 * k)
+	}
+	return out
+}
+
+func load(b []byte) (w [8]uint64) {
+	for i := range w {
+		w[i] = binary.LittleEndian.Uint64(b[8*i:])
+	}
+	return
+}
+
+func transform(w [8]uint64) (out [8]uint64) {
+	for k := 0; k < 8; k++ {
+		s := 8 * uint(k)
+		out[k] = lps[0][byte(w[0]>>s)] ^ lps[1][byte(w[1]>>s)] ^
+			lps[2][byte(w[2]>>s)] ^ lps[3][byte(w[3]>>s)] ^
+			lps[4][byte(w[4]>>s)] ^ lps[5][byte(w[5]>>s)] ^
+			lps[6][byte(w[6]>>s)] ^ lps[7][byte(w[7]>>s)]
+	}
+	return
+}
+
+func xor(a, b [8]uint64) (out [8]uint64) {
+	for i := range out {
+		out[i] = a[i] ^ b[i]
+	}
+	return
+}
+
+// add sets *a = a + b mod 2⁵¹².
+func add(a *[8]uint64, b [8]uint64) {
+	var carry uint64
+	for i := range a {
+		s := a[i] + b[i]
+		c1 := s < a[i]
+		s2 := s + carry
+		c2 := s2 < s
+		a[i] = s2
+		if c1 || c2 {
+			carry = 1
+		} else {
+			carry = 0
+		}
+	}
+}
+
+// g is the compression function gₙ(h, m) = E(LPS(h ⊕ N), m) ⊕ h ⊕ m.
+func g(n, h, m [8]uint64) [8]uint64 {
+	k := transform(xor(h, n))
+	s := xor(k, m)
+	for i := 0; i < 12; i++ {
+		s = transform(s)
+		k = transform(xor(k, c[i]))
+		s = xor(s, k)
+	}
+	return xor(xor(s, h), m)
+}
+
+type digest struct {
+	size  int
+	h     [8]uint64
+	n     [8]uint64
+	sigma [8]uint64
+	buf   [BlockSize]byte
+	nbuf  int
+}
+
+// New256 returns a new hash.Hash computing Streebog-256.
+func New256() hash.Hash {
+	d := &digest{size: Size256}
+	d.Reset()
+	return d
+}
+
+// New512 returns a new hash.Hash computing Streebog-512.
+func New512() hash.Hash {
+	d := &digest{size: Size512}
+	d.Reset()
+	return d
+}
+
+// Sum256 returns the Streebog-256 digest of data.
+func Sum256(data []byte) [Size256]byte {
+	var out [Size256]byte
+	d := New256()
+	d.Write(data)
+	d.Sum(out[:0])
+	return out
+}
+
+// Sum512 returns the Streebog-512 digest of data.
+func Sum512(data []byte) [Size512]byte {
+	var out [Size512]byte
+	d := New512()
+	d.Write(data)
+	d.Sum(out[:0])
+	return out
+}
+
+func (d *digest) Size() int      { return d.size }
+func (d *digest) BlockSize() int { return BlockSize }
+
+func (d *digest) Reset() {
+	iv := uint64(0)
+	if d.size == Size256 {
+		iv = 0x0101010101010101
+	}
+	for i := range d.h {
+		d.h[i] = iv
+	}
+	d.n = [8]uint64{}
+	d.sigma = [8]uint64{}
+	d.nbuf = 0
+}
+
+func (d *digest) block(m [8]uint64, bits uint64) {
+	d.h = g(d.n, d.h, m)
+	add(&d.n, [8]uint64{bits})
+	add(&d.sigma, m)
+}
+
+func (d *digest) Write(p []byte) (int, error) {
+	n := len(p)
+	if d.nbuf > 0 {
+		k := copy(d.buf[d.nbuf:], p)
+		d.nbuf += k
+		p = p[k:]
+		if d.nbuf < BlockSize {
+			return n, nil
+		}
+		d.block(load(d.buf[:]), BlockSize*8)
+		d.nbuf = 0
+	}
+	for len(p) >= BlockSize {
+		d.block(load(p[:BlockSize]), BlockSize*8)
+		p = p[BlockSize:]
+	}
+	d.nbuf = copy(d.buf[:], p)
+	return n, nil
+}
+
+func (d *digest) Sum(in []byte) []byte {
+	// Work on a copy so that the caller can keep writing.
+	dd := *d
+
+	var last [BlockSize]byte
+	copy(last[:], dd.buf[:dd.nbuf])
+	last[dd.nbuf] = 1
+	dd.block(load(last[:]), uint64(dd.nbuf)*8)
+	dd.h = g([8]uint64{}, dd.h, dd.n)
+	dd.h = g([8]uint64{}, dd.h, dd.sigma)
+
+	var out [Size512]byte
+	for i, w := range dd.h {
+		binary.LittleEndian.PutUint64(out[8*i:], w)
+	}
+	return append(in, out[Size512-dd.size:]...)
+}
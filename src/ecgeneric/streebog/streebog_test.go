@@ -0,0 +1,66 @@
+package streebog_test
+
+import (
+	"crypto/hmac"
+	"encoding/hex"
+	"testing"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/streebog"
+	"github.com/stretchr/testify/require"
+)
+
+// M1 from GOST R 34.11-2012, Appendix A (RFC 6986, section 10.1).
+var m1 = []byte("012345678901234567890123456789012345678901234567890123456789012")
+
+func TestStreebogVectors(t *testing.T) {
+	for _, v := range []struct {
+		msg  []byte
+		want string
+		size int
+	}{
+		{m1, "1b54d01a4af5b9d5cc3d86d68d285462b19abc2475222f35c085122be4ba1ffa00ad30f8767b3a82384c6574f024c311e2a481332b08ef7f41797891c1646f48", 512},
+		{m1, "9d151eefd8590b89daa6ba6cb74af9275dd051026bb149a452fd84e5e57b5500", 256},
+		{nil, "8e945da209aa869f0455928529bcae4679e9873ab707b55315f56ceb98bef0a7362f715528356ee83cda5f2aac4c6ad2ba3a715c1bcd81cb8e9f90bf4c1c1a8a", 512},
+		{nil, "3f539a213e97c802cc229d474c6aa32a825a360b2a933a949fd925208d9ce1bb", 256},
+	} {
+		var got []byte
+		if v.size == 256 {
+			sum := streebog.Sum256(v.msg)
+			got = sum[:]
+		} else {
+			sum := streebog.Sum512(v.msg)
+			got = sum[:]
+		}
+		require.Equal(t, v.want, hex.EncodeToString(got))
+	}
+}
+
+func TestStreebogStreaming(t *testing.T) {
+	msg := make([]byte, 1000)
+	for i := range msg {
+		msg[i] = byte(i * 7)
+	}
+	want := streebog.Sum512(msg)
+
+	h := streebog.New512()
+	for _, n := range []int{1, 63, 64, 65, 128, 200, 479} {
+		h.Write(msg[:n])
+		msg = msg[n:]
+	}
+	require.Equal(t, want[:], h.Sum(nil))
+	require.Equal(t, want[:], h.Sum(nil))
+}
+
+// HMAC test vectors from RFC 7836, section 4.1.
+func TestStreebogHMAC(t *testing.T) {
+	key, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	data, _ := hex.DecodeString("0126bdb87800af214341456563780100")
+
+	mac := hmac.New(streebog.New256, key)
+	mac.Write(data)
+	require.Equal(t, "a1aa5f7de402d7b3d323f2991c8d4534013137010a83754fd0af6d7cd4922ed9", hex.EncodeToString(mac.Sum(nil)))
+
+	mac = hmac.New(streebog.New512, key)
+	mac.Write(data)
+	require.Equal(t, "a59bab22ecae19c65fbde6e5f4e9f5d8549d31f037f9df9b905500e171923a773d5f1530f2ed7e964cb2eedc29e9ad2f3afe93b2814f79f5000ffc0366c251e6", hex.EncodeToString(mac.Sum(nil)))
+}
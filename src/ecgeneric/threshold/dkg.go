@@ -0,0 +1,318 @@
+// Package threshold implements t-of-n distributed key generation and
+// threshold signing over ecgeneric curves.
+//
+// Keys are generated with Pedersen's DKG: every party deals a random secret
+// with Feldman VSS and proves knowledge of it, and the key is the sum of the
+// dealt secrets, so it never exists in one place. The parties echo hashes of
+// the dealt commitments to each other, so that a dealer cannot give them
+// different ones. Signing works for GOST R 34.10-2012, whose equation
+// s = r·d + k·e is linear in both the key d and the nonce k, so t parties
+// can produce additive shares of s directly.
+//
+// ECDSA is not linear in the nonce, so threshold ECDSA additionally needs
+// Paillier keys, set up and renewed by a key refresh, to convert products of
+// secret shares into sums with a presigning protocol after Canetti et al.
+// A presignature is made ahead of time and turns into a signature in one
+// round once the message is known.
+//
+// Parties are identified by positive integers, which are also their share
+// indices. Each protocol is available both as a state machine, for callers
+// with their own message handling, and as a Run function driving it over a
+// Transport.
+package threshold
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/vss"
+)
+
+// FaultError reports a protocol violation attributable to Party.
+type FaultError struct {
+	Party int
+	Err   error
+}
+
+func (e *FaultError) Error() string {
+	return fmt.Sprintf("threshold: party %d: %v", e.Party, e.Err)
+}
+
+func (e *FaultError) Unwrap() error {
+	return e.Err
+}
+
+// KeyShare is one party's output of the distributed key generation.
+type KeyShare struct {
+	Curve     *ecgeneric.CurveParams
+	ID        int
+	Threshold int
+	Parties   []int
+	// Secret is this party's share xᵢ of the private key.
+	Secret *big.Int
+	// PublicKey is the joint public key Y = d·G.
+	PublicKey ecgeneric.Point
+	// VerificationShares holds xⱼ·G for every party j.
+	VerificationShares map[int]ecgeneric.Point
+}
+
+// DKGRound1 is broadcast: the Feldman commitments to the party's
+// polynomial and a proof of knowledge of its constant term. Parties confirm
+// that they all received the same round 1 messages by exchanging an Echo.
+type DKGRound1 struct {
+	Commitments vss.Commitments
+	Proof       *DLogProof
+}
+
+// DKGRound2 is sent privately to each party: its share of the sender's
+// secret.
+type DKGRound2 struct {
+	Share *big.Int
+}
+
+// DKG holds the state of one party in a key generation.
+type DKG struct {
+	curve     *ecgeneric.CurveParams
+	id        int
+	parties   []int
+	threshold int
+	session   []byte
+	rand      io.Reader
+
+	poly  *vss.Polynomial
+	echo  []byte
+	round int
+}
+
+// checkParties validates and returns a sorted copy of parties.
+func checkParties(id int, parties []int) ([]int, error) {
+	sorted := append([]int{}, parties...)
+	sort.Ints(sorted)
+	found := false
+	for i, p := range sorted {
+		if p < 1 {
+			return nil, errors.New("threshold: party ids must be positive")
+		}
+		if i > 0 && sorted[i-1] == p {
+			return nil, fmt.Errorf("threshold: duplicate party %d", p)
+		}
+		if p == id {
+			found = true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("threshold: party %d is not a participant", id)
+	}
+	return sorted, nil
+}
+
+// NewDKG starts a key generation for party id among parties, producing a key
+// that any threshold of them can use. session should be unique to this run;
+// it is bound into the proofs.
+func NewDKG(curve *ecgeneric.CurveParams, id int, parties []int, threshold int, session []byte, rand io.Reader) (*DKG, error) {
+	sorted, err := checkParties(id, parties)
+	if err != nil {
+		return nil, err
+	}
+	if threshold < 1 || threshold > len(sorted) {
+		return nil, fmt.Errorf("threshold: threshold %d out of range for %d parties", threshold, len(sorted))
+	}
+	return &DKG{
+		curve:     curve,
+		id:        id,
+		parties:   sorted,
+		threshold: threshold,
+		session:   derive("threshold/dkg", []byte(curve.Name), session, encodeIDs(sorted), encodeIDs([]int{threshold})),
+		rand:      rand,
+	}, nil
+}
+
+// Round1 deals this party's secret. It returns the broadcast message and the
+// private share for every party, including this one.
+func (d *DKG) Round1() (*DKGRound1, map[int]*DKGRound2, error) {
+	if d.round != 0 {
+		return nil, nil, errors.New("threshold: DKG round 1 already done")
+	}
+	poly, err := vss.NewPolynomial(d.rand, d.curve, nil, d.threshold)
+	if err != nil {
+		return nil, nil, err
+	}
+	proof, err := proveDLog(d.rand, d.curve, sessionContext("dkg", d.id, d.session), poly.Secret())
+	if err != nil {
+		return nil, nil, err
+	}
+	shares := make(map[int]*DKGRound2, len(d.parties))
+	for _, j := range d.parties {
+		s, err := poly.Share(j)
+		if err != nil {
+			return nil, nil, err
+		}
+		shares[j] = &DKGRound2{Share: s.Value}
+	}
+	d.poly = poly
+	d.round = 1
+	return &DKGRound1{Commitments: poly.Commit(), Proof: proof}, shares, nil
+}
+
+// Echo takes the round 1 messages of all parties, keyed by sender, and
+// returns the echo to send to every other party.
+func (d *DKG) Echo(r1 map[int]*DKGRound1) (*Echo, error) {
+	if d.round != 1 {
+		return nil, errors.New("threshold: DKG echo out of order")
+	}
+	d.echo = d.echoHash(r1)
+	d.round = 2
+	return &Echo{Hash: d.echo}, nil
+}
+
+func (d *DKG) echoHash(r1 map[int]*DKGRound1) []byte {
+	return echoHash("dkg", d.session, d.parties, func(j int) [][]byte {
+		m := r1[j]
+		if m == nil {
+			return nil
+		}
+		out := encodeCommitments(d.curve, m.Commitments)
+		if m.Proof != nil {
+			out = append(out, encodeInts(m.Proof.C, m.Proof.S)...)
+		}
+		return out
+	})
+}
+
+// Finish verifies the messages of all parties and returns this party's key
+// share. The maps are keyed by sender; r1 and r2 must cover every party and
+// echoes every other party. r1 must be the messages passed to Echo, and the
+// key generation fails with ErrInconsistentBroadcast unless every echo
+// matches.
+func (d *DKG) Finish(r1 map[int]*DKGRound1, r2 map[int]*DKGRound2, echoes map[int]*Echo) (*KeyShare, error) {
+	if d.round != 2 {
+		return nil, errors.New("threshold: DKG finished out of order")
+	}
+	d.round = 3
+	if !bytes.Equal(d.echoHash(r1), d.echo) {
+		return nil, errors.New("threshold: round 1 messages differ from those echoed")
+	}
+	if err := checkEchoes(d.echo, d.id, d.parties, echoes); err != nil {
+		return nil, err
+	}
+
+	all := make([]vss.Commitments, 0, len(d.parties))
+	secret := new(big.Int)
+	for _, j := range d.parties {
+		m1, m2 := r1[j], r2[j]
+		if m1 == nil || m2 == nil || m2.Share == nil {
+			return nil, &FaultError{j, errors.New("missing DKG message")}
+		}
+		c := m1.Commitments
+		if len(c) != d.threshold {
+			return nil, &FaultError{j, errors.New("commitments have the wrong degree")}
+		}
+		if err := c.Validate(d.curve); err != nil {
+			return nil, &FaultError{j, err}
+		}
+		if err := verifyDLog(d.curve, sessionContext("dkg", j, d.session), c[0].X, c[0].Y, m1.Proof); err != nil {
+			return nil, &FaultError{j, err}
+		}
+		if err := c.Verify(d.curve, &vss.Share{Index: d.id, Value: m2.Share}); err != nil {
+			return nil, &FaultError{j, err}
+		}
+		all = append(all, c)
+		secret.Add(secret, m2.Share)
+	}
+	secret.Mod(secret, d.curve.N)
+	d.poly = nil
+
+	sum, err := vss.Sum(d.curve, all...)
+	if err != nil {
+		return nil, err
+	}
+	ks := &KeyShare{
+		Curve:              d.curve,
+		ID:                 d.id,
+		Threshold:          d.threshold,
+		Parties:            d.parties,
+		Secret:             secret,
+		PublicKey:          sum[0],
+		VerificationShares: make(map[int]ecgeneric.Point, len(d.parties)),
+	}
+	if sum[0].X.Sign() == 0 && sum[0].Y.Sign() == 0 {
+		return nil, errors.New("threshold: joint public key is the point at infinity")
+	}
+	for _, j := range d.parties {
+		x, y := sum.Evaluate(d.curve, j)
+		ks.VerificationShares[j] = ecgeneric.Point{X: x, Y: y}
+	}
+	return ks, nil
+}
+
+// RunDKG runs the key generation for party id over t.
+func RunDKG(t Transport, curve *ecgeneric.CurveParams, id int, parties []int, threshold int, session []byte, rand io.Reader) (*KeyShare, error) {
+	d, err := NewDKG(curve, id, parties, threshold, session, rand)
+	if err != nil {
+		return nil, err
+	}
+	return runDKG(&router{t: t}, 0, d)
+}
+
+// dkgRounds is the number of rounds runDKG takes.
+const dkgRounds = 3
+
+// runDKG drives d with rounds numbered from base+1.
+func runDKG(r *router, base int, d *DKG) (*KeyShare, error) {
+	b, shares, err := d.Round1()
+	if err != nil {
+		return nil, err
+	}
+	others := without(d.parties, d.id)
+	m1, err := r.exchange(base+1, others, func(int) interface{} { return b })
+	if err != nil {
+		return nil, err
+	}
+	m2, err := r.exchange(base+2, others, func(j int) interface{} { return shares[j] })
+	if err != nil {
+		return nil, err
+	}
+	r1 := map[int]*DKGRound1{d.id: b}
+	r2 := map[int]*DKGRound2{d.id: shares[d.id]}
+	for _, j := range others {
+		p1, ok1 := m1[j].(*DKGRound1)
+		p2, ok2 := m2[j].(*DKGRound2)
+		if !ok1 || !ok2 {
+			return nil, &FaultError{j, errors.New("unexpected message type")}
+		}
+		r1[j], r2[j] = p1, p2
+	}
+	e, err := d.Echo(r1)
+	if err != nil {
+		return nil, err
+	}
+	m3, err := r.exchange(base+3, others, func(int) interface{} { return e })
+	if err != nil {
+		return nil, err
+	}
+	echoes := make(map[int]*Echo, len(others))
+	for _, j := range others {
+		p3, ok := m3[j].(*Echo)
+		if !ok {
+			return nil, &FaultError{j, errors.New("unexpected message type")}
+		}
+		echoes[j] = p3
+	}
+	return d.Finish(r1, r2, echoes)
+}
+
+// without returns ids with id removed.
+func without(ids []int, id int) []int {
+	out := make([]int, 0, len(ids))
+	for _, j := range ids {
+		if j != id {
+			out = append(out, j)
+		}
+	}
+	return out
+}
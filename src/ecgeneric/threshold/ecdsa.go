@@ -0,0 +1,589 @@
+package threshold
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/paillier"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/vss"
+)
+
+// PresignRound1 carries the signer's encrypted nonce share K = Enc(k) and
+// mask share G = Enc(γ), with a range proof for K made against the
+// recipient's ring-Pedersen parameters. K and G must be the same for every
+// recipient.
+type PresignRound1 struct {
+	K, G  *big.Int
+	Proof *paillier.EncProof
+}
+
+// PresignRound2 carries Γ = γ·G and the signer's side of the two
+// multiplicative-to-additive conversions with the recipient: D encrypts
+// γ·k' + β under the recipient's key and F encrypts β under the sender's,
+// and likewise DHat and FHat for w·k' + β̂.
+type PresignRound2 struct {
+	Gamma    ecgeneric.Point
+	D, F     *big.Int
+	DHat     *big.Int
+	FHat     *big.Int
+	Proof    *paillier.AffGProof
+	ProofHat *paillier.AffGProof
+	LogProof *paillier.LogStarProof
+}
+
+// PresignRound3 carries the signer's share δ of k·γ, Δ = k·Γ and S = χ·Γ,
+// which lets the others check its partial signature if the combined one
+// fails.
+type PresignRound3 struct {
+	Delta    *big.Int
+	BigDelta ecgeneric.Point
+	S        ecgeneric.Point
+	Proof    *paillier.LogStarProof
+}
+
+// Presignature is one signer's output of presigning: the nonce point R and
+// additive shares of the nonce inverse k and of k·d. It can be used for one
+// signature only.
+//
+// KPoints and ChiPoints hold kⱼ·R and χⱼ·R for every signer j, against which
+// Combine checks the partial signatures.
+type Presignature struct {
+	Curve     *ecgeneric.CurveParams
+	ID        int
+	Signers   []int
+	PublicKey ecgeneric.Point
+	R         ecgeneric.Point
+	K, Chi    *big.Int
+
+	KPoints   map[int]ecgeneric.Point
+	ChiPoints map[int]ecgeneric.Point
+}
+
+// Presigner holds the state of one party in a threshold ECDSA presigning
+// session.
+//
+// The protocol follows the three-round presigning of Canetti et al. Each
+// signer i holds an additive share wᵢ = λᵢ·xᵢ of the key and draws kᵢ and
+// γᵢ. Pairwise Paillier-based multiplicative-to-additive conversions, each
+// backed by range proofs, give additive shares δᵢ of δ = k·γ and χᵢ of
+// χ = k·d, where k = Σ kᵢ and γ = Σ γᵢ. With Γ = γ·G, R = δ⁻¹·Γ = k⁻¹·G,
+// so that s = Σ (kᵢ·m + r·χᵢ) = k·(m + r·d) is an ECDSA signature for the
+// nonce k⁻¹. Messages that are the same for all recipients must reach them
+// unchanged, which the transport has to guarantee.
+type Presigner struct {
+	key     *ECDSAKey
+	curve   *ecgeneric.CurveParams
+	signers []int
+	others  []int
+	session []byte
+	rand    io.Reader
+
+	w       *big.Int
+	wPoints map[int]ecgeneric.Point
+
+	k, gamma, rho, nu *big.Int
+	kc, gc            *big.Int
+	gammaPoint        ecgeneric.Point
+	peerK, peerG      map[int]*big.Int
+	beta, betaHat     map[int]*big.Int
+
+	bigGamma ecgeneric.Point
+	bigDelta ecgeneric.Point
+	bigS     ecgeneric.Point
+	delta    *big.Int
+	chi      *big.Int
+	round    int
+}
+
+// NewPresigner starts presigning for key.ID among signers, a set of at least
+// key.Threshold parties. session should be unique to this run.
+func NewPresigner(key *ECDSAKey, signers []int, session []byte, rand io.Reader) (*Presigner, error) {
+	sorted, err := checkParties(key.ID, signers)
+	if err != nil {
+		return nil, err
+	}
+	if len(sorted) < key.Threshold {
+		return nil, fmt.Errorf("threshold: %d signers, need %d", len(sorted), key.Threshold)
+	}
+	curve := key.Curve
+	if curve.N.BitLen() > paillier.Ell {
+		return nil, fmt.Errorf("threshold: %s is too large for the range proofs", curve.Name)
+	}
+	if key.Paillier == nil {
+		return nil, errors.New("threshold: key has no Paillier key, run a refresh")
+	}
+
+	p := &Presigner{
+		key:     key,
+		curve:   curve,
+		signers: sorted,
+		others:  without(sorted, key.ID),
+		session: derive("threshold/ecdsa/presign", []byte(curve.Name), session,
+			encodeIDs(sorted), encodePoint(curve, key.PublicKey.X, key.PublicKey.Y)),
+		rand:    rand,
+		wPoints: make(map[int]ecgeneric.Point, len(sorted)),
+	}
+	for _, j := range sorted {
+		y, ok := key.VerificationShares[j]
+		if !ok || key.Aux[j] == nil {
+			return nil, fmt.Errorf("threshold: party %d does not hold a share", j)
+		}
+		lambda, err := vss.Lagrange(curve.N, sorted, j)
+		if err != nil {
+			return nil, err
+		}
+		x, yy := curve.ScalarMultGLV(y.X, y.Y, lambda.Bytes())
+		p.wPoints[j] = ecgeneric.Point{X: x, Y: yy}
+		if j == key.ID {
+			p.w = lambda.Mul(lambda, key.Secret)
+			p.w.Mod(p.w, curve.N)
+		}
+	}
+	return p, nil
+}
+
+func (p *Presigner) context(id int) []byte {
+	return sessionContext("presign", id, p.session)
+}
+
+func (p *Presigner) generator() ecgeneric.Point {
+	return ecgeneric.Point{X: p.curve.Gx, Y: p.curve.Gy}
+}
+
+// Round1 draws the nonce and mask shares and returns the messages for the
+// other signers.
+func (p *Presigner) Round1() (map[int]*PresignRound1, error) {
+	if p.round != 0 {
+		return nil, errors.New("threshold: presigning round 1 already done")
+	}
+	var err error
+	if p.k, err = vss.RandomScalar(p.rand, p.curve); err != nil {
+		return nil, err
+	}
+	if p.gamma, err = vss.RandomScalar(p.rand, p.curve); err != nil {
+		return nil, err
+	}
+	pk := &p.key.Paillier.PublicKey
+	if p.kc, p.rho, err = pk.Encrypt(p.rand, p.k); err != nil {
+		return nil, err
+	}
+	if p.gc, p.nu, err = pk.Encrypt(p.rand, p.gamma); err != nil {
+		return nil, err
+	}
+	out := make(map[int]*PresignRound1, len(p.others))
+	for _, j := range p.others {
+		proof, err := paillier.ProveEnc(p.rand, p.context(p.key.ID), pk, p.kc, p.k, p.rho, p.key.Aux[j].Pedersen)
+		if err != nil {
+			return nil, err
+		}
+		out[j] = &PresignRound1{K: p.kc, G: p.gc, Proof: proof}
+	}
+	p.round = 1
+	return out, nil
+}
+
+// Round2 checks the other signers' range proofs and runs this signer's side
+// of the conversions with each of them.
+func (p *Presigner) Round2(in map[int]*PresignRound1) (map[int]*PresignRound2, error) {
+	if p.round != 1 {
+		return nil, errors.New("threshold: presigning round 2 out of order")
+	}
+	own := p.key.Aux[p.key.ID]
+	p.peerK = make(map[int]*big.Int, len(p.others))
+	p.peerG = make(map[int]*big.Int, len(p.others))
+	for _, j := range p.others {
+		m := in[j]
+		if m == nil || m.K == nil || m.G == nil {
+			return nil, &FaultError{j, errors.New("missing presigning message")}
+		}
+		pkj := p.key.Aux[j].Paillier
+		if !pkj.ValidateCiphertext(m.G) || !m.Proof.Verify(p.context(j), pkj, m.K, own.Pedersen) {
+			return nil, &FaultError{j, errors.New("invalid nonce range proof")}
+		}
+		p.peerK[j], p.peerG[j] = m.K, m.G
+	}
+
+	x, y := p.curve.ScalarBaseMultGLV(p.gamma.Bytes())
+	p.gammaPoint = ecgeneric.Point{X: x, Y: y}
+	p.beta = make(map[int]*big.Int, len(p.others))
+	p.betaHat = make(map[int]*big.Int, len(p.others))
+	ctx := p.context(p.key.ID)
+	out := make(map[int]*PresignRound2, len(p.others))
+	for _, j := range p.others {
+		rp := p.key.Aux[j].Pedersen
+		d, f, proof, beta, err := p.mta(j, p.gamma, p.gammaPoint)
+		if err != nil {
+			return nil, err
+		}
+		dHat, fHat, proofHat, betaHat, err := p.mta(j, p.w, p.wPoints[p.key.ID])
+		if err != nil {
+			return nil, err
+		}
+		logProof, err := paillier.ProveLogStar(p.rand, ctx, p.curve, own.Paillier, p.gc, p.gammaPoint, p.generator(), p.gamma, p.nu, rp)
+		if err != nil {
+			return nil, err
+		}
+		p.beta[j], p.betaHat[j] = beta, betaHat
+		out[j] = &PresignRound2{
+			Gamma:    p.gammaPoint,
+			D:        d,
+			F:        f,
+			DHat:     dHat,
+			FHat:     fHat,
+			Proof:    proof,
+			ProofHat: proofHat,
+			LogProof: logProof,
+		}
+	}
+	p.round = 2
+	return out, nil
+}
+
+// mta is the respondent's side of a multiplicative-to-additive conversion
+// with j: it returns D = x·Kⱼ + Encⱼ(β), F = Enc(β) and the proof that both
+// are well formed for X = x·G. j ends up with x·kⱼ + β, this party with -β.
+func (p *Presigner) mta(j int, x *big.Int, X ecgeneric.Point) (d, f *big.Int, proof *paillier.AffGProof, beta *big.Int, err error) {
+	pkj := p.key.Aux[j].Paillier
+	pk := &p.key.Paillier.PublicKey
+	if beta, err = paillier.SampleSigned(p.rand, paillier.EllPrime); err != nil {
+		return
+	}
+	eb, s, err := pkj.Encrypt(p.rand, beta)
+	if err != nil {
+		return
+	}
+	d = pkj.Add(pkj.Mul(p.peerK[j], x), eb)
+	f, r, err := pk.Encrypt(p.rand, beta)
+	if err != nil {
+		return
+	}
+	proof, err = paillier.ProveAffG(p.rand, p.context(p.key.ID), p.curve, pkj, pk, p.peerK[j], d, f, X, x, beta, s, r, p.key.Aux[j].Pedersen)
+	return
+}
+
+// Round3 checks the conversions of the other signers and returns the shares
+// of δ = k·γ.
+func (p *Presigner) Round3(in map[int]*PresignRound2) (map[int]*PresignRound3, error) {
+	if p.round != 2 {
+		return nil, errors.New("threshold: presigning round 3 out of order")
+	}
+	curve := p.curve
+	sk := p.key.Paillier
+	rp := p.key.Aux[p.key.ID].Pedersen
+	n := curve.N
+
+	gamma := p.gammaPoint
+	// δᵢ = γᵢ·kᵢ + Σ (αᵢⱼ - βᵢⱼ), χᵢ = wᵢ·kᵢ + Σ (α̂ᵢⱼ - β̂ᵢⱼ)
+	delta := new(big.Int).Mul(p.gamma, p.k)
+	chi := new(big.Int).Mul(p.w, p.k)
+	for _, j := range p.others {
+		m := in[j]
+		if m == nil {
+			return nil, &FaultError{j, errors.New("missing presigning message")}
+		}
+		pkj := p.key.Aux[j].Paillier
+		ctx := p.context(j)
+		if !m.Proof.Verify(ctx, curve, &sk.PublicKey, pkj, p.kc, m.D, m.F, m.Gamma, rp) {
+			return nil, &FaultError{j, errors.New("invalid conversion proof")}
+		}
+		if !m.ProofHat.Verify(ctx, curve, &sk.PublicKey, pkj, p.kc, m.DHat, m.FHat, p.wPoints[j], rp) {
+			return nil, &FaultError{j, errors.New("invalid conversion proof")}
+		}
+		if !m.LogProof.Verify(ctx, curve, pkj, p.peerG[j], m.Gamma, p.generator(), rp) {
+			return nil, &FaultError{j, errors.New("invalid mask proof")}
+		}
+		alpha, err := sk.DecryptSigned(m.D)
+		if err != nil {
+			return nil, &FaultError{j, err}
+		}
+		alphaHat, err := sk.DecryptSigned(m.DHat)
+		if err != nil {
+			return nil, &FaultError{j, err}
+		}
+		delta.Add(delta, alpha.Sub(alpha, p.beta[j]))
+		chi.Add(chi, alphaHat.Sub(alphaHat, p.betaHat[j]))
+		x, y := curve.AddJ(gamma.X, gamma.Y, m.Gamma.X, m.Gamma.Y)
+		gamma = ecgeneric.Point{X: x, Y: y}
+	}
+	p.delta, p.chi = delta.Mod(delta, n), chi.Mod(chi, n)
+	p.bigGamma = gamma
+	p.beta, p.betaHat = nil, nil
+
+	x, y := curve.ScalarMultGLV(gamma.X, gamma.Y, p.k.Bytes())
+	bigDelta := ecgeneric.Point{X: x, Y: y}
+	x, y = curve.ScalarMultGLV(gamma.X, gamma.Y, p.chi.Bytes())
+	p.bigDelta, p.bigS = bigDelta, ecgeneric.Point{X: x, Y: y}
+	out := make(map[int]*PresignRound3, len(p.others))
+	for _, j := range p.others {
+		proof, err := paillier.ProveLogStar(p.rand, p.context(p.key.ID), curve, &sk.PublicKey, p.kc, bigDelta, gamma, p.k, p.rho, p.key.Aux[j].Pedersen)
+		if err != nil {
+			return nil, err
+		}
+		out[j] = &PresignRound3{Delta: p.delta, BigDelta: bigDelta, S: p.bigS, Proof: proof}
+	}
+	p.round = 3
+	return out, nil
+}
+
+// Finish checks the shares of δ and of χ and returns the presignature.
+func (p *Presigner) Finish(in map[int]*PresignRound3) (*Presignature, error) {
+	if p.round != 3 {
+		return nil, errors.New("threshold: presigning finished out of order")
+	}
+	p.round = 4
+	curve := p.curve
+	rp := p.key.Aux[p.key.ID].Pedersen
+
+	delta := new(big.Int).Set(p.delta)
+	dx, dy := p.bigDelta.X, p.bigDelta.Y
+	bigDelta := map[int]ecgeneric.Point{p.key.ID: p.bigDelta}
+	bigS := map[int]ecgeneric.Point{p.key.ID: p.bigS}
+	for _, j := range p.others {
+		m := in[j]
+		if m == nil || m.Delta == nil || m.S.X == nil || m.S.Y == nil {
+			return nil, &FaultError{j, errors.New("missing presigning message")}
+		}
+		if !m.Proof.Verify(p.context(j), curve, p.key.Aux[j].Paillier, p.peerK[j], m.BigDelta, p.bigGamma, rp) {
+			return nil, &FaultError{j, errors.New("invalid nonce proof")}
+		}
+		if !curve.IsOnCurve(m.S.X, m.S.Y) {
+			return nil, &FaultError{j, errors.New("invalid point")}
+		}
+		delta.Add(delta, m.Delta)
+		dx, dy = curve.AddJ(dx, dy, m.BigDelta.X, m.BigDelta.Y)
+		bigDelta[j], bigS[j] = m.BigDelta, m.S
+	}
+	delta.Mod(delta, curve.N)
+	// δ·G = Σ Δⱼ = k·γ·G
+	gx, gy := curve.ScalarBaseMultGLV(delta.Bytes())
+	if gx.Cmp(dx) != 0 || gy.Cmp(dy) != 0 {
+		return nil, errors.New("threshold: inconsistent presigning shares")
+	}
+	inv := new(big.Int).ModInverse(delta, curve.N)
+	if inv == nil {
+		return nil, errors.New("threshold: δ is zero, restart presigning")
+	}
+	rx, ry := curve.ScalarMultGLV(p.bigGamma.X, p.bigGamma.Y, inv.Bytes())
+
+	// kⱼ·R = δ⁻¹·Δⱼ and χⱼ·R = δ⁻¹·Sⱼ, and Σ χⱼ·R = k·d·k⁻¹·G is the
+	// public key.
+	kPoints := make(map[int]ecgeneric.Point, len(p.signers))
+	chiPoints := make(map[int]ecgeneric.Point, len(p.signers))
+	sx, sy := new(big.Int), new(big.Int)
+	for _, j := range p.signers {
+		x, y := curve.ScalarMultGLV(bigDelta[j].X, bigDelta[j].Y, inv.Bytes())
+		kPoints[j] = ecgeneric.Point{X: x, Y: y}
+		x, y = curve.ScalarMultGLV(bigS[j].X, bigS[j].Y, inv.Bytes())
+		chiPoints[j] = ecgeneric.Point{X: x, Y: y}
+		sx, sy = curve.AddJ(sx, sy, x, y)
+	}
+	if sx.Cmp(p.key.PublicKey.X) != 0 || sy.Cmp(p.key.PublicKey.Y) != 0 {
+		return nil, errors.New("threshold: inconsistent presigning shares")
+	}
+
+	pre := &Presignature{
+		Curve:     curve,
+		ID:        p.key.ID,
+		Signers:   p.signers,
+		PublicKey: p.key.PublicKey,
+		R:         ecgeneric.Point{X: rx, Y: ry},
+		K:         p.k,
+		Chi:       p.chi,
+		KPoints:   kPoints,
+		ChiPoints: chiPoints,
+	}
+	p.k, p.gamma, p.chi = nil, nil, nil
+	return pre, nil
+}
+
+// PartialSign returns this signer's share kᵢ·m + r·χᵢ of the signature of
+// hash. It consumes the presignature.
+func (p *Presignature) PartialSign(hash []byte) (*big.Int, error) {
+	if p.K == nil || p.Chi == nil {
+		return nil, errors.New("threshold: presignature already used")
+	}
+	n := p.Curve.N
+	r := new(big.Int).Mod(p.R.X, n)
+	m := ecgeneric.HashToInt(hash, p.Curve)
+	s := new(big.Int).Mul(p.K, m)
+	s.Add(s, new(big.Int).Mul(r, p.Chi))
+	p.K, p.Chi = nil, nil
+	return s.Mod(s, n), nil
+}
+
+// Combine sums the partial signatures of all signers into a signature of
+// hash in the [R || S || V] format of ecgeneric.Sign, with S in the lower
+// half of the order, and checks it against the public key. If the signature
+// does not verify, Combine checks every partial signature σⱼ against the
+// presignature, σⱼ·R = m·kⱼ·R + r·χⱼ·R, and returns a FaultError naming
+// the first signer whose partial fails.
+func (p *Presignature) Combine(hash []byte, partials map[int]*big.Int) ([]byte, error) {
+	curve := p.Curve
+	n := curve.N
+	s := new(big.Int)
+	for _, j := range p.Signers {
+		sj := partials[j]
+		if sj == nil || sj.Sign() < 0 || sj.Cmp(n) >= 0 {
+			return nil, &FaultError{j, errors.New("invalid partial signature")}
+		}
+		s.Add(s, sj)
+	}
+	s.Mod(s, n)
+	r := new(big.Int).Mod(p.R.X, n)
+	if r.Sign() == 0 || s.Sign() == 0 {
+		return nil, errors.New("threshold: degenerate signature")
+	}
+
+	v := byte(p.R.Y.Bit(0))
+	if p.R.X.Cmp(n) >= 0 {
+		v |= 2
+	}
+	// Negating s negates R, which flips the parity of its y coordinate.
+	if s.Cmp(new(big.Int).Rsh(n, 1)) > 0 {
+		s.Sub(n, s)
+		v ^= 1
+	}
+
+	// u₁ = m/s, u₂ = r/s, x(u₁·G + u₂·Y) = r
+	w := new(big.Int).ModInverse(s, n)
+	u1 := ecgeneric.HashToInt(hash, curve)
+	u1.Mul(u1, w)
+	u1.Mod(u1, n)
+	u2 := new(big.Int).Mul(r, w)
+	u2.Mod(u2, n)
+	x, _ := curve.CombinedMult(p.PublicKey.X, p.PublicKey.Y, u1.Bytes(), u2.Bytes())
+	if x.Mod(x, n).Cmp(r) != 0 {
+		if err := p.identify(hash, partials); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("threshold: combined signature does not verify")
+	}
+
+	size := (n.BitLen() + 7) / 8
+	sig := make([]byte, 2*size+1)
+	r.FillBytes(sig[:size])
+	s.FillBytes(sig[size : 2*size])
+	sig[2*size] = v
+	return sig, nil
+}
+
+// identify returns a FaultError for the first signer whose partial signature
+// does not match its points in the presignature.
+func (p *Presignature) identify(hash []byte, partials map[int]*big.Int) error {
+	curve := p.Curve
+	r := new(big.Int).Mod(p.R.X, curve.N)
+	m := ecgeneric.HashToInt(hash, curve)
+	for _, j := range p.Signers {
+		kp, cp := p.KPoints[j], p.ChiPoints[j]
+		if kp.X == nil || cp.X == nil {
+			return &FaultError{j, errors.New("presignature has no points for the signer")}
+		}
+		lx, ly := curve.ScalarMultGLV(p.R.X, p.R.Y, partials[j].Bytes())
+		rx, ry := curve.MultiScalarMult([]ecgeneric.Point{kp, cp}, []*big.Int{m, r})
+		if lx.Cmp(rx) != 0 || ly.Cmp(ry) != 0 {
+			return &FaultError{j, errors.New("partial signature does not match the presignature")}
+		}
+	}
+	return nil
+}
+
+// RunPresign runs presigning for key.ID over t.
+func RunPresign(t Transport, key *ECDSAKey, signers []int, session []byte, rand io.Reader) (*Presignature, error) {
+	p, err := NewPresigner(key, signers, session, rand)
+	if err != nil {
+		return nil, err
+	}
+	return runPresign(&router{t: t}, 0, p)
+}
+
+// runPresign drives p with rounds numbered from base+1.
+func runPresign(rt *router, base int, p *Presigner) (*Presignature, error) {
+	out1, err := p.Round1()
+	if err != nil {
+		return nil, err
+	}
+	in1, err := rt.exchange(base+1, p.others, func(j int) interface{} { return out1[j] })
+	if err != nil {
+		return nil, err
+	}
+	r1 := make(map[int]*PresignRound1, len(in1))
+	for j, m := range in1 {
+		if r1[j], _ = m.(*PresignRound1); r1[j] == nil {
+			return nil, &FaultError{j, errors.New("unexpected message type")}
+		}
+	}
+
+	out2, err := p.Round2(r1)
+	if err != nil {
+		return nil, err
+	}
+	in2, err := rt.exchange(base+2, p.others, func(j int) interface{} { return out2[j] })
+	if err != nil {
+		return nil, err
+	}
+	r2 := make(map[int]*PresignRound2, len(in2))
+	for j, m := range in2 {
+		if r2[j], _ = m.(*PresignRound2); r2[j] == nil {
+			return nil, &FaultError{j, errors.New("unexpected message type")}
+		}
+	}
+
+	out3, err := p.Round3(r2)
+	if err != nil {
+		return nil, err
+	}
+	in3, err := rt.exchange(base+3, p.others, func(j int) interface{} { return out3[j] })
+	if err != nil {
+		return nil, err
+	}
+	r3 := make(map[int]*PresignRound3, len(in3))
+	for j, m := range in3 {
+		if r3[j], _ = m.(*PresignRound3); r3[j] == nil {
+			return nil, &FaultError{j, errors.New("unexpected message type")}
+		}
+	}
+	return p.Finish(r3)
+}
+
+// RunSignPresigned signs hash with pre over t in a single round.
+func RunSignPresigned(t Transport, pre *Presignature, hash []byte) ([]byte, error) {
+	return runSign(&router{t: t}, 0, pre, hash)
+}
+
+// runSign exchanges the partial signatures in round base+1.
+func runSign(rt *router, base int, pre *Presignature, hash []byte) ([]byte, error) {
+	si, err := pre.PartialSign(hash)
+	if err != nil {
+		return nil, err
+	}
+	in, err := rt.exchange(base+1, without(pre.Signers, pre.ID), func(int) interface{} { return si })
+	if err != nil {
+		return nil, err
+	}
+	partials := map[int]*big.Int{pre.ID: si}
+	for j, m := range in {
+		if partials[j], _ = m.(*big.Int); partials[j] == nil {
+			return nil, &FaultError{j, errors.New("unexpected message type")}
+		}
+	}
+	return pre.Combine(hash, partials)
+}
+
+// RunECDSASign presigns and signs hash for key.ID among signers over t.
+// session identifies the presigning run, as for NewPresigner; it must be
+// unique even when the same hash is signed twice.
+func RunECDSASign(t Transport, key *ECDSAKey, signers []int, session, hash []byte, rand io.Reader) ([]byte, error) {
+	p, err := NewPresigner(key, signers, session, rand)
+	if err != nil {
+		return nil, err
+	}
+	rt := &router{t: t}
+	pre, err := runPresign(rt, 0, p)
+	if err != nil {
+		return nil, err
+	}
+	return runSign(rt, 3, pre, hash)
+}
@@ -0,0 +1,202 @@
+package threshold_test
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/nist"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/threshold"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+)
+
+// simulate runs fn for every party over a fresh in-memory network and
+// returns the results by party.
+func simulate(t *testing.T, ids []int, fn func(id int, tr threshold.Transport) (interface{}, error)) map[int]interface{} {
+	net := threshold.NewLocalNetwork(ids)
+	defer net.Close()
+	out := make(map[int]interface{}, len(ids))
+	errs := make(map[int]error)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, id := range ids {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			v, err := fn(id, net.Transport(id))
+			if err != nil {
+				// Unblock the others.
+				net.Close()
+			}
+			mu.Lock()
+			out[id], errs[id] = v, err
+			mu.Unlock()
+		}(id)
+	}
+	wg.Wait()
+	for id, err := range errs {
+		require.NoError(t, err, "party %d", id)
+	}
+	return out
+}
+
+var (
+	pairOnce sync.Once
+	pairKeys map[int]*threshold.ECDSAKey
+)
+
+// pair returns a 2-of-2 key shared by the tests.
+func pair(t *testing.T) map[int]*threshold.ECDSAKey {
+	pairOnce.Do(func() {
+		pairKeys = ecdsaKeygen(t, []int{1, 2}, 2)
+	})
+	return pairKeys
+}
+
+func ecdsaKeygen(t *testing.T, parties []int, th int) map[int]*threshold.ECDSAKey {
+	res := simulate(t, parties, func(id int, tr threshold.Transport) (interface{}, error) {
+		return threshold.RunECDSAKeygen(tr, &nist.Secp256k1, id, parties, th, []byte("keygen"), rand.Reader)
+	})
+	keys := make(map[int]*threshold.ECDSAKey, len(res))
+	for id, v := range res {
+		keys[id] = v.(*threshold.ECDSAKey)
+	}
+	return keys
+}
+
+func ecdsaSign(t *testing.T, keys map[int]*threshold.ECDSAKey, signers []int, hash []byte) []byte {
+	session := make([]byte, 16)
+	_, err := rand.Read(session)
+	require.NoError(t, err)
+	res := simulate(t, signers, func(id int, tr threshold.Transport) (interface{}, error) {
+		return threshold.RunECDSASign(tr, keys[id], signers, session, hash, rand.Reader)
+	})
+	sig := res[signers[0]].([]byte)
+	for _, v := range res {
+		require.Equal(t, sig, v.([]byte))
+	}
+	return sig
+}
+
+func checkEthSignature(t *testing.T, key *threshold.ECDSAKey, hash, sig []byte) {
+	pub := ecgeneric.Marshal(key.Curve, key.PublicKey.X, key.PublicKey.Y)
+	require.Len(t, sig, ecgeneric.SignatureLength)
+	require.True(t, ecgeneric.VerifySignature(pub, hash, sig[:64]))
+	rec, err := ecgeneric.Ecrecover(hash, sig)
+	require.NoError(t, err)
+	require.Equal(t, pub, rec)
+}
+
+func keccak(msg string) []byte {
+	h := sha3.NewLegacyKeccak256()
+	h.Write([]byte(msg))
+	return h.Sum(nil)
+}
+
+func TestECDSA2of2(t *testing.T) {
+	parties := []int{1, 2}
+	keys := pair(t)
+	hash := keccak("two of two")
+	checkEthSignature(t, keys[1], hash, ecdsaSign(t, keys, parties, hash))
+
+	_, err := threshold.NewPresigner(keys[1], []int{1}, nil, rand.Reader)
+	require.Error(t, err)
+}
+
+func TestECDSAThresholdRefreshPresign(t *testing.T) {
+	if testing.Short() {
+		t.Skip("slow: three Paillier keys and a refresh")
+	}
+	parties := []int{1, 2, 3}
+	keys := ecdsaKeygen(t, parties, 2)
+	for _, signers := range [][]int{{1, 2}, {1, 2, 3}} {
+		hash := keccak("threshold")
+		checkEthSignature(t, keys[1], hash, ecdsaSign(t, keys, signers, hash))
+	}
+
+	// A refresh keeps the key but replaces the shares.
+	res := simulate(t, parties, func(id int, tr threshold.Transport) (interface{}, error) {
+		return threshold.RunRefresh(tr, keys[id].KeyShare, []byte("refresh"), rand.Reader)
+	})
+	fresh := make(map[int]*threshold.ECDSAKey)
+	for id, v := range res {
+		fresh[id] = v.(*threshold.ECDSAKey)
+		require.Equal(t, keys[id].PublicKey, fresh[id].PublicKey)
+		require.NotEqual(t, keys[id].Secret, fresh[id].Secret)
+		x, y := nist.Secp256k1.ScalarBaseMultGLV(fresh[id].Secret.Bytes())
+		require.Equal(t, ecgeneric.Point{X: x, Y: y}, fresh[id].VerificationShares[id])
+	}
+	for _, id := range parties {
+		require.Equal(t, fresh[1].VerificationShares, fresh[id].VerificationShares)
+	}
+
+	// Presign offline, then sign in one round.
+	signers := []int{1, 3}
+	res = simulate(t, signers, func(id int, tr threshold.Transport) (interface{}, error) {
+		return threshold.RunPresign(tr, fresh[id], signers, []byte("presign"), rand.Reader)
+	})
+	hash := keccak("presigned")
+	res = simulate(t, signers, func(id int, tr threshold.Transport) (interface{}, error) {
+		return threshold.RunSignPresigned(tr, res[id].(*threshold.Presignature), hash)
+	})
+	checkEthSignature(t, fresh[1], hash, res[1].([]byte))
+	require.Equal(t, res[1], res[3])
+}
+
+func TestPresignatureReuse(t *testing.T) {
+	parties := []int{1, 2}
+	keys := pair(t)
+	res := simulate(t, parties, func(id int, tr threshold.Transport) (interface{}, error) {
+		return threshold.RunPresign(tr, keys[id], parties, nil, rand.Reader)
+	})
+	p1, p2 := res[1].(*threshold.Presignature), res[2].(*threshold.Presignature)
+	hash := keccak("once")
+	s1, err := p1.PartialSign(hash)
+	require.NoError(t, err)
+	s2, err := p2.PartialSign(hash)
+	require.NoError(t, err)
+
+	_, err = p1.PartialSign(keccak("twice"))
+	require.Error(t, err)
+
+	bad := new(big.Int).Add(s2, big.NewInt(1))
+	_, err = p1.Combine(hash, map[int]*big.Int{1: s1, 2: bad})
+	require.Error(t, err)
+	sig, err := p1.Combine(hash, map[int]*big.Int{1: s1, 2: s2})
+	require.NoError(t, err)
+	checkEthSignature(t, keys[1], hash, sig)
+}
+
+func TestECDSASignFault(t *testing.T) {
+	parties := []int{1, 2}
+	keys := pair(t)
+	res := simulate(t, parties, func(id int, tr threshold.Transport) (interface{}, error) {
+		return threshold.RunPresign(tr, keys[id], parties, []byte("fault"), rand.Reader)
+	})
+	pre := map[int]*threshold.Presignature{1: res[1].(*threshold.Presignature), 2: res[2].(*threshold.Presignature)}
+	hash := keccak("fault")
+	partials := make(map[int]*big.Int)
+	for _, id := range parties {
+		s, err := pre[id].PartialSign(hash)
+		require.NoError(t, err)
+		partials[id] = s
+	}
+
+	// Each signer blames the other for a partial signature that was
+	// tampered with, even though it is in range.
+	for _, id := range parties {
+		bad := 3 - id
+		in := map[int]*big.Int{id: partials[id], bad: new(big.Int).Add(partials[bad], big.NewInt(1))}
+		_, err := pre[id].Combine(hash, in)
+		var fe *threshold.FaultError
+		require.True(t, errors.As(err, &fe), "party %d", id)
+		require.Equal(t, bad, fe.Party)
+	}
+	sig, err := pre[1].Combine(hash, partials)
+	require.NoError(t, err)
+	checkEthSignature(t, keys[1], hash, sig)
+}
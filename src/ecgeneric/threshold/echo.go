@@ -0,0 +1,90 @@
+package threshold
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/vss"
+)
+
+// Echo is sent to every party after a broadcast round. It holds a hash of
+// all the broadcast messages of that round as the sender received them.
+//
+// Transports deliver broadcasts as one point-to-point message per peer, so a
+// dishonest sender could give different peers different commitments, each
+// consistent with the shares it sent them. Every party would accept its own
+// view and end up with a different key. Comparing echoes makes the parties
+// agree on what was broadcast before they use it.
+type Echo struct {
+	Hash []byte
+}
+
+// ErrInconsistentBroadcast is returned when another party's echo shows that
+// it received different broadcast messages. The fault cannot be attributed:
+// either a broadcaster equivocated or the echoing party lied.
+var ErrInconsistentBroadcast = errors.New("threshold: parties received different broadcast messages")
+
+// echoHash hashes the broadcast messages of every party in ids, each encoded
+// by enc, under the run's session.
+func echoHash(label string, session []byte, ids []int, enc func(id int) [][]byte) []byte {
+	parts := [][]byte{session}
+	for _, id := range ids {
+		m := enc(id)
+		parts = append(parts, encodeIDs([]int{id}), encodeIDs([]int{len(m)}))
+		parts = append(parts, m...)
+	}
+	return derive("threshold/echo/"+label, parts...)
+}
+
+// checkEchoes compares the echo of every party in ids other than self with
+// own.
+func checkEchoes(own []byte, self int, ids []int, echoes map[int]*Echo) error {
+	for _, j := range ids {
+		if j == self {
+			continue
+		}
+		e := echoes[j]
+		if e == nil {
+			return &FaultError{j, errors.New("missing echo")}
+		}
+		if !bytes.Equal(e.Hash, own) {
+			return fmt.Errorf("%w: party %d saw other messages than party %d", ErrInconsistentBroadcast, j, self)
+		}
+	}
+	return nil
+}
+
+func encodeCommitments(curve *ecgeneric.CurveParams, c vss.Commitments) [][]byte {
+	out := make([][]byte, len(c))
+	for i, p := range c {
+		if p.X == nil || p.Y == nil {
+			out[i] = nil
+			continue
+		}
+		out[i] = encodePoint(curve, p.X, p.Y)
+	}
+	return out
+}
+
+func encodeInts(xs ...*big.Int) [][]byte {
+	out := make([][]byte, len(xs))
+	for i, x := range xs {
+		if x != nil {
+			out[i] = x.Bytes()
+		}
+	}
+	return out
+}
+
+func encodeBools(bs []bool) []byte {
+	out := make([]byte, len(bs))
+	for i, b := range bs {
+		if b {
+			out[i] = 1
+		}
+	}
+	return out
+}
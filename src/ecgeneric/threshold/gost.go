@@ -0,0 +1,287 @@
+package threshold
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/gost"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/vss"
+)
+
+// GOSTRound1 commits to the signer's nonce point.
+type GOSTRound1 struct {
+	Commitment []byte
+}
+
+// GOSTRound2 opens the commitment and proves knowledge of the nonce.
+type GOSTRound2 struct {
+	R     ecgeneric.Point
+	Proof *DLogProof
+}
+
+// GOSTRound3 carries the signer's additive share of s.
+type GOSTRound3 struct {
+	S *big.Int
+}
+
+// GOSTSigner holds the state of one party in a threshold GOST R 34.10-2012
+// signing session.
+//
+// The nonce is k = Σ kᵢ over the signers, with Rᵢ = kᵢ·G committed to before
+// any of them is revealed so that no signer can bias R = k·G. With λᵢ the
+// Lagrange coefficients of the signer set, each signer outputs
+// sᵢ = r·λᵢ·xᵢ + e·kᵢ, which everyone can check against the public
+// verification share Yᵢ, and s = Σ sᵢ = r·d + k·e.
+type GOSTSigner struct {
+	share   *KeyShare
+	signers []int
+	e       *big.Int
+	lambda  *big.Int
+	session []byte
+	rand    io.Reader
+
+	k           *big.Int
+	ri          ecgeneric.Point
+	commitments map[int][]byte
+	points      map[int]ecgeneric.Point
+	r           *big.Int
+	round       int
+}
+
+// NewGOSTSigner starts a signing session of digest for share.ID among
+// signers, a set of at least share.Threshold parties. The digest is
+// interpreted as gost.Sign does.
+func NewGOSTSigner(share *KeyShare, signers []int, digest []byte, rand io.Reader) (*GOSTSigner, error) {
+	sorted, err := checkParties(share.ID, signers)
+	if err != nil {
+		return nil, err
+	}
+	if len(sorted) < share.Threshold {
+		return nil, fmt.Errorf("threshold: %d signers, need %d", len(sorted), share.Threshold)
+	}
+	for _, j := range sorted {
+		if _, ok := share.VerificationShares[j]; !ok {
+			return nil, fmt.Errorf("threshold: party %d does not hold a share", j)
+		}
+	}
+	curve := share.Curve
+	lambda, err := vss.Lagrange(curve.N, sorted, share.ID)
+	if err != nil {
+		return nil, err
+	}
+	e := new(big.Int).SetBytes(digest)
+	e.Mod(e, curve.N)
+	if e.Sign() == 0 {
+		e.SetInt64(1)
+	}
+	return &GOSTSigner{
+		share:   share,
+		signers: sorted,
+		e:       e,
+		lambda:  lambda,
+		session: derive("threshold/gost", []byte(curve.Name),
+			encodePoint(curve, share.PublicKey.X, share.PublicKey.Y), encodeIDs(sorted), digest),
+		rand: rand,
+	}, nil
+}
+
+func (s *GOSTSigner) commitment(id int, x, y *big.Int) []byte {
+	return derive("threshold/commit", s.session, encodeIDs([]int{id}), encodePoint(s.share.Curve, x, y))
+}
+
+// Round1 draws the nonce share and returns the commitment to Rᵢ.
+func (s *GOSTSigner) Round1() (*GOSTRound1, error) {
+	if s.round != 0 {
+		return nil, errors.New("threshold: signing round 1 already done")
+	}
+	k, err := vss.RandomScalar(s.rand, s.share.Curve)
+	if err != nil {
+		return nil, err
+	}
+	x, y := s.share.Curve.ScalarBaseMultGLV(k.Bytes())
+	s.k, s.ri = k, ecgeneric.Point{X: x, Y: y}
+	s.round = 1
+	return &GOSTRound1{Commitment: s.commitment(s.share.ID, x, y)}, nil
+}
+
+// Round2 records the other signers' commitments and reveals Rᵢ.
+func (s *GOSTSigner) Round2(r1 map[int]*GOSTRound1) (*GOSTRound2, error) {
+	if s.round != 1 {
+		return nil, errors.New("threshold: signing round 2 out of order")
+	}
+	s.commitments = make(map[int][]byte, len(s.signers))
+	for _, j := range s.signers {
+		m := r1[j]
+		if m == nil || len(m.Commitment) == 0 {
+			return nil, &FaultError{j, errors.New("missing nonce commitment")}
+		}
+		s.commitments[j] = m.Commitment
+	}
+	if subtle.ConstantTimeCompare(s.commitments[s.share.ID], s.commitment(s.share.ID, s.ri.X, s.ri.Y)) != 1 {
+		return nil, errors.New("threshold: own nonce commitment was replaced")
+	}
+	proof, err := proveDLog(s.rand, s.share.Curve, sessionContext("nonce", s.share.ID, s.session), s.k)
+	if err != nil {
+		return nil, err
+	}
+	s.round = 2
+	return &GOSTRound2{R: s.ri, Proof: proof}, nil
+}
+
+// Round3 checks the revealed nonce points, derives r and returns sᵢ.
+func (s *GOSTSigner) Round3(r2 map[int]*GOSTRound2) (*GOSTRound3, error) {
+	if s.round != 2 {
+		return nil, errors.New("threshold: signing round 3 out of order")
+	}
+	curve := s.share.Curve
+	s.points = make(map[int]ecgeneric.Point, len(s.signers))
+	rx, ry := new(big.Int), new(big.Int)
+	for _, j := range s.signers {
+		m := r2[j]
+		if m == nil || m.R.X == nil || m.R.Y == nil || !curve.IsOnCurve(m.R.X, m.R.Y) {
+			return nil, &FaultError{j, errors.New("invalid nonce point")}
+		}
+		if subtle.ConstantTimeCompare(s.commitment(j, m.R.X, m.R.Y), s.commitments[j]) != 1 {
+			return nil, &FaultError{j, errors.New("nonce point does not match its commitment")}
+		}
+		if err := verifyDLog(curve, sessionContext("nonce", j, s.session), m.R.X, m.R.Y, m.Proof); err != nil {
+			return nil, &FaultError{j, err}
+		}
+		s.points[j] = m.R
+		rx, ry = curve.AddJ(rx, ry, m.R.X, m.R.Y)
+	}
+	r := new(big.Int).Mod(rx, curve.N)
+	if r.Sign() == 0 {
+		return nil, errors.New("threshold: r is zero, restart the session")
+	}
+	s.r = r
+
+	// sᵢ = r·λᵢ·xᵢ + e·kᵢ
+	si := new(big.Int).Mul(r, s.lambda)
+	si.Mul(si, s.share.Secret)
+	si.Add(si, new(big.Int).Mul(s.e, s.k))
+	si.Mod(si, curve.N)
+	s.k = nil
+	s.round = 3
+	return &GOSTRound3{S: si}, nil
+}
+
+// Finish checks every partial signature and returns the signature (r, s).
+func (s *GOSTSigner) Finish(r3 map[int]*GOSTRound3) (r, sig *big.Int, err error) {
+	if s.round != 3 {
+		return nil, nil, errors.New("threshold: signing finished out of order")
+	}
+	curve := s.share.Curve
+	sig = new(big.Int)
+	for _, j := range s.signers {
+		m := r3[j]
+		if m == nil || m.S == nil || m.S.Sign() < 0 || m.S.Cmp(curve.N) >= 0 {
+			return nil, nil, &FaultError{j, errors.New("invalid partial signature")}
+		}
+		// sⱼ·G = r·λⱼ·Yⱼ + e·Rⱼ
+		lambda, err := vss.Lagrange(curve.N, s.signers, j)
+		if err != nil {
+			return nil, nil, err
+		}
+		c := lambda.Mul(lambda, s.r)
+		c.Mod(c, curve.N)
+		yj, rj := s.share.VerificationShares[j], s.points[j]
+		ax, ay := curve.ScalarMultGLV(yj.X, yj.Y, c.Bytes())
+		bx, by := curve.ScalarMultGLV(rj.X, rj.Y, s.e.Bytes())
+		wx, wy := curve.AddJ(ax, ay, bx, by)
+		gx, gy := curve.ScalarBaseMultGLV(m.S.Bytes())
+		if gx.Cmp(wx) != 0 || gy.Cmp(wy) != 0 {
+			return nil, nil, &FaultError{j, errors.New("partial signature does not verify")}
+		}
+		sig.Add(sig, m.S)
+	}
+	sig.Mod(sig, curve.N)
+	if sig.Sign() == 0 {
+		return nil, nil, errors.New("threshold: s is zero, restart the session")
+	}
+	s.round = 4
+	return new(big.Int).Set(s.r), sig, nil
+}
+
+// RunGOSTSign runs a signing session for share.ID over t and returns the
+// signature, which verifies with gost.Verify under share.PublicKey.
+func RunGOSTSign(t Transport, share *KeyShare, signers []int, digest []byte, rand io.Reader) (r, s *big.Int, err error) {
+	g, err := NewGOSTSigner(share, signers, digest, rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	others := without(g.signers, share.ID)
+	rt := &router{t: t}
+
+	// Each party's own message is part of its inputs for every round.
+	exchange := func(round int, v interface{}) (map[int]interface{}, error) {
+		in, err := rt.exchange(round, others, func(int) interface{} { return v })
+		if err != nil {
+			return nil, err
+		}
+		in[share.ID] = v
+		return in, nil
+	}
+
+	m1, err := g.Round1()
+	if err != nil {
+		return nil, nil, err
+	}
+	in1, err := exchange(1, m1)
+	if err != nil {
+		return nil, nil, err
+	}
+	r1 := make(map[int]*GOSTRound1, len(in1))
+	for j, p := range in1 {
+		if r1[j], _ = p.(*GOSTRound1); r1[j] == nil {
+			return nil, nil, &FaultError{j, errors.New("unexpected message type")}
+		}
+	}
+
+	m2, err := g.Round2(r1)
+	if err != nil {
+		return nil, nil, err
+	}
+	in2, err := exchange(2, m2)
+	if err != nil {
+		return nil, nil, err
+	}
+	r2 := make(map[int]*GOSTRound2, len(in2))
+	for j, p := range in2 {
+		if r2[j], _ = p.(*GOSTRound2); r2[j] == nil {
+			return nil, nil, &FaultError{j, errors.New("unexpected message type")}
+		}
+	}
+
+	m3, err := g.Round3(r2)
+	if err != nil {
+		return nil, nil, err
+	}
+	in3, err := exchange(3, m3)
+	if err != nil {
+		return nil, nil, err
+	}
+	r3 := make(map[int]*GOSTRound3, len(in3))
+	for j, p := range in3 {
+		if r3[j], _ = p.(*GOSTRound3); r3[j] == nil {
+			return nil, nil, &FaultError{j, errors.New("unexpected message type")}
+		}
+	}
+
+	r, s, err = g.Finish(r3)
+	if err != nil {
+		return nil, nil, err
+	}
+	ok, err := gost.Verify(digest, r, s, share.PublicKey.X, share.PublicKey.Y, share.Curve)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ok {
+		return nil, nil, errors.New("threshold: combined signature does not verify")
+	}
+	return r, s, nil
+}
@@ -0,0 +1,92 @@
+package threshold
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/transcript"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/vss"
+)
+
+var errProof = errors.New("threshold: invalid proof of knowledge")
+
+// DLogProof is a non-interactive Schnorr proof of knowledge of x with
+// X = x·G. The challenge is drawn from a Streebog transcript of the context,
+// G, X and the commitment T = t·G, and S = t + C·x mod N.
+type DLogProof struct {
+	C *big.Int
+	S *big.Int
+}
+
+// derive returns 32 bytes drawn from a Streebog transcript of parts for
+// the protocol named by label. It yields session identifiers, commitments
+// and echoes.
+func derive(label string, parts ...[]byte) []byte {
+	t := transcript.New(transcript.Streebog, label)
+	for _, p := range parts {
+		t.AppendMessage("part", p)
+	}
+	return t.ChallengeBytes("digest", 32)
+}
+
+func encodePoint(curve *ecgeneric.CurveParams, x, y *big.Int) []byte {
+	return ecgeneric.Marshal(curve, x, y)
+}
+
+func proofChallenge(curve *ecgeneric.CurveParams, context []byte, x, y, tx, ty *big.Int) *big.Int {
+	t := transcript.New(transcript.Streebog, "threshold/dlog")
+	t.AppendMessage("curve", []byte(curve.Name))
+	t.AppendMessage("context", context)
+	t.AppendPoint(curve, "G", ecgeneric.Point{X: curve.Gx, Y: curve.Gy})
+	t.AppendPoint(curve, "X", ecgeneric.Point{X: x, Y: y})
+	t.AppendPoint(curve, "T", ecgeneric.Point{X: tx, Y: ty})
+	return t.ChallengeScalar(curve, "c")
+}
+
+// proveDLog proves knowledge of secret for the point secret·G.
+func proveDLog(rand io.Reader, curve *ecgeneric.CurveParams, context []byte, secret *big.Int) (*DLogProof, error) {
+	t, err := vss.RandomScalar(rand, curve)
+	if err != nil {
+		return nil, err
+	}
+	x, y := curve.ScalarBaseMultGLV(secret.Bytes())
+	tx, ty := curve.ScalarBaseMultGLV(t.Bytes())
+	c := proofChallenge(curve, context, x, y, tx, ty)
+	s := new(big.Int).Mul(c, secret)
+	s.Add(s, t)
+	return &DLogProof{C: c, S: s.Mod(s, curve.N)}, nil
+}
+
+// verifyDLog checks p against the point (x, y).
+func verifyDLog(curve *ecgeneric.CurveParams, context []byte, x, y *big.Int, p *DLogProof) error {
+	if p == nil || p.C == nil || p.S == nil || p.S.Sign() < 0 || p.S.Cmp(curve.N) >= 0 {
+		return errProof
+	}
+	// T = S·G - C·X
+	negC := new(big.Int).Sub(curve.N, new(big.Int).Mod(p.C, curve.N))
+	tx, ty := curve.CombinedMult(x, y, p.S.Bytes(), negC.Bytes())
+	if proofChallenge(curve, context, x, y, tx, ty).Cmp(p.C) != 0 {
+		return errProof
+	}
+	return nil
+}
+
+// sessionContext binds proofs and commitments to a protocol instance.
+func sessionContext(label string, id int, parts ...[]byte) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(id))
+	return derive(label, append([][]byte{b[:]}, parts...)...)
+}
+
+func encodeIDs(ids []int) []byte {
+	out := make([]byte, 0, 8*len(ids))
+	var b [8]byte
+	for _, id := range ids {
+		binary.BigEndian.PutUint64(b[:], uint64(id))
+		out = append(out, b[:]...)
+	}
+	return out
+}
@@ -0,0 +1,316 @@
+package threshold
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/paillier"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/vss"
+)
+
+// AuxInfo holds a party's public Paillier key and ring-Pedersen parameters,
+// which share one modulus.
+type AuxInfo struct {
+	Paillier *paillier.PublicKey
+	Pedersen *paillier.RingPedersen
+}
+
+// ECDSAKey is a key share together with the auxiliary information threshold
+// ECDSA needs. It is produced, and renewed, by a key refresh.
+type ECDSAKey struct {
+	*KeyShare
+	Paillier *paillier.PrivateKey
+	Aux      map[int]*AuxInfo
+}
+
+// RefreshRound1 is broadcast: the Feldman commitments to a sharing of zero,
+// whose first element is the point at infinity, and the party's new Paillier
+// modulus and ring-Pedersen parameters with their proofs. As in the DKG, the
+// parties exchange an Echo of the round 1 messages.
+type RefreshRound1 struct {
+	Commitments vss.Commitments
+	Paillier    *big.Int
+	Pedersen    *paillier.RingPedersen
+	ModProof    *paillier.ModProof
+	PrmProof    *paillier.PrmProof
+}
+
+// RefreshRound2 is sent privately to each party: its share of the sender's
+// sharing of zero.
+type RefreshRound2 struct {
+	Share *big.Int
+}
+
+// Refresh holds the state of one party in a key refresh.
+//
+// A refresh re-randomizes the key shares without changing the key: every
+// party deals a sharing of zero and adds the shares it receives to its own,
+// so shares leaked before a refresh are useless with shares from after it.
+// It also replaces every party's Paillier key.
+type Refresh struct {
+	key     *KeyShare
+	session []byte
+	rand    io.Reader
+
+	poly  *vss.Polynomial
+	sk    *paillier.PrivateKey
+	echo  []byte
+	round int
+}
+
+// NewRefresh starts a refresh of key, which may come from a DKG or from an
+// earlier refresh.
+func NewRefresh(key *KeyShare, session []byte, rand io.Reader) (*Refresh, error) {
+	curve := key.Curve
+	if curve.N.BitLen() > paillier.Ell {
+		return nil, fmt.Errorf("threshold: %s is too large for the range proofs", curve.Name)
+	}
+	return &Refresh{
+		key: key,
+		session: derive("threshold/refresh", []byte(curve.Name), session,
+			encodeIDs(key.Parties), encodeIDs([]int{key.Threshold}),
+			encodePoint(curve, key.PublicKey.X, key.PublicKey.Y)),
+		rand: rand,
+	}, nil
+}
+
+// Round1 generates the new Paillier key and the sharing of zero. It returns
+// the broadcast message and the private share for every party, including
+// this one.
+func (r *Refresh) Round1() (*RefreshRound1, map[int]*RefreshRound2, error) {
+	if r.round != 0 {
+		return nil, nil, errors.New("threshold: refresh round 1 already done")
+	}
+	key := r.key
+	poly, err := vss.NewPolynomial(r.rand, key.Curve, new(big.Int), key.Threshold)
+	if err != nil {
+		return nil, nil, err
+	}
+	sk, err := paillier.GenerateKey(r.rand, paillier.ModulusBits)
+	if err != nil {
+		return nil, nil, err
+	}
+	rp, lambda, err := sk.RingPedersen(r.rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx := sessionContext("refresh", key.ID, r.session)
+	mod, err := paillier.ProveMod(r.rand, ctx, sk)
+	if err != nil {
+		return nil, nil, err
+	}
+	prm, err := paillier.ProvePrm(r.rand, ctx, rp, lambda, sk.Phi())
+	if err != nil {
+		return nil, nil, err
+	}
+	shares := make(map[int]*RefreshRound2, len(key.Parties))
+	for _, j := range key.Parties {
+		s, err := poly.Share(j)
+		if err != nil {
+			return nil, nil, err
+		}
+		shares[j] = &RefreshRound2{Share: s.Value}
+	}
+	r.poly, r.sk = poly, sk
+	r.round = 1
+	return &RefreshRound1{
+		Commitments: poly.Commit(),
+		Paillier:    sk.N,
+		Pedersen:    rp,
+		ModProof:    mod,
+		PrmProof:    prm,
+	}, shares, nil
+}
+
+// Echo takes the round 1 messages of all parties, keyed by sender, and
+// returns the echo to send to every other party.
+func (r *Refresh) Echo(r1 map[int]*RefreshRound1) (*Echo, error) {
+	if r.round != 1 {
+		return nil, errors.New("threshold: refresh echo out of order")
+	}
+	r.echo = r.echoHash(r1)
+	r.round = 2
+	return &Echo{Hash: r.echo}, nil
+}
+
+func (r *Refresh) echoHash(r1 map[int]*RefreshRound1) []byte {
+	return echoHash("refresh", r.session, r.key.Parties, func(j int) [][]byte {
+		m := r1[j]
+		if m == nil {
+			return nil
+		}
+		out := encodeCommitments(r.key.Curve, m.Commitments)
+		out = append(out, encodeInts(m.Paillier)...)
+		if m.Pedersen != nil {
+			out = append(out, encodeInts(m.Pedersen.N, m.Pedersen.S, m.Pedersen.T)...)
+		}
+		if m.ModProof != nil {
+			out = append(out, encodeInts(m.ModProof.W)...)
+			out = append(out, encodeInts(m.ModProof.X...)...)
+			out = append(out, encodeInts(m.ModProof.Z...)...)
+			out = append(out, encodeBools(m.ModProof.A), encodeBools(m.ModProof.B))
+		}
+		if m.PrmProof != nil {
+			out = append(out, encodeInts(m.PrmProof.A...)...)
+			out = append(out, encodeInts(m.PrmProof.Z...)...)
+		}
+		return out
+	})
+}
+
+// Finish verifies the messages of all parties and returns the refreshed key.
+// The maps are keyed by sender; r1 and r2 must cover every party and echoes
+// every other party. r1 must be the messages passed to Echo, and the refresh
+// fails with ErrInconsistentBroadcast unless every echo matches.
+func (r *Refresh) Finish(r1 map[int]*RefreshRound1, r2 map[int]*RefreshRound2, echoes map[int]*Echo) (*ECDSAKey, error) {
+	if r.round != 2 {
+		return nil, errors.New("threshold: refresh finished out of order")
+	}
+	r.round = 3
+	key := r.key
+	curve := key.Curve
+	if !bytes.Equal(r.echoHash(r1), r.echo) {
+		return nil, errors.New("threshold: round 1 messages differ from those echoed")
+	}
+	if err := checkEchoes(r.echo, key.ID, key.Parties, echoes); err != nil {
+		return nil, err
+	}
+
+	aux := make(map[int]*AuxInfo, len(key.Parties))
+	all := make([]vss.Commitments, 0, len(key.Parties))
+	secret := new(big.Int).Set(key.Secret)
+	for _, j := range key.Parties {
+		m1, m2 := r1[j], r2[j]
+		if m1 == nil || m2 == nil || m2.Share == nil || m1.Paillier == nil {
+			return nil, &FaultError{j, errors.New("missing refresh message")}
+		}
+		c := m1.Commitments
+		if len(c) != key.Threshold || c[0].X == nil || c[0].Y == nil || c[0].X.Sign() != 0 || c[0].Y.Sign() != 0 {
+			return nil, &FaultError{j, errors.New("commitments are not to a sharing of zero")}
+		}
+		if len(c) > 1 {
+			if err := c[1:].Validate(curve); err != nil {
+				return nil, &FaultError{j, err}
+			}
+		}
+		if err := c.Verify(curve, &vss.Share{Index: key.ID, Value: m2.Share}); err != nil {
+			return nil, &FaultError{j, err}
+		}
+
+		if m1.Paillier.BitLen() != paillier.ModulusBits {
+			return nil, &FaultError{j, errors.New("Paillier modulus has the wrong size")}
+		}
+		if err := m1.Pedersen.Validate(); err != nil {
+			return nil, &FaultError{j, err}
+		}
+		if m1.Pedersen.N.Cmp(m1.Paillier) != 0 {
+			return nil, &FaultError{j, errors.New("ring-Pedersen modulus differs from the Paillier modulus")}
+		}
+		pk := paillier.NewPublicKey(m1.Paillier)
+		ctx := sessionContext("refresh", j, r.session)
+		if !m1.ModProof.Verify(ctx, pk) {
+			return nil, &FaultError{j, errors.New("invalid Paillier modulus proof")}
+		}
+		if !m1.PrmProof.Verify(ctx, m1.Pedersen) {
+			return nil, &FaultError{j, errors.New("invalid ring-Pedersen proof")}
+		}
+
+		aux[j] = &AuxInfo{Paillier: pk, Pedersen: m1.Pedersen}
+		all = append(all, c)
+		secret.Add(secret, m2.Share)
+	}
+	secret.Mod(secret, curve.N)
+	r.poly = nil
+
+	sum, err := vss.Sum(curve, all...)
+	if err != nil {
+		return nil, err
+	}
+	ks := *key
+	ks.Secret = secret
+	ks.VerificationShares = make(map[int]ecgeneric.Point, len(key.Parties))
+	for _, j := range key.Parties {
+		x, y := sum.Evaluate(curve, j)
+		old := key.VerificationShares[j]
+		x, y = curve.AddJ(old.X, old.Y, x, y)
+		ks.VerificationShares[j] = ecgeneric.Point{X: x, Y: y}
+	}
+	return &ECDSAKey{KeyShare: &ks, Paillier: r.sk, Aux: aux}, nil
+}
+
+// RunRefresh runs a key refresh for key.ID over t.
+func RunRefresh(t Transport, key *KeyShare, session []byte, rand io.Reader) (*ECDSAKey, error) {
+	r, err := NewRefresh(key, session, rand)
+	if err != nil {
+		return nil, err
+	}
+	return runRefresh(&router{t: t}, 0, r)
+}
+
+// RunECDSAKeygen runs a DKG followed by a refresh, which sets up the Paillier
+// keys, for party id over t.
+func RunECDSAKeygen(t Transport, curve *ecgeneric.CurveParams, id int, parties []int, threshold int, session []byte, rand io.Reader) (*ECDSAKey, error) {
+	d, err := NewDKG(curve, id, parties, threshold, session, rand)
+	if err != nil {
+		return nil, err
+	}
+	rt := &router{t: t}
+	ks, err := runDKG(rt, 0, d)
+	if err != nil {
+		return nil, err
+	}
+	r, err := NewRefresh(ks, session, rand)
+	if err != nil {
+		return nil, err
+	}
+	return runRefresh(rt, dkgRounds, r)
+}
+
+// runRefresh drives r with rounds numbered from base+1.
+func runRefresh(rt *router, base int, r *Refresh) (*ECDSAKey, error) {
+	b, shares, err := r.Round1()
+	if err != nil {
+		return nil, err
+	}
+	id := r.key.ID
+	others := without(r.key.Parties, id)
+	m1, err := rt.exchange(base+1, others, func(int) interface{} { return b })
+	if err != nil {
+		return nil, err
+	}
+	m2, err := rt.exchange(base+2, others, func(j int) interface{} { return shares[j] })
+	if err != nil {
+		return nil, err
+	}
+	r1 := map[int]*RefreshRound1{id: b}
+	r2 := map[int]*RefreshRound2{id: shares[id]}
+	for _, j := range others {
+		p1, ok1 := m1[j].(*RefreshRound1)
+		p2, ok2 := m2[j].(*RefreshRound2)
+		if !ok1 || !ok2 {
+			return nil, &FaultError{j, errors.New("unexpected message type")}
+		}
+		r1[j], r2[j] = p1, p2
+	}
+	e, err := r.Echo(r1)
+	if err != nil {
+		return nil, err
+	}
+	m3, err := rt.exchange(base+3, others, func(int) interface{} { return e })
+	if err != nil {
+		return nil, err
+	}
+	echoes := make(map[int]*Echo, len(others))
+	for _, j := range others {
+		p3, ok := m3[j].(*Echo)
+		if !ok {
+			return nil, &FaultError{j, errors.New("unexpected message type")}
+		}
+		echoes[j] = p3
+	}
+	return r.Finish(r1, r2, echoes)
+}
@@ -0,0 +1,229 @@
+package threshold_test
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/gost"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/streebog"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/threshold"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/vss"
+	"github.com/stretchr/testify/require"
+)
+
+func runDKG(t *testing.T, curve *ecgeneric.CurveParams, parties []int, th int) map[int]*threshold.KeyShare {
+	net := threshold.NewLocalNetwork(parties)
+	defer net.Close()
+	out := make(map[int]*threshold.KeyShare, len(parties))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, id := range parties {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			ks, err := threshold.RunDKG(net.Transport(id), curve, id, parties, th, []byte("test"), rand.Reader)
+			require.NoError(t, err)
+			mu.Lock()
+			out[id] = ks
+			mu.Unlock()
+		}(id)
+	}
+	wg.Wait()
+	return out
+}
+
+func runSign(t *testing.T, shares map[int]*threshold.KeyShare, signers []int, digest []byte) (r, s *big.Int) {
+	net := threshold.NewLocalNetwork(signers)
+	defer net.Close()
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, id := range signers {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			ri, si, err := threshold.RunGOSTSign(net.Transport(id), shares[id], signers, digest, rand.Reader)
+			require.NoError(t, err)
+			mu.Lock()
+			if r != nil {
+				require.Equal(t, r, ri)
+				require.Equal(t, s, si)
+			}
+			r, s = ri, si
+			mu.Unlock()
+		}(id)
+	}
+	wg.Wait()
+	return r, s
+}
+
+func TestDKGAndSign(t *testing.T) {
+	for _, curve := range []*ecgeneric.CurveParams{&gost.Gost34102001paramSetA, &gost.Gost341012512paramSetA} {
+		parties := []int{1, 2, 3, 4, 5}
+		shares := runDKG(t, curve, parties, 3)
+
+		pub := shares[1].PublicKey
+		var vs []*vss.Share
+		for _, id := range parties {
+			ks := shares[id]
+			require.Equal(t, pub, ks.PublicKey)
+			x, y := curve.ScalarBaseMultJ(ks.Secret.Bytes())
+			require.Equal(t, ecgeneric.Point{X: x, Y: y}, shares[1].VerificationShares[id])
+			vs = append(vs, &vss.Share{Index: id, Value: ks.Secret})
+		}
+		d, err := vss.Combine(curve, vs[1:4])
+		require.NoError(t, err)
+		x, y := curve.ScalarBaseMultJ(d.Bytes())
+		require.Equal(t, pub, ecgeneric.Point{X: x, Y: y})
+
+		h := streebog.New256()
+		h.Write([]byte("threshold message"))
+		digest := h.Sum(nil)
+		for _, signers := range [][]int{{1, 2, 3}, {2, 4, 5}, {1, 2, 3, 4, 5}} {
+			r, s := runSign(t, shares, signers, digest)
+			ok, err := gost.Verify(digest, r, s, pub.X, pub.Y, curve)
+			require.NoError(t, err)
+			require.True(t, ok)
+		}
+
+		_, err = threshold.NewGOSTSigner(shares[1], []int{1, 2}, digest, rand.Reader)
+		require.Error(t, err)
+	}
+}
+
+func TestDKGFault(t *testing.T) {
+	curve := &gost.Gost34102001paramSetA
+	parties := []int{1, 2, 3}
+	dkgs := make(map[int]*threshold.DKG)
+	r1 := make(map[int]*threshold.DKGRound1)
+	r2 := make(map[int]map[int]*threshold.DKGRound2)
+	for _, id := range parties {
+		d, err := threshold.NewDKG(curve, id, parties, 2, nil, rand.Reader)
+		require.NoError(t, err)
+		dkgs[id] = d
+		r1[id], r2[id], err = d.Round1()
+		require.NoError(t, err)
+	}
+	// Party 3 sends party 1 a share that does not match its commitments.
+	r2[3][1].Share = new(big.Int).Add(r2[3][1].Share, big.NewInt(1))
+
+	echoes := make(map[int]*threshold.Echo)
+	for _, id := range parties {
+		e, err := dkgs[id].Echo(r1)
+		require.NoError(t, err)
+		echoes[id] = e
+	}
+	for _, id := range parties {
+		in := make(map[int]*threshold.DKGRound2)
+		for _, j := range parties {
+			in[j] = r2[j][id]
+		}
+		_, err := dkgs[id].Finish(r1, in, echoes)
+		if id != 1 {
+			require.NoError(t, err)
+			continue
+		}
+		var fe *threshold.FaultError
+		require.True(t, errors.As(err, &fe))
+		require.Equal(t, 3, fe.Party)
+		require.ErrorIs(t, err, vss.ErrInvalidCommit)
+	}
+}
+
+func TestDKGEquivocation(t *testing.T) {
+	curve := &gost.Gost34102001paramSetA
+	parties := []int{1, 2, 3}
+	dkgs := make(map[int]*threshold.DKG)
+	r1 := make(map[int]*threshold.DKGRound1)
+	r2 := make(map[int]map[int]*threshold.DKGRound2)
+	for _, id := range parties {
+		d, err := threshold.NewDKG(curve, id, parties, 2, []byte("test"), rand.Reader)
+		require.NoError(t, err)
+		dkgs[id] = d
+		r1[id], r2[id], err = d.Round1()
+		require.NoError(t, err)
+	}
+	// Party 1 deals a second polynomial and shows it to party 3 only. Each
+	// view is consistent on its own.
+	d, err := threshold.NewDKG(curve, 1, parties, 2, []byte("test"), rand.Reader)
+	require.NoError(t, err)
+	alt1, alt2, err := d.Round1()
+	require.NoError(t, err)
+	view := func(id int) (map[int]*threshold.DKGRound1, map[int]*threshold.DKGRound2) {
+		v1 := map[int]*threshold.DKGRound1{}
+		v2 := map[int]*threshold.DKGRound2{}
+		for _, j := range parties {
+			v1[j], v2[j] = r1[j], r2[j][id]
+		}
+		if id == 3 {
+			v1[1], v2[1] = alt1, alt2[3]
+		}
+		return v1, v2
+	}
+
+	echoes := make(map[int]*threshold.Echo)
+	for _, id := range parties {
+		v1, _ := view(id)
+		e, err := dkgs[id].Echo(v1)
+		require.NoError(t, err)
+		echoes[id] = e
+	}
+	for _, id := range []int{2, 3} {
+		v1, v2 := view(id)
+		_, err := dkgs[id].Finish(v1, v2, echoes)
+		require.ErrorIs(t, err, threshold.ErrInconsistentBroadcast, "party %d", id)
+	}
+}
+
+func TestSignFault(t *testing.T) {
+	curve := &gost.Gost34102001paramSetA
+	shares := runDKG(t, curve, []int{1, 2, 3}, 2)
+	signers := []int{1, 3}
+	digest := make([]byte, 32)
+	_, err := rand.Read(digest)
+	require.NoError(t, err)
+
+	g := make(map[int]*threshold.GOSTSigner)
+	r1 := make(map[int]*threshold.GOSTRound1)
+	for _, id := range signers {
+		g[id], err = threshold.NewGOSTSigner(shares[id], signers, digest, rand.Reader)
+		require.NoError(t, err)
+		r1[id], err = g[id].Round1()
+		require.NoError(t, err)
+	}
+	r2 := make(map[int]*threshold.GOSTRound2)
+	for _, id := range signers {
+		r2[id], err = g[id].Round2(r1)
+		require.NoError(t, err)
+	}
+	r3 := make(map[int]*threshold.GOSTRound3)
+	for _, id := range signers {
+		r3[id], err = g[id].Round3(r2)
+		require.NoError(t, err)
+	}
+	r3[3].S = new(big.Int).Add(r3[3].S, big.NewInt(1))
+	_, _, err = g[1].Finish(r3)
+	var fe *threshold.FaultError
+	require.True(t, errors.As(err, &fe))
+	require.Equal(t, 3, fe.Party)
+
+	// A nonce point swapped after the commitments is rejected.
+	other, err := threshold.NewGOSTSigner(shares[3], signers, digest, rand.Reader)
+	require.NoError(t, err)
+	o1, err := other.Round1()
+	require.NoError(t, err)
+	o2, err := other.Round2(map[int]*threshold.GOSTRound1{1: r1[1], 3: o1})
+	require.NoError(t, err)
+	h, err := threshold.NewGOSTSigner(shares[1], signers, digest, rand.Reader)
+	require.NoError(t, err)
+	h1, err := h.Round1()
+	require.NoError(t, err)
+	h2, err := h.Round2(map[int]*threshold.GOSTRound1{1: h1, 3: r1[3]})
+	require.NoError(t, err)
+	_, err = h.Round3(map[int]*threshold.GOSTRound2{1: h2, 3: o2})
+	require.True(t, errors.As(err, &fe))
+	require.Equal(t, 3, fe.Party)
+}
@@ -0,0 +1,203 @@
+package threshold
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Broadcast is the To value of a message sent to every other party.
+const Broadcast = 0
+
+// Message is the envelope exchanged between parties. Payload holds one of
+// the round message types of this package.
+type Message struct {
+	From    int
+	To      int
+	Round   int
+	Payload interface{}
+}
+
+// Transport delivers messages between parties. Implementations must provide
+// authenticated channels; point-to-point messages carry secret shares and must
+// also be confidential. Broadcast need not be reliable: the key generation
+// and refresh follow their broadcast round with an Echo round that detects a
+// sender who told parties different things. A Transport carries a single
+// protocol run: the Run functions drop messages left over for other runs when
+// they return.
+type Transport interface {
+	// Send delivers msg to msg.To, or to every other party if msg.To is
+	// Broadcast.
+	Send(msg *Message) error
+	// Receive blocks until a message for this party arrives.
+	Receive() (*Message, error)
+}
+
+// ErrClosed is returned by a LocalNetwork transport after Close.
+var ErrClosed = errors.New("threshold: network closed")
+
+// LocalNetwork connects parties running in one process. It is meant for
+// tests and simulations.
+type LocalNetwork struct {
+	mu      sync.Mutex
+	inboxes map[int]*inbox
+}
+
+type inbox struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	msgs   []*Message
+	closed bool
+}
+
+// NewLocalNetwork returns a network with a mailbox for each of ids.
+func NewLocalNetwork(ids []int) *LocalNetwork {
+	n := &LocalNetwork{inboxes: make(map[int]*inbox, len(ids))}
+	for _, id := range ids {
+		in := new(inbox)
+		in.cond = sync.NewCond(&in.mu)
+		n.inboxes[id] = in
+	}
+	return n
+}
+
+// Transport returns the endpoint of party id.
+func (n *LocalNetwork) Transport(id int) Transport {
+	return &localTransport{net: n, id: id}
+}
+
+// Close wakes all blocked receivers with ErrClosed.
+func (n *LocalNetwork) Close() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, in := range n.inboxes {
+		in.mu.Lock()
+		in.closed = true
+		in.cond.Broadcast()
+		in.mu.Unlock()
+	}
+}
+
+func (in *inbox) push(msg *Message) {
+	in.mu.Lock()
+	in.msgs = append(in.msgs, msg)
+	in.cond.Signal()
+	in.mu.Unlock()
+}
+
+type localTransport struct {
+	net *LocalNetwork
+	id  int
+}
+
+func (t *localTransport) Send(msg *Message) error {
+	m := *msg
+	m.From = t.id
+	t.net.mu.Lock()
+	defer t.net.mu.Unlock()
+	if m.To == Broadcast {
+		for id, in := range t.net.inboxes {
+			if id != t.id {
+				in.push(&m)
+			}
+		}
+		return nil
+	}
+	in, ok := t.net.inboxes[m.To]
+	if !ok {
+		return fmt.Errorf("threshold: unknown party %d", m.To)
+	}
+	in.push(&m)
+	return nil
+}
+
+func (t *localTransport) Receive() (*Message, error) {
+	in := t.net.inboxes[t.id]
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	for len(in.msgs) == 0 && !in.closed {
+		in.cond.Wait()
+	}
+	if len(in.msgs) == 0 {
+		return nil, ErrClosed
+	}
+	msg := in.msgs[0]
+	in.msgs = in.msgs[1:]
+	return msg, nil
+}
+
+// router reads from a Transport and hands out messages round by round,
+// keeping early messages of later rounds for when they are asked for.
+type router struct {
+	t       Transport
+	pending []*Message
+}
+
+// collect returns exactly one message of round from each party in from.
+func (r *router) collect(round int, from []int) (map[int]*Message, error) {
+	want := make(map[int]bool, len(from))
+	for _, id := range from {
+		want[id] = true
+	}
+	got := make(map[int]*Message, len(from))
+
+	take := func(m *Message) (bool, error) {
+		if m.Round != round {
+			return false, nil
+		}
+		if !want[m.From] {
+			return true, fmt.Errorf("threshold: unexpected message from party %d in round %d", m.From, round)
+		}
+		if _, dup := got[m.From]; dup {
+			return true, fmt.Errorf("threshold: party %d sent round %d twice", m.From, round)
+		}
+		got[m.From] = m
+		return true, nil
+	}
+
+	rest := r.pending[:0]
+	for _, m := range r.pending {
+		used, err := take(m)
+		if err != nil {
+			return nil, err
+		}
+		if !used {
+			rest = append(rest, m)
+		}
+	}
+	r.pending = rest
+
+	for len(got) < len(want) {
+		m, err := r.t.Receive()
+		if err != nil {
+			return nil, err
+		}
+		used, err := take(m)
+		if err != nil {
+			return nil, err
+		}
+		if !used {
+			r.pending = append(r.pending, m)
+		}
+	}
+	return got, nil
+}
+
+// exchange sends msg(j) to every j in peers as a message of round and
+// returns the payloads the peers sent in that round.
+func (r *router) exchange(round int, peers []int, msg func(to int) interface{}) (map[int]interface{}, error) {
+	for _, j := range peers {
+		if err := r.t.Send(&Message{To: j, Round: round, Payload: msg(j)}); err != nil {
+			return nil, err
+		}
+	}
+	got, err := r.collect(round, peers)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[int]interface{}, len(got))
+	for j, m := range got {
+		out[j] = m.Payload
+	}
+	return out, nil
+}
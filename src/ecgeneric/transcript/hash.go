@@ -0,0 +1,66 @@
+package transcript
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"hash"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/streebog"
+)
+
+// Tags that frame the operations of a hashDuplex, so that no sequence of
+// operations hashes like another.
+const (
+	opAppend byte = iota
+	opChallenge
+	opOutput
+	opRatchet
+)
+
+// hashDuplex keeps a chaining value of one digest. Every operation replaces
+// it with the hash of the old value and the framed operation; challenge
+// output is expanded in counter mode from the value after the challenge is
+// recorded, and a final ratchet keeps that output from being recovered from
+// later states.
+type hashDuplex struct {
+	newHash func() hash.Hash
+	state   []byte
+}
+
+func newSHA256() hash.Hash { return sha256.New() }
+
+func newStreebog512() hash.Hash { return streebog.New512() }
+
+func newHashDuplex(name string, newHash func() hash.Hash) *hashDuplex {
+	d := &hashDuplex{newHash: newHash}
+	d.state = d.next(opAppend, []byte(name))
+	return d
+}
+
+func (d *hashDuplex) append(label string, msg []byte) {
+	d.state = d.next(opAppend, le32(len(label)), []byte(label), le32(len(msg)), msg)
+}
+
+func (d *hashDuplex) challenge(label string, out []byte) {
+	d.state = d.next(opChallenge, le32(len(label)), []byte(label), le32(len(out)))
+	var ctr [4]byte
+	for i := 0; len(out) > 0; i++ {
+		binary.BigEndian.PutUint32(ctr[:], uint32(i))
+		out = out[copy(out, d.next(opOutput, ctr[:])):]
+	}
+	d.state = d.next(opRatchet)
+}
+
+func (d *hashDuplex) clone() duplex {
+	return &hashDuplex{newHash: d.newHash, state: append([]byte{}, d.state...)}
+}
+
+func (d *hashDuplex) next(op byte, fields ...[]byte) []byte {
+	h := d.newHash()
+	h.Write(d.state)
+	h.Write([]byte{op})
+	for _, f := range fields {
+		h.Write(f)
+	}
+	return h.Sum(nil)
+}
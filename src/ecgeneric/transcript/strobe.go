@@ -0,0 +1,177 @@
+package transcript
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// strobeR is the STROBE-128 rate in bytes, less the two bytes reserved for
+// padding.
+const strobeR = 166
+
+// STROBE operation flags.
+const (
+	flagI = 1 << iota
+	flagA
+	flagC
+	flagT
+	flagM
+	flagK
+)
+
+// strobe128 is the subset of STROBE v1.0.2 at 128-bit security that Merlin
+// uses: meta-AD, AD and PRF, with continued operations.
+type strobe128 struct {
+	state    [200]byte
+	pos      int
+	posBegin byte
+	curFlags byte
+}
+
+func newStrobe128(protocol string) *strobe128 {
+	s := new(strobe128)
+	copy(s.state[:], []byte{1, strobeR + 2, 1, 0, 1, 96})
+	copy(s.state[6:], "STROBEv1.0.2")
+	keccakF1600(&s.state)
+	s.metaAD([]byte(protocol), false)
+	return s
+}
+
+// append is Merlin's append_message: meta-AD(label || LE32(len(msg))) and
+// AD(msg).
+func (s *strobe128) append(label string, msg []byte) {
+	s.metaAD([]byte(label), false)
+	s.metaAD(le32(len(msg)), true)
+	s.ad(msg)
+}
+
+// challenge is Merlin's challenge_bytes: meta-AD(label || LE32(len(out)))
+// and PRF into out.
+func (s *strobe128) challenge(label string, out []byte) {
+	s.metaAD([]byte(label), false)
+	s.metaAD(le32(len(out)), true)
+	s.prf(out)
+}
+
+func (s *strobe128) clone() duplex {
+	c := *s
+	return &c
+}
+
+func (s *strobe128) metaAD(data []byte, more bool) {
+	s.beginOp(flagM|flagA, more)
+	s.absorb(data)
+}
+
+func (s *strobe128) ad(data []byte) {
+	s.beginOp(flagA, false)
+	s.absorb(data)
+}
+
+func (s *strobe128) prf(out []byte) {
+	s.beginOp(flagI|flagA|flagC, false)
+	s.squeeze(out)
+}
+
+func (s *strobe128) beginOp(flags byte, more bool) {
+	if more {
+		if s.curFlags != flags {
+			panic("transcript: continued STROBE operation with different flags")
+		}
+		return
+	}
+	oldBegin := s.posBegin
+	s.posBegin = byte(s.pos + 1)
+	s.curFlags = flags
+	s.absorb([]byte{oldBegin, flags})
+	if flags&(flagC|flagK) != 0 && s.pos != 0 {
+		s.runF()
+	}
+}
+
+func (s *strobe128) absorb(data []byte) {
+	for _, b := range data {
+		s.state[s.pos] ^= b
+		s.pos++
+		if s.pos == strobeR {
+			s.runF()
+		}
+	}
+}
+
+func (s *strobe128) squeeze(out []byte) {
+	for i := range out {
+		out[i] = s.state[s.pos]
+		s.state[s.pos] = 0
+		s.pos++
+		if s.pos == strobeR {
+			s.runF()
+		}
+	}
+}
+
+func (s *strobe128) runF() {
+	s.state[s.pos] ^= s.posBegin
+	s.state[s.pos+1] ^= 0x04
+	s.state[strobeR+1] ^= 0x80
+	keccakF1600(&s.state)
+	s.pos = 0
+	s.posBegin = 0
+}
+
+var keccakRC = [24]uint64{
+	0x0000000000000001, 0x0000000000008082, 0x800000000000808a, 0x8000000080008000,
+	0x000000000000808b, 0x0000000080000001, 0x8000000080008081, 0x8000000000008009,
+	0x000000000000008a, 0x0000000000000088, 0x0000000080008009, 0x000000008000000a,
+	0x000000008000808b, 0x800000000000008b, 0x8000000000008089, 0x8000000000008003,
+	0x8000000000008002, 0x8000000000000080, 0x000000000000800a, 0x800000008000000a,
+	0x8000000080008081, 0x8000000000008080, 0x0000000080000001, 0x8000000080008008,
+}
+
+// keccakRho holds the rotation of lane x + 5y.
+var keccakRho = [25]int{
+	0, 1, 62, 28, 27,
+	36, 44, 6, 55, 20,
+	3, 10, 43, 25, 39,
+	41, 45, 15, 21, 8,
+	18, 2, 61, 56, 14,
+}
+
+// keccakF1600 applies the Keccak-f[1600] permutation to a state stored as
+// 25 little-endian lanes.
+func keccakF1600(state *[200]byte) {
+	var a, b [25]uint64
+	for i := range a {
+		a[i] = binary.LittleEndian.Uint64(state[8*i:])
+	}
+	for _, rc := range keccakRC {
+		// θ
+		var c [5]uint64
+		for x := 0; x < 5; x++ {
+			c[x] = a[x] ^ a[x+5] ^ a[x+10] ^ a[x+15] ^ a[x+20]
+		}
+		for x := 0; x < 5; x++ {
+			d := c[(x+4)%5] ^ bits.RotateLeft64(c[(x+1)%5], 1)
+			for y := 0; y < 25; y += 5 {
+				a[x+y] ^= d
+			}
+		}
+		// ρ and π
+		for x := 0; x < 5; x++ {
+			for y := 0; y < 5; y++ {
+				b[y+5*((2*x+3*y)%5)] = bits.RotateLeft64(a[x+5*y], keccakRho[x+5*y])
+			}
+		}
+		// χ
+		for y := 0; y < 25; y += 5 {
+			for x := 0; x < 5; x++ {
+				a[x+y] = b[x+y] ^ (^b[(x+1)%5+y] & b[(x+2)%5+y])
+			}
+		}
+		// ι
+		a[0] ^= rc
+	}
+	for i := range a {
+		binary.LittleEndian.PutUint64(state[8*i:], a[i])
+	}
+}
@@ -0,0 +1,142 @@
+// Package transcript implements Fiat–Shamir transcripts in the style of
+// Merlin.
+//
+// A protocol appends every public value to a Transcript under a label and
+// draws its challenges from it, so each challenge depends on the whole
+// conversation before it: the protocol name, session data, earlier
+// messages and earlier challenges. Prover and verifier replay the same
+// sequence of operations and arrive at the same challenges. The proofs in
+// zkp, paillier and threshold, the DLEQ proofs of adaptor and the ring
+// signatures all draw their challenges from a Transcript; standardized
+// signatures such as BIP-340 and EC-SDSA keep the hashes their standards fix.
+//
+// A transcript runs on one of several backends. Strobe is STROBE-128 over
+// Keccak-f[1600] and produces the same bytes as Merlin v1.0; SHA256 and
+// Streebog chain a hash function over framed operations, for settings that
+// require a standardised hash.
+package transcript
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+)
+
+// Backend selects the primitive a Transcript is built on.
+type Backend int
+
+const (
+	// Strobe is STROBE-128 over Keccak-f[1600], compatible with Merlin v1.0.
+	Strobe Backend = iota
+	// SHA256 chains SHA-256 over the transcript.
+	SHA256
+	// Streebog chains Streebog-512 (GOST R 34.11-2012) over the transcript.
+	Streebog
+)
+
+// domainSeparatorLabel is the label under which New records the protocol
+// name, as in Merlin.
+const domainSeparatorLabel = "dom-sep"
+
+// duplex is the state a backend keeps. append records msg under label and
+// challenge fills out with bytes derived from everything recorded, then
+// records that it did so.
+type duplex interface {
+	append(label string, msg []byte)
+	challenge(label string, out []byte)
+	clone() duplex
+}
+
+// Transcript is a Fiat–Shamir transcript. The zero value is not usable;
+// create one with New.
+type Transcript struct {
+	d duplex
+}
+
+// New returns a transcript for the protocol named by label on backend. It
+// panics if backend is unknown.
+func New(backend Backend, label string) *Transcript {
+	var d duplex
+	switch backend {
+	case Strobe:
+		d = newStrobe128("Merlin v1.0")
+	case SHA256:
+		d = newHashDuplex("transcript/sha256", newSHA256)
+	case Streebog:
+		d = newHashDuplex("transcript/streebog512", newStreebog512)
+	default:
+		panic("transcript: unknown backend")
+	}
+	t := &Transcript{d: d}
+	t.AppendMessage(domainSeparatorLabel, []byte(label))
+	return t
+}
+
+// Clone returns an independent copy of t, so that a common prefix can be
+// shared between several proofs.
+func (t *Transcript) Clone() *Transcript {
+	return &Transcript{d: t.d.clone()}
+}
+
+// AppendMessage records msg under label.
+func (t *Transcript) AppendMessage(label string, msg []byte) {
+	t.d.append(label, msg)
+}
+
+// AppendUint64 records v under label as 8 little-endian bytes.
+func (t *Transcript) AppendUint64(label string, v uint64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], v)
+	t.AppendMessage(label, b[:])
+}
+
+// AppendPoint records the point p of curve under label in its canonical
+// encoding: compressed SEC 1, or the single byte 0 for the point at
+// infinity.
+func (t *Transcript) AppendPoint(curve *ecgeneric.CurveParams, label string, p ecgeneric.Point) {
+	t.AppendMessage(label, encodePoint(curve, p))
+}
+
+// AppendScalar records k modulo curve.N under label as a big-endian integer
+// of the byte length of N.
+func (t *Transcript) AppendScalar(curve *ecgeneric.CurveParams, label string, k *big.Int) {
+	r := new(big.Int).Mod(k, curve.N)
+	t.AppendMessage(label, r.FillBytes(make([]byte, (curve.N.BitLen()+7)/8)))
+}
+
+// ChallengeBytes returns n bytes derived from the transcript under label.
+func (t *Transcript) ChallengeBytes(label string, n int) []byte {
+	out := make([]byte, n)
+	t.d.challenge(label, out)
+	return out
+}
+
+// ChallengeScalar returns a scalar uniformly distributed modulo curve.N. It
+// draws integers of the bit length of N under label until one is below N,
+// so, unlike reducing a wide hash output, it has no bias at all.
+func (t *Transcript) ChallengeScalar(curve *ecgeneric.CurveParams, label string) *big.Int {
+	bits := curve.N.BitLen()
+	size := (bits + 7) / 8
+	mask := byte(0xff >> uint(8*size-bits))
+	for {
+		b := t.ChallengeBytes(label, size)
+		b[0] &= mask
+		if c := new(big.Int).SetBytes(b); c.Cmp(curve.N) < 0 {
+			return c
+		}
+	}
+}
+
+func encodePoint(curve *ecgeneric.CurveParams, p ecgeneric.Point) []byte {
+	if p.X == nil || p.Y == nil || (p.X.Sign() == 0 && p.Y.Sign() == 0) {
+		return []byte{0}
+	}
+	return ecgeneric.MarshalCompressed(curve, p.X, p.Y)
+}
+
+func le32(n int) []byte {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], uint32(n))
+	return b[:]
+}
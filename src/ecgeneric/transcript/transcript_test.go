@@ -0,0 +1,96 @@
+package transcript_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/gost"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/nist"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/transcript"
+	"github.com/stretchr/testify/require"
+)
+
+var backends = []transcript.Backend{transcript.Strobe, transcript.SHA256, transcript.Streebog}
+
+// Test vectors from the Merlin reference implementation.
+func TestMerlinSimple(t *testing.T) {
+	tr := transcript.New(transcript.Strobe, "test protocol")
+	tr.AppendMessage("some label", []byte("some data"))
+	require.Equal(t, "d5a21972d0d5fe320c0d263fac7fffb8145aa640af6e9bca177c03c7efcf0615",
+		hex.EncodeToString(tr.ChallengeBytes("challenge", 32)))
+}
+
+func TestMerlinComplex(t *testing.T) {
+	tr := transcript.New(transcript.Strobe, "test protocol")
+	tr.AppendMessage("step1", []byte("some data"))
+	data := bytes.Repeat([]byte{99}, 1024)
+	var c []byte
+	for i := 0; i < 32; i++ {
+		c = tr.ChallengeBytes("challenge", 32)
+		tr.AppendMessage("bigdata", data)
+		tr.AppendMessage("challengedata", c)
+	}
+	require.Equal(t, "a8c933f54fae76e3f9bea93648c1308e7dfa2152dd51674ff3ca438351cf003c", hex.EncodeToString(c))
+}
+
+func TestBackends(t *testing.T) {
+	var outputs [][]byte
+	for _, b := range backends {
+		run := func(label, msg string) []byte {
+			tr := transcript.New(b, "proto")
+			tr.AppendMessage(label, []byte(msg))
+			return tr.ChallengeBytes("c", 100)
+		}
+		c := run("a", "bc")
+		require.Equal(t, c, run("a", "bc"))
+		// Labels and messages are framed, so moving bytes between them
+		// changes the challenge.
+		require.NotEqual(t, c, run("ab", "c"))
+		require.NotEqual(t, c, run("a", "bd"))
+
+		tr := transcript.New(b, "proto")
+		tr.AppendMessage("a", []byte("bc"))
+		fork := tr.Clone()
+		c1 := tr.ChallengeBytes("c", 32)
+		require.Equal(t, c1, fork.ChallengeBytes("c", 32))
+		// Every challenge is recorded in the transcript.
+		require.NotEqual(t, c1, tr.ChallengeBytes("c", 32))
+		outputs = append(outputs, c)
+	}
+	require.NotEqual(t, outputs[0], outputs[1])
+	require.NotEqual(t, outputs[1], outputs[2])
+}
+
+func TestChallengeScalar(t *testing.T) {
+	curves := []*ecgeneric.CurveParams{
+		&nist.Secp256k1,
+		&nist.P256,
+		&gost.Gost34102001paramSetA,
+		&gost.Gost341012512paramSetA,
+	}
+	for _, b := range backends {
+		for _, curve := range curves {
+			G := ecgeneric.Point{X: curve.Gx, Y: curve.Gy}
+			prover := transcript.New(b, "scalar")
+			verifier := transcript.New(b, "scalar")
+			for _, tr := range []*transcript.Transcript{prover, verifier} {
+				tr.AppendPoint(curve, "G", G)
+				tr.AppendPoint(curve, "O", ecgeneric.Point{X: new(big.Int), Y: new(big.Int)})
+				tr.AppendScalar(curve, "k", big.NewInt(-1))
+				tr.AppendUint64("n", 42)
+			}
+			for i := 0; i < 16; i++ {
+				c := prover.ChallengeScalar(curve, "c")
+				require.True(t, c.Sign() >= 0 && c.Cmp(curve.N) < 0, curve.Name)
+				require.Equal(t, c, verifier.ChallengeScalar(curve, "c"), curve.Name)
+			}
+		}
+	}
+}
+
+func TestUnknownBackend(t *testing.T) {
+	require.Panics(t, func() { transcript.New(transcript.Backend(-1), "") })
+}
@@ -0,0 +1,298 @@
+// Package vrf implements the elliptic curve verifiable random function
+// ECVRF of RFC 9381 over ecgeneric curves.
+//
+// A VRF is a keyed hash whose output beta can be checked by anyone holding
+// the public key: Prove returns a proof pi for an input alpha, and Verify
+// recovers beta from pi after checking it. Only the key holder can compute
+// beta, and for each key and input there is exactly one beta.
+//
+// P256SHA256TAI and P256SHA256SSWU are the ECVRF-P256-SHA256-TAI and
+// ECVRF-P256-SHA256-SSWU suites of RFC 9381. The same construction is
+// offered on secp256k1 and, with Streebog in place of SHA-256, on the GOST
+// curves; those suites are not standardized and their suite strings are
+// our own.
+package vrf
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"hash"
+	"math/big"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/gost"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/h2c"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/nist"
+)
+
+var (
+	ErrInvalidProof = errors.New("vrf: invalid proof")
+
+	errPrivateKey = errors.New("vrf: private key out of range")
+	errPublicKey  = errors.New("vrf: invalid public key")
+	errHashPoint  = errors.New("vrf: no point found for input")
+	errCurve      = errors.New("vrf: key is on another curve")
+)
+
+// Domain separators of RFC 9381, Section 5.
+const (
+	encodeToCurveFront = 0x01
+	challengeFront     = 0x02
+	proofToHashFront   = 0x03
+	back               = 0x00
+)
+
+// Suite is an ECVRF cipher suite.
+type Suite struct {
+	// ID is the suite_string that separates the hashes of different suites.
+	ID    byte
+	Curve *ecgeneric.CurveParams
+	Hash  func() hash.Hash
+
+	// h2c is nil for try-and-increment encoding; otherwise inputs are
+	// encoded with encode_to_curve under dst.
+	h2c *h2c.Suite
+	dst []byte
+}
+
+// NewSuite returns an ECVRF suite on curve using newHash for all hashing
+// and for the RFC 6979 nonce. If h2cID is empty, inputs are encoded to the
+// curve by try-and-increment, as in the TAI suites; otherwise they are
+// encoded with RFC 9380 encode_to_curve, where h2cID names the hash-to-curve
+// suite in the domain separation tag.
+func NewSuite(id byte, curve *ecgeneric.CurveParams, newHash func() hash.Hash, h2cID string) (*Suite, error) {
+	s := &Suite{ID: id, Curve: curve, Hash: newHash}
+	if h2cID != "" {
+		hs, err := h2c.NewSuite(curve, newHash, 0)
+		if err != nil {
+			return nil, err
+		}
+		s.h2c = hs
+		s.dst = append([]byte("ECVRF_"+h2cID), id)
+	}
+	return s, nil
+}
+
+func mustSuite(id byte, curve *ecgeneric.CurveParams, newHash func() hash.Hash, h2cID string) *Suite {
+	s, err := NewSuite(id, curve, newHash, h2cID)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+var (
+	// P256SHA256TAI is ECVRF-P256-SHA256-TAI, RFC 9381 Section 5.5.
+	P256SHA256TAI = mustSuite(0x01, &nist.P256, sha256.New, "")
+	// P256SHA256SSWU is ECVRF-P256-SHA256-SSWU, RFC 9381 Section 5.5.
+	P256SHA256SSWU = mustSuite(0x02, &nist.P256, sha256.New, "P256_XMD:SHA-256_SSWU_NU_")
+
+	// Secp256k1SHA256TAI is the TAI construction on secp256k1, with the
+	// suite string used by other secp256k1 ECVRF implementations.
+	Secp256k1SHA256TAI = mustSuite(0xfe, &nist.Secp256k1, sha256.New, "")
+	// Secp256k1SHA256SSWU encodes inputs with secp256k1_XMD:SHA-256_SSWU_NU_.
+	Secp256k1SHA256SSWU = mustSuite(0xff, &nist.Secp256k1, sha256.New, "secp256k1_XMD:SHA-256_SSWU_NU_")
+)
+
+// GOSTSuite returns the ECVRF suite on a GOST curve, hashing with Streebog
+// sized to the curve as in gost.SDSAHash. With sswu false inputs are encoded
+// by try-and-increment, otherwise with RFC 9380 encode_to_curve.
+func GOSTSuite(curve *ecgeneric.CurveParams, sswu bool) (*Suite, error) {
+	newHash := gost.SDSAHash(curve)
+	if !sswu {
+		return NewSuite(0xf0, curve, newHash, "")
+	}
+	return NewSuite(0xf1, curve, newHash, curve.Name+"_XMD:STREEBOG-"+bitsName(newHash().Size())+"_SSWU_NU_")
+}
+
+func bitsName(size int) string {
+	if size == 64 {
+		return "512"
+	}
+	return "256"
+}
+
+// qLen is the length of an encoded scalar, cLen that of a challenge.
+func (s *Suite) qLen() int { return (s.Curve.N.BitLen() + 7) / 8 }
+
+func (s *Suite) cLen() int { return s.qLen() / 2 }
+
+func (s *Suite) ptLen() int { return 1 + (s.Curve.P.BitLen()+7)/8 }
+
+// ProofLen returns the length of a proof: an encoded point and two scalars.
+func (s *Suite) ProofLen() int { return s.ptLen() + s.cLen() + s.qLen() }
+
+func (s *Suite) point(p ecgeneric.Point) []byte {
+	return ecgeneric.MarshalCompressed(s.Curve, p.X, p.Y)
+}
+
+// Prove returns the proof pi that beta is the VRF output of priv for alpha.
+func (s *Suite) Prove(priv *ecgeneric.PrivateKey, alpha []byte) ([]byte, error) {
+	curve := s.Curve
+	N := curve.N
+	if priv.D == nil || priv.D.Sign() <= 0 || priv.D.Cmp(N) >= 0 {
+		return nil, errPrivateKey
+	}
+	if c := priv.Curve.Params(); c != curve && c.Name != curve.Name {
+		return nil, errCurve
+	}
+	Y := ecgeneric.Point{X: priv.X, Y: priv.Y}
+	H, err := s.encodeToCurve(Y, alpha)
+	if err != nil {
+		return nil, err
+	}
+	hString := s.point(H)
+	gx, gy := curve.ScalarMultJ(H.X, H.Y, priv.D.Bytes())
+	gamma := ecgeneric.Point{X: gx, Y: gy}
+
+	k := s.nonce(priv.D, hString)
+	ux, uy := curve.ScalarBaseMultJ(k.Bytes())
+	vx, vy := curve.ScalarMultJ(H.X, H.Y, k.Bytes())
+	c := s.challenge(Y, H, gamma, ecgeneric.Point{X: ux, Y: uy}, ecgeneric.Point{X: vx, Y: vy})
+	sc := new(big.Int).Mul(c, priv.D)
+	sc.Add(sc, k)
+	sc.Mod(sc, N)
+
+	pi := s.point(gamma)
+	pi = append(pi, c.FillBytes(make([]byte, s.cLen()))...)
+	return append(pi, sc.FillBytes(make([]byte, s.qLen()))...), nil
+}
+
+// Verify checks pi against the public key pub and input alpha, and returns
+// the VRF output beta if it is valid.
+func (s *Suite) Verify(pub *ecgeneric.PublicKey, alpha, pi []byte) ([]byte, error) {
+	curve := s.Curve
+	N := curve.N
+	if err := s.validateKey(pub); err != nil {
+		return nil, err
+	}
+	gamma, c, sc, err := s.decodeProof(pi)
+	if err != nil {
+		return nil, err
+	}
+	Y := ecgeneric.Point{X: pub.X, Y: pub.Y}
+	H, err := s.encodeToCurve(Y, alpha)
+	if err != nil {
+		return nil, err
+	}
+	negC := new(big.Int).Sub(N, c)
+	g := ecgeneric.Point{X: curve.Gx, Y: curve.Gy}
+	ux, uy := curve.MultiScalarMult([]ecgeneric.Point{g, Y}, []*big.Int{sc, negC})
+	vx, vy := curve.MultiScalarMult([]ecgeneric.Point{H, gamma}, []*big.Int{sc, negC})
+	c2 := s.challenge(Y, H, gamma, ecgeneric.Point{X: ux, Y: uy}, ecgeneric.Point{X: vx, Y: vy})
+	if subtle.ConstantTimeCompare(c.Bytes(), c2.Bytes()) != 1 {
+		return nil, ErrInvalidProof
+	}
+	return s.proofToHash(gamma), nil
+}
+
+// ProofToHash returns the VRF output beta of pi without checking the proof.
+// It must only be used on proofs that have already been verified.
+func (s *Suite) ProofToHash(pi []byte) ([]byte, error) {
+	gamma, _, _, err := s.decodeProof(pi)
+	if err != nil {
+		return nil, err
+	}
+	return s.proofToHash(gamma), nil
+}
+
+func (s *Suite) proofToHash(gamma ecgeneric.Point) []byte {
+	curve := s.Curve
+	if h := curve.Cofactor(); h.Cmp(big.NewInt(1)) != 0 {
+		x, y := curve.ScalarMultJ(gamma.X, gamma.Y, h.Bytes())
+		gamma = ecgeneric.Point{X: x, Y: y}
+	}
+	h := s.Hash()
+	h.Write([]byte{s.ID, proofToHashFront})
+	h.Write(s.point(gamma))
+	h.Write([]byte{back})
+	return h.Sum(nil)
+}
+
+func (s *Suite) validateKey(pub *ecgeneric.PublicKey) error {
+	curve := s.Curve
+	if pub == nil || pub.X == nil || pub.Y == nil || pub.Curve == nil {
+		return errPublicKey
+	}
+	if c := pub.Curve.Params(); c != curve && c.Name != curve.Name {
+		return errCurve
+	}
+	if (pub.X.Sign() == 0 && pub.Y.Sign() == 0) || !curve.IsOnCurve(pub.X, pub.Y) {
+		return errPublicKey
+	}
+	if curve.Cofactor().Cmp(big.NewInt(1)) != 0 {
+		if x, y := curve.ScalarMultJ(pub.X, pub.Y, curve.N.Bytes()); x.Sign() != 0 || y.Sign() != 0 {
+			return errPublicKey
+		}
+	}
+	return nil
+}
+
+func (s *Suite) decodeProof(pi []byte) (gamma ecgeneric.Point, c, sc *big.Int, err error) {
+	if len(pi) != s.ProofLen() {
+		return gamma, nil, nil, ErrInvalidProof
+	}
+	ptLen, cLen := s.ptLen(), s.cLen()
+	x, y := ecgeneric.UnmarshalCompressed(s.Curve, pi[:ptLen])
+	if x == nil {
+		return gamma, nil, nil, ErrInvalidProof
+	}
+	c = new(big.Int).SetBytes(pi[ptLen : ptLen+cLen])
+	sc = new(big.Int).SetBytes(pi[ptLen+cLen:])
+	if sc.Cmp(s.Curve.N) >= 0 {
+		return gamma, nil, nil, ErrInvalidProof
+	}
+	return ecgeneric.Point{X: x, Y: y}, c, sc, nil
+}
+
+// encodeToCurve maps alpha, salted with the public key, to a point H.
+func (s *Suite) encodeToCurve(Y ecgeneric.Point, alpha []byte) (ecgeneric.Point, error) {
+	pk := s.point(Y)
+	if s.h2c != nil {
+		return s.h2c.EncodeToCurve(append(pk, alpha...), s.dst)
+	}
+	// Try-and-increment, RFC 9381 Section 5.4.1.1: the hash, truncated to
+	// the field length, is the x coordinate of a point with even y, which
+	// is then multiplied by the cofactor.
+	fieldLen := s.ptLen() - 1
+	for ctr := 0; ctr < 256; ctr++ {
+		h := s.Hash()
+		h.Write([]byte{s.ID, encodeToCurveFront})
+		h.Write(pk)
+		h.Write(alpha)
+		h.Write([]byte{byte(ctr), back})
+		sum := h.Sum(nil)
+		if len(sum) < fieldLen {
+			break
+		}
+		enc := append([]byte{2}, sum[:fieldLen]...)
+		if x, y := ecgeneric.UnmarshalCompressed(s.Curve, enc); x != nil {
+			if h := s.Curve.Cofactor(); h.Cmp(big.NewInt(1)) != 0 {
+				x, y = s.Curve.ScalarMultJ(x, y, h.Bytes())
+			}
+			return ecgeneric.Point{X: x, Y: y}, nil
+		}
+	}
+	return ecgeneric.Point{}, errHashPoint
+}
+
+// nonce derives k as in RFC 9381 Section 5.4.2.1: RFC 6979 with the encoded
+// point H as the message.
+func (s *Suite) nonce(d *big.Int, hString []byte) *big.Int {
+	h := s.Hash()
+	h.Write(hString)
+	return ecgeneric.NonceRFC6979(d, h.Sum(nil), s.Curve.N, s.Hash)
+}
+
+// challenge hashes the points to a cLen-byte challenge, RFC 9381 Section
+// 5.4.3.
+func (s *Suite) challenge(points ...ecgeneric.Point) *big.Int {
+	h := s.Hash()
+	h.Write([]byte{s.ID, challengeFront})
+	for _, p := range points {
+		h.Write(s.point(p))
+	}
+	h.Write([]byte{back})
+	return new(big.Int).SetBytes(h.Sum(nil)[:s.cLen()])
+}
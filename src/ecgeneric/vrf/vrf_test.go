@@ -0,0 +1,209 @@
+package vrf_test
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/gost"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/nist"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/vrf"
+	"github.com/stretchr/testify/require"
+)
+
+func mustHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
+
+func privateKey(curve *ecgeneric.CurveParams, sk string) *ecgeneric.PrivateKey {
+	d := ecgeneric.BigFromHex(sk)
+	x, y := curve.ScalarBaseMultJ(d.Bytes())
+	return &ecgeneric.PrivateKey{PublicKey: ecgeneric.PublicKey{Curve: curve, X: x, Y: y}, D: d}
+}
+
+type vector struct {
+	sk, pk, alpha, pi, beta string
+}
+
+// Examples 10 to 12 of RFC 9381, Appendix B.1.
+var p256TAIVectors = []vector{
+	{
+		"c9afa9d845ba75166b5c215767b1d6934e50c3db36e89b127b8a622b120f6721",
+		"0360fed4ba255a9d31c961eb74c6356d68c049b8923b61fa6ce669622e60f29fb6",
+		"73616d706c65",
+		"035b5c726e8c0e2c488a107c600578ee75cb702343c153cb1eb8dec77f4b5071b4a53f0a46f018bc2c56e58d383f2305e0975972c26feea0eb122fe7893c15af376b33edf7de17c6ea056d4d82de6bc02f",
+		"a3ad7b0ef73d8fc6655053ea22f9bede8c743f08bbed3d38821f0e16474b505e",
+	},
+	{
+		"c9afa9d845ba75166b5c215767b1d6934e50c3db36e89b127b8a622b120f6721",
+		"0360fed4ba255a9d31c961eb74c6356d68c049b8923b61fa6ce669622e60f29fb6",
+		"74657374",
+		"034dac60aba508ba0c01aa9be80377ebd7562c4a52d74722e0abae7dc3080ddb56c19e067b15a8a8174905b13617804534214f935b94c2287f797e393eb0816969d864f37625b443f30f1a5a33f2b3c854",
+		"a284f94ceec2ff4b3794629da7cbafa49121972671b466cab4ce170aa365f26d",
+	},
+	{
+		"2ca1411a41b17b24cc8c3b089cfd033f1920202a6c0de8abb97df1498d50d2c8",
+		"03596375e6ce57e0f20294fc46bdfcfd19a39f8161b58695b3ec5b3d16427c274d",
+		"4578616d706c65207573696e67204543445341206b65792066726f6d20417070656e646978204c2e342e32206f6620414e53492e58392d36322d32303035",
+		"03d03398bf53aa23831d7d1b2937e005fb0062cbefa06796579f2a1fc7e7b8c667d091c00b0f5c3619d10ecea44363b5a599cadc5b2957e223fec62e81f7b4825fc799a771a3d7334b9186bdbee87316b1",
+		"90871e06da5caa39a3c61578ebb844de8635e27ac0b13e829997d0d95dd98c19",
+	},
+}
+
+// Examples 13 to 15 of RFC 9381, Appendix B.2.
+var p256SSWUVectors = []vector{
+	{
+		"c9afa9d845ba75166b5c215767b1d6934e50c3db36e89b127b8a622b120f6721",
+		"0360fed4ba255a9d31c961eb74c6356d68c049b8923b61fa6ce669622e60f29fb6",
+		"73616d706c65",
+		"0331d984ca8fece9cbb9a144c0d53df3c4c7a33080c1e02ddb1a96a365394c7888782fffde7b842c38c20c08de6ec6c2e7027a97000f2c9fa4425d5c03e639fb48fde58114d755985498d7eb234cf4aed9",
+		"21e66dc9747430f17ed9efeda054cf4a264b097b9e8956a1787526ed00dc664b",
+	},
+	{
+		"c9afa9d845ba75166b5c215767b1d6934e50c3db36e89b127b8a622b120f6721",
+		"0360fed4ba255a9d31c961eb74c6356d68c049b8923b61fa6ce669622e60f29fb6",
+		"74657374",
+		"03f814c0455d32dbc75ad3aea08c7e2db31748e12802db23640203aebf1fa8db2743aad348a3006dc1caad7da28687320740bf7dd78fe13c298867321ce3b36b79ec3093b7083ac5e4daf3465f9f43c627",
+		"8e7185d2b420e4f4681f44ce313a26d05613323837da09a69f00491a83ad25dd",
+	},
+	{
+		"2ca1411a41b17b24cc8c3b089cfd033f1920202a6c0de8abb97df1498d50d2c8",
+		"03596375e6ce57e0f20294fc46bdfcfd19a39f8161b58695b3ec5b3d16427c274d",
+		"4578616d706c65207573696e67204543445341206b65792066726f6d20417070656e646978204c2e342e32206f6620414e53492e58392d36322d32303035",
+		"039f8d9cdc162c89be2871cbcb1435144739431db7fab437ab7bc4e2651a9e99d5488405a11a6c7fc8defddd9e1573a563b7333aab4effe73ae9803274174c659269fd39b53e133dcd9e0d24f01288de9a",
+		"4fbadf33b42a5f42f23a6f89952d2e634a6e3810f15878b46ef1bb85a04fe95a",
+	},
+}
+
+func testVectors(t *testing.T, suite *vrf.Suite, vectors []vector) {
+	for _, v := range vectors {
+		priv := privateKey(&nist.P256, v.sk)
+		require.Equal(t, v.pk, hex.EncodeToString(ecgeneric.MarshalCompressed(&nist.P256, priv.X, priv.Y)))
+		alpha := mustHex(t, v.alpha)
+		pi, err := suite.Prove(priv, alpha)
+		require.NoError(t, err)
+		require.Equal(t, v.pi, hex.EncodeToString(pi))
+		beta, err := suite.Verify(&priv.PublicKey, alpha, pi)
+		require.NoError(t, err)
+		require.Equal(t, v.beta, hex.EncodeToString(beta))
+	}
+}
+
+func TestP256SHA256TAI(t *testing.T) {
+	testVectors(t, vrf.P256SHA256TAI, p256TAIVectors)
+}
+
+func TestP256SHA256SSWU(t *testing.T) {
+	testVectors(t, vrf.P256SHA256SSWU, p256SSWUVectors)
+}
+
+func suites(t *testing.T) []*vrf.Suite {
+	out := []*vrf.Suite{vrf.P256SHA256TAI, vrf.P256SHA256SSWU, vrf.Secp256k1SHA256TAI, vrf.Secp256k1SHA256SSWU}
+	for _, curve := range []*ecgeneric.CurveParams{
+		&gost.GostEx1,
+		&gost.Gost34102001paramSetA,
+		&gost.Gost341012512paramSetA,
+		&gost.Gost341012512paramSetB,
+	} {
+		for _, sswu := range []bool{false, true} {
+			suite, err := vrf.GOSTSuite(curve, sswu)
+			require.NoError(t, err)
+			out = append(out, suite)
+		}
+	}
+	return out
+}
+
+func TestProveVerify(t *testing.T) {
+	alpha := []byte("round 42")
+	for _, suite := range suites(t) {
+		name := suite.Curve.Name
+		priv, err := ecgeneric.GenerateKey(suite.Curve, rand.Reader)
+		require.NoError(t, err)
+		pi, err := suite.Prove(priv, alpha)
+		require.NoError(t, err, name)
+		require.Len(t, pi, suite.ProofLen(), name)
+		beta, err := suite.Verify(&priv.PublicKey, alpha, pi)
+		require.NoError(t, err, name)
+		require.Len(t, beta, suite.Hash().Size(), name)
+
+		// Proofs are deterministic and ProofToHash agrees with Verify.
+		again, err := suite.Prove(priv, alpha)
+		require.NoError(t, err)
+		require.Equal(t, pi, again, name)
+		hashed, err := suite.ProofToHash(pi)
+		require.NoError(t, err)
+		require.Equal(t, beta, hashed, name)
+
+		_, err = suite.Verify(&priv.PublicKey, []byte("round 43"), pi)
+		require.ErrorIs(t, err, vrf.ErrInvalidProof, name)
+		other, err := ecgeneric.GenerateKey(suite.Curve, rand.Reader)
+		require.NoError(t, err)
+		_, err = suite.Verify(&other.PublicKey, alpha, pi)
+		require.ErrorIs(t, err, vrf.ErrInvalidProof, name)
+		for _, i := range []int{1, len(pi) - 20, len(pi) - 1} {
+			bad := append([]byte{}, pi...)
+			bad[i] ^= 1
+			_, err = suite.Verify(&priv.PublicKey, alpha, bad)
+			require.Error(t, err, name)
+		}
+		_, err = suite.Verify(&priv.PublicKey, alpha, pi[1:])
+		require.ErrorIs(t, err, vrf.ErrInvalidProof, name)
+	}
+}
+
+func TestSuiteSeparation(t *testing.T) {
+	priv := privateKey(&nist.P256, p256TAIVectors[0].sk)
+	alpha := []byte("sample")
+	tai, err := vrf.P256SHA256TAI.Prove(priv, alpha)
+	require.NoError(t, err)
+	sswu, err := vrf.P256SHA256SSWU.Prove(priv, alpha)
+	require.NoError(t, err)
+	require.NotEqual(t, tai, sswu)
+	_, err = vrf.P256SHA256SSWU.Verify(&priv.PublicKey, alpha, tai)
+	require.ErrorIs(t, err, vrf.ErrInvalidProof)
+
+	// Keys must be on the suite's curve.
+	k1, err := ecgeneric.GenerateKey(&nist.Secp256k1, rand.Reader)
+	require.NoError(t, err)
+	_, err = vrf.P256SHA256TAI.Prove(k1, alpha)
+	require.Error(t, err)
+	_, err = vrf.P256SHA256TAI.Verify(&k1.PublicKey, alpha, tai)
+	require.Error(t, err)
+}
+
+// tc26A is id-tc26-gost-3410-2012-256-paramSetA in short Weierstrass form,
+// whose group order is 4·N.
+var tc26A = &ecgeneric.CurveParams{
+	P:       ecgeneric.BigFromHex("FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFD97"),
+	N:       ecgeneric.BigFromHex("400000000000000000000000000000000FD8CDDFC87B6635C115AF556C360C67"),
+	A:       ecgeneric.BigFromHex("C2173F1513981673AF4892C23035A27CE25E2013BF95AA33B22C656F277E7335"),
+	B:       ecgeneric.BigFromHex("295F9BAE7428ED9CCC20E7C359A9D41A22FCCD9108E17BF7BA9337A6F8AE9513"),
+	Gx:      ecgeneric.BigFromHex("91E38443A5E82C0D880923425712B2BB658B9196932E02C78B2582FE742DAA28"),
+	Gy:      ecgeneric.BigFromHex("32879423AB1A0375895786C4BB46E9565FDE0B5344766740AF268ADB32322E5C"),
+	BitSize: 256,
+	Name:    "tc26-gost-3410-2012-256-paramSetA",
+}
+
+// TestCofactor checks that try-and-increment clears the cofactor, so that
+// Gamma = x·H lies in the subgroup of order N.
+func TestCofactor(t *testing.T) {
+	suite, err := vrf.NewSuite(0xf0, tc26A, sha256.New, "")
+	require.NoError(t, err)
+	priv, err := ecgeneric.GenerateKey(tc26A, rand.Reader)
+	require.NoError(t, err)
+	for _, alpha := range []string{"", "a", "b", "c", "d", "e", "f", "g"} {
+		pi, err := suite.Prove(priv, []byte(alpha))
+		require.NoError(t, err)
+		_, err = suite.Verify(&priv.PublicKey, []byte(alpha), pi)
+		require.NoError(t, err)
+		x, y := ecgeneric.UnmarshalCompressed(tc26A, pi[:33])
+		require.NotNil(t, x)
+		x, y = tc26A.ScalarMultJ(x, y, tc26A.N.Bytes())
+		require.Zero(t, x.Sign()+y.Sign(), alpha)
+	}
+}
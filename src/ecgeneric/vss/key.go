@@ -0,0 +1,206 @@
+package vss
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+)
+
+// keyShareVersion is the first byte of a serialized KeyShare.
+const keyShareVersion = 1
+
+// MaxShares is the largest number of shares SplitKey produces; share indices
+// are serialized as a single byte.
+const MaxShares = 255
+
+// KeyShare is one holder's share of a private key, together with the curve
+// and the dealer's Feldman commitments needed to verify and combine it. The
+// first commitment is the public key.
+type KeyShare struct {
+	Curve       *ecgeneric.CurveParams
+	Share       Share
+	Commitments Commitments
+}
+
+// SplitKey splits priv.D into n shares, any threshold of which recover it.
+func SplitKey(rand io.Reader, priv *ecgeneric.PrivateKey, threshold, n int) ([]*KeyShare, error) {
+	curve := priv.Curve.Params()
+	if threshold < 1 || threshold > n {
+		return nil, fmt.Errorf("vss: threshold %d out of range for %d shares", threshold, n)
+	}
+	if n > MaxShares {
+		return nil, fmt.Errorf("vss: at most %d shares", MaxShares)
+	}
+	if priv.D.Sign() <= 0 || priv.D.Cmp(curve.N) >= 0 {
+		return nil, errors.New("vss: invalid private key")
+	}
+	p, err := NewPolynomial(rand, curve, priv.D, threshold)
+	if err != nil {
+		return nil, err
+	}
+	c := p.Commit()
+	if c[0].X.Cmp(priv.X) != 0 || c[0].Y.Cmp(priv.Y) != 0 {
+		return nil, errors.New("vss: private key does not match its public key")
+	}
+	shares := make([]*KeyShare, n)
+	for i := range shares {
+		s, err := p.Share(i + 1)
+		if err != nil {
+			return nil, err
+		}
+		shares[i] = &KeyShare{Curve: curve, Share: *s, Commitments: c}
+	}
+	return shares, nil
+}
+
+// Threshold returns the number of shares needed to recover the key.
+func (ks *KeyShare) Threshold() int {
+	return len(ks.Commitments)
+}
+
+// PublicKey returns the public key the share belongs to.
+func (ks *KeyShare) PublicKey() *ecgeneric.PublicKey {
+	c := ks.Commitments[0]
+	return &ecgeneric.PublicKey{Curve: ks.Curve, X: c.X, Y: c.Y}
+}
+
+// Verify checks the share against the commitments.
+func (ks *KeyShare) Verify() error {
+	if err := ks.Commitments.Validate(ks.Curve); err != nil {
+		return err
+	}
+	if ks.Share.Value == nil || ks.Share.Value.Sign() < 0 || ks.Share.Value.Cmp(ks.Curve.N) >= 0 {
+		return ErrInvalidCommit
+	}
+	return ks.Commitments.Verify(ks.Curve, &ks.Share)
+}
+
+// CombineKey verifies the shares and recovers the private key from them. All
+// shares must come from the same split and there must be at least threshold
+// of them.
+func CombineKey(shares []*KeyShare) (*ecgeneric.PrivateKey, error) {
+	if len(shares) == 0 {
+		return nil, ErrTooFewShares
+	}
+	first := shares[0]
+	ref := first.Commitments.marshal(first.Curve)
+	if len(shares) < first.Threshold() {
+		return nil, ErrTooFewShares
+	}
+	plain := make([]*Share, len(shares))
+	for i, ks := range shares {
+		if ks.Curve != first.Curve && ks.Curve.Name != first.Curve.Name {
+			return nil, errors.New("vss: shares are for different curves")
+		}
+		if !bytes.Equal(ks.Commitments.marshal(first.Curve), ref) {
+			return nil, errors.New("vss: shares come from different splits")
+		}
+		if err := ks.Verify(); err != nil {
+			return nil, fmt.Errorf("vss: share %d: %w", ks.Share.Index, err)
+		}
+		plain[i] = &ks.Share
+	}
+	d, err := Combine(first.Curve, plain)
+	if err != nil {
+		return nil, err
+	}
+	pub := first.PublicKey()
+	x, y := first.Curve.ScalarBaseMultGLV(d.Bytes())
+	if x.Cmp(pub.X) != 0 || y.Cmp(pub.Y) != 0 {
+		return nil, errors.New("vss: recovered key does not match the public key")
+	}
+	return &ecgeneric.PrivateKey{PublicKey: *pub, D: d}, nil
+}
+
+func (c Commitments) marshal(curve *ecgeneric.CurveParams) []byte {
+	var b []byte
+	for _, p := range c {
+		b = append(b, ecgeneric.MarshalCompressed(curve, p.X, p.Y)...)
+	}
+	return b
+}
+
+// MarshalBinary encodes the share as a version byte, the length-prefixed
+// curve name, the threshold and index bytes, the share value and the
+// compressed commitments.
+func (ks *KeyShare) MarshalBinary() ([]byte, error) {
+	name := ks.Curve.Name
+	if name == "" || len(name) > 255 {
+		return nil, errors.New("vss: curve needs a registered name")
+	}
+	if ks.Threshold() < 1 || ks.Threshold() > MaxShares || ks.Share.Index < 1 || ks.Share.Index > MaxShares {
+		return nil, errors.New("vss: share does not fit the encoding")
+	}
+	b := []byte{keyShareVersion, byte(len(name))}
+	b = append(b, name...)
+	b = append(b, byte(ks.Threshold()), byte(ks.Share.Index))
+	v := make([]byte, (ks.Curve.N.BitLen()+7)/8)
+	ks.Share.Value.FillBytes(v)
+	b = append(b, v...)
+	return append(b, ks.Commitments.marshal(ks.Curve)...), nil
+}
+
+// UnmarshalBinary decodes a share encoded by MarshalBinary. The curve must be
+// registered with ecgeneric.RegisterCurve.
+func (ks *KeyShare) UnmarshalBinary(data []byte) error {
+	errFormat := errors.New("vss: malformed key share")
+	if len(data) < 2 || data[0] != keyShareVersion || len(data) < 2+int(data[1])+2 {
+		return errFormat
+	}
+	name := string(data[2 : 2+data[1]])
+	data = data[2+len(name):]
+	curve, ok := ecgeneric.CurveByName(name)
+	if !ok {
+		return fmt.Errorf("vss: unknown curve %q", name)
+	}
+	threshold, index := int(data[0]), int(data[1])
+	data = data[2:]
+	vLen := (curve.N.BitLen() + 7) / 8
+	pLen := 1 + (curve.P.BitLen()+7)/8
+	if threshold < 1 || index < 1 || len(data) != vLen+threshold*pLen {
+		return errFormat
+	}
+	out := KeyShare{
+		Curve:       curve,
+		Share:       Share{Index: index, Value: new(big.Int).SetBytes(data[:vLen])},
+		Commitments: make(Commitments, threshold),
+	}
+	data = data[vLen:]
+	for i := range out.Commitments {
+		x, y := ecgeneric.UnmarshalCompressed(curve, data[:pLen])
+		if x == nil {
+			return errFormat
+		}
+		out.Commitments[i] = ecgeneric.Point{X: x, Y: y}
+		data = data[pLen:]
+	}
+	*ks = out
+	return nil
+}
+
+// String returns the hex encoding of MarshalBinary.
+func (ks *KeyShare) String() string {
+	b, err := ks.MarshalBinary()
+	if err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// ParseKeyShare decodes a share in the format of String.
+func ParseKeyShare(s string) (*KeyShare, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("vss: malformed key share: %w", err)
+	}
+	ks := new(KeyShare)
+	if err := ks.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return ks, nil
+}
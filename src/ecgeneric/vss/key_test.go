@@ -0,0 +1,84 @@
+package vss_test
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/gost"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/vss"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitCombineKey(t *testing.T) {
+	priv, err := ecgeneric.GenerateKey(&gost.Gost341012512paramSetA, rand.Reader)
+	require.NoError(t, err)
+	shares, err := vss.SplitKey(rand.Reader, priv, 3, 5)
+	require.NoError(t, err)
+	require.Len(t, shares, 5)
+
+	// Round trip through the text format.
+	parsed := make([]*vss.KeyShare, len(shares))
+	for i, ks := range shares {
+		require.NoError(t, ks.Verify())
+		require.Equal(t, 3, ks.Threshold())
+		require.Equal(t, priv.X, ks.PublicKey().X)
+		parsed[i], err = vss.ParseKeyShare(ks.String())
+		require.NoError(t, err)
+		require.Equal(t, ks.Share, parsed[i].Share)
+		require.Equal(t, ks.Commitments, parsed[i].Commitments)
+		require.Same(t, ks.Curve, parsed[i].Curve)
+	}
+
+	for _, set := range [][]int{{0, 1, 2}, {4, 2, 0}, {1, 3, 4}, {0, 1, 2, 3, 4}} {
+		var in []*vss.KeyShare
+		for _, i := range set {
+			in = append(in, parsed[i])
+		}
+		got, err := vss.CombineKey(in)
+		require.NoError(t, err)
+		require.Equal(t, priv.D, got.D)
+		require.Equal(t, priv.Y, got.Y)
+	}
+
+	_, err = vss.CombineKey(parsed[:2])
+	require.ErrorIs(t, err, vss.ErrTooFewShares)
+
+	bad := *parsed[1]
+	bad.Share.Value = new(big.Int).Add(bad.Share.Value, big.NewInt(1))
+	require.ErrorIs(t, bad.Verify(), vss.ErrInvalidCommit)
+	_, err = vss.CombineKey([]*vss.KeyShare{parsed[0], &bad, parsed[2]})
+	require.ErrorIs(t, err, vss.ErrInvalidCommit)
+
+	other, err := vss.SplitKey(rand.Reader, priv, 3, 5)
+	require.NoError(t, err)
+	_, err = vss.CombineKey([]*vss.KeyShare{parsed[0], parsed[1], other[2]})
+	require.Error(t, err)
+
+	_, err = vss.ParseKeyShare(shares[0].String()[:40])
+	require.Error(t, err)
+	_, err = vss.SplitKey(rand.Reader, priv, 4, 3)
+	require.Error(t, err)
+}
+
+// wideCurve is GostEx2 under a name of its own. Its prime is 511 bits long
+// but its BitSize is 256, so its points must be encoded at the width of P.
+// The registry leaves example curves out, so the test registers the copy.
+var wideCurve = func() *ecgeneric.CurveParams {
+	c := gost.GostEx2
+	c.Name = "vss-test-GostEx2"
+	ecgeneric.RegisterCurve(&c)
+	return &c
+}()
+
+func TestKeyShareFieldWidth(t *testing.T) {
+	priv, err := ecgeneric.GenerateKey(wideCurve, rand.Reader)
+	require.NoError(t, err)
+	shares, err := vss.SplitKey(rand.Reader, priv, 2, 3)
+	require.NoError(t, err)
+	parsed, err := vss.ParseKeyShare(shares[0].String())
+	require.NoError(t, err)
+	require.Equal(t, shares[0].Commitments, parsed.Commitments)
+	require.NoError(t, parsed.Verify())
+}
@@ -0,0 +1,241 @@
+// Package vss implements Shamir secret sharing over the scalar field of an
+// elliptic curve, with Feldman commitments that let every holder check its
+// share against the dealer's public polynomial.
+//
+// Shares are evaluations f(i) of a random polynomial f of degree t-1 with
+// f(0) = secret at non-zero indices i; any t of them recover the secret with
+// Lagrange interpolation.
+package vss
+
+import (
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+)
+
+var (
+	ErrThreshold     = errors.New("vss: threshold must be at least 1")
+	ErrIndex         = errors.New("vss: share index must be positive")
+	ErrDuplicate     = errors.New("vss: duplicate share index")
+	ErrTooFewShares  = errors.New("vss: not enough shares")
+	ErrInvalidCommit = errors.New("vss: share does not match the commitments")
+)
+
+// Share is the evaluation of a sharing polynomial at Index.
+type Share struct {
+	Index int
+	Value *big.Int
+}
+
+// Polynomial is a secret sharing polynomial with coefficients modulo the
+// order of the curve's base point.
+type Polynomial struct {
+	curve  *ecgeneric.CurveParams
+	coeffs []*big.Int
+}
+
+// RandomScalar returns a uniformly random scalar in [1, N-1].
+func RandomScalar(rand io.Reader, curve *ecgeneric.CurveParams) (*big.Int, error) {
+	max := new(big.Int).Sub(curve.N, big.NewInt(1))
+	k, err := randInt(rand, max)
+	if err != nil {
+		return nil, err
+	}
+	return k.Add(k, big.NewInt(1)), nil
+}
+
+// randInt returns a uniform value in [0, max) by rejection sampling.
+func randInt(rand io.Reader, max *big.Int) (*big.Int, error) {
+	bitLen := max.BitLen()
+	buf := make([]byte, (bitLen+7)/8)
+	k := new(big.Int)
+	for {
+		if _, err := io.ReadFull(rand, buf); err != nil {
+			return nil, err
+		}
+		if excess := len(buf)*8 - bitLen; excess > 0 {
+			buf[0] &= byte(0xff >> excess)
+		}
+		if k.SetBytes(buf).Cmp(max) < 0 {
+			return k, nil
+		}
+	}
+}
+
+// NewPolynomial returns a random polynomial of degree threshold-1 whose
+// constant term is secret. A nil secret is replaced by a random one.
+func NewPolynomial(rand io.Reader, curve *ecgeneric.CurveParams, secret *big.Int, threshold int) (*Polynomial, error) {
+	if threshold < 1 {
+		return nil, ErrThreshold
+	}
+	p := &Polynomial{curve: curve, coeffs: make([]*big.Int, threshold)}
+	for i := range p.coeffs {
+		if i == 0 && secret != nil {
+			p.coeffs[0] = new(big.Int).Mod(secret, curve.N)
+			continue
+		}
+		c, err := RandomScalar(rand, curve)
+		if err != nil {
+			return nil, err
+		}
+		p.coeffs[i] = c
+	}
+	return p, nil
+}
+
+// Secret returns f(0).
+func (p *Polynomial) Secret() *big.Int {
+	return new(big.Int).Set(p.coeffs[0])
+}
+
+// Threshold returns the number of shares needed to recover the secret.
+func (p *Polynomial) Threshold() int {
+	return len(p.coeffs)
+}
+
+// Share returns f(index).
+func (p *Polynomial) Share(index int) (*Share, error) {
+	if index < 1 {
+		return nil, ErrIndex
+	}
+	x := big.NewInt(int64(index))
+	v := new(big.Int)
+	for i := len(p.coeffs) - 1; i >= 0; i-- {
+		v.Mul(v, x)
+		v.Add(v, p.coeffs[i])
+		v.Mod(v, p.curve.N)
+	}
+	return &Share{Index: index, Value: v}, nil
+}
+
+// Commit returns the Feldman commitments aᵢ·G to the coefficients.
+func (p *Polynomial) Commit() Commitments {
+	c := make(Commitments, len(p.coeffs))
+	for i, a := range p.coeffs {
+		x, y := p.curve.ScalarBaseMultGLV(a.Bytes())
+		c[i] = ecgeneric.Point{X: x, Y: y}
+	}
+	return c
+}
+
+// Commitments are the Feldman commitments to a sharing polynomial; the first
+// one commits to the secret.
+type Commitments []ecgeneric.Point
+
+// Evaluate returns f(index)·G computed from the commitments alone.
+func (c Commitments) Evaluate(curve *ecgeneric.CurveParams, index int) (x, y *big.Int) {
+	powers := make([]*big.Int, len(c))
+	idx := big.NewInt(int64(index))
+	pow := big.NewInt(1)
+	for i := range c {
+		powers[i] = new(big.Int).Set(pow)
+		pow.Mul(pow, idx)
+		pow.Mod(pow, curve.N)
+	}
+	return curve.MultiScalarMult(c, powers)
+}
+
+// Verify checks that share is consistent with the commitments.
+func (c Commitments) Verify(curve *ecgeneric.CurveParams, share *Share) error {
+	if share.Index < 1 {
+		return ErrIndex
+	}
+	x, y := c.Evaluate(curve, share.Index)
+	sx, sy := curve.ScalarBaseMultGLV(new(big.Int).Mod(share.Value, curve.N).Bytes())
+	if x.Cmp(sx) != 0 || y.Cmp(sy) != 0 {
+		return ErrInvalidCommit
+	}
+	return nil
+}
+
+// Validate checks that every commitment is a point on curve.
+func (c Commitments) Validate(curve *ecgeneric.CurveParams) error {
+	if len(c) == 0 {
+		return ErrThreshold
+	}
+	for _, p := range c {
+		if p.X == nil || p.Y == nil || !curve.IsOnCurve(p.X, p.Y) {
+			return errors.New("vss: commitment is not on the curve")
+		}
+	}
+	return nil
+}
+
+// Sum returns the commitments to the sum of the committed polynomials, all of
+// which must have the same length.
+func Sum(curve *ecgeneric.CurveParams, cs ...Commitments) (Commitments, error) {
+	if len(cs) == 0 {
+		return nil, ErrTooFewShares
+	}
+	out := make(Commitments, len(cs[0]))
+	for i := range out {
+		out[i] = ecgeneric.Point{X: new(big.Int), Y: new(big.Int)}
+	}
+	for _, c := range cs {
+		if len(c) != len(out) {
+			return nil, errors.New("vss: commitments of different degrees")
+		}
+		for i := range c {
+			x, y := curve.AddJ(out[i].X, out[i].Y, c[i].X, c[i].Y)
+			out[i] = ecgeneric.Point{X: x, Y: y}
+		}
+	}
+	return out, nil
+}
+
+// Lagrange returns the coefficient λᵢ = Π_{j≠i} j/(j-i) mod n that weights
+// the share with index i when interpolating f(0) from indices.
+func Lagrange(n *big.Int, indices []int, i int) (*big.Int, error) {
+	num, den := big.NewInt(1), big.NewInt(1)
+	found := false
+	seen := make(map[int]bool, len(indices))
+	for _, j := range indices {
+		if j < 1 {
+			return nil, ErrIndex
+		}
+		if seen[j] {
+			return nil, ErrDuplicate
+		}
+		seen[j] = true
+		if j == i {
+			found = true
+			continue
+		}
+		num.Mul(num, big.NewInt(int64(j)))
+		num.Mod(num, n)
+		den.Mul(den, big.NewInt(int64(j-i)))
+		den.Mod(den, n)
+	}
+	if !found {
+		return nil, errors.New("vss: index not in the interpolation set")
+	}
+	inv := new(big.Int).ModInverse(den, n)
+	if inv == nil {
+		return nil, errors.New("vss: indices are not invertible modulo the order")
+	}
+	return num.Mul(num, inv).Mod(num, n), nil
+}
+
+// Combine recovers f(0) from at least threshold shares. It cannot tell
+// whether enough shares were given; with fewer the result is meaningless.
+func Combine(curve *ecgeneric.CurveParams, shares []*Share) (*big.Int, error) {
+	if len(shares) == 0 {
+		return nil, ErrTooFewShares
+	}
+	indices := make([]int, len(shares))
+	for i, s := range shares {
+		indices[i] = s.Index
+	}
+	secret := new(big.Int)
+	for _, s := range shares {
+		l, err := Lagrange(curve.N, indices, s.Index)
+		if err != nil {
+			return nil, err
+		}
+		secret.Add(secret, l.Mul(l, s.Value))
+		secret.Mod(secret, curve.N)
+	}
+	return secret, nil
+}
@@ -0,0 +1,69 @@
+package vss_test
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/gost"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/vss"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShareCombine(t *testing.T) {
+	curve := &gost.Gost34102001paramSetA
+	secret := big.NewInt(123456789)
+	p, err := vss.NewPolynomial(rand.Reader, curve, secret, 3)
+	require.NoError(t, err)
+	require.Equal(t, 3, p.Threshold())
+	c := p.Commit()
+	require.NoError(t, c.Validate(curve))
+
+	shares := make([]*vss.Share, 5)
+	for i := range shares {
+		shares[i], err = p.Share(i + 1)
+		require.NoError(t, err)
+		require.NoError(t, c.Verify(curve, shares[i]))
+	}
+
+	got, err := vss.Combine(curve, []*vss.Share{shares[4], shares[0], shares[2]})
+	require.NoError(t, err)
+	require.Equal(t, secret, got)
+	got, err = vss.Combine(curve, shares)
+	require.NoError(t, err)
+	require.Equal(t, secret, got)
+	got, err = vss.Combine(curve, shares[:2])
+	require.NoError(t, err)
+	require.NotEqual(t, secret, got)
+
+	bad := &vss.Share{Index: 2, Value: new(big.Int).Add(shares[1].Value, big.NewInt(1))}
+	require.ErrorIs(t, c.Verify(curve, bad), vss.ErrInvalidCommit)
+	_, err = vss.Combine(curve, []*vss.Share{shares[0], shares[0]})
+	require.ErrorIs(t, err, vss.ErrDuplicate)
+	_, err = p.Share(0)
+	require.ErrorIs(t, err, vss.ErrIndex)
+}
+
+func TestSum(t *testing.T) {
+	curve := &gost.Gost34102001paramSetA
+	a, err := vss.NewPolynomial(rand.Reader, curve, nil, 2)
+	require.NoError(t, err)
+	b, err := vss.NewPolynomial(rand.Reader, curve, nil, 2)
+	require.NoError(t, err)
+	sum, err := vss.Sum(curve, a.Commit(), b.Commit())
+	require.NoError(t, err)
+
+	for i := 1; i <= 3; i++ {
+		sa, err := a.Share(i)
+		require.NoError(t, err)
+		sb, err := b.Share(i)
+		require.NoError(t, err)
+		v := new(big.Int).Add(sa.Value, sb.Value)
+		require.NoError(t, sum.Verify(curve, &vss.Share{Index: i, Value: v.Mod(v, curve.N)}))
+	}
+
+	c, err := vss.NewPolynomial(rand.Reader, curve, nil, 3)
+	require.NoError(t, err)
+	_, err = vss.Sum(curve, a.Commit(), c.Commit())
+	require.Error(t, err)
+}
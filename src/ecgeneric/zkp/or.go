@@ -0,0 +1,121 @@
+package zkp
+
+import (
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+)
+
+// ORProof proves knowledge of the discrete logarithm to a base G of one of
+// several points X_j. Each branch has its own challenge C[j] and response
+// S[j], with the commitment S[j]·G - C[j]·X_j, and the challenges sum to the
+// Fiat–Shamir challenge. The prover simulates every branch but the one it
+// knows, so the proof does not show which that is.
+type ORProof struct {
+	Curve *ecgeneric.CurveParams
+	C, S  []*big.Int
+}
+
+// ProveOR proves knowledge of x with Xs[i] = x·G.
+func ProveOR(rand io.Reader, t *Transcript, curve *ecgeneric.CurveParams, G ecgeneric.Point, Xs []ecgeneric.Point, i int, x *big.Int) (*ORProof, error) {
+	if i < 0 || i >= len(Xs) {
+		return nil, errors.New("zkp: OR-proof branch out of range")
+	}
+	if !validPoint(curve, G) {
+		return nil, errPoint
+	}
+	for _, X := range Xs {
+		if !validPoint(curve, X) {
+			return nil, errPoint
+		}
+	}
+	if !validScalar(curve, x) || x.Sign() == 0 {
+		return nil, errScalar
+	}
+	n := len(Xs)
+	p := &ORProof{Curve: curve, C: make([]*big.Int, n), S: make([]*big.Int, n)}
+	Ts := make([]ecgeneric.Point, n)
+	sum := new(big.Int)
+	for j := range Xs {
+		if j == i {
+			continue
+		}
+		var err error
+		if p.C[j], err = randomScalar(rand, curve); err != nil {
+			return nil, err
+		}
+		if p.S[j], err = randomScalar(rand, curve); err != nil {
+			return nil, err
+		}
+		Ts[j] = combine(curve, G, p.S[j], Xs[j], neg(curve, p.C[j]))
+		sum.Add(sum, p.C[j])
+	}
+	w, err := randomScalar(rand, curve)
+	if err != nil {
+		return nil, err
+	}
+	Ts[i] = mul(curve, G, w)
+
+	c := orChallenge(orNew(t), curve, G, Xs, Ts)
+	ci := c.Sub(c, sum)
+	p.C[i] = ci.Mod(ci, curve.N)
+	si := new(big.Int).Mul(p.C[i], x)
+	si.Add(si, w)
+	p.S[i] = si.Mod(si, curve.N)
+	return p, nil
+}
+
+// VerifyOR reports whether p proves knowledge of the discrete logarithm to
+// the base G of one of Xs.
+func VerifyOR(t *Transcript, curve *ecgeneric.CurveParams, G ecgeneric.Point, Xs []ecgeneric.Point, p *ORProof) bool {
+	if p == nil || !sameCurve(p.Curve, curve) || len(Xs) == 0 || len(p.C) != len(Xs) || len(p.S) != len(Xs) {
+		return false
+	}
+	if !validPoint(curve, G) {
+		return false
+	}
+	Ts := make([]ecgeneric.Point, len(Xs))
+	sum := new(big.Int)
+	for j, X := range Xs {
+		if !validPoint(curve, X) || !validScalar(curve, p.C[j]) || !validScalar(curve, p.S[j]) {
+			return false
+		}
+		Ts[j] = combine(curve, G, p.S[j], X, neg(curve, p.C[j]))
+		sum.Add(sum, p.C[j])
+	}
+	c := orChallenge(orNew(t), curve, G, Xs, Ts)
+	return sum.Mod(sum, curve.N).Cmp(c) == 0
+}
+
+func orChallenge(t *Transcript, curve *ecgeneric.CurveParams, G ecgeneric.Point, Xs, Ts []ecgeneric.Point) *big.Int {
+	t.AppendMessage("zkp/proof", []byte("or"))
+	t.AppendMessage("curve", []byte(curve.Name))
+	t.AppendPoint(curve, "G", G)
+	for j := range Xs {
+		t.AppendPoint(curve, "X", Xs[j])
+		t.AppendPoint(curve, "T", Ts[j])
+	}
+	return t.ChallengeScalar(curve, "c")
+}
+
+// MarshalBinary encodes the proof as the length-prefixed curve name followed
+// by the challenges and then the responses.
+func (p *ORProof) MarshalBinary() ([]byte, error) {
+	if len(p.C) == 0 || len(p.C) != len(p.S) {
+		return nil, errFormat
+	}
+	return marshalScalars(p.Curve, append(append([]*big.Int{}, p.C...), p.S...)...)
+}
+
+// UnmarshalBinary decodes a proof encoded by MarshalBinary.
+func (p *ORProof) UnmarshalBinary(data []byte) error {
+	curve, k, err := unmarshalScalars(data, 0)
+	if err != nil {
+		return err
+	}
+	n := len(k) / 2
+	*p = ORProof{Curve: curve, C: k[:n], S: k[n:]}
+	return nil
+}
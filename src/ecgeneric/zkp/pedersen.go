@@ -0,0 +1,97 @@
+package zkp
+
+import (
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/gost"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/h2c"
+)
+
+// pedersenDST is the hash-to-curve domain separation tag for H.
+const pedersenDST = "zkp/pedersen/H"
+
+// Pedersen holds the parameters of Pedersen commitments C = v·G + r·H on a
+// curve, where G is the curve's base point.
+//
+// H is hashed to the curve from the curve name and G with RFC 9380
+// hash_to_curve over Streebog, so nobody knows its discrete logarithm to the
+// base G and a commitment binds its value.
+type Pedersen struct {
+	Curve *ecgeneric.CurveParams
+	H     ecgeneric.Point
+}
+
+// NewPedersen returns the commitment parameters for curve.
+func NewPedersen(curve *ecgeneric.CurveParams) (*Pedersen, error) {
+	suite, err := h2c.NewSuite(curve, gost.SDSAHash(curve), 0)
+	if err != nil {
+		return nil, err
+	}
+	msg := append([]byte(curve.Name), ecgeneric.Marshal(curve, curve.Gx, curve.Gy)...)
+	H, err := suite.HashToCurve(msg, []byte(pedersenDST))
+	if err != nil {
+		return nil, err
+	}
+	if !validPoint(curve, H) {
+		return nil, errors.New("zkp: no second generator for curve")
+	}
+	return &Pedersen{Curve: curve, H: H}, nil
+}
+
+// Commit returns v·G + r·H.
+func (pp *Pedersen) Commit(v, r *big.Int) ecgeneric.Point {
+	return combine(pp.Curve, generator(pp.Curve), v, pp.H, r)
+}
+
+// CommitRandom commits to v with a fresh blinding factor r.
+func (pp *Pedersen) CommitRandom(rand io.Reader, v *big.Int) (C ecgeneric.Point, r *big.Int, err error) {
+	if r, err = randomScalar(rand, pp.Curve); err != nil {
+		return ecgeneric.Point{}, nil, err
+	}
+	return pp.Commit(v, r), r, nil
+}
+
+// Open reports whether C is a commitment to v with blinding factor r.
+func (pp *Pedersen) Open(C ecgeneric.Point, v, r *big.Int) bool {
+	if C.X == nil || C.Y == nil {
+		return false
+	}
+	D := pp.Commit(v, r)
+	return D.X.Cmp(C.X) == 0 && D.Y.Cmp(C.Y) == 0
+}
+
+// Add returns the commitment to the sum of the values, and of the blinding
+// factors, of a and b.
+func (pp *Pedersen) Add(a, b ecgeneric.Point) ecgeneric.Point {
+	x, y := pp.Curve.AddJ(a.X, a.Y, b.X, b.Y)
+	return ecgeneric.Point{X: x, Y: y}
+}
+
+// ProveBit proves that C = b·G + r·H commits to 0 or 1 without revealing
+// which: it is an OR-proof of knowledge of r for C = r·H or C - G = r·H.
+func (pp *Pedersen) ProveBit(rand io.Reader, t *Transcript, C ecgeneric.Point, b uint, r *big.Int) (*ORProof, error) {
+	if b > 1 {
+		return nil, errors.New("zkp: committed value is not a bit")
+	}
+	return ProveOR(rand, t, pp.Curve, pp.H, pp.bitStatements(C), int(b), r)
+}
+
+// VerifyBit reports whether p proves that C commits to 0 or 1.
+func (pp *Pedersen) VerifyBit(t *Transcript, C ecgeneric.Point, p *ORProof) bool {
+	if !validPoint(pp.Curve, C) {
+		return false
+	}
+	return VerifyOR(t, pp.Curve, pp.H, pp.bitStatements(C), p)
+}
+
+func (pp *Pedersen) bitStatements(C ecgeneric.Point) []ecgeneric.Point {
+	curve := pp.Curve
+	if C.X == nil || C.Y == nil {
+		return []ecgeneric.Point{C, C}
+	}
+	x, y := curve.AddJ(C.X, C.Y, curve.Gx, new(big.Int).Sub(curve.P, curve.Gy))
+	return []ecgeneric.Point{C, {X: x, Y: y}}
+}
@@ -0,0 +1,143 @@
+package zkp
+
+import (
+	"io"
+	"math/big"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+)
+
+// DLogProof is a Schnorr proof of knowledge of x with X = x·G. With the
+// commitment T = w·G, C is the challenge and S = w + C·x mod N.
+type DLogProof struct {
+	Curve *ecgeneric.CurveParams
+	C, S  *big.Int
+}
+
+// ProveDLog proves knowledge of x for X = x·G, where G is any point of
+// prime order N; pass the curve's base point for an ordinary key.
+func ProveDLog(rand io.Reader, t *Transcript, curve *ecgeneric.CurveParams, G ecgeneric.Point, x *big.Int) (*DLogProof, error) {
+	if !validPoint(curve, G) {
+		return nil, errPoint
+	}
+	if !validScalar(curve, x) || x.Sign() == 0 {
+		return nil, errScalar
+	}
+	w, err := randomScalar(rand, curve)
+	if err != nil {
+		return nil, err
+	}
+	c := dlogChallenge(orNew(t), curve, G, mul(curve, G, x), mul(curve, G, w))
+	s := new(big.Int).Mul(c, x)
+	s.Add(s, w)
+	return &DLogProof{Curve: curve, C: c, S: s.Mod(s, curve.N)}, nil
+}
+
+// VerifyDLog reports whether p proves knowledge of the discrete logarithm of
+// X to the base G.
+func VerifyDLog(t *Transcript, curve *ecgeneric.CurveParams, G, X ecgeneric.Point, p *DLogProof) bool {
+	if p == nil || !sameCurve(p.Curve, curve) || !validScalar(curve, p.C) || !validScalar(curve, p.S) {
+		return false
+	}
+	if !validPoint(curve, G) || !validPoint(curve, X) {
+		return false
+	}
+	T := combine(curve, G, p.S, X, neg(curve, p.C))
+	return dlogChallenge(orNew(t), curve, G, X, T).Cmp(p.C) == 0
+}
+
+func dlogChallenge(t *Transcript, curve *ecgeneric.CurveParams, G, X, T ecgeneric.Point) *big.Int {
+	t.AppendMessage("zkp/proof", []byte("dlog"))
+	t.AppendMessage("curve", []byte(curve.Name))
+	t.AppendPoint(curve, "G", G)
+	t.AppendPoint(curve, "X", X)
+	t.AppendPoint(curve, "T", T)
+	return t.ChallengeScalar(curve, "c")
+}
+
+// MarshalBinary encodes the proof as the length-prefixed curve name, C and
+// S.
+func (p *DLogProof) MarshalBinary() ([]byte, error) {
+	return marshalScalars(p.Curve, p.C, p.S)
+}
+
+// UnmarshalBinary decodes a proof encoded by MarshalBinary.
+func (p *DLogProof) UnmarshalBinary(data []byte) error {
+	curve, k, err := unmarshalScalars(data, 2)
+	if err != nil {
+		return err
+	}
+	*p = DLogProof{Curve: curve, C: k[0], S: k[1]}
+	return nil
+}
+
+// DLEQProof is a Chaum–Pedersen proof that X = x·G and Y = x·H for the same
+// x. With the commitments w·G and w·H, C is the challenge and
+// S = w + C·x mod N.
+type DLEQProof struct {
+	Curve *ecgeneric.CurveParams
+	C, S  *big.Int
+}
+
+// ProveDLEQ proves that x·G and x·H share the discrete logarithm x.
+func ProveDLEQ(rand io.Reader, t *Transcript, curve *ecgeneric.CurveParams, G, H ecgeneric.Point, x *big.Int) (*DLEQProof, error) {
+	if !validPoint(curve, G) || !validPoint(curve, H) {
+		return nil, errPoint
+	}
+	if !validScalar(curve, x) || x.Sign() == 0 {
+		return nil, errScalar
+	}
+	w, err := randomScalar(rand, curve)
+	if err != nil {
+		return nil, err
+	}
+	c := dleqChallenge(orNew(t), curve, G, mul(curve, G, x), H, mul(curve, H, x), mul(curve, G, w), mul(curve, H, w))
+	s := new(big.Int).Mul(c, x)
+	s.Add(s, w)
+	return &DLEQProof{Curve: curve, C: c, S: s.Mod(s, curve.N)}, nil
+}
+
+// VerifyDLEQ reports whether p proves that X and Y have the same discrete
+// logarithm to the bases G and H.
+func VerifyDLEQ(t *Transcript, curve *ecgeneric.CurveParams, G, X, H, Y ecgeneric.Point, p *DLEQProof) bool {
+	if p == nil || !sameCurve(p.Curve, curve) || !validScalar(curve, p.C) || !validScalar(curve, p.S) {
+		return false
+	}
+	for _, q := range []ecgeneric.Point{G, X, H, Y} {
+		if !validPoint(curve, q) {
+			return false
+		}
+	}
+	negC := neg(curve, p.C)
+	A := combine(curve, G, p.S, X, negC)
+	B := combine(curve, H, p.S, Y, negC)
+	return dleqChallenge(orNew(t), curve, G, X, H, Y, A, B).Cmp(p.C) == 0
+}
+
+func dleqChallenge(t *Transcript, curve *ecgeneric.CurveParams, G, X, H, Y, A, B ecgeneric.Point) *big.Int {
+	t.AppendMessage("zkp/proof", []byte("dleq"))
+	t.AppendMessage("curve", []byte(curve.Name))
+	t.AppendPoint(curve, "G", G)
+	t.AppendPoint(curve, "X", X)
+	t.AppendPoint(curve, "H", H)
+	t.AppendPoint(curve, "Y", Y)
+	t.AppendPoint(curve, "A", A)
+	t.AppendPoint(curve, "B", B)
+	return t.ChallengeScalar(curve, "c")
+}
+
+// MarshalBinary encodes the proof as the length-prefixed curve name, C and
+// S.
+func (p *DLEQProof) MarshalBinary() ([]byte, error) {
+	return marshalScalars(p.Curve, p.C, p.S)
+}
+
+// UnmarshalBinary decodes a proof encoded by MarshalBinary.
+func (p *DLEQProof) UnmarshalBinary(data []byte) error {
+	curve, k, err := unmarshalScalars(data, 2)
+	if err != nil {
+		return err
+	}
+	*p = DLEQProof{Curve: curve, C: k[0], S: k[1]}
+	return nil
+}
@@ -0,0 +1,135 @@
+// Package zkp implements Pedersen commitments and Schnorr-style
+// zero-knowledge proofs over ecgeneric curves: proofs of knowledge of a
+// discrete logarithm, Chaum–Pedersen proofs of equal discrete logarithms and
+// Cramer–Damgård–Schoenmakers OR-proofs, which show knowledge of one of
+// several discrete logarithms without revealing which.
+//
+// The proofs are made non-interactive with the Fiat–Shamir transform. Prover
+// and verifier feed the same public data into a Transcript, from which the
+// challenges are drawn, so a proof is bound to everything appended before
+// it: a protocol label, session identifiers, earlier proofs.
+//
+// Proofs are in the compact (challenge, response) form. Each records its
+// curve, which must be registered with ecgeneric.RegisterCurve for the proof
+// to be serialized.
+package zkp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/transcript"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/vss"
+)
+
+var (
+	errPoint  = errors.New("zkp: point is not on the curve")
+	errScalar = errors.New("zkp: scalar out of range")
+	errFormat = errors.New("zkp: malformed proof")
+)
+
+// Transcript is the Fiat–Shamir transcript proofs are bound to.
+type Transcript = transcript.Transcript
+
+// NewTranscript returns a transcript on the Streebog backend for the
+// protocol named by label.
+func NewTranscript(label string) *Transcript {
+	return transcript.New(transcript.Streebog, label)
+}
+
+func orNew(t *Transcript) *Transcript {
+	if t == nil {
+		return NewTranscript("")
+	}
+	return t
+}
+
+func generator(curve *ecgeneric.CurveParams) ecgeneric.Point {
+	return ecgeneric.Point{X: curve.Gx, Y: curve.Gy}
+}
+
+func isInfinity(p ecgeneric.Point) bool {
+	return p.X.Sign() == 0 && p.Y.Sign() == 0
+}
+
+func validPoint(curve *ecgeneric.CurveParams, p ecgeneric.Point) bool {
+	return p.X != nil && p.Y != nil && !isInfinity(p) && curve.IsOnCurve(p.X, p.Y)
+}
+
+func validScalar(curve *ecgeneric.CurveParams, k *big.Int) bool {
+	return k != nil && k.Sign() >= 0 && k.Cmp(curve.N) < 0
+}
+
+func sameCurve(a, b *ecgeneric.CurveParams) bool {
+	return a != nil && b != nil && (a == b || a.Name == b.Name)
+}
+
+func mul(curve *ecgeneric.CurveParams, p ecgeneric.Point, k *big.Int) ecgeneric.Point {
+	x, y := curve.ScalarMultJ(p.X, p.Y, new(big.Int).Mod(k, curve.N).Bytes())
+	return ecgeneric.Point{X: x, Y: y}
+}
+
+// combine returns a·P + b·Q.
+func combine(curve *ecgeneric.CurveParams, P ecgeneric.Point, a *big.Int, Q ecgeneric.Point, b *big.Int) ecgeneric.Point {
+	x, y := curve.MultiScalarMult([]ecgeneric.Point{P, Q}, []*big.Int{a, b})
+	return ecgeneric.Point{X: x, Y: y}
+}
+
+func neg(curve *ecgeneric.CurveParams, k *big.Int) *big.Int {
+	r := new(big.Int).Mod(k, curve.N)
+	return r.Mod(r.Sub(curve.N, r), curve.N)
+}
+
+func randomScalar(rand io.Reader, curve *ecgeneric.CurveParams) (*big.Int, error) {
+	return vss.RandomScalar(rand, curve)
+}
+
+// marshalScalars encodes the length-prefixed curve name followed by the
+// scalars at the byte length of N.
+func marshalScalars(curve *ecgeneric.CurveParams, scalars ...*big.Int) ([]byte, error) {
+	if curve == nil || curve.Name == "" || len(curve.Name) > 255 {
+		return nil, errors.New("zkp: curve needs a registered name")
+	}
+	b := append([]byte{byte(len(curve.Name))}, curve.Name...)
+	size := (curve.N.BitLen() + 7) / 8
+	for _, k := range scalars {
+		if !validScalar(curve, k) {
+			return nil, errScalar
+		}
+		b = append(b, k.FillBytes(make([]byte, size))...)
+	}
+	return b, nil
+}
+
+// unmarshalScalars decodes the output of marshalScalars. If n is zero it
+// accepts any positive, even number of scalars.
+func unmarshalScalars(data []byte, n int) (*ecgeneric.CurveParams, []*big.Int, error) {
+	if len(data) < 1 || len(data) < 1+int(data[0]) {
+		return nil, nil, errFormat
+	}
+	name := string(data[1 : 1+data[0]])
+	data = data[1+len(name):]
+	curve, ok := ecgeneric.CurveByName(name)
+	if !ok {
+		return nil, nil, fmt.Errorf("zkp: unknown curve %q", name)
+	}
+	size := (curve.N.BitLen() + 7) / 8
+	if len(data)%size != 0 {
+		return nil, nil, errFormat
+	}
+	count := len(data) / size
+	if (n > 0 && count != n) || (n == 0 && (count == 0 || count%2 != 0)) {
+		return nil, nil, errFormat
+	}
+	scalars := make([]*big.Int, count)
+	for i := range scalars {
+		scalars[i] = new(big.Int).SetBytes(data[i*size : (i+1)*size])
+		if !validScalar(curve, scalars[i]) {
+			return nil, nil, errScalar
+		}
+	}
+	return curve, scalars, nil
+}
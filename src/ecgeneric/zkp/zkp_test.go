@@ -0,0 +1,177 @@
+package zkp_test
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/gost"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/nist"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/vss"
+	"github.com/pavelkrolevets/gost-elliptic/src/ecgeneric/zkp"
+	"github.com/stretchr/testify/require"
+)
+
+var curves = []*ecgeneric.CurveParams{
+	&nist.Secp256k1,
+	&gost.Gost34102001paramSetA,
+	&gost.Gost341012512paramSetA,
+}
+
+func scalar(t *testing.T, curve *ecgeneric.CurveParams) *big.Int {
+	k, err := vss.RandomScalar(rand.Reader, curve)
+	require.NoError(t, err)
+	return k
+}
+
+func point(curve *ecgeneric.CurveParams, k *big.Int) ecgeneric.Point {
+	x, y := curve.ScalarBaseMultJ(k.Bytes())
+	return ecgeneric.Point{X: x, Y: y}
+}
+
+func TestPedersen(t *testing.T) {
+	for _, curve := range curves {
+		pp, err := zkp.NewPedersen(curve)
+		require.NoError(t, err)
+		require.True(t, curve.IsOnCurve(pp.H.X, pp.H.Y), curve.Name)
+		// H is a fixed function of the curve.
+		again, err := zkp.NewPedersen(curve)
+		require.NoError(t, err)
+		require.Equal(t, pp.H, again.H)
+
+		v1, v2 := big.NewInt(30), big.NewInt(12)
+		C1, r1, err := pp.CommitRandom(rand.Reader, v1)
+		require.NoError(t, err)
+		C2, r2, err := pp.CommitRandom(rand.Reader, v2)
+		require.NoError(t, err)
+		require.True(t, pp.Open(C1, v1, r1), curve.Name)
+		require.False(t, pp.Open(C1, v2, r1), curve.Name)
+		require.False(t, pp.Open(C1, v1, r2), curve.Name)
+
+		// Commitments add homomorphically.
+		sum := pp.Add(C1, C2)
+		require.True(t, pp.Open(sum, big.NewInt(42), new(big.Int).Add(r1, r2)), curve.Name)
+	}
+}
+
+func TestDLog(t *testing.T) {
+	for _, curve := range curves {
+		G := ecgeneric.Point{X: curve.Gx, Y: curve.Gy}
+		x := scalar(t, curve)
+		X := point(curve, x)
+		p, err := zkp.ProveDLog(rand.Reader, zkp.NewTranscript("test"), curve, G, x)
+		require.NoError(t, err)
+		require.True(t, zkp.VerifyDLog(zkp.NewTranscript("test"), curve, G, X, p), curve.Name)
+		require.False(t, zkp.VerifyDLog(zkp.NewTranscript("other"), curve, G, X, p), curve.Name)
+		require.False(t, zkp.VerifyDLog(zkp.NewTranscript("test"), curve, G, point(curve, scalar(t, curve)), p), curve.Name)
+
+		b, err := p.MarshalBinary()
+		require.NoError(t, err)
+		var q zkp.DLogProof
+		require.NoError(t, q.UnmarshalBinary(b))
+		require.True(t, zkp.VerifyDLog(zkp.NewTranscript("test"), curve, G, X, &q), curve.Name)
+		require.Error(t, q.UnmarshalBinary(b[:len(b)-1]))
+
+		// The transcript binds context added before the proof.
+		tr := zkp.NewTranscript("test")
+		tr.AppendMessage("session", []byte{1})
+		p, err = zkp.ProveDLog(rand.Reader, tr, curve, G, x)
+		require.NoError(t, err)
+		tr = zkp.NewTranscript("test")
+		tr.AppendMessage("session", []byte{2})
+		require.False(t, zkp.VerifyDLog(tr, curve, G, X, p), curve.Name)
+	}
+}
+
+func TestDLEQ(t *testing.T) {
+	for _, curve := range curves {
+		pp, err := zkp.NewPedersen(curve)
+		require.NoError(t, err)
+		G := ecgeneric.Point{X: curve.Gx, Y: curve.Gy}
+		H := pp.H
+		x := scalar(t, curve)
+		X := point(curve, x)
+		yx, yy := curve.ScalarMultJ(H.X, H.Y, x.Bytes())
+		Y := ecgeneric.Point{X: yx, Y: yy}
+
+		p, err := zkp.ProveDLEQ(rand.Reader, nil, curve, G, H, x)
+		require.NoError(t, err)
+		require.True(t, zkp.VerifyDLEQ(nil, curve, G, X, H, Y, p), curve.Name)
+		// Y with another discrete logarithm is rejected.
+		zx, zy := curve.ScalarMultJ(H.X, H.Y, scalar(t, curve).Bytes())
+		require.False(t, zkp.VerifyDLEQ(nil, curve, G, X, H, ecgeneric.Point{X: zx, Y: zy}, p), curve.Name)
+
+		b, err := p.MarshalBinary()
+		require.NoError(t, err)
+		var q zkp.DLEQProof
+		require.NoError(t, q.UnmarshalBinary(b))
+		require.True(t, zkp.VerifyDLEQ(nil, curve, G, X, H, Y, &q), curve.Name)
+	}
+}
+
+func TestOR(t *testing.T) {
+	for _, curve := range curves {
+		G := ecgeneric.Point{X: curve.Gx, Y: curve.Gy}
+		xs := make([]*big.Int, 4)
+		Xs := make([]ecgeneric.Point, 4)
+		for i := range xs {
+			xs[i] = scalar(t, curve)
+			Xs[i] = point(curve, xs[i])
+		}
+		for i := range xs {
+			p, err := zkp.ProveOR(rand.Reader, zkp.NewTranscript("or"), curve, G, Xs, i, xs[i])
+			require.NoError(t, err)
+			require.True(t, zkp.VerifyOR(zkp.NewTranscript("or"), curve, G, Xs, p), curve.Name)
+
+			b, err := p.MarshalBinary()
+			require.NoError(t, err)
+			var q zkp.ORProof
+			require.NoError(t, q.UnmarshalBinary(b))
+			require.True(t, zkp.VerifyOR(zkp.NewTranscript("or"), curve, G, Xs, &q), curve.Name)
+
+			swapped := append([]ecgeneric.Point{}, Xs...)
+			swapped[0], swapped[3] = swapped[3], swapped[0]
+			require.False(t, zkp.VerifyOR(zkp.NewTranscript("or"), curve, G, swapped, p), curve.Name)
+		}
+
+		// A witness for none of the points does not give a proof.
+		p, err := zkp.ProveOR(rand.Reader, nil, curve, G, Xs, 1, scalar(t, curve))
+		require.NoError(t, err)
+		require.False(t, zkp.VerifyOR(nil, curve, G, Xs, p), curve.Name)
+	}
+}
+
+func TestBitProof(t *testing.T) {
+	for _, curve := range curves {
+		pp, err := zkp.NewPedersen(curve)
+		require.NoError(t, err)
+		for b := uint(0); b < 2; b++ {
+			C, r, err := pp.CommitRandom(rand.Reader, big.NewInt(int64(b)))
+			require.NoError(t, err)
+			p, err := pp.ProveBit(rand.Reader, zkp.NewTranscript("bit"), C, b, r)
+			require.NoError(t, err)
+			require.True(t, pp.VerifyBit(zkp.NewTranscript("bit"), C, p), curve.Name)
+		}
+
+		C, r, err := pp.CommitRandom(rand.Reader, big.NewInt(2))
+		require.NoError(t, err)
+		for b := uint(0); b < 2; b++ {
+			p, err := pp.ProveBit(rand.Reader, zkp.NewTranscript("bit"), C, b, r)
+			require.NoError(t, err)
+			require.False(t, pp.VerifyBit(zkp.NewTranscript("bit"), C, p), curve.Name)
+		}
+		_, err = pp.ProveBit(rand.Reader, nil, C, 2, r)
+		require.Error(t, err)
+	}
+}
+
+func TestCurveMismatch(t *testing.T) {
+	curve := &nist.Secp256k1
+	G := ecgeneric.Point{X: curve.Gx, Y: curve.Gy}
+	x := scalar(t, curve)
+	p, err := zkp.ProveDLog(rand.Reader, nil, curve, G, x)
+	require.NoError(t, err)
+	p.Curve = &gost.Gost34102001paramSetA
+	require.False(t, zkp.VerifyDLog(nil, curve, G, point(curve, x), p))
+}